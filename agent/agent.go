@@ -0,0 +1,245 @@
+// Package agent implements local keys agent. Agent reads keys and secret once,
+// keeps them in memory and serves crypto operations over unix socket,
+// so GUI and other client tools don't read key files and don't need secret.
+package agent
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/crypto/cryptoblock"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SocketFile name of agent socket in keys folder
+const SocketFile = "agent.sock"
+
+// Agent operations
+const (
+	opInfo       = "info"
+	opEncrypt    = "encrypt"
+	opDecrypt    = "decrypt"
+	opSign       = "sign"
+	opVerify     = "verify"
+	opPublicKey  = "public-key"
+	opSymEncrypt = "sym-encrypt"
+	opSymDecrypt = "sym-decrypt"
+	opLock       = "lock"
+)
+
+var (
+	ErrLocked        = errors.New("keys agent is locked")
+	ErrUnknownOp     = errors.New("unknown keys agent operation")
+	ErrPeer          = errors.New("keys agent accepts connections only from the same user")
+	ErrSocketDir     = errors.New("folder of agent socket must be accessible only by owner")
+	ErrAgentIsActive = errors.New("keys agent is already listening on socket")
+)
+
+// request operation of agent, algorithm is empty for operations of main algorithm and sym crypt
+type request struct {
+	Op        string `json:"op"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Payload   string `json:"payload,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      []byte `json:"data,omitempty"`
+}
+
+// response result of agent operation
+type response struct {
+	Result string `json:"result,omitempty"`
+	Data   []byte `json:"data,omitempty"`
+	OK     bool   `json:"ok,omitempty"`
+	Info   *Info  `json:"info,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Info describes keys of agent
+type Info struct {
+	Algorithm string `json:"algorithm"`
+	KeysPath  string `json:"keys_path"`
+}
+
+// Agent keeps unlocked keys in memory until idle timeout
+type Agent struct {
+	mu          sync.Mutex
+	cryptorizer *crypto.Cryptorizer
+	symCrypto   *cryptoblock.AEADCrypto
+	keysPath    string
+	idle        time.Duration
+	timer       *time.Timer
+	listener    net.Listener
+}
+
+// New initializer of Agent struct, keys are forgotten after idle time without requests
+func New(cryptorizer *crypto.Cryptorizer, symCrypto *cryptoblock.AEADCrypto, keysPath string, idle time.Duration) *Agent {
+	return &Agent{
+		cryptorizer: cryptorizer,
+		symCrypto:   symCrypto,
+		keysPath:    keysPath,
+		idle:        idle,
+	}
+}
+
+// Listen creates agent socket, folder of socket must be accessible only by owner
+// and socket itself is readable only by owner
+func Listen(socketPath string) (net.Listener, error) {
+	info, statErr := os.Stat(filepath.Dir(socketPath))
+	if statErr != nil {
+		return nil, statErr
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return nil, ErrSocketDir
+	}
+	if _, socketErr := os.Stat(socketPath); socketErr == nil {
+		// socket could be left by agent that was killed
+		if conn, dialErr := net.Dial("unix", socketPath); dialErr == nil {
+			_ = conn.Close()
+			return nil, ErrAgentIsActive
+		}
+		if removeErr := os.Remove(socketPath); removeErr != nil {
+			return nil, removeErr
+		}
+	}
+	listener, listenErr := net.Listen("unix", socketPath)
+	if listenErr != nil {
+		return nil, listenErr
+	}
+	if chmodErr := os.Chmod(socketPath, 0600); chmodErr != nil {
+		_ = listener.Close()
+		return nil, chmodErr
+	}
+	return listener, nil
+}
+
+// Serve accepts connections until agent is locked by request or idle timeout
+func (a *Agent) Serve(listener net.Listener) error {
+	a.mu.Lock()
+	a.listener = listener
+	a.timer = time.AfterFunc(a.idle, a.Lock)
+	a.mu.Unlock()
+	for {
+		conn, acceptErr := listener.Accept()
+		if acceptErr != nil {
+			if a.locked() {
+				return nil
+			}
+			return acceptErr
+		}
+		go a.handle(conn)
+	}
+}
+
+// Lock forgets keys and stops agent
+func (a *Agent) Lock() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cryptorizer = nil
+	a.symCrypto = nil
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	if a.listener != nil {
+		_ = a.listener.Close()
+	}
+}
+
+func (a *Agent) locked() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cryptorizer == nil
+}
+
+// handle serves requests of one connection
+func (a *Agent) handle(conn net.Conn) {
+	defer conn.Close()
+	if peerErr := checkPeer(conn); peerErr != nil {
+		log.Println(peerErr)
+		return
+	}
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var req request
+		if decodeErr := decoder.Decode(&req); decodeErr != nil {
+			if !errors.Is(decodeErr, io.EOF) {
+				log.Println(decodeErr)
+			}
+			return
+		}
+		resp := a.do(&req)
+		if encodeErr := encoder.Encode(resp); encodeErr != nil {
+			log.Println(encodeErr)
+			return
+		}
+		if req.Op == opLock {
+			a.Lock()
+			return
+		}
+	}
+}
+
+// do runs operation, every operation delays idle timeout
+func (a *Agent) do(req *request) *response {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cryptorizer == nil {
+		return &response{Error: ErrLocked.Error()}
+	}
+	a.timer.Reset(a.idle)
+
+	switch req.Op {
+	case opInfo:
+		return &response{Info: &Info{Algorithm: a.cryptorizer.Algorithm, KeysPath: a.keysPath}}
+	case opLock:
+		return &response{OK: true}
+	case opSymEncrypt:
+		data, encryptErr := a.symCrypto.Encrypt(req.Data)
+		return dataResponse(data, encryptErr)
+	case opSymDecrypt:
+		data, decryptErr := a.symCrypto.Decrypt(req.Data)
+		return dataResponse(data, decryptErr)
+	}
+
+	workCrypto, workCryptoErr := a.cryptorizer.CryptoFor(req.Algorithm)
+	if workCryptoErr != nil {
+		return &response{Error: workCryptoErr.Error()}
+	}
+	switch req.Op {
+	case opEncrypt:
+		result, encryptErr := workCrypto.Encrypt(req.Payload)
+		return resultResponse(result, encryptErr)
+	case opDecrypt:
+		result, decryptErr := workCrypto.Decrypt(req.Payload)
+		return resultResponse(result, decryptErr)
+	case opSign:
+		result, signErr := workCrypto.Sign(req.Payload)
+		return resultResponse(result, signErr)
+	case opVerify:
+		return &response{OK: workCrypto.Verify(req.Payload, req.Signature)}
+	case opPublicKey:
+		data, publicKeyErr := workCrypto.PublicKey()
+		return dataResponse(data, publicKeyErr)
+	}
+	return &response{Error: fmt.Sprintf("%s: %s", ErrUnknownOp, req.Op)}
+}
+
+func resultResponse(result string, err error) *response {
+	if err != nil {
+		return &response{Error: err.Error()}
+	}
+	return &response{Result: result}
+}
+
+func dataResponse(data []byte, err error) *response {
+	if err != nil {
+		return &response{Error: err.Error()}
+	}
+	return &response{Data: data}
+}
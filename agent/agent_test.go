@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/crypto/cryptoblock"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startAgent(t *testing.T, idle time.Duration) (*crypto.Cryptorizer, string, chan error) {
+	keysPath := t.TempDir()
+	assert.NoError(t, os.Chmod(keysPath, 0700))
+	cryptorizer, cryptorizerErr := crypto.InitCryptorizer(keysPath, 1024, crypto.AlgorithmECC)
+	assert.NoError(t, cryptorizerErr)
+	socketPath := filepath.Join(keysPath, SocketFile)
+	listener, listenErr := Listen(socketPath)
+	assert.NoError(t, listenErr)
+	served := make(chan error, 1)
+	keysAgent := New(cryptorizer, cryptoblock.InitAEADCrypto("secret"), keysPath, idle)
+	go func() {
+		served <- keysAgent.Serve(listener)
+	}()
+	return cryptorizer, socketPath, served
+}
+
+func TestAgent(t *testing.T) {
+	local, socketPath, served := startAgent(t, time.Minute)
+
+	_, listenErr := Listen(socketPath)
+	assert.ErrorIs(t, listenErr, ErrAgentIsActive)
+
+	client, dialErr := Dial(socketPath)
+	assert.NoError(t, dialErr)
+	info, infoErr := client.Info()
+	assert.NoError(t, infoErr)
+	assert.Equal(t, crypto.AlgorithmECC, info.Algorithm)
+
+	remote, remoteErr := crypto.InitRemoteCryptorizer(info.Algorithm, client.Crypto)
+	assert.NoError(t, remoteErr)
+	cipher, encryptErr := remote.Encrypt("card number")
+	assert.NoError(t, encryptErr)
+	plain, decryptErr := local.Decrypt(cipher)
+	assert.NoError(t, decryptErr)
+	assert.Equal(t, "card number", plain)
+	signature, signErr := remote.Sign("payload")
+	assert.NoError(t, signErr)
+	assert.True(t, local.Verify("payload", signature))
+	assert.False(t, remote.Verify("other payload", signature))
+	_, decryptErr = remote.Decrypt("rsa:AAAA")
+	assert.ErrorIs(t, decryptErr, crypto.ErrNoKeys)
+
+	sym := client.SymCrypto()
+	sealed, sealErr := sym.Encrypt([]byte("file"))
+	assert.NoError(t, sealErr)
+	opened, openErr := cryptoblock.InitAEADCrypto("secret").Decrypt(sealed)
+	assert.NoError(t, openErr)
+	assert.Equal(t, "file", string(opened))
+
+	assert.NoError(t, client.Lock())
+	assert.NoError(t, <-served)
+	_, dialErr = Dial(socketPath)
+	assert.Error(t, dialErr)
+}
+
+func TestAgentIdle(t *testing.T) {
+	_, socketPath, served := startAgent(t, 50*time.Millisecond)
+	select {
+	case serveErr := <-served:
+		assert.NoError(t, serveErr)
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent wasn't locked after idle timeout")
+	}
+	_, dialErr := Dial(socketPath)
+	assert.Error(t, dialErr)
+}
@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/crypto/cryptoblock"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// callTimeout timeout of one agent operation
+const callTimeout = 10 * time.Second
+
+// knownErrors errors that are restored from agent responses, so callers could check them with errors.Is
+var knownErrors = []error{ErrLocked, crypto.ErrNoKeys, crypto.ErrUnknownAlgorithm}
+
+// Client connection to keys agent, it is safe for concurrent use
+type Client struct {
+	mu         sync.Mutex
+	socketPath string
+	conn       net.Conn
+	encoder    *json.Encoder
+	decoder    *json.Decoder
+}
+
+// Dial connects to agent and checks that it is unlocked
+func Dial(socketPath string) (*Client, error) {
+	client := &Client{socketPath: socketPath}
+	if _, infoErr := client.Info(); infoErr != nil {
+		client.Close()
+		return nil, infoErr
+	}
+	return client, nil
+}
+
+// Info returns algorithm and keys folder of agent
+func (c *Client) Info() (*Info, error) {
+	resp, callErr := c.call(&request{Op: opInfo})
+	if callErr != nil {
+		return nil, callErr
+	}
+	return resp.Info, nil
+}
+
+// Crypto returns crypto of algorithm that is served by agent, it returns crypto.ErrNoKeys
+// if agent has no keys for algorithm
+func (c *Client) Crypto(algorithm string) (crypto.Crypto, error) {
+	if _, callErr := c.call(&request{Op: opPublicKey, Algorithm: algorithm}); callErr != nil {
+		return nil, callErr
+	}
+	return &remoteCrypto{client: c, algorithm: algorithm}, nil
+}
+
+// SymCrypto returns sym crypt of files that is served by agent
+func (c *Client) SymCrypto() cryptoblock.SymCrypto {
+	return &remoteSymCrypto{client: c}
+}
+
+// Lock makes agent forget keys and stop
+func (c *Client) Lock() error {
+	_, callErr := c.call(&request{Op: opLock})
+	c.Close()
+	return callErr
+}
+
+// Close closes connection to agent
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disconnect()
+}
+
+// call sends request to agent, connection is reopened once if it was broken
+func (c *Client) call(req *request) (*response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, callErr := c.roundTrip(req)
+	if callErr != nil {
+		c.disconnect()
+		resp, callErr = c.roundTrip(req)
+		if callErr != nil {
+			c.disconnect()
+			return nil, callErr
+		}
+	}
+	if resp.Error != "" {
+		return nil, remoteError(resp.Error)
+	}
+	return resp, nil
+}
+
+func (c *Client) roundTrip(req *request) (*response, error) {
+	if c.conn == nil {
+		conn, dialErr := net.Dial("unix", c.socketPath)
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		c.conn, c.encoder, c.decoder = conn, json.NewEncoder(conn), json.NewDecoder(conn)
+	}
+	if deadlineErr := c.conn.SetDeadline(time.Now().Add(callTimeout)); deadlineErr != nil {
+		return nil, deadlineErr
+	}
+	if encodeErr := c.encoder.Encode(req); encodeErr != nil {
+		return nil, encodeErr
+	}
+	var resp response
+	if decodeErr := c.decoder.Decode(&resp); decodeErr != nil {
+		return nil, decodeErr
+	}
+	return &resp, nil
+}
+
+func (c *Client) disconnect() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn, c.encoder, c.decoder = nil, nil, nil
+	}
+}
+
+// remoteError restores known error from its message
+func remoteError(message string) error {
+	for _, known := range knownErrors {
+		if known.Error() == message {
+			return known
+		}
+	}
+	return errors.New(message)
+}
+
+// remoteCrypto implements crypto.Crypto by agent operations of one algorithm
+type remoteCrypto struct {
+	client    *Client
+	algorithm string
+}
+
+// Verify check sign on payload
+func (r *remoteCrypto) Verify(payload string, signature64 string) bool {
+	resp, callErr := r.client.call(&request{Op: opVerify, Algorithm: r.algorithm, Payload: payload, Signature: signature64})
+	return callErr == nil && resp.OK
+}
+
+// Sign signs payload
+func (r *remoteCrypto) Sign(payload string) (string, error) {
+	resp, callErr := r.client.call(&request{Op: opSign, Algorithm: r.algorithm, Payload: payload})
+	if callErr != nil {
+		return "", callErr
+	}
+	return resp.Result, nil
+}
+
+// Encrypt cipher payload
+func (r *remoteCrypto) Encrypt(payload string) (string, error) {
+	resp, callErr := r.client.call(&request{Op: opEncrypt, Algorithm: r.algorithm, Payload: payload})
+	if callErr != nil {
+		return "", callErr
+	}
+	return resp.Result, nil
+}
+
+// Decrypt deciphers payload
+func (r *remoteCrypto) Decrypt(payload string) (string, error) {
+	resp, callErr := r.client.call(&request{Op: opDecrypt, Algorithm: r.algorithm, Payload: payload})
+	if callErr != nil {
+		return "", callErr
+	}
+	return resp.Result, nil
+}
+
+// PublicKey returns public key of algorithm
+func (r *remoteCrypto) PublicKey() ([]byte, error) {
+	resp, callErr := r.client.call(&request{Op: opPublicKey, Algorithm: r.algorithm})
+	if callErr != nil {
+		return nil, callErr
+	}
+	return resp.Data, nil
+}
+
+// remoteSymCrypto implements cryptoblock.SymCrypto by agent operations
+type remoteSymCrypto struct {
+	client *Client
+}
+
+// Encrypt cipher payload with secret of agent
+func (r *remoteSymCrypto) Encrypt(payload []byte) ([]byte, error) {
+	resp, callErr := r.client.call(&request{Op: opSymEncrypt, Data: payload})
+	if callErr != nil {
+		return nil, callErr
+	}
+	return resp.Data, nil
+}
+
+// Decrypt deciphers payload with secret of agent
+func (r *remoteSymCrypto) Decrypt(text []byte) ([]byte, error) {
+	resp, callErr := r.client.call(&request{Op: opSymDecrypt, Data: text})
+	if callErr != nil {
+		return nil, callErr
+	}
+	return resp.Data, nil
+}
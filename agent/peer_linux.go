@@ -0,0 +1,36 @@
+//go:build linux
+
+package agent
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// checkPeer checks that process on other side of socket runs under the same user
+func checkPeer(conn net.Conn) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ErrPeer
+	}
+	rawConn, rawConnErr := unixConn.SyscallConn()
+	if rawConnErr != nil {
+		return rawConnErr
+	}
+	var cred *syscall.Ucred
+	var credErr error
+	controlErr := rawConn.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if controlErr != nil {
+		return controlErr
+	}
+	if credErr != nil {
+		return credErr
+	}
+	if int(cred.Uid) != os.Getuid() {
+		return ErrPeer
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package agent
+
+import "net"
+
+// checkPeer relies on permissions of socket and its folder,
+// credentials of peer are checked only on linux
+func checkPeer(conn net.Conn) error {
+	return nil
+}
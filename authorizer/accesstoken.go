@@ -0,0 +1,124 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrAccessTokenName = errors.New("name of personal access token is empty")
+var ErrAccessTokenScope = errors.New("unknown scope of personal access token, use <kind>:read or <kind>:write of notes, cards, creds or files")
+var ErrAccessTokenExpiry = errors.New("expiry of personal access token should be in the future and not later than a year")
+var ErrInvalidPersonalToken = errors.New("personal access token is invalid or expired")
+
+const (
+	// AccessTokenPrefix prefix of personal access tokens, it tells them from JWT
+	AccessTokenPrefix = "gkpat_"
+	// MaxAccessTokenLifetime the latest expiry of personal access token since its creation
+	MaxAccessTokenLifetime = 365 * 24 * time.Hour
+	// accessTokenTouch minimal interval of updates of last used time, so every request doesn't write database
+	accessTokenTouch = time.Minute
+)
+
+// IsAccessToken reports whether bearer token is personal access token
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// CreateAccessToken creates personal access token of user, token itself is returned only here
+func (a *Authorizer) CreateAccessToken(userID uuid.UUID, newToken *models.NewAccessToken) (*models.AccessToken, error) {
+	name := strings.TrimSpace(newToken.Name)
+	if name == "" {
+		return nil, ErrAccessTokenName
+	}
+	scopes, scopesErr := accessScopes(newToken.Scopes)
+	if scopesErr != nil {
+		return nil, scopesErr
+	}
+	now := time.Now()
+	if !newToken.Expires.After(now) || newToken.Expires.After(now.Add(MaxAccessTokenLifetime)) {
+		return nil, ErrAccessTokenExpiry
+	}
+
+	random := make([]byte, refreshTokenSize)
+	if _, randErr := rand.Read(random); randErr != nil {
+		return nil, ErrGenerateToken
+	}
+	token := &models.AccessToken{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Name:    name,
+		Scopes:  scopes,
+		Items:   newToken.Items,
+		Created: now,
+		Expires: newToken.Expires,
+		Token:   AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(random),
+	}
+	token.TokenHash = hashRefreshToken(token.Token)
+	if storeErr := a.adminDB.NewAccessToken(token); storeErr != nil {
+		return nil, storeErr
+	}
+	return token, nil
+}
+
+// AccessTokens returns personal access tokens of user that are not expired
+func (a *Authorizer) AccessTokens(userID uuid.UUID) ([]models.AccessToken, error) {
+	return a.adminDB.UserAccessTokens(userID)
+}
+
+// RevokeAccessToken removes personal access token of user, it is rejected at once.
+// storage.ErrNoValues is returned if user has no such token
+func (a *Authorizer) RevokeAccessToken(userID, id uuid.UUID) error {
+	return a.adminDB.DeleteAccessToken(userID, id)
+}
+
+// ParseAccessToken returns personal access token by its value, last used time of token is updated
+func (a *Authorizer) ParseAccessToken(value string) (*models.AccessToken, error) {
+	if !IsAccessToken(value) {
+		return nil, ErrInvalidPersonalToken
+	}
+	token, getErr := a.adminDB.GetAccessToken(hashRefreshToken(value))
+	if getErr != nil {
+		if errors.Is(getErr, storage.ErrNoValues) {
+			return nil, ErrInvalidPersonalToken
+		}
+		return nil, getErr
+	}
+	if token.LastUsed == nil || time.Since(*token.LastUsed) > accessTokenTouch {
+		if touchErr := a.adminDB.TouchAccessToken(token.ID); touchErr != nil {
+			log.Printf("last use of access token %s is not saved: %s", token.ID, touchErr)
+		}
+	}
+	return token, nil
+}
+
+// accessScopes checks scopes of personal access token and removes duplicates
+func accessScopes(scopes []string) (models.Scopes, error) {
+	known := make(map[string]bool, 2*len(models.AccessKinds))
+	for _, kind := range models.AccessKinds {
+		known[kind+":read"] = true
+		known[kind+":write"] = true
+	}
+	checked := make(models.Scopes, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if !known[scope] {
+			return nil, ErrAccessTokenScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			checked = append(checked, scope)
+		}
+	}
+	if len(checked) == 0 {
+		return nil, ErrAccessTokenScope
+	}
+	return checked, nil
+}
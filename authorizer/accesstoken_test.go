@@ -0,0 +1,95 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/passhash"
+	"AlexSarva/GophKeeper/storage/admin"
+	"AlexSarva/GophKeeper/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   models.Scopes
+		err    error
+	}{
+		{name: "read and write", scopes: []string{"creds:read", "notes:write"}, want: models.Scopes{"creds:read", "notes:write"}},
+		{name: "duplicates are removed", scopes: []string{"files:read", "files:read"}, want: models.Scopes{"files:read"}},
+		{name: "unknown kind", scopes: []string{"vault:write"}, err: ErrAccessTokenScope},
+		{name: "unknown access", scopes: []string{"cards:admin"}, err: ErrAccessTokenScope},
+		{name: "no scopes", scopes: nil, err: ErrAccessTokenScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scopes, scopesErr := accessScopes(tt.scopes)
+			assert.ErrorIs(t, scopesErr, tt.err)
+			assert.Equal(t, tt.want, scopes)
+		})
+	}
+}
+
+func TestIsAccessToken(t *testing.T) {
+	assert.True(t, IsAccessToken(AccessTokenPrefix+"abc"))
+	assert.False(t, IsAccessToken("eyJhbGciOiJFZERTQSJ9.e30.c2ln"))
+}
+
+// newTestAccount returns authorizer over admin database of test config with registered user,
+// user is deleted after test. Test is skipped if database is not available
+func newTestAccount(t *testing.T) (*Authorizer, *admin.Admin, models.User) {
+	var cfg models.ServerConfig
+	require.NoError(t, models.ReadServerJSONConfig(&cfg, "../test/test_server_config.json"))
+	conn, connErr := sqlx.Connect("postgres", cfg.AdminDatabase)
+	if connErr != nil {
+		t.Skip("admin database is not available:", connErr)
+	}
+	require.NoError(t, conn.Close())
+	adminDB := admin.NewAdminDBConnection(cfg.AdminDatabase, nil)
+	hasher, hasherErr := passhash.New(passhash.DefaultParams)
+	require.NoError(t, hasherErr)
+	keys, _ := newTestKeyRing(t, KeyRingParams{Algorithm: AlgorithmEdDSA})
+	a := NewAuthorizer(adminDB, hasher, nil, keys, time.Hour, time.Hour)
+
+	user := models.User{ID: uuid.New(), Username: utils.LoginGenerator(7), Email: utils.LoginGenerator(7) + "@gmail.com"}
+	hash, hashErr := hasher.Hash("dPQzaKPD99v")
+	require.NoError(t, hashErr)
+	user.Password = hash
+	require.NoError(t, adminDB.Register(user))
+	t.Cleanup(func() {
+		assert.NoError(t, adminDB.MarkUserDeleted(user.ID))
+		assert.NoError(t, adminDB.DeleteUser(user.ID, time.Now().Add(time.Second)))
+	})
+	return a, adminDB, user
+}
+
+func TestAccessTokenRejectedAfterReset(t *testing.T) {
+	a, adminDB, user := newTestAccount(t)
+	token, tokenErr := a.CreateAccessToken(user.ID, &models.NewAccessToken{
+		Name:    "ci",
+		Scopes:  []string{"creds:read"},
+		Expires: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, tokenErr)
+	_, parseErr := a.ParseAccessToken(token.Token)
+	require.NoError(t, parseErr)
+
+	code, codeErr := newEmailToken()
+	require.NoError(t, codeErr)
+	require.NoError(t, adminDB.NewEmailToken(&models.EmailToken{
+		TokenHash: hashEmailToken(code),
+		UserID:    user.ID,
+		Purpose:   models.EmailTokenReset,
+		Expires:   time.Now().Add(time.Hour),
+	}, EmailTokenCooldown))
+	require.NoError(t, a.ResetPassword(&models.PasswordReset{Token: code, NewPassword: "kS8vmQ2pWx4"}))
+
+	_, parseErr = a.ParseAccessToken(token.Token)
+	assert.ErrorIs(t, parseErr, ErrInvalidPersonalToken)
+}
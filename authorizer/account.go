@@ -0,0 +1,109 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrPasswordChanged = errors.New("password is changed meanwhile")
+
+// removalMargin time after the latest expiry of access tokens of deleted user when user is removed,
+// it covers sync of revocations between servers and clock skew
+const removalMargin = 5 * time.Minute
+
+// ChangePassword replaces password of user after current password is checked,
+// other sessions and personal access tokens of user are revoked, session of request stays
+func (a *Authorizer) ChangePassword(userID, sessionID uuid.UUID, change *models.PasswordChange, client *models.SessionClient) error {
+	user, checkErr := a.checkPassword(userID, change.Password, client)
+	if checkErr != nil {
+		return checkErr
+	}
+	hash, hashErr := a.hasher.Hash(change.NewPassword)
+	if hashErr != nil {
+		return ErrHashPassword
+	}
+	if setErr := a.adminDB.SetPasswordHash(userID, user.Password, hash); setErr != nil {
+		if errors.Is(setErr, storage.ErrNoValues) {
+			return ErrPasswordChanged
+		}
+		return setErr
+	}
+
+	sessions, sessionsErr := a.adminDB.UserSessions(userID)
+	if sessionsErr != nil {
+		return sessionsErr
+	}
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			continue
+		}
+		if revokeErr := a.revokeSession(userID, session.ID); revokeErr != nil {
+			return revokeErr
+		}
+	}
+	return a.adminDB.DeleteUserAccessTokens(userID)
+}
+
+// DeleteAccount marks user as deleted after password and code of second factor are checked,
+// all tokens of user are revoked at once in the same transaction. User is removed by RemoveAccount
+// after data of user is removed
+func (a *Authorizer) DeleteAccount(userID uuid.UUID, deletion *models.AccountDeletion, client *models.SessionClient) error {
+	user, checkErr := a.checkPassword(userID, deletion.Password, client)
+	if checkErr != nil {
+		return checkErr
+	}
+	twoFactor, twoFactorErr := a.twoFactorEnabled(userID)
+	if twoFactorErr != nil {
+		return twoFactorErr
+	}
+	if twoFactor {
+		if verifyErr := a.verifyCode(userID, deletion.Code); verifyErr != nil {
+			if errors.Is(verifyErr, ErrInvalidTwoFactorCode) {
+				return a.failLogin(client.IP, user.Email, verifyErr)
+			}
+			return verifyErr
+		}
+	}
+
+	return a.revocations.RevokeDeletedUser(userID)
+}
+
+// DeletedAccounts returns users that are deleted, but not removed yet
+func (a *Authorizer) DeletedAccounts() ([]uuid.UUID, error) {
+	return a.adminDB.DeletedUsers()
+}
+
+// RemoveAccount removes deleted user with sessions and tokens, it should be called after data of user is removed.
+// User is removed only when no access token of user could be valid on any server, so no write of user comes later.
+// storage.ErrNoValues is returned if there is no such deleted user or it is deleted recently
+func (a *Authorizer) RemoveAccount(userID uuid.UUID) error {
+	return a.adminDB.DeleteUser(userID, time.Now().Add(-a.expireDuration-removalMargin))
+}
+
+// checkPassword checks password of logged in user before sensitive change,
+// wrong passwords are throttled as failed logins
+func (a *Authorizer) checkPassword(userID uuid.UUID, password string, client *models.SessionClient) (*models.User, error) {
+	user, userErr := a.adminDB.GetUserInfo(userID)
+	if userErr != nil {
+		if errors.Is(userErr, sql.ErrNoRows) {
+			return nil, ErrNoUserExists
+		}
+		return nil, userErr
+	}
+	if throttleErr := a.throttle.Check(client.IP, user.Email); throttleErr != nil {
+		return nil, throttleErr
+	}
+	match, _, verifyErr := a.hasher.Verify(password, user.Password)
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
+	if !match {
+		return nil, a.failLogin(client.IP, user.Email, ErrComparePassword)
+	}
+	return user, nil
+}
@@ -1,16 +1,23 @@
 package authorizer
 
 import (
+	"AlexSarva/GophKeeper/mailer"
 	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/passhash"
+	"AlexSarva/GophKeeper/storage"
 	"AlexSarva/GophKeeper/storage/admin"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var ErrInvalidAccessToken = errors.New("invalid auth token")
@@ -18,138 +25,358 @@ var ErrGenerateToken = errors.New("something wrong happens when generate token")
 var ErrHashPassword = errors.New("something wrong happens when hashing password")
 var ErrComparePassword = errors.New("password doesnt match")
 var ErrNoUserExists = errors.New("user doesnt exist in database")
+var ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+var ErrRefreshTokenReused = errors.New("refresh token is already used, all sessions of this login are revoked")
+var ErrRevokedToken = errors.New("token is revoked")
 
+// refreshTokenSize size of random part of refresh token
+const refreshTokenSize = 32
+
+// claims of access token, id of token is in jti claim and session, that is family of refresh tokens, is in fid claim
 type claims struct {
 	jwt.StandardClaims
-	UserID uuid.UUID `json:"user_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	FamilyID uuid.UUID `json:"fid,omitempty"`
 }
 
-// Authorizer component that used for registration, authorization and authentication users in service
+// Authorizer component that used for registration, authorization and authentication users in service.
+// Users get short-lived access JWT and single-use refresh token that is exchanged for new pair,
+// access tokens could be revoked before they expire
 type Authorizer struct {
-	adminDB        *admin.Admin
-	signingKey     []byte
-	expireDuration time.Duration
+	adminDB         *admin.Admin
+	hasher          *passhash.Hasher
+	mail            *mailer.Sender
+	keys            *KeyRing
+	expireDuration  time.Duration
+	refreshDuration time.Duration
+	revocations     *Revocations
+	throttle        *Throttle
 }
 
 // NewAuthorizer initializer of Authorizer struct
-// should exist connect to admin database, signing key for JWT and expire duration limit
-func NewAuthorizer(db *admin.Admin, signingKey []byte, expireDuration time.Duration) *Authorizer {
+// should exist connect to admin database, hasher of passwords, sender of messages to email of users,
+// key ring of JWT signatures, expire duration of access token and expire duration of refresh token
+func NewAuthorizer(db *admin.Admin, hasher *passhash.Hasher, mail *mailer.Sender, keys *KeyRing, expireDuration, refreshDuration time.Duration) *Authorizer {
 	return &Authorizer{
-		adminDB:        db,
-		signingKey:     signingKey,
-		expireDuration: expireDuration, // 86400
+		adminDB:         db,
+		hasher:          hasher,
+		mail:            mail,
+		keys:            keys,
+		expireDuration:  expireDuration,
+		refreshDuration: refreshDuration,
+		revocations:     NewRevocations(db, expireDuration),
+		throttle:        NewThrottle(db, AccountPolicy, IPPolicy),
 	}
 }
 
-// SignUp register user in Database and create personal JWT token, returns user's info and user JWT
-func (a *Authorizer) SignUp(user models.User) (*models.User, error) {
-	// Create password hash
-
-	hashedPassword, bcrypteErr := bcrypt.GenerateFromPassword([]byte(user.Password), 4)
-	if bcrypteErr != nil {
-		return nil, ErrHashPassword
-	}
-
-	user.Password = string(hashedPassword)
+// Revocations returns cache of revoked access tokens
+func (a *Authorizer) Revocations() *Revocations {
+	return a.revocations
+}
 
-	expires := time.Now().Add(a.expireDuration)
+// KeyRing returns keys of JWT signatures
+func (a *Authorizer) KeyRing() *KeyRing {
+	return a.keys
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: jwt.At(expires),
-			IssuedAt:  jwt.At(time.Now()),
-		},
-		UserID: user.ID,
-	})
+// SignUp register user in Database and create personal JWT token, returns user's info with pair of tokens
+// of new session of client. Code that confirms email is sent to user, registration doesn't fail if it is not sent
+func (a *Authorizer) SignUp(user models.User, client *models.SessionClient) (*models.User, error) {
+	// Create password hash
 
-	tokenValue, tokenErr := token.SignedString(a.signingKey)
-	if tokenErr != nil {
-		return nil, ErrGenerateToken
+	hashedPassword, hashErr := a.hasher.Hash(user.Password)
+	if hashErr != nil {
+		return nil, ErrHashPassword
 	}
 
-	user.Token = tokenValue
-	user.TokenExp = expires
+	user.Password = hashedPassword
 
 	err := a.adminDB.Register(user)
 	if err != nil {
 		return nil, err
 	}
 
+	tokens, tokensErr := a.startSession(user.ID, client)
+	if tokensErr != nil {
+		return nil, tokensErr
+	}
+	if sendErr := a.sendEmailToken(&user, models.EmailTokenVerify); sendErr != nil {
+		log.Printf("verification of email of user %s is not sent: %s", user.ID, sendErr)
+	}
 	user.Password = ""
-	user.Token = fmt.Sprintf("Bearer %s", user.Token)
+	setTokens(&user, tokens)
 
 	return &user, nil
 }
 
-// SignIn check user in Database, compare passwords, return personal JWT token and info about user
-func (a *Authorizer) SignIn(userLogin *models.UserLogin) (*models.User, error) {
+// SignIn check user in Database, compare passwords, return info about user with new pair of tokens,
+// every login starts new session of client with its own family of refresh tokens.
+// If user enabled two-factor authentication, only challenge of second step is returned.
+// Failed logins are throttled by address of client and by account.
+// Hash of password made by old algorithm or parameters is upgraded after password is checked
+func (a *Authorizer) SignIn(userLogin *models.UserLogin, client *models.SessionClient) (*models.User, *models.TwoFactorChallenge, error) {
+	if throttleErr := a.throttle.Check(client.IP, userLogin.Email); throttleErr != nil {
+		return nil, nil, throttleErr
+	}
 	userCred, err := a.adminDB.Login(userLogin)
 	if err != nil {
-		if errors.Is(sql.ErrNoRows, err) {
-			return nil, ErrNoUserExists
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, a.failLogin(client.IP, userLogin.Email, ErrNoUserExists)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
-	cryptErr := bcrypt.CompareHashAndPassword([]byte(userCred.Password), []byte(userLogin.Password))
-	if cryptErr != nil {
-		return nil, ErrComparePassword
+	match, rehash, verifyErr := a.hasher.Verify(userLogin.Password, userCred.Password)
+	if verifyErr != nil {
+		return nil, nil, verifyErr
+	}
+	if !match {
+		return nil, nil, a.failLogin(client.IP, userLogin.Email, ErrComparePassword)
+	}
+	if rehash {
+		a.upgradePasswordHash(userCred.ID, userCred.Password, userLogin.Password)
+	}
+
+	twoFactor, twoFactorErr := a.twoFactorEnabled(userCred.ID)
+	if twoFactorErr != nil {
+		return nil, nil, twoFactorErr
+	}
+	if twoFactor {
+		challenge, challengeErr := a.challengeToken(userCred.ID)
+		if challengeErr != nil {
+			return nil, nil, challengeErr
+		}
+		return nil, challenge, nil
+	}
+	if succeedErr := a.throttle.Succeed(userLogin.Email); succeedErr != nil {
+		return nil, nil, succeedErr
 	}
 
+	tokens, tokensErr := a.startSession(userCred.ID, client)
+	if tokensErr != nil {
+		return nil, nil, tokensErr
+	}
 	userCred.Password = ""
-	userCred.Token = fmt.Sprintf("Bearer %s", userCred.Token)
+	setTokens(userCred, tokens)
+
+	return userCred, nil, nil
+}
+
+// Refresh exchanges refresh token for new pair of tokens, refresh token could be used only once.
+// Reuse of spent token means that it is stolen, so the whole session is revoked.
+// Last seen time and address of session are updated by client of refresh
+func (a *Authorizer) Refresh(refreshToken string, client *models.SessionClient) (*models.Tokens, error) {
+	tokenHash := hashRefreshToken(refreshToken)
+	token, useErr := a.adminDB.UseRefreshToken(tokenHash)
+	if useErr != nil {
+		if !errors.Is(useErr, storage.ErrNoValues) {
+			return nil, useErr
+		}
+		spent, getErr := a.adminDB.GetRefreshToken(tokenHash)
+		if getErr != nil {
+			if errors.Is(getErr, storage.ErrNoValues) {
+				return nil, ErrInvalidRefreshToken
+			}
+			return nil, getErr
+		}
+		if spent.Used == nil && !spent.Revoked {
+			return nil, ErrInvalidRefreshToken
+		}
+		if revokeErr := a.revokeSession(spent.UserID, spent.FamilyID); revokeErr != nil {
+			return nil, revokeErr
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if touchErr := a.adminDB.TouchSession(token.FamilyID, client.IP); touchErr != nil {
+		return nil, touchErr
+	}
+	return a.issueTokens(token.UserID, token.FamilyID)
+}
+
+// upgradePasswordHash replaces hash of checked password by hash made by current parameters,
+// login doesn't fail if hash is not replaced, it is upgraded on next login
+func (a *Authorizer) upgradePasswordHash(userID uuid.UUID, checked, password string) {
+	hash, hashErr := a.hasher.Hash(password)
+	if hashErr != nil {
+		log.Printf("password hash of user %s is not upgraded: %s", userID, hashErr)
+		return
+	}
+	if setErr := a.adminDB.SetPasswordHash(userID, checked, hash); setErr != nil && !errors.Is(setErr, storage.ErrNoValues) {
+		log.Printf("password hash of user %s is not upgraded: %s", userID, setErr)
+	}
+}
+
+// failLogin counts failed login and returns its error
+func (a *Authorizer) failLogin(ip, account string, loginErr error) error {
+	if failErr := a.throttle.Fail(ip, account); failErr != nil {
+		return failErr
+	}
+	return loginErr
+}
+
+// Lockouts returns recent lockouts of logins, active lockouts are not cleared and not expired
+func (a *Authorizer) Lockouts(active bool) ([]models.Lockout, error) {
+	return a.adminDB.Lockouts(active)
+}
+
+// ClearLockout allows logins of subject of lockout at once,
+// storage.ErrNoValues is returned if there is no such lockout that is not cleared
+func (a *Authorizer) ClearLockout(id uuid.UUID) error {
+	return a.adminDB.ClearLockout(id)
+}
 
-	return userCred, nil
+// startSession saves new session of client and issues first pair of its tokens
+func (a *Authorizer) startSession(userID uuid.UUID, client *models.SessionClient) (*models.Tokens, error) {
+	session := &models.Session{
+		ID:            uuid.New(),
+		UserID:        userID,
+		DeviceName:    client.DeviceName,
+		ClientVersion: client.ClientVersion,
+		IP:            client.IP,
+		UserAgent:     client.UserAgent,
+	}
+	if sessionErr := a.adminDB.NewSession(session); sessionErr != nil {
+		return nil, sessionErr
+	}
+	return a.issueTokens(userID, session.ID)
+}
+
+// revokeSession revokes session with all its tokens, session could be already revoked
+func (a *Authorizer) revokeSession(userID, sessionID uuid.UUID) error {
+	revokeErr := a.revocations.RevokeSession(userID, sessionID)
+	if revokeErr != nil && !errors.Is(revokeErr, storage.ErrNoValues) {
+		return revokeErr
+	}
+	return nil
 }
 
-// RenewToken refresh user JWT, if it is expired
-func (a *Authorizer) RenewToken(user models.User) (*models.User, error) {
+// Sessions returns active sessions of user
+func (a *Authorizer) Sessions(userID uuid.UUID) ([]models.Session, error) {
+	return a.adminDB.UserSessions(userID)
+}
 
+// RevokeSession revokes session of user with all its tokens,
+// storage.ErrNoValues is returned if user has no such active session
+func (a *Authorizer) RevokeSession(userID, sessionID uuid.UUID) error {
+	return a.revocations.RevokeSession(userID, sessionID)
+}
+
+// issueTokens creates access token and refresh token of session, tokens of session form family
+func (a *Authorizer) issueTokens(userID, familyID uuid.UUID) (*models.Tokens, error) {
+	accessToken, expires, tokenErr := a.accessToken(userID, familyID)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+
+	random := make([]byte, refreshTokenSize)
+	if _, randErr := rand.Read(random); randErr != nil {
+		return nil, ErrGenerateToken
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(random)
+	refreshExpires := time.Now().Add(a.refreshDuration)
+	storeErr := a.adminDB.NewRefreshToken(&models.RefreshToken{
+		ID:        uuid.New(),
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: hashRefreshToken(refreshToken),
+		Expires:   refreshExpires,
+	})
+	if storeErr != nil {
+		return nil, storeErr
+	}
+
+	return &models.Tokens{
+		Token:        fmt.Sprintf("Bearer %s", accessToken),
+		TokenExp:     expires,
+		RefreshToken: refreshToken,
+		RefreshExp:   refreshExpires,
+	}, nil
+}
+
+// accessToken creates signed access JWT of user with new id
+func (a *Authorizer) accessToken(userID, familyID uuid.UUID) (string, time.Time, error) {
 	expires := time.Now().Add(a.expireDuration)
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims{
+	tokenValue, tokenErr := a.keys.Sign(&claims{
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: jwt.At(expires),
 			IssuedAt:  jwt.At(time.Now()),
+			ID:        uuid.New().String(),
 		},
-		UserID: user.ID,
+		UserID:   userID,
+		FamilyID: familyID,
 	})
-
-	tokenValue, tokenErr := token.SignedString(a.signingKey)
 	if tokenErr != nil {
-		return nil, ErrGenerateToken
+		return "", time.Time{}, ErrGenerateToken
 	}
+	return tokenValue, expires, nil
+}
 
-	user.Token = tokenValue
-	user.TokenExp = expires
+// hashRefreshToken returns hash of refresh token, only hashes are stored
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
 
-	err := a.adminDB.RenewToken(user)
+// setTokens sets pair of tokens in user info
+func setTokens(user *models.User, tokens *models.Tokens) {
+	user.Token = tokens.Token
+	user.TokenExp = tokens.TokenExp
+	user.RefreshToken = tokens.RefreshToken
+	user.RefreshExp = &tokens.RefreshExp
+}
+
+// ParseToken parses JWT into user uuid and id of session, revoked tokens are rejected
+func (a *Authorizer) ParseToken(accessToken string) (uuid.UUID, uuid.UUID, error) {
+	workClaims, jti, err := a.parseClaims(accessToken)
 	if err != nil {
-		return nil, err
+		return uuid.UUID{}, uuid.UUID{}, err
+	}
+	if a.revocations.Revoked(jti, workClaims.UserID, workClaims.FamilyID, workClaims.IssuedAt.Time) {
+		return uuid.UUID{}, uuid.UUID{}, ErrRevokedToken
 	}
+	return workClaims.UserID, workClaims.FamilyID, nil
+}
 
-	user.Password = ""
-	user.Token = fmt.Sprintf("Bearer %s", user.Token)
+// Logout revokes access token and its session
+func (a *Authorizer) Logout(accessToken string) error {
+	workClaims, jti, err := a.parseClaims(accessToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAccessToken) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidAccessToken, err)
+	}
+	if revokeErr := a.revocations.RevokeToken(jti, workClaims.UserID, workClaims.ExpiresAt.Time); revokeErr != nil {
+		return revokeErr
+	}
+	if workClaims.FamilyID == uuid.Nil {
+		return nil
+	}
+	return a.revokeSession(workClaims.UserID, workClaims.FamilyID)
+}
 
-	return &user, nil
+// LogoutEverywhere revokes all access, refresh and personal access tokens of user
+func (a *Authorizer) LogoutEverywhere(userID uuid.UUID) error {
+	return a.revocations.RevokeUser(userID)
 }
 
-// ParseToken parses JWT into user uuid
-func (a *Authorizer) ParseToken(accessToken string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return a.signingKey, nil
-	})
+// parseClaims checks signature and expiration of JWT, returns its claims and id.
+// Tokens without id are rejected, because they can't be revoked
+func (a *Authorizer) parseClaims(accessToken string) (*claims, uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &claims{}, a.keys.Keyfunc)
 
 	if err != nil {
-		return uuid.UUID{}, err
+		return nil, uuid.UUID{}, err
 	}
 
-	if workClaims, ok := token.Claims.(*claims); ok && token.Valid {
-		return workClaims.UserID, nil
+	workClaims, ok := token.Claims.(*claims)
+	if !ok || !token.Valid || workClaims.ExpiresAt == nil || workClaims.IssuedAt == nil {
+		return nil, uuid.UUID{}, ErrInvalidAccessToken
 	}
-
-	return uuid.UUID{}, ErrInvalidAccessToken
+	jti, jtiErr := uuid.Parse(workClaims.ID)
+	if jtiErr != nil {
+		return nil, uuid.UUID{}, ErrInvalidAccessToken
+	}
+	return workClaims, jti, nil
 }
@@ -0,0 +1,50 @@
+package authorizer
+
+import (
+	"crypto/ed25519"
+
+	"github.com/dgrijalva/jwt-go/v4"
+)
+
+// signingMethodEdDSA EdDSA signatures of JWT (RFC 8037) with Ed25519 keys,
+// jwt-go doesn't implement them. It expects ed25519.PrivateKey for signing and ed25519.PublicKey for validation
+type signingMethodEdDSA struct{}
+
+// SigningMethodEdDSA signing method of EdDSA tokens
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+// Alg returns name of algorithm in header of token
+func (m *signingMethodEdDSA) Alg() string {
+	return AlgorithmEdDSA
+}
+
+// Verify checks signature of signing string by public key
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	public, ok := key.(ed25519.PublicKey)
+	if !ok || len(public) != ed25519.PublicKeySize {
+		return jwt.NewInvalidKeyTypeError("ed25519.PublicKey", key)
+	}
+	sig, decodeErr := jwt.DecodeSegment(signature)
+	if decodeErr != nil {
+		return decodeErr
+	}
+	if !ed25519.Verify(public, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Sign returns encoded signature of signing string by private key
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok || len(private) != ed25519.PrivateKeySize {
+		return "", jwt.NewInvalidKeyTypeError("ed25519.PrivateKey", key)
+	}
+	return jwt.EncodeSegment(ed25519.Sign(private, []byte(signingString))), nil
+}
@@ -0,0 +1,157 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/mailer"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidEmailToken = errors.New("code is invalid, expired or already used")
+var ErrEmailVerified = errors.New("email is already confirmed")
+var ErrEmailCooldown = errors.New("message with code is sent recently, check email or try again later")
+
+const (
+	// verifyTokenDuration lifetime of code that confirms email
+	verifyTokenDuration = 24 * time.Hour
+	// resetTokenDuration lifetime of code of password reset
+	resetTokenDuration = time.Hour
+	// EmailTokenCooldown time before next message with code of the same purpose is sent to user
+	EmailTokenCooldown = time.Minute
+	// emailTokenSize size of random part of code sent to email
+	emailTokenSize = 10
+)
+
+// ResendVerification sends new code that confirms email of user
+func (a *Authorizer) ResendVerification(userID uuid.UUID) error {
+	user, userErr := a.adminDB.GetUserInfo(userID)
+	if userErr != nil {
+		return userErr
+	}
+	if user.EmailVerified != nil {
+		return ErrEmailVerified
+	}
+	return a.sendEmailToken(user, models.EmailTokenVerify)
+}
+
+// VerifyEmail confirms email of user by code from message, every code could be used once
+func (a *Authorizer) VerifyEmail(token string) error {
+	emailToken, useErr := a.adminDB.UseEmailToken(hashEmailToken(token), models.EmailTokenVerify)
+	if useErr != nil {
+		if errors.Is(useErr, storage.ErrNoValues) {
+			return ErrInvalidEmailToken
+		}
+		return useErr
+	}
+	return a.adminDB.SetEmailVerified(emailToken.UserID)
+}
+
+// ForgotPassword sends code of password reset to email. Nothing is reported if there is no such user
+// or code is sent recently, so existence of accounts is not revealed
+func (a *Authorizer) ForgotPassword(email string) error {
+	user, userErr := a.adminDB.Login(&models.UserLogin{Email: email})
+	if userErr != nil {
+		if errors.Is(userErr, sql.ErrNoRows) {
+			return nil
+		}
+		return userErr
+	}
+	sendErr := a.sendEmailToken(user, models.EmailTokenReset)
+	if sendErr != nil && !errors.Is(sendErr, ErrEmailCooldown) {
+		return sendErr
+	}
+	return nil
+}
+
+// ResetPassword sets new password of user by code of password reset. Code proves email of user,
+// so email is confirmed, all tokens of user are revoked and failed logins of account are forgotten
+func (a *Authorizer) ResetPassword(reset *models.PasswordReset) error {
+	emailToken, useErr := a.adminDB.UseEmailToken(hashEmailToken(reset.Token), models.EmailTokenReset)
+	if useErr != nil {
+		if errors.Is(useErr, storage.ErrNoValues) {
+			return ErrInvalidEmailToken
+		}
+		return useErr
+	}
+	user, userErr := a.adminDB.GetUserInfo(emailToken.UserID)
+	if userErr != nil {
+		return userErr
+	}
+	hash, hashErr := a.hasher.Hash(reset.NewPassword)
+	if hashErr != nil {
+		return ErrHashPassword
+	}
+	if setErr := a.adminDB.SetPasswordHash(user.ID, user.Password, hash); setErr != nil {
+		if errors.Is(setErr, storage.ErrNoValues) {
+			return ErrPasswordChanged
+		}
+		return setErr
+	}
+	if verifyErr := a.adminDB.SetEmailVerified(user.ID); verifyErr != nil {
+		return verifyErr
+	}
+	if revokeErr := a.revocations.RevokeUser(user.ID); revokeErr != nil {
+		return revokeErr
+	}
+	return a.throttle.Succeed(user.Email)
+}
+
+// sendEmailToken saves new code of purpose and sends it to email of user.
+// Message is sent in background, so response time doesn't depend on mail server
+func (a *Authorizer) sendEmailToken(user *models.User, purpose string) error {
+	template, duration := mailer.TemplateVerification, verifyTokenDuration
+	if purpose == models.EmailTokenReset {
+		template, duration = mailer.TemplatePasswordReset, resetTokenDuration
+	}
+	token, tokenErr := newEmailToken()
+	if tokenErr != nil {
+		return tokenErr
+	}
+	expires := time.Now().Add(duration)
+	saveErr := a.adminDB.NewEmailToken(&models.EmailToken{
+		TokenHash: hashEmailToken(token),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Expires:   expires,
+	}, EmailTokenCooldown)
+	if saveErr != nil {
+		if errors.Is(saveErr, storage.ErrDuplicatePK) {
+			return ErrEmailCooldown
+		}
+		return saveErr
+	}
+
+	data := &mailer.TemplateData{Username: user.Username, Email: user.Email, Token: token, Expires: expires}
+	go func() {
+		if sendErr := a.mail.Send(template, data); sendErr != nil {
+			log.Printf("message %s to user %s is not sent: %s", template, user.ID, sendErr)
+		}
+	}()
+	return nil
+}
+
+// newEmailToken returns random code in groups of four characters, it is typed or pasted by people
+func newEmailToken() (string, error) {
+	random := make([]byte, emailTokenSize)
+	if _, randErr := rand.Read(random); randErr != nil {
+		return "", ErrGenerateToken
+	}
+	encoded := strings.ToLower(recoveryEncoding.EncodeToString(random))
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// hashEmailToken returns hash of code, case, dashes and spaces of typed code are ignored like in recovery codes
+func hashEmailToken(token string) string {
+	return hashRecoveryCode(token)
+}
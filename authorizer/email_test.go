@@ -0,0 +1,22 @@
+package authorizer
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmailToken(t *testing.T) {
+	token, tokenErr := newEmailToken()
+	require.NoError(t, tokenErr)
+	assert.Regexp(t, regexp.MustCompile(`^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`), token)
+	assert.Equal(t, hashEmailToken(token), hashEmailToken(strings.ToUpper(strings.ReplaceAll(token, "-", " "))),
+		"typed code is accepted in any case and grouping")
+
+	other, otherErr := newEmailToken()
+	require.NoError(t, otherErr)
+	assert.NotEqual(t, token, other)
+}
@@ -0,0 +1,352 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+)
+
+// Algorithms of JWT signatures
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+var ErrAlgorithm = errors.New("unknown algorithm of JWT signatures, use EdDSA or RS256")
+var ErrNoSigningKey = errors.New("there is no active key of JWT signatures")
+var ErrUnknownKey = errors.New("token is signed by unknown or retired key")
+var ErrRotationNotDue = errors.New("active key of JWT signatures is not old enough to be rotated")
+
+const (
+	// rsaKeyBits size of RSA keys
+	rsaKeyBits = 3072
+	// keyIDSize size of random id of key
+	keyIDSize = 12
+	// unknownKeyReload minimal interval of loading keys when token of unknown key comes,
+	// key could be added by another server
+	unknownKeyReload = 10 * time.Second
+)
+
+// KeyRingParams parameters of key ring
+type KeyRingParams struct {
+	// Algorithm of new keys, keys of another algorithm verify tokens until they retire
+	Algorithm string
+	// Rotation age of active key when it is replaced by new one, keys are not rotated if it is zero
+	Rotation time.Duration
+	// Publish time while new key is published in JWKS before it signs tokens,
+	// so services that cache JWKS know key before its tokens come
+	Publish time.Duration
+	// Overlap time while replaced key verifies tokens, it should be not less than lifetime of tokens
+	Overlap time.Duration
+}
+
+// keyStore storage of signing keys, it is implemented by admin database
+type keyStore interface {
+	SigningKeys() ([]models.SigningKey, error)
+	AddSigningKey(key *models.SigningKey, rotateAfter, overlap time.Duration) error
+}
+
+// ringKey key of ring with parsed private key
+type ringKey struct {
+	info    models.SigningKey
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+// KeyRing keys of JWT signatures shared by servers through storage. The latest activated key signs tokens,
+// every key that is not retired verifies them. Id of key is in kid header of token
+type KeyRing struct {
+	store  keyStore
+	params KeyRingParams
+	mu     sync.RWMutex
+	keys   []*ringKey
+	loaded time.Time
+}
+
+// NewKeyRing initializer of KeyRing, keys are loaded from storage.
+// The first key is created and activated at once if storage has no keys
+func NewKeyRing(store keyStore, params KeyRingParams) (*KeyRing, error) {
+	if params.Algorithm != AlgorithmEdDSA && params.Algorithm != AlgorithmRS256 {
+		return nil, ErrAlgorithm
+	}
+	ring := &KeyRing{store: store, params: params}
+	if loadErr := ring.Load(); loadErr != nil {
+		return nil, loadErr
+	}
+	if len(ring.keys) == 0 {
+		if _, addErr := ring.add(time.Now(), 0); addErr != nil {
+			return nil, addErr
+		}
+	}
+	return ring, nil
+}
+
+// Load replaces keys of ring by keys from storage
+func (r *KeyRing) Load() error {
+	stored, storedErr := r.store.SigningKeys()
+	if storedErr != nil {
+		return storedErr
+	}
+	keys := make([]*ringKey, 0, len(stored))
+	for _, info := range stored {
+		key, parseErr := parseRingKey(info)
+		if parseErr != nil {
+			return fmt.Errorf("signing key %s: %w", info.ID, parseErr)
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].info.Activates.Before(keys[j].info.Activates)
+	})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = keys
+	r.loaded = time.Now()
+	return nil
+}
+
+// Sign returns token with claims signed by active key
+func (r *KeyRing) Sign(tokenClaims jwt.Claims) (string, error) {
+	key := r.activeKey(time.Now())
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(key.method, tokenClaims)
+	token.Header["kid"] = key.info.ID
+	return token.SignedString(key.private)
+}
+
+// Keyfunc returns public key of kid header of token for jwt.Parse, algorithm of token should be algorithm of key.
+// Keys are loaded again if key is unknown, it could be added by another server
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key := r.verifyingKey(kid, time.Now())
+	if key == nil && r.reloadUnknown() {
+		key = r.verifyingKey(kid, time.Now())
+	}
+	if key == nil {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.private.Public(), nil
+}
+
+// Rotate adds new key of ring algorithm, it signs tokens after it is published and previous keys retire after overlap.
+// Key is added only if the latest key is older than rotation period unless force is set, ErrRotationNotDue otherwise
+func (r *KeyRing) Rotate(force bool) (*models.SigningKey, error) {
+	rotateAfter := r.params.Rotation
+	if force {
+		rotateAfter = 0
+	}
+	return r.add(time.Now().Add(r.params.Publish), rotateAfter)
+}
+
+// Keys returns keys of ring without private keys ordered by activation
+func (r *KeyRing) Keys() []models.SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]models.SigningKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		info := key.info
+		info.PrivateKey = nil
+		keys = append(keys, info)
+	}
+	return keys
+}
+
+// JWKS returns public keys of ring that are not retired, keys that are not activated yet are included
+func (r *KeyRing) JWKS() *models.JWKSet {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set := &models.JWKSet{Keys: make([]models.JWK, 0, len(r.keys))}
+	for _, key := range r.keys {
+		if key.retired(now) {
+			continue
+		}
+		jwk := models.JWK{KeyID: key.info.ID, Use: "sig", Algorithm: key.info.Algorithm}
+		switch public := key.private.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// Sync loads keys from storage with interval until context is done, key is rotated when active key is old enough
+func (r *KeyRing) Sync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if loadErr := r.Load(); loadErr != nil {
+			log.Println("signing keys:", loadErr)
+		}
+		if r.rotationDue(time.Now()) {
+			key, rotateErr := r.Rotate(false)
+			switch {
+			case rotateErr == nil:
+				log.Printf("new signing key %s is activated at %s", key.ID, key.Activates.Format(time.RFC3339))
+			case !errors.Is(rotateErr, ErrRotationNotDue):
+				log.Println("signing keys:", rotateErr)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// add generates key that is activated at time and saves it, keys are loaded again after it
+func (r *KeyRing) add(activates time.Time, rotateAfter time.Duration) (*models.SigningKey, error) {
+	key, keyErr := newSigningKey(r.params.Algorithm, activates)
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if addErr := r.store.AddSigningKey(key, rotateAfter, r.params.Overlap); addErr != nil {
+		if errors.Is(addErr, storage.ErrDuplicatePK) {
+			return nil, ErrRotationNotDue
+		}
+		return nil, addErr
+	}
+	if loadErr := r.Load(); loadErr != nil {
+		return nil, loadErr
+	}
+	key.PrivateKey = nil
+	return key, nil
+}
+
+// activeKey returns the latest key activated before time
+func (r *KeyRing) activeKey(now time.Time) *ringKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].info.Activates.After(now) && !r.keys[i].retired(now) {
+			return r.keys[i]
+		}
+	}
+	return nil
+}
+
+// verifyingKey returns key of id if it is not retired
+func (r *KeyRing) verifyingKey(id string, now time.Time) *ringKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, key := range r.keys {
+		if key.info.ID == id && !key.retired(now) {
+			return key
+		}
+	}
+	return nil
+}
+
+// rotationDue reports whether the latest key is older than rotation period
+func (r *KeyRing) rotationDue(now time.Time) bool {
+	if r.params.Rotation <= 0 {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.keys) == 0 || now.Sub(r.keys[len(r.keys)-1].info.Activates) >= r.params.Rotation
+}
+
+// reloadUnknown loads keys again if they were not loaded recently, reports whether keys are loaded
+func (r *KeyRing) reloadUnknown() bool {
+	r.mu.RLock()
+	recent := time.Since(r.loaded) < unknownKeyReload
+	r.mu.RUnlock()
+	if recent {
+		return false
+	}
+	if loadErr := r.Load(); loadErr != nil {
+		log.Println("signing keys:", loadErr)
+		return false
+	}
+	return true
+}
+
+// retired reports whether key doesn't verify tokens at time
+func (k *ringKey) retired(now time.Time) bool {
+	return k.info.Retires != nil && !k.info.Retires.After(now)
+}
+
+// newSigningKey generates key of algorithm with random id
+func newSigningKey(algorithm string, activates time.Time) (*models.SigningKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, edKey, genErr := ed25519.GenerateKey(rand.Reader)
+		if genErr != nil {
+			return nil, genErr
+		}
+		private = edKey
+	case AlgorithmRS256:
+		rsaKey, genErr := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if genErr != nil {
+			return nil, genErr
+		}
+		private = rsaKey
+	default:
+		return nil, ErrAlgorithm
+	}
+	der, marshalErr := x509.MarshalPKCS8PrivateKey(private)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	random := make([]byte, keyIDSize)
+	if _, randErr := rand.Read(random); randErr != nil {
+		return nil, randErr
+	}
+	return &models.SigningKey{
+		ID:         base64.RawURLEncoding.EncodeToString(random),
+		Algorithm:  algorithm,
+		PrivateKey: der,
+		Created:    time.Now(),
+		Activates:  activates,
+	}, nil
+}
+
+// parseRingKey parses private key of stored key, type of key should match its algorithm
+func parseRingKey(info models.SigningKey) (*ringKey, error) {
+	parsed, parseErr := x509.ParsePKCS8PrivateKey(info.PrivateKey)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	key := &ringKey{info: info}
+	switch private := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.method, key.private = SigningMethodEdDSA, private
+	case *rsa.PrivateKey:
+		key.method, key.private = jwt.SigningMethodRS256, private
+	default:
+		return nil, ErrAlgorithm
+	}
+	if key.method.Alg() != info.Algorithm {
+		return nil, ErrAlgorithm
+	}
+	key.info.PrivateKey = nil
+	return key, nil
+}
@@ -0,0 +1,180 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryKeys keeps signing keys in memory, it is shared by rings like database of servers
+type memoryKeys struct {
+	mu   sync.Mutex
+	keys []models.SigningKey
+}
+
+func (m *memoryKeys) SigningKeys() ([]models.SigningKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []models.SigningKey
+	for _, key := range m.keys {
+		if key.Retires == nil || key.Retires.After(time.Now()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memoryKeys) AddSigningKey(key *models.SigningKey, rotateAfter, overlap time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.keys {
+		if rotateAfter > 0 && m.keys[i].Activates.After(time.Now().Add(-rotateAfter)) {
+			return storage.ErrDuplicatePK
+		}
+	}
+	retires := key.Activates.Add(overlap)
+	for i := range m.keys {
+		if m.keys[i].Activates.Before(key.Activates) && (m.keys[i].Retires == nil || m.keys[i].Retires.After(retires)) {
+			m.keys[i].Retires = &retires
+		}
+	}
+	m.keys = append(m.keys, *key)
+	return nil
+}
+
+// newTestKeyRing returns key ring with in-memory storage
+func newTestKeyRing(t *testing.T, params KeyRingParams) (*KeyRing, *memoryKeys) {
+	store := &memoryKeys{}
+	ring, ringErr := NewKeyRing(store, params)
+	require.NoError(t, ringErr)
+	return ring, store
+}
+
+func TestKeyRingSignVerify(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			ring, _ := newTestKeyRing(t, KeyRingParams{Algorithm: algorithm, Overlap: time.Hour})
+			signed, signErr := ring.Sign(jwt.MapClaims{"sub": "user"})
+			require.NoError(t, signErr)
+
+			token, parseErr := jwt.Parse(signed, ring.Keyfunc)
+			require.NoError(t, parseErr)
+			assert.Equal(t, algorithm, token.Header["alg"])
+			assert.Equal(t, ring.Keys()[0].ID, token.Header["kid"])
+
+			other, _ := newTestKeyRing(t, KeyRingParams{Algorithm: algorithm, Overlap: time.Hour})
+			_, parseErr = jwt.Parse(signed, other.Keyfunc)
+			assert.Error(t, parseErr, "token of another ring")
+
+			hmac, hmacErr := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user"}).SignedString([]byte("secret"))
+			require.NoError(t, hmacErr)
+			_, parseErr = jwt.Parse(hmac, ring.Keyfunc)
+			assert.Error(t, parseErr, "token signed by secret")
+		})
+	}
+}
+
+func TestKeyRingRotate(t *testing.T) {
+	params := KeyRingParams{Algorithm: AlgorithmEdDSA, Rotation: time.Hour, Publish: time.Minute, Overlap: time.Hour}
+	ring, store := newTestKeyRing(t, params)
+	first := ring.Keys()[0]
+	oldToken, signErr := ring.Sign(jwt.MapClaims{"sub": "user"})
+	require.NoError(t, signErr)
+
+	_, rotateErr := ring.Rotate(false)
+	assert.ErrorIs(t, rotateErr, ErrRotationNotDue)
+	second, rotateErr := ring.Rotate(true)
+	require.NoError(t, rotateErr)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Len(t, ring.JWKS().Keys, 2, "new key is published before it signs")
+
+	token, signErr := ring.Sign(jwt.MapClaims{"sub": "user"})
+	require.NoError(t, signErr)
+	parsed, parseErr := jwt.Parse(token, ring.Keyfunc)
+	require.NoError(t, parseErr)
+	assert.Equal(t, first.ID, parsed.Header["kid"], "old key signs until new one is activated")
+
+	// new key is activated and old one retires later
+	store.mu.Lock()
+	store.keys[1].Activates = time.Now()
+	retires := time.Now().Add(time.Hour)
+	store.keys[0].Retires = &retires
+	store.mu.Unlock()
+	require.NoError(t, ring.Load())
+	token, signErr = ring.Sign(jwt.MapClaims{"sub": "user"})
+	require.NoError(t, signErr)
+	parsed, parseErr = jwt.Parse(token, ring.Keyfunc)
+	require.NoError(t, parseErr)
+	assert.Equal(t, second.ID, parsed.Header["kid"])
+	_, parseErr = jwt.Parse(oldToken, ring.Keyfunc)
+	assert.NoError(t, parseErr, "old key verifies tokens while overlap")
+
+	// old key is retired
+	store.mu.Lock()
+	retired := time.Now().Add(-time.Second)
+	store.keys[0].Retires = &retired
+	store.mu.Unlock()
+	require.NoError(t, ring.Load())
+	_, parseErr = jwt.Parse(oldToken, ring.Keyfunc)
+	assert.Error(t, parseErr, "retired key doesn't verify tokens")
+	assert.Len(t, ring.JWKS().Keys, 1)
+}
+
+func TestKeyRingUnknownKeyReload(t *testing.T) {
+	params := KeyRingParams{Algorithm: AlgorithmEdDSA, Overlap: time.Hour}
+	ring, store := newTestKeyRing(t, params)
+	other, otherErr := NewKeyRing(store, params)
+	require.NoError(t, otherErr)
+	_, rotateErr := other.Rotate(true)
+	require.NoError(t, rotateErr)
+
+	token, signErr := other.Sign(jwt.MapClaims{"sub": "user"})
+	require.NoError(t, signErr)
+	ring.loaded = time.Now().Add(-unknownKeyReload)
+	_, parseErr := jwt.Parse(token, ring.Keyfunc)
+	assert.NoError(t, parseErr, "key added by another server is loaded")
+}
+
+func TestKeyRingJWKS(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			ring, store := newTestKeyRing(t, KeyRingParams{Algorithm: algorithm, Overlap: time.Hour})
+			key, parseErr := parseRingKey(store.keys[0])
+			require.NoError(t, parseErr)
+
+			set := ring.JWKS()
+			require.Len(t, set.Keys, 1)
+			jwk := set.Keys[0]
+			assert.Equal(t, store.keys[0].ID, jwk.KeyID)
+			assert.Equal(t, algorithm, jwk.Algorithm)
+			assert.Equal(t, "sig", jwk.Use)
+			switch public := key.private.Public().(type) {
+			case ed25519.PublicKey:
+				assert.Equal(t, "OKP", jwk.KeyType)
+				assert.Equal(t, "Ed25519", jwk.Curve)
+				assert.Equal(t, base64.RawURLEncoding.EncodeToString(public), jwk.X)
+			case *rsa.PublicKey:
+				assert.Equal(t, "RSA", jwk.KeyType)
+				n, decodeErr := base64.RawURLEncoding.DecodeString(jwk.N)
+				require.NoError(t, decodeErr)
+				assert.Equal(t, 0, public.N.Cmp(new(big.Int).SetBytes(n)))
+				assert.Equal(t, "AQAB", jwk.E)
+			}
+		})
+	}
+}
+
+func TestNewKeyRingAlgorithm(t *testing.T) {
+	_, ringErr := NewKeyRing(&memoryKeys{}, KeyRingParams{Algorithm: "HS256"})
+	assert.ErrorIs(t, ringErr, ErrAlgorithm)
+}
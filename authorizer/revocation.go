@@ -0,0 +1,176 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// revocationStore storage of revoked tokens, it is implemented by admin database
+type revocationStore interface {
+	RevokeToken(token *models.RevokedToken) error
+	RevokeUserTokens(userID uuid.UUID, before time.Time) ([]uuid.UUID, error)
+	RevokeDeletedUserTokens(userID uuid.UUID, before time.Time) ([]uuid.UUID, error)
+	RevokedTokens() ([]models.RevokedToken, error)
+	RevokedUsers(after time.Time) ([]models.UserRevocation, error)
+	RevokeSession(userID, id uuid.UUID) (time.Time, error)
+	RevokedSessions(after time.Time) ([]models.Session, error)
+}
+
+// Revocations in-memory cache of revoked access tokens, so check of token doesnt hit database.
+// Revocations of this server are cached at once, revocations of other servers are loaded by Sync
+type Revocations struct {
+	store    revocationStore
+	lifetime time.Duration
+	mu       sync.RWMutex
+	tokens   map[uuid.UUID]time.Time
+	users    map[uuid.UUID]time.Time
+	sessions map[uuid.UUID]time.Time
+}
+
+// NewRevocations initializer of Revocations, lifetime is lifetime of access tokens:
+// older revocations of users dont affect any valid token
+func NewRevocations(store revocationStore, lifetime time.Duration) *Revocations {
+	return &Revocations{
+		store:    store,
+		lifetime: lifetime,
+		tokens:   make(map[uuid.UUID]time.Time),
+		users:    make(map[uuid.UUID]time.Time),
+		sessions: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Revoked reports whether access token with id of user and session issued at time is revoked.
+// Issue time of token has seconds precision, so token issued in the second of logout everywhere is revoked too
+func (r *Revocations) Revoked(jti, userID, sessionID uuid.UUID, issued time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.tokens[jti]; ok {
+		return true
+	}
+	if _, ok := r.sessions[sessionID]; ok {
+		return true
+	}
+	validAfter, ok := r.users[userID]
+	return ok && !issued.After(validAfter.Truncate(time.Second))
+}
+
+// RevokeToken revokes access token until it expires
+func (r *Revocations) RevokeToken(jti, userID uuid.UUID, expires time.Time) error {
+	if err := r.store.RevokeToken(&models.RevokedToken{JTI: jti, UserID: userID, Expires: expires}); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.tokens[jti] = expires
+	r.mu.Unlock()
+	return nil
+}
+
+// RevokeUser revokes all tokens of user issued before now and all sessions of user,
+// so tokens of sessions are revoked whenever they are issued. Personal access tokens of user are removed
+func (r *Revocations) RevokeUser(userID uuid.UUID) error {
+	return r.revokeUser(userID, r.store.RevokeUserTokens)
+}
+
+// RevokeDeletedUser revokes tokens and sessions of user like RevokeUser and marks user as deleted
+// in one transaction of storage
+func (r *Revocations) RevokeDeletedUser(userID uuid.UUID) error {
+	return r.revokeUser(userID, r.store.RevokeDeletedUserTokens)
+}
+
+// revokeUser revokes tokens of user by storage and caches revocations
+func (r *Revocations) revokeUser(userID uuid.UUID, revoke func(userID uuid.UUID, before time.Time) ([]uuid.UUID, error)) error {
+	before := time.Now()
+	sessions, err := revoke(userID, before)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.users[userID] = before
+	for _, sessionID := range sessions {
+		r.sessions[sessionID] = before
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// RevokeSession revokes session of user with all its tokens
+func (r *Revocations) RevokeSession(userID, sessionID uuid.UUID) error {
+	revoked, revokeErr := r.store.RevokeSession(userID, sessionID)
+	if revokeErr != nil {
+		return revokeErr
+	}
+	r.mu.Lock()
+	r.sessions[sessionID] = revoked
+	r.mu.Unlock()
+	return nil
+}
+
+// Load replaces cache by revocations from storage
+func (r *Revocations) Load() error {
+	revokedTokens, tokensErr := r.store.RevokedTokens()
+	if tokensErr != nil {
+		return tokensErr
+	}
+	revokedUsers, usersErr := r.store.RevokedUsers(time.Now().Add(-r.lifetime))
+	if usersErr != nil {
+		return usersErr
+	}
+	revokedSessions, sessionsErr := r.store.RevokedSessions(time.Now().Add(-r.lifetime))
+	if sessionsErr != nil {
+		return sessionsErr
+	}
+	tokens := make(map[uuid.UUID]time.Time, len(revokedTokens))
+	for _, token := range revokedTokens {
+		tokens[token.JTI] = token.Expires
+	}
+	users := make(map[uuid.UUID]time.Time, len(revokedUsers))
+	for _, user := range revokedUsers {
+		users[user.UserID] = user.ValidAfter
+	}
+	sessions := make(map[uuid.UUID]time.Time, len(revokedSessions))
+	for _, session := range revokedSessions {
+		sessions[session.ID] = *session.Revoked
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// revocations made while loading are kept
+	now := time.Now()
+	for jti, expires := range r.tokens {
+		if expires.After(now) {
+			tokens[jti] = expires
+		}
+	}
+	for userID, validAfter := range r.users {
+		if validAfter.After(users[userID]) && validAfter.After(now.Add(-r.lifetime)) {
+			users[userID] = validAfter
+		}
+	}
+	for sessionID, revoked := range r.sessions {
+		if revoked.After(now.Add(-r.lifetime)) {
+			sessions[sessionID] = revoked
+		}
+	}
+	r.tokens, r.users, r.sessions = tokens, users, sessions
+	return nil
+}
+
+// Sync loads revocations from storage with interval until context is done
+func (r *Revocations) Sync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if loadErr := r.Load(); loadErr != nil {
+			log.Println("revocations:", loadErr)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRevocations keeps revocations in memory, it is shared by caches like database of servers
+type memoryRevocations struct {
+	mu       sync.Mutex
+	tokens   []models.RevokedToken
+	users    map[uuid.UUID]time.Time
+	sessions map[uuid.UUID]time.Time
+	// active sessions that are not revoked by users
+	active  map[uuid.UUID]uuid.UUID
+	deleted map[uuid.UUID]bool
+}
+
+func (m *memoryRevocations) RevokeToken(token *models.RevokedToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func (m *memoryRevocations) RevokeUserTokens(userID uuid.UUID, before time.Time) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users[userID] = before
+	var sessions []uuid.UUID
+	for id, sessionUserID := range m.active {
+		if sessionUserID == userID {
+			m.sessions[id] = before
+			delete(m.active, id)
+			sessions = append(sessions, id)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *memoryRevocations) RevokeDeletedUserTokens(userID uuid.UUID, before time.Time) ([]uuid.UUID, error) {
+	sessions, revokeErr := m.RevokeUserTokens(userID, before)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted[userID] = true
+	return sessions, revokeErr
+}
+
+func (m *memoryRevocations) RevokedTokens() ([]models.RevokedToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var tokens []models.RevokedToken
+	for _, token := range m.tokens {
+		if token.Expires.After(time.Now()) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *memoryRevocations) RevokedUsers(after time.Time) ([]models.UserRevocation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var users []models.UserRevocation
+	for userID, validAfter := range m.users {
+		if validAfter.After(after) {
+			users = append(users, models.UserRevocation{UserID: userID, ValidAfter: validAfter})
+		}
+	}
+	return users, nil
+}
+
+func (m *memoryRevocations) RevokeSession(_, id uuid.UUID) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	revoked := time.Now()
+	m.sessions[id] = revoked
+	return revoked, nil
+}
+
+func (m *memoryRevocations) RevokedSessions(after time.Time) ([]models.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sessions []models.Session
+	for id, revoked := range m.sessions {
+		if revoked.After(after) {
+			revoked := revoked
+			sessions = append(sessions, models.Session{ID: id, Revoked: &revoked})
+		}
+	}
+	return sessions, nil
+}
+
+func TestRevocations(t *testing.T) {
+	userID, otherID := uuid.New(), uuid.New()
+	sessionID, revokedSessionID, otherSessionID := uuid.New(), uuid.New(), uuid.New()
+	store := &memoryRevocations{users: make(map[uuid.UUID]time.Time), sessions: make(map[uuid.UUID]time.Time),
+		active: map[uuid.UUID]uuid.UUID{sessionID: userID, otherSessionID: otherID}, deleted: make(map[uuid.UUID]bool)}
+	local := NewRevocations(store, time.Hour)
+	remote := NewRevocations(store, time.Hour)
+
+	revokedJTI, activeJTI, expiredJTI := uuid.New(), uuid.New(), uuid.New()
+	issued := time.Now().Add(-time.Minute)
+	require.NoError(t, local.RevokeToken(revokedJTI, userID, time.Now().Add(time.Hour)))
+	require.NoError(t, local.RevokeToken(expiredJTI, userID, time.Now().Add(-time.Second)))
+	// issue time of JWT has seconds precision
+	issuedBeforeLogout := time.Now().Truncate(time.Second)
+	require.NoError(t, local.RevokeUser(otherID))
+	require.NoError(t, local.RevokeSession(userID, revokedSessionID))
+
+	tests := []struct {
+		name    string
+		jti     uuid.UUID
+		userID  uuid.UUID
+		session uuid.UUID
+		issued  time.Time
+		revoked bool
+	}{
+		{name: "revoked token", jti: revokedJTI, userID: userID, session: sessionID, issued: issued, revoked: true},
+		{name: "active token", jti: activeJTI, userID: userID, session: sessionID, issued: issued},
+		{name: "token of revoked session", jti: uuid.New(), userID: userID, session: revokedSessionID, issued: time.Now(), revoked: true},
+		{name: "token issued before logout everywhere", jti: uuid.New(), userID: otherID, issued: issued, revoked: true},
+		{name: "token issued after logout everywhere", jti: uuid.New(), userID: otherID, issued: time.Now().Add(time.Second)},
+		{name: "token issued in the same second before logout everywhere", jti: uuid.New(), userID: otherID, issued: issuedBeforeLogout, revoked: true},
+		{name: "token of session revoked by logout everywhere", jti: uuid.New(), userID: otherID, session: otherSessionID, issued: time.Now().Add(time.Second), revoked: true},
+	}
+	assert.False(t, remote.Revoked(revokedJTI, userID, sessionID, issued), "another server before sync")
+	require.NoError(t, remote.Load())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.revoked, local.Revoked(tt.jti, tt.userID, tt.session, tt.issued), "server of revocation")
+			assert.Equal(t, tt.revoked, remote.Revoked(tt.jti, tt.userID, tt.session, tt.issued), "another server after sync")
+		})
+	}
+
+	t.Run("expired revocations are dropped", func(t *testing.T) {
+		require.NoError(t, local.Load())
+		local.mu.RLock()
+		defer local.mu.RUnlock()
+		assert.NotContains(t, local.tokens, expiredJTI)
+		assert.Contains(t, local.tokens, revokedJTI)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		deletedID, deletedSessionID := uuid.New(), uuid.New()
+		store.mu.Lock()
+		store.active[deletedSessionID] = deletedID
+		store.mu.Unlock()
+		require.NoError(t, local.RevokeDeletedUser(deletedID))
+		assert.True(t, store.deleted[deletedID])
+		assert.False(t, store.deleted[userID])
+		assert.True(t, local.Revoked(uuid.New(), deletedID, deletedSessionID, time.Now().Add(time.Second)))
+		assert.True(t, local.Revoked(uuid.New(), deletedID, uuid.Nil, time.Now().Add(-time.Second)))
+	})
+}
@@ -0,0 +1,167 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrThrottled = errors.New("too many failed login attempts")
+
+// ThrottledError login is throttled, next attempt is allowed after RetryAfter
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+// Error returns message with time of next attempt
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrThrottled, e.RetryAfter.Round(time.Second))
+}
+
+// Is reports whether target is ErrThrottled
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// ThrottlePolicy limits failed logins: after free failures every next attempt waits delay,
+// that doubles with every failure up to max delay. Too many failures lock logins for a while.
+// Failures are forgotten after window without failures
+type ThrottlePolicy struct {
+	Free      int
+	Delay     time.Duration
+	MaxDelay  time.Duration
+	LockAfter int
+	LockFor   time.Duration
+	Window    time.Duration
+}
+
+// Policies of throttling, address could be shared by many users behind NAT, so it gets more attempts
+var (
+	AccountPolicy = ThrottlePolicy{Free: 3, Delay: time.Second, MaxDelay: time.Minute, LockAfter: 10, LockFor: 15 * time.Minute, Window: time.Hour}
+	IPPolicy      = ThrottlePolicy{Free: 10, Delay: time.Second, MaxDelay: time.Minute, LockAfter: 50, LockFor: 15 * time.Minute, Window: time.Hour}
+)
+
+// delay returns delay of attempt after count of failures
+func (p ThrottlePolicy) delay(failures int) time.Duration {
+	if failures <= p.Free {
+		return 0
+	}
+	delay := p.Delay
+	for i := p.Free + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// wait returns time until next attempt is allowed
+func (p ThrottlePolicy) wait(failure *models.LoginFailure, now time.Time) time.Duration {
+	if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+		return failure.LockedUntil.Sub(now)
+	}
+	if failure.LastFailure.Before(now.Add(-p.Window)) {
+		return 0
+	}
+	if next := failure.LastFailure.Add(p.delay(failure.Failures)); next.After(now) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+// throttleStore storage of failed logins, it is implemented by admin database
+type throttleStore interface {
+	LoginFailure(kind, subject string) (*models.LoginFailure, error)
+	AddLoginFailure(kind, subject string, window time.Duration) (*models.LoginFailure, error)
+	LockLogin(lockout *models.Lockout) error
+	ResetLoginFailures(kind, subject string) error
+}
+
+// Throttle tracks failed logins by address of client and by account, failures are kept in storage,
+// so they are shared by servers
+type Throttle struct {
+	store    throttleStore
+	policies map[string]ThrottlePolicy
+}
+
+// NewThrottle initializer of Throttle with policies of accounts and addresses
+func NewThrottle(store throttleStore, account, ip ThrottlePolicy) *Throttle {
+	return &Throttle{
+		store:    store,
+		policies: map[string]ThrottlePolicy{models.ThrottleAccount: account, models.ThrottleIP: ip},
+	}
+}
+
+// Check returns ThrottledError if login from address to account is not allowed now
+func (t *Throttle) Check(ip, account string) error {
+	var wait time.Duration
+	for kind, subject := range throttleSubjects(ip, account) {
+		failure, failureErr := t.store.LoginFailure(kind, subject)
+		if failureErr != nil {
+			if errors.Is(failureErr, storage.ErrNoValues) {
+				continue
+			}
+			return failureErr
+		}
+		if kindWait := t.policies[kind].wait(failure, time.Now()); kindWait > wait {
+			wait = kindWait
+		}
+	}
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Fail counts failed login from address to account, too many failures lock logins
+func (t *Throttle) Fail(ip, account string) error {
+	for kind, subject := range throttleSubjects(ip, account) {
+		policy := t.policies[kind]
+		failure, failureErr := t.store.AddLoginFailure(kind, subject, policy.Window)
+		if failureErr != nil {
+			return failureErr
+		}
+		if failure.Failures < policy.LockAfter {
+			continue
+		}
+		lockout := &models.Lockout{
+			ID:          uuid.New(),
+			Kind:        kind,
+			Subject:     subject,
+			Failures:    failure.Failures,
+			LockedUntil: time.Now().Add(policy.LockFor),
+		}
+		if lockErr := t.store.LockLogin(lockout); lockErr != nil {
+			return lockErr
+		}
+		log.Printf("logins of %s %s are locked until %s", kind, subject, lockout.LockedUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Succeed forgets failures of account after successful login, failures of address are kept,
+// so attacker can't reset them by login to own account
+func (t *Throttle) Succeed(account string) error {
+	return t.store.ResetLoginFailures(models.ThrottleAccount, normalizeAccount(account))
+}
+
+// throttleSubjects returns subjects of login by kinds of throttling, unknown address is not tracked
+func throttleSubjects(ip, account string) map[string]string {
+	subjects := map[string]string{models.ThrottleAccount: normalizeAccount(account)}
+	if ip != "" {
+		subjects[models.ThrottleIP] = ip
+	}
+	return subjects
+}
+
+// normalizeAccount returns email of account in one form
+func normalizeAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
@@ -0,0 +1,104 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryFailures keeps failed logins in memory
+type memoryFailures struct {
+	failures map[string]*models.LoginFailure
+	lockouts []models.Lockout
+}
+
+func (m *memoryFailures) LoginFailure(kind, subject string) (*models.LoginFailure, error) {
+	failure, ok := m.failures[kind+" "+subject]
+	if !ok {
+		return nil, storage.ErrNoValues
+	}
+	copied := *failure
+	return &copied, nil
+}
+
+func (m *memoryFailures) AddLoginFailure(kind, subject string, window time.Duration) (*models.LoginFailure, error) {
+	failure, ok := m.failures[kind+" "+subject]
+	if !ok || failure.LastFailure.Before(time.Now().Add(-window)) {
+		failure = &models.LoginFailure{Kind: kind, Subject: subject}
+		m.failures[kind+" "+subject] = failure
+	}
+	failure.Failures++
+	failure.LastFailure = time.Now()
+	copied := *failure
+	return &copied, nil
+}
+
+func (m *memoryFailures) LockLogin(lockout *models.Lockout) error {
+	failure := m.failures[lockout.Kind+" "+lockout.Subject]
+	failure.Failures = 0
+	failure.LockedUntil = &lockout.LockedUntil
+	m.lockouts = append(m.lockouts, *lockout)
+	return nil
+}
+
+func (m *memoryFailures) ResetLoginFailures(kind, subject string) error {
+	delete(m.failures, kind+" "+subject)
+	return nil
+}
+
+func TestThrottlePolicyDelay(t *testing.T) {
+	policy := ThrottlePolicy{Free: 2, Delay: time.Second, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: 8 * time.Second},
+		{failures: 7, want: 10 * time.Second},
+		{failures: 100, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.delay(tt.failures), "failures: %d", tt.failures)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	store := &memoryFailures{failures: make(map[string]*models.LoginFailure)}
+	account := ThrottlePolicy{Free: 1, Delay: time.Minute, MaxDelay: time.Hour, LockAfter: 3, LockFor: time.Hour, Window: time.Hour}
+	ip := ThrottlePolicy{Free: 5, Delay: time.Minute, MaxDelay: time.Hour, LockAfter: 10, LockFor: time.Hour, Window: time.Hour}
+	throttle := NewThrottle(store, account, ip)
+
+	require.NoError(t, throttle.Check("10.0.0.1", "user@example.com"))
+	require.NoError(t, throttle.Fail("10.0.0.1", "user@example.com"))
+	assert.NoError(t, throttle.Check("10.0.0.1", "User@Example.com"), "free failure")
+
+	require.NoError(t, throttle.Fail("10.0.0.1", "user@example.com"))
+	checkErr := throttle.Check("10.0.0.2", "USER@example.com")
+	var throttled *ThrottledError
+	require.True(t, errors.As(checkErr, &throttled), "account is throttled from any address")
+	assert.ErrorIs(t, checkErr, ErrThrottled)
+	assert.InDelta(t, time.Minute, throttled.RetryAfter, float64(time.Second))
+	assert.NoError(t, throttle.Check("10.0.0.1", "other@example.com"), "address is not throttled yet")
+
+	require.NoError(t, throttle.Fail("10.0.0.1", "user@example.com"))
+	require.Len(t, store.lockouts, 1)
+	assert.Equal(t, models.ThrottleAccount, store.lockouts[0].Kind)
+	assert.Equal(t, "user@example.com", store.lockouts[0].Subject)
+	require.True(t, errors.As(throttle.Check("10.0.0.1", "user@example.com"), &throttled))
+	assert.InDelta(t, time.Hour, throttled.RetryAfter, float64(time.Second), "account is locked")
+
+	require.NoError(t, throttle.Succeed("user@example.com"))
+	assert.NoError(t, throttle.Check("10.0.0.2", "user@example.com"), "failures of account are forgotten after login")
+	failure, failureErr := store.LoginFailure(models.ThrottleIP, "10.0.0.1")
+	require.NoError(t, failureErr)
+	assert.Equal(t, 3, failure.Failures, "failures of address are kept")
+}
@@ -0,0 +1,269 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"AlexSarva/GophKeeper/totp"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/google/uuid"
+)
+
+var ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+var ErrTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+var ErrInvalidTwoFactorCode = errors.New("two-factor code is invalid or already used")
+var ErrInvalidChallenge = errors.New("two-factor challenge is invalid or expired")
+
+const (
+	// challengeAudience audience of challenge token, access tokens have no audience,
+	// so challenge token is never accepted as access token
+	challengeAudience = "2fa"
+	// challengeDuration lifetime of challenge token
+	challengeDuration = 5 * time.Minute
+	// twoFactorIssuer issuer shown by authenticator apps
+	twoFactorIssuer = "GophKeeper"
+	// recoveryCodesCount count of recovery codes issued after two-factor authentication is enabled
+	recoveryCodesCount = 10
+	// recoveryCodeSize size of random part of recovery code
+	recoveryCodeSize = 5
+)
+
+// recoveryEncoding encoding of recovery codes, they are read and typed by people
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// SignInTwoFactor completes login by challenge token with TOTP code or recovery code,
+// returns info about user with new pair of tokens. Invalid codes are throttled as failed logins,
+// challenge is used up by the first successful login
+func (a *Authorizer) SignInTwoFactor(login *models.TwoFactorLogin, client *models.SessionClient) (*models.User, error) {
+	challengeClaims, challengeErr := a.parseChallenge(login.Challenge)
+	if challengeErr != nil {
+		return nil, challengeErr
+	}
+	userID := challengeClaims.UserID
+	user, userErr := a.adminDB.GetUserInfo(userID)
+	if userErr != nil {
+		return nil, userErr
+	}
+	if throttleErr := a.throttle.Check(client.IP, user.Email); throttleErr != nil {
+		return nil, throttleErr
+	}
+	if verifyErr := a.verifyCode(userID, login.Code); verifyErr != nil {
+		if errors.Is(verifyErr, ErrInvalidTwoFactorCode) {
+			return nil, a.failLogin(client.IP, user.Email, verifyErr)
+		}
+		return nil, verifyErr
+	}
+	if useErr := a.useChallenge(challengeClaims); useErr != nil {
+		return nil, useErr
+	}
+	if succeedErr := a.throttle.Succeed(user.Email); succeedErr != nil {
+		return nil, succeedErr
+	}
+
+	tokens, tokensErr := a.startSession(userID, client)
+	if tokensErr != nil {
+		return nil, tokensErr
+	}
+	user.Password = ""
+	setTokens(user, tokens)
+
+	return user, nil
+}
+
+// SetupTwoFactor generates new TOTP secret of user, two-factor authentication is enabled
+// only after confirmation by code of authenticator app
+func (a *Authorizer) SetupTwoFactor(userID uuid.UUID) (*models.TwoFactorSetup, error) {
+	user, userErr := a.adminDB.GetUserInfo(userID)
+	if userErr != nil {
+		return nil, userErr
+	}
+	secret, secretErr := totp.GenerateSecret()
+	if secretErr != nil {
+		return nil, secretErr
+	}
+	if setErr := a.adminDB.SetTwoFactorSecret(userID, secret); setErr != nil {
+		if errors.Is(setErr, storage.ErrDuplicatePK) {
+			return nil, ErrTwoFactorEnabled
+		}
+		return nil, setErr
+	}
+	return &models.TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.URI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication by code of authenticator app,
+// returns recovery codes that are shown only once. Invalid codes are throttled as failed logins
+func (a *Authorizer) ConfirmTwoFactor(userID uuid.UUID, code string, client *models.SessionClient) (*models.RecoveryCodes, error) {
+	user, userErr := a.adminDB.GetUserInfo(userID)
+	if userErr != nil {
+		return nil, userErr
+	}
+	if throttleErr := a.throttle.Check(client.IP, user.Email); throttleErr != nil {
+		return nil, throttleErr
+	}
+	twoFactor, getErr := a.adminDB.GetTwoFactor(userID)
+	if getErr != nil {
+		if errors.Is(getErr, storage.ErrNoValues) {
+			return nil, ErrTwoFactorDisabled
+		}
+		return nil, getErr
+	}
+	if twoFactor.Enabled != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+	step, ok := totp.Validate(string(twoFactor.Secret), strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, a.failLogin(client.IP, user.Email, ErrInvalidTwoFactorCode)
+	}
+	if succeedErr := a.throttle.Succeed(user.Email); succeedErr != nil {
+		return nil, succeedErr
+	}
+
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		random := make([]byte, recoveryCodeSize)
+		if _, randErr := rand.Read(random); randErr != nil {
+			return nil, ErrGenerateToken
+		}
+		encoded := strings.ToLower(recoveryEncoding.EncodeToString(random))
+		codes[i] = encoded[:4] + "-" + encoded[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if enableErr := a.adminDB.EnableTwoFactor(userID, step, hashes); enableErr != nil {
+		if errors.Is(enableErr, storage.ErrNoValues) {
+			return nil, ErrTwoFactorEnabled
+		}
+		return nil, enableErr
+	}
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTwoFactor disables two-factor authentication, user proves second factor by code.
+// Invalid codes are throttled as failed logins
+func (a *Authorizer) DisableTwoFactor(userID uuid.UUID, code string, client *models.SessionClient) error {
+	user, userErr := a.adminDB.GetUserInfo(userID)
+	if userErr != nil {
+		return userErr
+	}
+	if throttleErr := a.throttle.Check(client.IP, user.Email); throttleErr != nil {
+		return throttleErr
+	}
+	if verifyErr := a.verifyCode(userID, code); verifyErr != nil {
+		if errors.Is(verifyErr, ErrInvalidTwoFactorCode) {
+			return a.failLogin(client.IP, user.Email, verifyErr)
+		}
+		return verifyErr
+	}
+	if succeedErr := a.throttle.Succeed(user.Email); succeedErr != nil {
+		return succeedErr
+	}
+	return a.adminDB.DisableTwoFactor(userID)
+}
+
+// twoFactorEnabled reports whether login of user requires second factor
+func (a *Authorizer) twoFactorEnabled(userID uuid.UUID) (bool, error) {
+	twoFactor, getErr := a.adminDB.GetTwoFactor(userID)
+	if getErr != nil {
+		if errors.Is(getErr, storage.ErrNoValues) {
+			return false, nil
+		}
+		return false, getErr
+	}
+	return twoFactor.Enabled != nil, nil
+}
+
+// verifyCode checks TOTP code or recovery code of user. Every TOTP code is accepted only once,
+// as well as recovery code
+func (a *Authorizer) verifyCode(userID uuid.UUID, code string) error {
+	twoFactor, getErr := a.adminDB.GetTwoFactor(userID)
+	if getErr != nil {
+		if errors.Is(getErr, storage.ErrNoValues) {
+			return ErrTwoFactorDisabled
+		}
+		return getErr
+	}
+	if twoFactor.Enabled == nil {
+		return ErrTwoFactorDisabled
+	}
+
+	var useErr error
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(string(twoFactor.Secret), code, time.Now()); ok {
+		useErr = a.adminDB.UseTwoFactorStep(userID, step)
+	} else {
+		useErr = a.adminDB.UseRecoveryCode(userID, hashRecoveryCode(code))
+	}
+	if useErr != nil {
+		if errors.Is(useErr, storage.ErrNoValues) {
+			return ErrInvalidTwoFactorCode
+		}
+		return useErr
+	}
+	return nil
+}
+
+// challengeToken creates signed challenge JWT of user whose password is checked
+func (a *Authorizer) challengeToken(userID uuid.UUID) (*models.TwoFactorChallenge, error) {
+	expires := time.Now().Add(challengeDuration)
+
+	tokenValue, tokenErr := a.keys.Sign(&claims{
+		StandardClaims: jwt.StandardClaims{
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.At(expires),
+			IssuedAt:  jwt.At(time.Now()),
+			ID:        uuid.New().String(),
+		},
+		UserID: userID,
+	})
+	if tokenErr != nil {
+		return nil, ErrGenerateToken
+	}
+	return &models.TwoFactorChallenge{Challenge: tokenValue, Expires: expires}, nil
+}
+
+// parseChallenge checks challenge JWT, returns its claims
+func (a *Authorizer) parseChallenge(challenge string) (*claims, error) {
+	token, err := jwt.ParseWithClaims(challenge, &claims{}, a.keys.Keyfunc, jwt.WithAudience(challengeAudience))
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	challengeClaims, ok := token.Claims.(*claims)
+	if !ok || !token.Valid || challengeClaims.ExpiresAt == nil {
+		return nil, ErrInvalidChallenge
+	}
+	return challengeClaims, nil
+}
+
+// useChallenge records use of challenge until it expires, challenge that is already used is invalid
+func (a *Authorizer) useChallenge(challengeClaims *claims) error {
+	jti, jtiErr := uuid.Parse(challengeClaims.ID)
+	if jtiErr != nil {
+		return ErrInvalidChallenge
+	}
+	useErr := a.adminDB.UseChallenge(&models.RevokedToken{
+		JTI:     jti,
+		UserID:  challengeClaims.UserID,
+		Expires: challengeClaims.ExpiresAt.Time,
+	})
+	if errors.Is(useErr, storage.ErrNoValues) {
+		return ErrInvalidChallenge
+	}
+	return useErr
+}
+
+// hashRecoveryCode returns hash of recovery code, case and dashes of typed code are ignored
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
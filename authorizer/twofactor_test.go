@@ -0,0 +1,98 @@
+package authorizer
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/totp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeToken(t *testing.T) {
+	keys, _ := newTestKeyRing(t, KeyRingParams{Algorithm: AlgorithmEdDSA, Overlap: time.Hour})
+	a := &Authorizer{keys: keys}
+	userID := uuid.New()
+
+	challenge, challengeErr := a.challengeToken(userID)
+	require.NoError(t, challengeErr)
+	challengeClaims, parseErr := a.parseChallenge(challenge.Challenge)
+	require.NoError(t, parseErr)
+	assert.Equal(t, userID, challengeClaims.UserID)
+
+	_, _, claimsErr := a.parseClaims(challenge.Challenge)
+	assert.Error(t, claimsErr, "challenge is not access token")
+
+	accessToken, _, tokenErr := a.accessToken(userID, uuid.New())
+	require.NoError(t, tokenErr)
+	_, parseErr = a.parseChallenge(accessToken)
+	assert.ErrorIs(t, parseErr, ErrInvalidChallenge, "access token is not challenge")
+
+	otherKeys, _ := newTestKeyRing(t, KeyRingParams{Algorithm: AlgorithmEdDSA, Overlap: time.Hour})
+	other := &Authorizer{keys: otherKeys}
+	_, parseErr = other.parseChallenge(challenge.Challenge)
+	assert.ErrorIs(t, parseErr, ErrInvalidChallenge, "challenge of another key")
+}
+
+func TestTwoFactorSingleUse(t *testing.T) {
+	a, _, user := newTestAccount(t)
+	client := &models.SessionClient{IP: "192.0.2.1"}
+	setup, setupErr := a.SetupTwoFactor(user.ID)
+	require.NoError(t, setupErr)
+	code, codeErr := totp.Code(setup.Secret, time.Now())
+	require.NoError(t, codeErr)
+	recovery, confirmErr := a.ConfirmTwoFactor(user.ID, code, client)
+	require.NoError(t, confirmErr)
+
+	challenge, challengeErr := a.challengeToken(user.ID)
+	require.NoError(t, challengeErr)
+	_, signInErr := a.SignInTwoFactor(&models.TwoFactorLogin{Challenge: challenge.Challenge, Code: code}, client)
+	assert.ErrorIs(t, signInErr, ErrInvalidTwoFactorCode, "TOTP code of used step")
+
+	_, signInErr = a.SignInTwoFactor(&models.TwoFactorLogin{Challenge: challenge.Challenge, Code: recovery.Codes[0]}, client)
+	require.NoError(t, signInErr)
+	_, signInErr = a.SignInTwoFactor(&models.TwoFactorLogin{Challenge: challenge.Challenge, Code: recovery.Codes[1]}, client)
+	assert.ErrorIs(t, signInErr, ErrInvalidChallenge, "used challenge")
+}
+
+func TestTwoFactorCodesThrottled(t *testing.T) {
+	a, _, user := newTestAccount(t)
+	client := &models.SessionClient{IP: "192.0.2.2"}
+	setup, setupErr := a.SetupTwoFactor(user.ID)
+	require.NoError(t, setupErr)
+	// wrong code is the next one of authenticator app, so it is never valid
+	wrong, wrongErr := totp.Code(setup.Secret, time.Now().Add(time.Hour))
+	require.NoError(t, wrongErr)
+	for i := 0; i <= AccountPolicy.Free; i++ {
+		_, confirmErr := a.ConfirmTwoFactor(user.ID, wrong, client)
+		assert.Error(t, confirmErr)
+	}
+	var throttled *ThrottledError
+	code, codeErr := totp.Code(setup.Secret, time.Now())
+	require.NoError(t, codeErr)
+	_, confirmErr := a.ConfirmTwoFactor(user.ID, code, client)
+	assert.ErrorAs(t, confirmErr, &throttled, "valid code is not checked while confirmation is throttled")
+	disableErr := a.DisableTwoFactor(user.ID, code, client)
+	assert.ErrorAs(t, disableErr, &throttled, "disable shares throttle of account")
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		typed string
+		equal bool
+	}{
+		{name: "same code", typed: "abcd-efgh", equal: true},
+		{name: "upper case", typed: "ABCD-EFGH", equal: true},
+		{name: "without dash", typed: "abcdefgh", equal: true},
+		{name: "with spaces", typed: "abcd efgh", equal: true},
+		{name: "another code", typed: "abcd-efgi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.equal, hashRecoveryCode(tt.typed) == hashRecoveryCode("abcd-efgh"))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"AlexSarva/GophKeeper/agent"
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/crypto/cryptoblock"
+	"AlexSarva/GophKeeper/models"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// runAgent unlocks keys once and serves crypto operations over unix socket until idle timeout.
+// Secret is asked in terminal if it isn't set in config, so it doesn't appear in process list
+func runAgent(args []string) error {
+	var cfg models.GUIConfig
+	var configPath string
+	var idle time.Duration
+	var lock bool
+	fs := commandFlags("agent", &cfg, &configPath)
+	fs.StringVar(&cfg.Agent, "socket", "", "agent socket (default agent.sock in keys folder)")
+	fs.DurationVar(&idle, "idle", 15*time.Minute, "forget keys after idle time without requests")
+	fs.BoolVar(&lock, "lock", false, "make running agent forget keys and stop")
+	if readErr := readCommandConfig(fs, args, &cfg, &configPath); readErr != nil {
+		return readErr
+	}
+	if cfg.Agent == "" {
+		if cfg.KeysPath == "" {
+			return ErrNoKeysPath
+		}
+		cfg.Agent = filepath.Join(cfg.KeysPath, agent.SocketFile)
+	}
+
+	if lock {
+		agentClient, dialErr := agent.Dial(cfg.Agent)
+		if dialErr != nil {
+			return dialErr
+		}
+		return agentClient.Lock()
+	}
+
+	if cfg.KeysPath == "" {
+		return ErrNoKeysPath
+	}
+	if cfg.Secret == "" {
+		secret, secretErr := prompt("Secret", true)
+		if secretErr != nil {
+			return secretErr
+		}
+		cfg.Secret = secret
+	}
+	if cfg.Secret == "" {
+		return ErrNoSecret
+	}
+	cryptorizer, cryptorizerErr := crypto.InitCryptorizer(cfg.KeysPath, cfg.KeysSize, cfg.Algorithm)
+	if cryptorizerErr != nil {
+		return cryptorizerErr
+	}
+	keysAgent := agent.New(cryptorizer, cryptoblock.InitAEADCrypto(cfg.Secret), cfg.KeysPath, idle)
+	cfg.Secret = ""
+
+	listener, listenErr := agent.Listen(cfg.Agent)
+	if listenErr != nil {
+		return listenErr
+	}
+	log.Printf("keys agent is listening on %s, keys are forgotten after %s without requests", cfg.Agent, idle)
+	log.Printf("run client with: -agent %s", cfg.Agent)
+	if serveErr := keysAgent.Serve(listener); serveErr != nil {
+		return serveErr
+	}
+	log.Println("keys agent is locked")
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"AlexSarva/GophKeeper/crypto/keybundle"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/utils/qrtext"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// backupKey splits private keys and secret of files encryption in Shamir shares
+// and prints every share as mnemonic and QR code
+func backupKey(args []string) error {
+	var cfg models.GUIConfig
+	var configPath string
+	var shares, threshold int
+	var noQR bool
+	fs := commandFlags("backup-key", &cfg, &configPath)
+	fs.IntVar(&shares, "shares", 5, "number of shares")
+	fs.IntVar(&threshold, "threshold", 3, "number of shares required to recover keys")
+	fs.BoolVar(&noQR, "no-qr", false, "dont print QR codes")
+	if readErr := readCommandConfig(fs, args, &cfg, &configPath); readErr != nil {
+		return readErr
+	}
+	if cfg.KeysPath == "" {
+		return ErrNoKeysPath
+	}
+	if cfg.Secret == "" {
+		log.Println("Warning! Secret is not set, only keys will be saved in shares")
+	}
+
+	keyShares, splitErr := keybundle.Split(cfg.KeysPath, cfg.Secret, shares, threshold)
+	if splitErr != nil {
+		return splitErr
+	}
+
+	out := os.Stdout
+	fmt.Fprintf(out, "Keys are split in %d shares, any %d of them restore keys.\n", shares, threshold)
+	fmt.Fprintln(out, "Keep shares in different places, nobody should have access to the threshold of them.")
+	for _, share := range keyShares {
+		fmt.Fprintf(out, "\n=== Share %d of %d (threshold %d) ===\n", share.Number(), shares, threshold)
+		fmt.Fprintln(out, share.Mnemonic())
+		if noQR {
+			continue
+		}
+		if qrErr := printQR(out, share.Compact()); qrErr != nil {
+			return qrErr
+		}
+	}
+	return nil
+}
+
+// recoverKey restores private keys and secret of files encryption from shares entered by user,
+// share could be entered as mnemonic or as text from QR code
+func recoverKey(args []string) error {
+	var cfg models.GUIConfig
+	var configPath string
+	fs := commandFlags("recover-key", &cfg, &configPath)
+	if readErr := readCommandConfig(fs, args, &cfg, &configPath); readErr != nil {
+		return readErr
+	}
+	if cfg.KeysPath == "" {
+		return ErrNoKeysPath
+	}
+
+	var shares []keybundle.Share
+	for len(shares) == 0 || len(shares) < shares[0].Threshold {
+		text, promptErr := prompt(fmt.Sprintf("Share %d", len(shares)+1), false)
+		if promptErr != nil && text == "" {
+			return promptErr
+		}
+		share, shareErr := keybundle.ParseShare(text)
+		if shareErr != nil {
+			log.Println(shareErr)
+			continue
+		}
+		shares = append(shares, share)
+	}
+
+	secret, recoverErr := keybundle.Recover(shares, cfg.KeysPath)
+	if recoverErr != nil {
+		return recoverErr
+	}
+	log.Printf("keys are restored in %s", cfg.KeysPath)
+	if secret != "" {
+		log.Printf("secret for files encryption: %s", secret)
+	}
+	return nil
+}
+
+// printQR prints QR code of text
+func printQR(out io.Writer, text string) error {
+	code, codeErr := qrtext.Render(text)
+	if codeErr != nil {
+		return codeErr
+	}
+	_, writeErr := io.WriteString(out, code)
+	return writeErr
+}
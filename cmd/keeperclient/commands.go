@@ -0,0 +1,137 @@
+package main
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/workclient"
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var ErrNoServerAddress = errors.New("cant obtain server address")
+var ErrNoSecret = errors.New("cant obtain secret for sym crypto")
+var ErrNoKeysPath = errors.New("cant obtain keys filepath")
+
+// command additional client command that works without GUI
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+// commands list of additional client commands, first argument of the client selects command
+var commands = map[string]command{
+	"rotate-keys":      {usage: "generate new keys and re-encrypt all information", run: rotateKeys},
+	"encrypt-metadata": {usage: "encrypt titles and file names saved in plaintext", run: encryptMetadata},
+	"backup-key":       {usage: "split private keys and secret in Shamir shares", run: backupKey},
+	"recover-key":      {usage: "restore private keys and secret from Shamir shares", run: recoverKey},
+	"enroll":           {usage: "request keys for this device from another logged-in device", run: enroll},
+	"approve":          {usage: "send keys to new device that shows enrollment code", run: approve},
+	"agent":            {usage: "unlock keys once and serve them to GUI over unix socket", run: runAgent},
+}
+
+// usage prints client flags and list of additional commands
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	flag.PrintDefaults()
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(out, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", name, commands[name].usage)
+	}
+}
+
+// commandFlags returns flag set with common client flags for command
+func commandFlags(name string, cfg *models.GUIConfig, configPath *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&cfg.ServerAddress, "address", "", "address of service")
+	fs.StringVar(&cfg.KeysPath, "keys", "", "keys filepath")
+	fs.IntVar(&cfg.KeysSize, "size", 0, "keys size")
+	fs.StringVar(&cfg.Algorithm, "algorithm", "", "crypto algorithm: rsa or ecc")
+	fs.StringVar(&cfg.Secret, "secret", "", "secret for sym crypt")
+	fs.StringVar(&cfg.Transport, "transport", "", "transport of service: rest (default) or grpc")
+	fs.StringVar(&cfg.GRPCAddress, "grpc", "", "gRPC address of service")
+	fs.BoolVar(&cfg.GRPCTLS, "grpc-tls", false, "use TLS for gRPC")
+	fs.StringVar(&cfg.DeviceName, "device", "", "name of device in sessions of user, host name by default")
+	fs.StringVar(configPath, "config", "", "JSON config")
+	return fs
+}
+
+// readCommandConfig parses command arguments and reads JSON config if it provided
+func readCommandConfig(fs *flag.FlagSet, args []string, cfg *models.GUIConfig, configPath *string) error {
+	if parseErr := fs.Parse(args); parseErr != nil {
+		return parseErr
+	}
+	if *configPath != "" {
+		if JSONErr := models.ReadClientJSONConfig(cfg, *configPath); JSONErr != nil {
+			return fmt.Errorf("wrong json format: %w", JSONErr)
+		}
+	}
+	cfg.Version = buildVersion
+	return nil
+}
+
+// parseCommandConfig reads command config and checks service address and secret
+func parseCommandConfig(fs *flag.FlagSet, args []string, cfg *models.GUIConfig, configPath *string) error {
+	if readErr := readCommandConfig(fs, args, cfg, configPath); readErr != nil {
+		return readErr
+	}
+	if cfg.ServerAddress == "" {
+		return ErrNoServerAddress
+	}
+	if cfg.Secret == "" {
+		return ErrNoSecret
+	}
+	return nil
+}
+
+// stdin is shared by prompts, so lines pasted at once are not lost
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt asks user for value in terminal, hidden values are not echoed
+func prompt(label string, hidden bool) (string, error) {
+	fmt.Printf("%s: ", label)
+	if hidden {
+		value, readErr := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		return string(value), readErr
+	}
+	value, readErr := stdin.ReadString('\n')
+	return strings.TrimSpace(value), readErr
+}
+
+// login asks user for email and password and logs in service,
+// code of second factor is asked if user enabled two-factor authentication
+func login(cli *workclient.Client, email string) error {
+	if email == "" {
+		var emailErr error
+		email, emailErr = prompt("Email", false)
+		if emailErr != nil {
+			return emailErr
+		}
+	}
+	password, passwordErr := prompt("Password", true)
+	if passwordErr != nil {
+		return passwordErr
+	}
+	_, loginErr := cli.Login(&models.UserLogin{Email: email, Password: password})
+	if !errors.Is(loginErr, workclient.ErrTwoFactorRequired) {
+		return loginErr
+	}
+	code, codeErr := prompt("Two-factor code or recovery code", false)
+	if codeErr != nil {
+		return codeErr
+	}
+	_, loginErr = cli.LoginTwoFactor(code)
+	return loginErr
+}
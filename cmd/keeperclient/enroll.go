@@ -0,0 +1,127 @@
+package main
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/crypto/keybundle"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/workclient"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var ErrFingerprintRejected = errors.New("fingerprints are not confirmed, received keys are removed")
+
+// enrollPoll interval of enrollment status checks
+const enrollPoll = 2 * time.Second
+
+// enroll requests keys for new device: shows code for approving device, waits for approve
+// and installs received keys in keys folder after user confirms their fingerprints
+func enroll(args []string) error {
+	var cfg models.GUIConfig
+	var configPath, email string
+	fs := commandFlags("enroll", &cfg, &configPath)
+	fs.StringVar(&email, "email", "", "account email")
+	if readErr := readCommandConfig(fs, args, &cfg, &configPath); readErr != nil {
+		return readErr
+	}
+	if cfg.ServerAddress == "" {
+		return ErrNoServerAddress
+	}
+	if cfg.KeysPath == "" {
+		return ErrNoKeysPath
+	}
+
+	cli, cliErr := workclient.InitServiceClient(&cfg)
+	if cliErr != nil {
+		return cliErr
+	}
+	if loginErr := login(cli, email); loginErr != nil {
+		return loginErr
+	}
+	device, deviceErr := keybundle.NewDeviceKey()
+	if deviceErr != nil {
+		return deviceErr
+	}
+	enrollment, enrollmentErr := cli.StartEnrollment(device)
+	if enrollmentErr != nil {
+		return enrollmentErr
+	}
+	if keybundle.DeviceCode(device.Public[:]) != enrollment.Code {
+		return keybundle.ErrDeviceCode
+	}
+
+	log.Printf("enrollment code: %s", enrollment.Code)
+	log.Printf("run on logged-in device: %s approve -code %s", os.Args[0], enrollment.Code)
+	log.Printf("waiting for approve until %s", enrollment.Expires.Local().Format(time.Kitchen))
+
+	pending, completeErr := cli.CompleteEnrollment(enrollment, device, cfg.KeysPath, enrollPoll)
+	if completeErr != nil {
+		return completeErr
+	}
+	// service could seal its own keys for this device, so user compares fingerprints with approving device
+	for _, fingerprint := range pending.Fingerprints {
+		log.Printf("received key fingerprint: %s", fingerprint)
+	}
+	answer, answerErr := prompt("Fingerprints match ones shown on approving device? (yes/no)", false)
+	if answerErr != nil {
+		return answerErr
+	}
+	if !strings.EqualFold(answer, "yes") {
+		if discardErr := pending.Discard(); discardErr != nil {
+			return discardErr
+		}
+		return ErrFingerprintRejected
+	}
+	if installErr := pending.Install(); installErr != nil {
+		return installErr
+	}
+	log.Printf("keys are installed in %s", cfg.KeysPath)
+	// secret is never printed, user sets it in config as on approving device
+	if pending.Secret != "" && cfg.Secret != pending.Secret {
+		log.Printf("set secret for files encryption of approving device in config")
+	}
+	return nil
+}
+
+// approve uploads keys of this device sealed for new device, which shows the code
+func approve(args []string) error {
+	var cfg models.GUIConfig
+	var configPath, email, code string
+	fs := commandFlags("approve", &cfg, &configPath)
+	fs.StringVar(&email, "email", "", "account email")
+	fs.StringVar(&code, "code", "", "enrollment code shown on new device")
+	if parseErr := parseCommandConfig(fs, args, &cfg, &configPath); parseErr != nil {
+		return parseErr
+	}
+	if code == "" {
+		var codeErr error
+		code, codeErr = prompt("Code", false)
+		if codeErr != nil {
+			return codeErr
+		}
+	}
+
+	cli, cliErr := workclient.InitClient(&cfg)
+	if cliErr != nil {
+		return cliErr
+	}
+	if loginErr := login(cli, email); loginErr != nil {
+		return loginErr
+	}
+
+	if approveErr := cli.ApproveEnrollment(code, cfg.Secret); approveErr != nil {
+		return approveErr
+	}
+	log.Printf("device %s is approved", keybundle.NormalizeCode(code))
+	fingerprints, fingerprintsErr := crypto.Fingerprints(cfg.KeysPath)
+	if fingerprintsErr != nil {
+		return fingerprintsErr
+	}
+	for _, fingerprint := range fingerprints {
+		log.Printf("confirm on new device key fingerprint: %s", fingerprint)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"AlexSarva/GophKeeper/models"
 	"flag"
 	"log"
+	"os"
 )
 
 var (
@@ -19,19 +20,36 @@ func version() {
 	log.Printf("Build version: %s\n", buildVersion)
 	log.Printf("Build date: %s\n", buildDate)
 	log.Printf("Build commit: %s\n", buildCommit)
-	log.Println("Warning! Save you id_rsa! if u lost them, u cat'n get any information from database!")
+	log.Println("Warning! Backup your keys with backup-key command! If you lose them, you can't get any information from database!")
 }
 
 func init() {
 	flag.StringVar(&cfg.ServerAddress, "address", "", "address of service")
 	flag.StringVar(&cfg.KeysPath, "keys", "", "keys filepath")
 	flag.IntVar(&cfg.KeysSize, "size", 0, "keys size")
+	flag.StringVar(&cfg.Algorithm, "algorithm", "", "crypto algorithm: rsa or ecc")
 	flag.StringVar(&cfg.Secret, "secret", "", "secret for sym crypt")
+	flag.StringVar(&cfg.Agent, "agent", "", "socket of keys agent, keys and secret are used through it")
+	flag.StringVar(&cfg.Transport, "transport", "", "transport of service: rest (default) or grpc")
+	flag.StringVar(&cfg.GRPCAddress, "grpc", "", "gRPC address of service")
+	flag.BoolVar(&cfg.GRPCTLS, "grpc-tls", false, "use TLS for gRPC")
+	flag.StringVar(&cfg.DeviceName, "device", "", "name of device in sessions of user, host name by default")
 	flag.StringVar(&JSONConfig.DSN, "config", "", "JSON config")
+	flag.Usage = usage
 }
 
 func main() {
 	version()
+
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if cmdErr := cmd.run(os.Args[2:]); cmdErr != nil {
+				log.Fatalln(cmdErr)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
 	if configFilename := JSONConfig.DSN; configFilename != "" {
@@ -41,11 +59,13 @@ func main() {
 		}
 	}
 
+	cfg.Version = buildVersion
+
 	if cfg.ServerAddress == "" {
 		log.Fatalln("cant obtain server address")
 	}
 
-	if cfg.Secret == "" {
+	if cfg.Secret == "" && cfg.Agent == "" {
 		log.Fatalln("cant obtain secret for sym crypto")
 	}
 
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/workclient"
+	"log"
+)
+
+// encryptMetadata encrypts titles, file names and notes that were saved in plaintext
+// by previous versions of the client
+func encryptMetadata(args []string) error {
+	var cfg models.GUIConfig
+	var configPath, email string
+	fs := commandFlags("encrypt-metadata", &cfg, &configPath)
+	fs.StringVar(&email, "email", "", "account email")
+	if parseErr := parseCommandConfig(fs, args, &cfg, &configPath); parseErr != nil {
+		return parseErr
+	}
+
+	cli, cliErr := workclient.InitClient(&cfg)
+	if cliErr != nil {
+		return cliErr
+	}
+	if loginErr := login(cli, email); loginErr != nil {
+		return loginErr
+	}
+
+	changed, migrateErr := cli.EncryptMetadata()
+	if migrateErr != nil {
+		return migrateErr
+	}
+
+	log.Printf("metadata of %d elements is encrypted", changed)
+	return nil
+}
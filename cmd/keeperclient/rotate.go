@@ -0,0 +1,65 @@
+package main
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/workclient"
+	"errors"
+	"log"
+)
+
+var ErrSecretMismatch = errors.New("secrets dont match")
+
+// rotateKeys generates new keys and re-encrypts all user information with them,
+// interrupted rotation is resumed by the same command. New secret is asked without echo,
+// so it doesn't stay in shell history
+func rotateKeys(args []string) error {
+	var cfg models.GUIConfig
+	var configPath, email string
+	fs := commandFlags("rotate-keys", &cfg, &configPath)
+	fs.StringVar(&email, "email", "", "account email")
+	if parseErr := parseCommandConfig(fs, args, &cfg, &configPath); parseErr != nil {
+		return parseErr
+	}
+	newSecret, secretErr := promptNewSecret()
+	if secretErr != nil {
+		return secretErr
+	}
+	if newSecret == "" {
+		newSecret = cfg.Secret
+	}
+
+	cli, cliErr := workclient.InitClient(&cfg)
+	if cliErr != nil {
+		return cliErr
+	}
+	if loginErr := login(cli, email); loginErr != nil {
+		return loginErr
+	}
+
+	if rotateErr := cli.RotateKeys(newSecret); rotateErr != nil {
+		log.Println("rotation is interrupted, run the same command again to resume it")
+		return rotateErr
+	}
+
+	log.Println("keys are successfully rotated")
+	if newSecret != cfg.Secret {
+		log.Println("Warning! Don't forget to use new secret in config, files are encrypted with it now!")
+	}
+	return nil
+}
+
+// promptNewSecret asks new secret for sym crypt twice, empty secret keeps the current one
+func promptNewSecret() (string, error) {
+	secret, secretErr := prompt("New secret (empty keeps current)", true)
+	if secretErr != nil || secret == "" {
+		return "", secretErr
+	}
+	repeated, repeatedErr := prompt("Repeat new secret", true)
+	if repeatedErr != nil {
+		return "", repeatedErr
+	}
+	if repeated != secret {
+		return "", ErrSecretMismatch
+	}
+	return secret, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/internal/app"
+	"AlexSarva/GophKeeper/storage/admin"
+	"AlexSarva/GophKeeper/storage/atrest"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var ErrKeysCommand = errors.New("unknown keys command, use: keys rotate or keys list")
+
+// keysCommand manages keys of JWT signatures in admin database: rotate adds new key that is published
+// before it signs tokens, list prints keys of ring. Running servers load new keys by themselves
+func keysCommand(args []string) error {
+	if len(args) != 1 || (args[0] != "rotate" && args[0] != "list") {
+		return ErrKeysCommand
+	}
+	var sealer *atrest.Sealer
+	if cfg.AtRestKeys != "" {
+		provider, providerErr := atrest.LoadFileKeys(cfg.AtRestKeys)
+		if providerErr != nil {
+			return providerErr
+		}
+		sealer = atrest.NewSealer(provider)
+	}
+	keys, keysErr := authorizer.NewKeyRing(admin.NewAdminDBConnection(cfg.AdminDatabase, sealer), app.KeyRingParams(&cfg))
+	if keysErr != nil {
+		return keysErr
+	}
+
+	if args[0] == "rotate" {
+		key, rotateErr := keys.Rotate(true)
+		if rotateErr != nil {
+			return rotateErr
+		}
+		fmt.Printf("New signing key %s (%s) signs tokens from %s\n", key.ID, key.Algorithm, key.Activates.Local().Format(time.RFC3339))
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALGORITHM\tCREATED\tACTIVATES\tRETIRES\tSTATUS")
+	ring := keys.Keys()
+	for i, key := range ring {
+		retires := "-"
+		if key.Retires != nil {
+			retires = key.Retires.Local().Format(time.RFC3339)
+		}
+		status := "previous"
+		switch {
+		case key.Activates.After(now):
+			status = "pending"
+		case i == len(ring)-1 || ring[i+1].Activates.After(now):
+			status = "active"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Algorithm, key.Created.Local().Format(time.RFC3339),
+			key.Activates.Local().Format(time.RFC3339), retires, status)
+	}
+	return w.Flush()
+}
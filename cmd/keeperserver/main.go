@@ -1,11 +1,15 @@
 package main
 
 import (
+	"AlexSarva/GophKeeper/authorizer"
 	"AlexSarva/GophKeeper/constant"
 	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/passhash"
 	"AlexSarva/GophKeeper/server"
+	"AlexSarva/GophKeeper/storage/atrest"
 	"flag"
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
@@ -16,6 +20,7 @@ var (
 	buildCommit  = "N/A"
 	cfg          models.ServerConfig
 	JSONConfig   models.JSONConfig
+	rotateKey    bool
 )
 
 func version() {
@@ -26,13 +31,32 @@ func version() {
 
 func init() {
 	flag.StringVar(&cfg.ServerAddress, "address", "", "host:port to listen on")
+	flag.StringVar(&cfg.GRPCAddress, "grpc", "", "host:port to listen on by gRPC server, gRPC is disabled if it is empty")
 	flag.StringVar(&cfg.Database, "database", "", "database config")
 	flag.StringVar(&cfg.AdminDatabase, "admin", "", "admin database config")
-	flag.StringVar(&cfg.Secret, "secret", "", "secret word")
+	flag.StringVar(&cfg.Secret, "secret", "", "not used, tokens are signed by rotating keys of admin database")
 	flag.StringVar(&cfg.CORS, "cors", "", "cors settings")
 	flag.StringVar(&JSONConfig.DSN, "config", "", "JSON config")
 	flag.BoolVar(&cfg.EnableHTTPS, "secure", false, "enable HTTPS")
-	flag.StringVar(&cfg.TrustedSubnet, "trusted", "", "trusted subnet")
+	flag.StringVar(&cfg.TrustedSubnet, "trusted", "", "trusted subnet of admins in CIDR notation, admin routes are closed if it is empty")
+	flag.StringVar(&cfg.TrustedProxies, "trusted-proxies", "", "subnets of reverse proxies in CIDR notation separated by spaces, forwarding headers of other clients are ignored")
+	flag.StringVar(&cfg.AtRestKeys, "at-rest-keys", "", "path to key file of at-rest encryption")
+	flag.BoolVar(&cfg.EventsNotify, "events-notify", false, "share events between server instances by PostgreSQL LISTEN/NOTIFY")
+	flag.DurationVar(&cfg.IdempotencyWindow, "idempotency-window", 24*time.Hour, "time while responses of requests with Idempotency-Key are replayed")
+	flag.StringVar(&cfg.PasswordHash, "password-hash", passhash.DefaultParams.Algorithm, "algorithm of password hashes: argon2id or bcrypt")
+	flag.IntVar(&cfg.BcryptCost, "bcrypt-cost", passhash.DefaultParams.BcryptCost, "cost of bcrypt password hashes")
+	flag.UintVar(&cfg.Argon2Time, "argon2-time", uint(passhash.DefaultParams.Argon2Time), "passes of argon2id password hashes")
+	flag.UintVar(&cfg.Argon2Memory, "argon2-memory", uint(passhash.DefaultParams.Argon2Memory), "memory of argon2id password hashes in KiB")
+	flag.UintVar(&cfg.Argon2Threads, "argon2-threads", uint(passhash.DefaultParams.Argon2Threads), "threads of argon2id password hashes")
+	flag.StringVar(&cfg.SMTPAddress, "smtp", "", "host:port of SMTP server, messages are written to mail file or log if it is empty")
+	flag.StringVar(&cfg.SMTPUsername, "smtp-username", "", "username of SMTP server")
+	flag.StringVar(&cfg.SMTPPassword, "smtp-password", "", "password of SMTP server")
+	flag.StringVar(&cfg.MailFrom, "mail-from", "GophKeeper <noreply@localhost>", "sender address of messages to users")
+	flag.StringVar(&cfg.MailFile, "mail-file", "", "file of messages to users if SMTP server is not set")
+	flag.StringVar(&cfg.MailTemplates, "mail-templates", "", "directory of templates of messages that replace built-in ones")
+	flag.StringVar(&cfg.JWTAlgorithm, "jwt-algorithm", authorizer.AlgorithmEdDSA, "algorithm of new keys of JWT signatures: EdDSA or RS256")
+	flag.DurationVar(&cfg.JWTRotation, "jwt-rotation", 30*24*time.Hour, "age of key of JWT signatures when it is rotated, keys are rotated only by command if it is zero")
+	flag.BoolVar(&rotateKey, "rotate-at-rest-key", false, "add new at-rest key and exit, running server re-wraps rows")
 }
 
 func main() {
@@ -56,7 +80,26 @@ func main() {
 		}
 	}
 
-	log.Printf("ServerAddress: %v, EnableHTTPS: %v", cfg.ServerAddress, cfg.EnableHTTPS)
+	if rotateKey {
+		if cfg.AtRestKeys == "" {
+			log.Fatalln("at-rest key file is not set")
+		}
+		keyID, rotateErr := atrest.RotateFileKeys(cfg.AtRestKeys)
+		if rotateErr != nil {
+			log.Fatalln(rotateErr)
+		}
+		log.Printf("New at-rest key: %s, old keys are retired after running server re-wraps all rows", keyID)
+		return
+	}
+
+	if flag.Arg(0) == "keys" {
+		if keysErr := keysCommand(flag.Args()[1:]); keysErr != nil {
+			log.Fatalln(keysErr)
+		}
+		return
+	}
+
+	log.Printf("ServerAddress: %v, GRPCAddress: %v, EnableHTTPS: %v", cfg.ServerAddress, cfg.GRPCAddress, cfg.EnableHTTPS)
 
 	GlobalContainerErr := constant.BuildContainer(cfg)
 	if GlobalContainerErr != nil {
@@ -0,0 +1,206 @@
+// Package codec contains encodings of REST API bodies: JSON, MessagePack and CBOR.
+// Binary encodings use json tags of models, so every encoding has the same field names
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Content types of encodings
+const (
+	MIMEJSON    = "application/json"
+	MIMEMsgPack = "application/msgpack"
+	MIMECBOR    = "application/cbor"
+)
+
+// Preferred Accept header of clients that support binary encodings
+const Preferred = MIMEMsgPack + ", " + MIMECBOR + ";q=0.9, " + MIMEJSON + ";q=0.8"
+
+// Codec encodes and decodes bodies of one content type
+type Codec interface {
+	// ContentType returns content type of encoding
+	ContentType() string
+	// Marshal encodes value
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data in value, unknown fields are ignored
+	Unmarshal(data []byte, v interface{}) error
+	// DecodeStrict decodes body in value, unknown fields are errors
+	DecodeStrict(r io.Reader, v interface{}) error
+}
+
+var (
+	JSON    Codec = jsonCodec{}
+	MsgPack Codec = msgpackCodec{}
+	CBOR    Codec = newCBORCodec()
+)
+
+// aliases content types that are used for encodings besides the main ones
+var aliases = map[string]Codec{
+	MIMEJSON:                JSON,
+	MIMEMsgPack:             MsgPack,
+	"application/x-msgpack": MsgPack,
+	MIMECBOR:                CBOR,
+}
+
+// ForContentType returns codec of content type, JSON is used for unknown and empty types
+func ForContentType(contentType string) Codec {
+	mediaType, _, parseErr := mime.ParseMediaType(contentType)
+	if parseErr != nil {
+		return JSON
+	}
+	if found, ok := aliases[mediaType]; ok {
+		return found
+	}
+	return JSON
+}
+
+// IsSupported reports whether content type has codec
+func IsSupported(contentType string) bool {
+	mediaType, _, parseErr := mime.ParseMediaType(contentType)
+	if parseErr != nil {
+		return false
+	}
+	_, ok := aliases[mediaType]
+	return ok
+}
+
+// Negotiate chooses codec by Accept header: supported type with the highest quality wins,
+// JSON is used if header is empty or has no supported types
+func Negotiate(accept string) Codec {
+	type candidate struct {
+		codec   Codec
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, parseErr := mime.ParseMediaType(strings.TrimSpace(part))
+		if parseErr != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			parsed, qErr := strconv.ParseFloat(q, 64)
+			if qErr != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		found, ok := aliases[mediaType]
+		if !ok {
+			if mediaType != "*/*" && mediaType != "application/*" {
+				continue
+			}
+			found = JSON
+		}
+		candidates = append(candidates, candidate{codec: found, quality: quality})
+	}
+	if len(candidates) == 0 {
+		return JSON
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].codec
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return MIMEJSON
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) DecodeStrict(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string {
+	return MIMEMsgPack
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if encodeErr := encoder.Encode(v); encodeErr != nil {
+		return nil, encodeErr
+	}
+	return buf.Bytes(), nil
+}
+
+func (c msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.decode(bytes.NewReader(data), v, false)
+}
+
+func (c msgpackCodec) DecodeStrict(r io.Reader, v interface{}) error {
+	return c.decode(r, v, true)
+}
+
+func (msgpackCodec) decode(r io.Reader, v interface{}, strict bool) error {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+	decoder.DisallowUnknownFields(strict)
+	return decoder.Decode(v)
+}
+
+type cborCodec struct {
+	enc    cbor.EncMode
+	dec    cbor.DecMode
+	strict cbor.DecMode
+}
+
+func newCBORCodec() cborCodec {
+	// time is encoded as string to keep nanoseconds and time zone
+	enc, encErr := cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	if encErr != nil {
+		panic(encErr)
+	}
+	dec, decErr := cbor.DecOptions{}.DecMode()
+	if decErr != nil {
+		panic(decErr)
+	}
+	strict, strictErr := cbor.DecOptions{ExtraReturnErrors: cbor.ExtraDecErrorUnknownField}.DecMode()
+	if strictErr != nil {
+		panic(strictErr)
+	}
+	return cborCodec{enc: enc, dec: dec, strict: strict}
+}
+
+func (cborCodec) ContentType() string {
+	return MIMECBOR
+}
+
+func (c cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return c.enc.Marshal(v)
+}
+
+func (c cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.dec.Unmarshal(data, v)
+}
+
+func (c cborCodec) DecodeStrict(r io.Reader, v interface{}) error {
+	return c.strict.NewDecoder(r).Decode(v)
+}
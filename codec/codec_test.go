@@ -0,0 +1,62 @@
+package codec
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecs(t *testing.T) {
+	created := time.Date(2022, 12, 1, 10, 0, 0, 123456789, time.UTC)
+	file := models.File{
+		ID:        uuid.New(),
+		Title:     "title",
+		File:      []byte{0, 1, 2, 255},
+		FileName:  "file.bin",
+		Version:   2,
+		Signature: "sign",
+		Created:   created,
+		Changed:   &models.NullTime{Time: created.Add(time.Hour), Valid: true},
+	}
+	for _, codec := range []Codec{JSON, MsgPack, CBOR} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			data, marshalErr := codec.Marshal(file)
+			assert.NoError(t, marshalErr)
+
+			var decoded models.File
+			assert.NoError(t, codec.Unmarshal(data, &decoded))
+			assert.Equal(t, file.ID, decoded.ID)
+			assert.Equal(t, file.File, decoded.File)
+			assert.True(t, file.Created.Equal(decoded.Created))
+			assert.True(t, file.Changed.Get().Equal(decoded.Changed.Get()))
+
+			var note models.NewNote
+			assert.Error(t, codec.DecodeStrict(bytes.NewReader(data), &note), "unknown fields should be rejected")
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   Codec
+	}{
+		{name: "empty", accept: "", want: JSON},
+		{name: "any", accept: "*/*", want: JSON},
+		{name: "msgpack", accept: "application/msgpack", want: MsgPack},
+		{name: "preferred", accept: Preferred, want: MsgPack},
+		{name: "quality", accept: "application/msgpack;q=0.5, application/cbor", want: CBOR},
+		{name: "unsupported", accept: "text/html", want: JSON},
+		{name: "excluded", accept: "application/cbor;q=0, application/json;q=0.1", want: JSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Negotiate(tt.accept))
+		})
+	}
+}
@@ -1,6 +1,26 @@
 package crypto
 
-import "AlexSarva/GophKeeper/crypto/cryptorsa"
+import (
+	"AlexSarva/GophKeeper/crypto/cryptoecc"
+	"AlexSarva/GophKeeper/crypto/cryptorsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Algorithms that could be selected for encryption
+const (
+	AlgorithmRSA = "rsa"
+	AlgorithmECC = "ecc"
+)
+
+// defaultKeySize key size for RSA keys that used only for decryption of old values
+const defaultKeySize = 2048
+
+var ErrUnknownAlgorithm = errors.New("unknown crypto algorithm")
+var ErrNoKeys = errors.New("no keys for algorithm of encrypted value")
 
 // Crypto interface that used for different types of crypt
 type Crypto interface {
@@ -8,20 +28,216 @@ type Crypto interface {
 	Sign(payload string) (string, error)
 	Encrypt(payload string) (string, error)
 	Decrypt(payload string) (string, error)
+	PublicKey() ([]byte, error)
 }
 
-// Cryptorizer used for implements different types of crypt
+// Cryptorizer used for implements different types of crypt.
+// Values are encrypted by selected algorithm and tagged with its name (like "ecc:<base64>"),
+// so values encrypted by other algorithm could be decrypted while migration, if there are keys for it.
+// Values without tag are encrypted by RSA. Cryptos of algorithms are initialized on demand,
+// so Cryptorizer is safe for concurrent use
 type Cryptorizer struct {
 	Cryptorizer Crypto
+	Algorithm   string
+	keysPath    string
+	size        int
+	mu          sync.RWMutex
+	cryptos     map[string]Crypto
+	remote      func(algorithm string) (Crypto, error)
+}
+
+// InitCryptorizer initializer of Cryptorizer struct,
+// keys for selected algorithm are created if they dont exist
+func InitCryptorizer(keysPath string, size int, algorithm string) (*Cryptorizer, error) {
+	if algorithm == "" {
+		algorithm = AlgorithmRSA
+	}
+	cryptorizer := &Cryptorizer{
+		Algorithm: algorithm,
+		keysPath:  keysPath,
+		size:      size,
+		cryptos:   make(map[string]Crypto),
+	}
+	mainCrypto, mainCryptoErr := cryptorizer.initCrypto(algorithm, size)
+	if mainCryptoErr != nil {
+		return nil, mainCryptoErr
+	}
+	cryptorizer.Cryptorizer = mainCrypto
+	cryptorizer.cryptos[algorithm] = mainCrypto
+	return cryptorizer, nil
 }
 
-// InitCryptorizer initializer of Cryptorizer struct
-func InitCryptorizer(ketsPath string, size int) (*Cryptorizer, error) {
-	cryptorizer := cryptorsa.InitRSACrypt(ketsPath, size)
-	if initErr := cryptorizer.InitCrypto(); initErr != nil {
-		return nil, initErr
+// InitRemoteCryptorizer initializer of Cryptorizer which keys are kept by other process (keys agent),
+// remote returns crypto of algorithm or ErrNoKeys if process has no keys for it
+func InitRemoteCryptorizer(algorithm string, remote func(algorithm string) (Crypto, error)) (*Cryptorizer, error) {
+	mainCrypto, mainCryptoErr := remote(algorithm)
+	if mainCryptoErr != nil {
+		return nil, mainCryptoErr
 	}
 	return &Cryptorizer{
-		Cryptorizer: cryptorizer,
+		Cryptorizer: mainCrypto,
+		Algorithm:   algorithm,
+		cryptos:     map[string]Crypto{algorithm: mainCrypto},
+		remote:      remote,
 	}, nil
 }
+
+// initCrypto initialize crypto of selected algorithm
+func (c *Cryptorizer) initCrypto(algorithm string, size int) (Crypto, error) {
+	switch algorithm {
+	case AlgorithmRSA:
+		rsaCrypto := cryptorsa.InitRSACrypt(c.keysPath, size)
+		if initErr := rsaCrypto.InitCrypto(); initErr != nil {
+			return nil, initErr
+		}
+		return rsaCrypto, nil
+	case AlgorithmECC:
+		eccCrypto := cryptoecc.InitECCCrypt(c.keysPath)
+		if initErr := eccCrypto.InitCrypto(); initErr != nil {
+			return nil, initErr
+		}
+		return eccCrypto, nil
+	}
+	return nil, ErrUnknownAlgorithm
+}
+
+// CryptoFor returns crypto of selected algorithm, crypto of not main algorithm
+// is initialized only if there are keys for it
+func (c *Cryptorizer) CryptoFor(algorithm string) (Crypto, error) {
+	c.mu.RLock()
+	workCrypto, ok := c.cryptos[algorithm]
+	c.mu.RUnlock()
+	if ok {
+		return workCrypto, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// crypto could be initialized by concurrent call meanwhile
+	if workCrypto, ok = c.cryptos[algorithm]; ok {
+		return workCrypto, nil
+	}
+	workCrypto, workCryptoErr := c.loadCrypto(algorithm)
+	if workCryptoErr != nil {
+		return nil, workCryptoErr
+	}
+	c.cryptos[algorithm] = workCrypto
+	return workCrypto, nil
+}
+
+// loadCrypto initializes crypto of not main algorithm by remote process or by keys in keys folder
+func (c *Cryptorizer) loadCrypto(algorithm string) (Crypto, error) {
+	if c.remote != nil {
+		return c.remote(algorithm)
+	}
+	switch algorithm {
+	case AlgorithmRSA:
+		if !cryptorsa.KeysExist(c.keysPath) {
+			return nil, ErrNoKeys
+		}
+	case AlgorithmECC:
+		if !cryptoecc.KeysExist(c.keysPath) {
+			return nil, ErrNoKeys
+		}
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+	size := c.size
+	if size < defaultKeySize {
+		size = defaultKeySize
+	}
+	return c.initCrypto(algorithm, size)
+}
+
+// Fingerprint returns fingerprint of public key of selected algorithm, like "rsa:SHA256:<base64>".
+// It is registered on account, so client with other keys cant write in vault
+func (c *Cryptorizer) Fingerprint() (string, error) {
+	publicKey, publicKeyErr := c.Cryptorizer.PublicKey()
+	if publicKeyErr != nil {
+		return "", publicKeyErr
+	}
+	hash := sha256.Sum256(publicKey)
+	return c.Algorithm + ":SHA256:" + base64.RawStdEncoding.EncodeToString(hash[:]), nil
+}
+
+// Fingerprints returns fingerprints of keys of all algorithms that exist in keys folder
+func Fingerprints(keysPath string) ([]string, error) {
+	var fingerprints []string
+	for _, algorithm := range []string{AlgorithmRSA, AlgorithmECC} {
+		cryptorizer := &Cryptorizer{keysPath: keysPath, cryptos: make(map[string]Crypto)}
+		workCrypto, workCryptoErr := cryptorizer.loadCrypto(algorithm)
+		if errors.Is(workCryptoErr, ErrNoKeys) {
+			continue
+		}
+		if workCryptoErr != nil {
+			return nil, workCryptoErr
+		}
+		cryptorizer.Cryptorizer, cryptorizer.Algorithm = workCrypto, algorithm
+		fingerprint, fingerprintErr := cryptorizer.Fingerprint()
+		if fingerprintErr != nil {
+			return nil, fingerprintErr
+		}
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	if len(fingerprints) == 0 {
+		return nil, ErrNoKeys
+	}
+	return fingerprints, nil
+}
+
+// KeyFiles returns names of files with keys of all algorithms
+func KeyFiles() []string {
+	return append(cryptorsa.KeyFiles(), cryptoecc.KeyFiles()...)
+}
+
+// ValueAlgorithm returns algorithm of encrypted value or signature and value without tag
+func ValueAlgorithm(value string) (string, string) {
+	tag, payload, found := strings.Cut(value, ":")
+	if !found {
+		return AlgorithmRSA, value
+	}
+	return tag, payload
+}
+
+// Encrypt cipher payload by envelope with data key sealed by selected algorithm,
+// result is tagged with algorithm name
+func (c *Cryptorizer) Encrypt(payload string) (string, error) {
+	cipher, cipherErr := sealEnvelope(c.Cryptorizer, payload)
+	if cipherErr != nil {
+		return "", cipherErr
+	}
+	return c.Algorithm + ":" + cipher, nil
+}
+
+// Decrypt deciphers payload by algorithm from its tag,
+// values encrypted before envelopes are deciphered by keys directly
+func (c *Cryptorizer) Decrypt(payload string) (string, error) {
+	algorithm, cipher := ValueAlgorithm(payload)
+	workCrypto, workCryptoErr := c.CryptoFor(algorithm)
+	if workCryptoErr != nil {
+		return "", workCryptoErr
+	}
+	if strings.Contains(cipher, envelopeSeparator) {
+		return openEnvelope(workCrypto, cipher)
+	}
+	return workCrypto.Decrypt(cipher)
+}
+
+// Sign signs payload by selected algorithm, signature is tagged with algorithm name
+func (c *Cryptorizer) Sign(payload string) (string, error) {
+	signature, signatureErr := c.Cryptorizer.Sign(payload)
+	if signatureErr != nil {
+		return "", signatureErr
+	}
+	return c.Algorithm + ":" + signature, nil
+}
+
+// Verify check sign on payload by algorithm from signature tag
+func (c *Cryptorizer) Verify(payload string, signature64 string) bool {
+	algorithm, signature := ValueAlgorithm(signature64)
+	workCrypto, workCryptoErr := c.CryptoFor(algorithm)
+	if workCryptoErr != nil {
+		return false
+	}
+	return workCrypto.Verify(payload, signature)
+}
@@ -0,0 +1,187 @@
+package crypto
+
+import (
+	"AlexSarva/GophKeeper/crypto/cryptoecc"
+	"AlexSarva/GophKeeper/crypto/cryptorsa"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMixedAlgorithms(t *testing.T) {
+	keysPath := t.TempDir()
+	rsaCryptorizer, rsaErr := InitCryptorizer(keysPath, 1024, AlgorithmRSA)
+	assert.NoError(t, rsaErr)
+	eccCryptorizer, eccErr := InitCryptorizer(keysPath, 1024, AlgorithmECC)
+	assert.NoError(t, eccErr)
+
+	tests := []struct {
+		name        string
+		cryptorizer *Cryptorizer
+		payload     string
+		algorithm   string
+	}{
+		{
+			name:        "rsa value",
+			cryptorizer: rsaCryptorizer,
+			payload:     "4405 1111 1000 1383",
+			algorithm:   AlgorithmRSA,
+		},
+		{
+			name:        "ecc value",
+			cryptorizer: eccCryptorizer,
+			payload:     "dPQzakp9DMSW",
+			algorithm:   AlgorithmECC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipher, cipherErr := tt.cryptorizer.Encrypt(tt.payload)
+			assert.NoError(t, cipherErr)
+			algorithm, _ := ValueAlgorithm(cipher)
+			assert.Equal(t, tt.algorithm, algorithm, fmt.Errorf("expected algorithm %s, got %s", tt.algorithm, algorithm))
+
+			// both cryptorizers should decrypt values of mixed vault
+			for _, cryptorizer := range []*Cryptorizer{rsaCryptorizer, eccCryptorizer} {
+				payload, payloadErr := cryptorizer.Decrypt(cipher)
+				assert.NoError(t, payloadErr)
+				assert.Equal(t, tt.payload, payload)
+			}
+
+			signature, signatureErr := tt.cryptorizer.Sign(tt.payload)
+			assert.NoError(t, signatureErr)
+			assert.True(t, eccCryptorizer.Verify(tt.payload, signature))
+			assert.False(t, rsaCryptorizer.Verify(tt.payload+"x", signature))
+		})
+	}
+}
+
+func TestCryptoForConcurrent(t *testing.T) {
+	keysPath := t.TempDir()
+	_, rsaErr := InitCryptorizer(keysPath, 1024, AlgorithmRSA)
+	assert.NoError(t, rsaErr)
+	eccCryptorizer, eccErr := InitCryptorizer(keysPath, 1024, AlgorithmECC)
+	assert.NoError(t, eccErr)
+
+	var wg sync.WaitGroup
+	cryptos := make([]Crypto, 8)
+	for i := range cryptos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			workCrypto, cryptoErr := eccCryptorizer.CryptoFor(AlgorithmRSA)
+			assert.NoError(t, cryptoErr)
+			cryptos[i] = workCrypto
+		}(i)
+	}
+	wg.Wait()
+	for _, workCrypto := range cryptos {
+		assert.Same(t, cryptos[0], workCrypto, "crypto of algorithm is initialized once")
+	}
+}
+
+func TestKeyPair(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		privFile  string
+		pubFile   string
+		pairErr   error
+	}{
+		{
+			name:      "rsa keys",
+			algorithm: AlgorithmRSA,
+			privFile:  "id_rsa",
+			pubFile:   "id_rsa.pub",
+			pairErr:   cryptorsa.ErrKeyPair,
+		},
+		{
+			name:      "ecc keys",
+			algorithm: AlgorithmECC,
+			privFile:  "id_ed25519",
+			pubFile:   "id_ed25519.pub",
+			pairErr:   cryptoecc.ErrKeyPair,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keysPath, otherPath := t.TempDir(), t.TempDir()
+			cryptorizer, cryptorizerErr := InitCryptorizer(keysPath, 1024, tt.algorithm)
+			assert.NoError(t, cryptorizerErr)
+			other, otherErr := InitCryptorizer(otherPath, 1024, tt.algorithm)
+			assert.NoError(t, otherErr)
+			privInfo, statErr := os.Stat(filepath.Join(keysPath, tt.privFile))
+			assert.NoError(t, statErr)
+			assert.Equal(t, os.FileMode(0600), privInfo.Mode().Perm(), "private key is readable only by owner")
+
+			fingerprint, fingerprintErr := cryptorizer.Fingerprint()
+			assert.NoError(t, fingerprintErr)
+			assert.True(t, strings.HasPrefix(fingerprint, tt.algorithm+":SHA256:"))
+			fingerprints, fingerprintsErr := Fingerprints(keysPath)
+			assert.NoError(t, fingerprintsErr)
+			assert.Equal(t, []string{fingerprint}, fingerprints)
+			otherFingerprint, _ := other.Fingerprint()
+			assert.NotEqual(t, fingerprint, otherFingerprint)
+
+			reloaded, reloadedErr := InitCryptorizer(keysPath, 1024, tt.algorithm)
+			assert.NoError(t, reloadedErr)
+			reloadedFingerprint, _ := reloaded.Fingerprint()
+			assert.Equal(t, fingerprint, reloadedFingerprint)
+
+			otherPub, readErr := os.ReadFile(filepath.Join(otherPath, tt.pubFile))
+			assert.NoError(t, readErr)
+			assert.NoError(t, os.WriteFile(filepath.Join(keysPath, tt.pubFile), otherPub, 0644))
+			_, pairErr := InitCryptorizer(keysPath, 1024, tt.algorithm)
+			assert.ErrorIs(t, pairErr, tt.pairErr)
+		})
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	keysPath := t.TempDir()
+	rsaCryptorizer, rsaErr := InitCryptorizer(keysPath, 1024, AlgorithmRSA)
+	assert.NoError(t, rsaErr)
+	eccCryptorizer, eccErr := InitCryptorizer(keysPath, 1024, AlgorithmECC)
+	assert.NoError(t, eccErr)
+	// notes are longer than RSA block
+	notes := strings.Repeat("long notes of element ", 200)
+
+	for _, cryptorizer := range []*Cryptorizer{rsaCryptorizer, eccCryptorizer} {
+		t.Run(cryptorizer.Algorithm, func(t *testing.T) {
+			cipher, cipherErr := cryptorizer.Encrypt(notes)
+			assert.NoError(t, cipherErr)
+			assert.True(t, IsEncrypted(cipher))
+			payload, payloadErr := cryptorizer.Decrypt(cipher)
+			assert.NoError(t, payloadErr)
+			assert.Equal(t, notes, payload)
+
+			// values encrypted by keys directly before envelopes, untagged values are RSA ones
+			legacy, legacyErr := cryptorizer.Cryptorizer.Encrypt("Bank card")
+			assert.NoError(t, legacyErr)
+			legacyValues := []string{cryptorizer.Algorithm + ":" + legacy}
+			if cryptorizer.Algorithm == AlgorithmRSA {
+				legacyValues = append(legacyValues, legacy)
+			}
+			for _, value := range legacyValues {
+				assert.True(t, IsEncrypted(value))
+				payload, payloadErr = cryptorizer.Decrypt(value)
+				assert.NoError(t, payloadErr)
+				assert.Equal(t, "Bank card", payload)
+			}
+
+			algorithm, envelope := ValueAlgorithm(cipher)
+			sealedKey, _, _ := strings.Cut(envelope, envelopeSeparator)
+			_, tamperedErr := cryptorizer.Decrypt(algorithm + ":" + sealedKey + envelopeSeparator + "AAAAAAAAAAAAAAAAAAAAAAAA")
+			assert.Error(t, tamperedErr)
+		})
+	}
+
+	for _, plain := range []string{"", "Bank card", "Bank: main", "rsa:short", "ecc:c2hvcnQ="} {
+		assert.False(t, IsEncrypted(plain), plain)
+	}
+}
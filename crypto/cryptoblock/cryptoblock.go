@@ -7,6 +7,13 @@ import (
 	"log"
 )
 
+// SymCrypto symmetric crypto of file contents and local index,
+// it is implemented by AEADCrypto and by client of keys agent
+type SymCrypto interface {
+	Encrypt(payload []byte) ([]byte, error)
+	Decrypt(text []byte) ([]byte, error)
+}
+
 // AEADCrypto implements Authenticated Encryption with Associated Data crypto methods
 type AEADCrypto struct {
 	aesgcm cipher.AEAD
@@ -33,9 +40,9 @@ func InitAEADCrypto(secret string) *AEADCrypto {
 }
 
 // Encrypt cipher payload with AEAD and secret key
-func (sc *AEADCrypto) Encrypt(payload []byte) []byte {
+func (sc *AEADCrypto) Encrypt(payload []byte) ([]byte, error) {
 	dst := sc.aesgcm.Seal(nil, sc.nonce, payload, nil)
-	return dst
+	return dst, nil
 }
 
 // Decrypt deciphers payload with AEAD and secret key
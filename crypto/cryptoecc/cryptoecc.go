@@ -0,0 +1,291 @@
+package cryptoecc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const (
+	boxPrivateType = "X25519 PRIVATE KEY"
+	boxPublicType  = "X25519 PUBLIC KEY"
+)
+
+var (
+	ErrDecrypt = errors.New("fail to open sealed box")
+	ErrKeyPair = errors.New("private and public ecc keys dont form a key pair")
+)
+
+// ECCCrypt implements elliptic-curve crypto methods:
+// X25519 sealed boxes for encryption and Ed25519 for signatures.
+// Keys are read from disk once, while initialization
+type ECCCrypt struct {
+	keysPath   string
+	idBox      string
+	idBoxPub   string
+	idSign     string
+	idSignPub  string
+	boxPublic  *[32]byte
+	boxPrivate *[32]byte
+	signKey    ed25519.PrivateKey
+	verifyKey  ed25519.PublicKey
+}
+
+// InitECCCrypt initializer of ECCCrypt struct
+// path - folder path for store keys (id_x25519 / id_x25519.pub / id_ed25519 / id_ed25519.pub)
+func InitECCCrypt(path string) *ECCCrypt {
+	return &ECCCrypt{
+		keysPath:  path,
+		idBox:     filepath.Join(path, "id_x25519"),
+		idBoxPub:  filepath.Join(path, "id_x25519.pub"),
+		idSign:    filepath.Join(path, "id_ed25519"),
+		idSignPub: filepath.Join(path, "id_ed25519.pub"),
+	}
+}
+
+// KeysExist checks for the presence of private keys in the directory
+func KeysExist(path string) bool {
+	_, boxErr := os.Stat(filepath.Join(path, "id_x25519"))
+	_, signErr := os.Stat(filepath.Join(path, "id_ed25519"))
+	return boxErr == nil && signErr == nil
+}
+
+// KeyFiles returns names of files with keys
+func KeyFiles() []string {
+	return []string{"id_x25519", "id_x25519.pub", "id_ed25519", "id_ed25519.pub"}
+}
+
+// InitCrypto checks for the presence of keys in the directory,
+// if no keys are found - creates X25519 and Ed25519 key pairs, after that keys are loaded in memory
+func (e *ECCCrypt) InitCrypto() error {
+	if _, err := os.Stat(e.keysPath); err != nil {
+		log.Printf("keys will be add in this path: %s", e.keysPath)
+		err = os.Mkdir(e.keysPath, 0700)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(e.idBox); err != nil {
+		if generateErr := e.generateBoxKeys(); generateErr != nil {
+			return generateErr
+		}
+	}
+
+	if _, err := os.Stat(e.idSign); err != nil {
+		if generateErr := e.generateSignKeys(); generateErr != nil {
+			return generateErr
+		}
+	}
+
+	return e.loadKeys()
+}
+
+func (e *ECCCrypt) generateBoxKeys() error {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if saveErr := savePEM(e.idBox, boxPrivateType, privateKey[:], 0600); saveErr != nil {
+		return saveErr
+	}
+	return savePEM(e.idBoxPub, boxPublicType, publicKey[:], 0644)
+}
+
+func (e *ECCCrypt) generateSignKeys() error {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	privateBytes, privateErr := x509.MarshalPKCS8PrivateKey(privateKey)
+	if privateErr != nil {
+		return privateErr
+	}
+	publicBytes, publicErr := x509.MarshalPKIXPublicKey(publicKey)
+	if publicErr != nil {
+		return publicErr
+	}
+	if saveErr := savePEM(e.idSign, "PRIVATE KEY", privateBytes, 0600); saveErr != nil {
+		return saveErr
+	}
+	return savePEM(e.idSignPub, "PUBLIC KEY", publicBytes, 0644)
+}
+
+func (e *ECCCrypt) loadKeys() error {
+	boxPrivate, boxPrivateErr := readPEM(e.idBox, boxPrivateType)
+	if boxPrivateErr != nil {
+		return boxPrivateErr
+	}
+	boxPublic, boxPublicErr := readPEM(e.idBoxPub, boxPublicType)
+	if boxPublicErr != nil {
+		return boxPublicErr
+	}
+	if len(boxPrivate) != 32 || len(boxPublic) != 32 {
+		return errors.New("fail get id_x25519, invalid key size")
+	}
+	e.boxPrivate = new([32]byte)
+	e.boxPublic = new([32]byte)
+	copy(e.boxPrivate[:], boxPrivate)
+	copy(e.boxPublic[:], boxPublic)
+
+	signPrivate, signPrivateErr := readPEM(e.idSign, "PRIVATE KEY")
+	if signPrivateErr != nil {
+		return signPrivateErr
+	}
+	privateKey, privateKeyErr := x509.ParsePKCS8PrivateKey(signPrivate)
+	if privateKeyErr != nil {
+		return privateKeyErr
+	}
+	signKey, ok := privateKey.(ed25519.PrivateKey)
+	if !ok {
+		return errors.New("fail get id_ed25519, invalid type")
+	}
+
+	signPublic, signPublicErr := readPEM(e.idSignPub, "PUBLIC KEY")
+	if signPublicErr != nil {
+		return signPublicErr
+	}
+	publicKey, publicKeyErr := x509.ParsePKIXPublicKey(signPublic)
+	if publicKeyErr != nil {
+		return publicKeyErr
+	}
+	verifyKey, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		return errors.New("fail get id_ed25519.pub, invalid type")
+	}
+
+	derivedPublic, derivedErr := curve25519.X25519(boxPrivate, curve25519.Basepoint)
+	if derivedErr != nil {
+		return derivedErr
+	}
+	if !bytes.Equal(derivedPublic, boxPublic) || !verifyKey.Equal(signKey.Public()) {
+		return ErrKeyPair
+	}
+
+	e.signKey = signKey
+	e.verifyKey = verifyKey
+	return nil
+}
+
+// PublicKey returns X25519 public key followed by Ed25519 public key, used for key fingerprint
+func (e *ECCCrypt) PublicKey() ([]byte, error) {
+	if e.boxPublic == nil {
+		return nil, errors.New("ecc keys are not loaded")
+	}
+	return append(append([]byte{}, e.boxPublic[:]...), e.verifyKey...), nil
+}
+
+// ExportSeeds returns X25519 private key and Ed25519 seed, they are enough to restore both key pairs
+func (e *ECCCrypt) ExportSeeds() ([]byte, []byte, error) {
+	if loadErr := e.loadKeys(); loadErr != nil {
+		return nil, nil, loadErr
+	}
+	return e.boxPrivate[:], e.signKey.Seed(), nil
+}
+
+// ImportSeeds restores key pairs from X25519 private key and Ed25519 seed and saves them in keys folder
+func (e *ECCCrypt) ImportSeeds(boxPrivate, signSeed []byte) error {
+	if len(boxPrivate) != curve25519.ScalarSize || len(signSeed) != ed25519.SeedSize {
+		return errors.New("fail restore ecc keys, invalid key size")
+	}
+	boxPublic, boxPublicErr := curve25519.X25519(boxPrivate, curve25519.Basepoint)
+	if boxPublicErr != nil {
+		return boxPublicErr
+	}
+	signKey := ed25519.NewKeyFromSeed(signSeed)
+	privateBytes, privateErr := x509.MarshalPKCS8PrivateKey(signKey)
+	if privateErr != nil {
+		return privateErr
+	}
+	publicBytes, publicErr := x509.MarshalPKIXPublicKey(signKey.Public())
+	if publicErr != nil {
+		return publicErr
+	}
+
+	if mkdirErr := os.MkdirAll(e.keysPath, 0700); mkdirErr != nil {
+		return mkdirErr
+	}
+	if saveErr := savePEM(e.idBox, boxPrivateType, boxPrivate, 0600); saveErr != nil {
+		return saveErr
+	}
+	if saveErr := savePEM(e.idBoxPub, boxPublicType, boxPublic, 0644); saveErr != nil {
+		return saveErr
+	}
+	if saveErr := savePEM(e.idSign, "PRIVATE KEY", privateBytes, 0600); saveErr != nil {
+		return saveErr
+	}
+	if saveErr := savePEM(e.idSignPub, "PUBLIC KEY", publicBytes, 0644); saveErr != nil {
+		return saveErr
+	}
+	return e.loadKeys()
+}
+
+// savePEM writes key bytes in PEM file
+func savePEM(path, blockType string, key []byte, perm os.FileMode) error {
+	block := &pem.Block{
+		Type:  blockType,
+		Bytes: key,
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(block), perm)
+}
+
+// readPEM reads key bytes from PEM file
+func readPEM(path, blockType string) ([]byte, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keyBlock, _ := pem.Decode(keyData)
+	if keyBlock == nil || keyBlock.Type != blockType {
+		return nil, errors.New("fail get " + filepath.Base(path) + ", invalid key")
+	}
+	return keyBlock.Bytes, nil
+}
+
+// Sign signs the payload with Ed25519 and returns the signature in base64
+func (e *ECCCrypt) Sign(payload string) (string, error) {
+	signature := ed25519.Sign(e.signKey, []byte(payload))
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// Verify check Ed25519 sign on payload
+func (e *ECCCrypt) Verify(payload string, signature64 string) bool {
+	signature, err := base64.StdEncoding.DecodeString(signature64)
+	if err != nil {
+		log.Println("ERROR: fail to base64 decode, ", err.Error())
+		return false
+	}
+	return ed25519.Verify(e.verifyKey, []byte(payload), signature)
+}
+
+// Encrypt seals payload in anonymous box for personal X25519 public key
+func (e *ECCCrypt) Encrypt(payload string) (string, error) {
+	sealed, err := box.SealAnonymous(nil, []byte(payload), e.boxPublic, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens sealed box via personal X25519 private key
+func (e *ECCCrypt) Decrypt(payload string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", err
+	}
+	plainText, ok := box.OpenAnonymous(nil, sealed, e.boxPublic, e.boxPrivate)
+	if !ok {
+		return "", ErrDecrypt
+	}
+	return string(plainText), nil
+}
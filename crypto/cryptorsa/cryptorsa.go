@@ -10,17 +10,23 @@ import (
 	"encoding/pem"
 	"errors"
 	"log"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-// RSACrypt implements ID_RSA crypto methods
+var ErrKeyPair = errors.New("id_rsa and id_rsa.pub dont form a key pair")
+
+// RSACrypt implements ID_RSA crypto methods,
+// keys are read from files once while initialization and kept in memory
 type RSACrypt struct {
-	keysPath string
-	idRsa    string
-	idRsaPub string
-	keySize  int
+	keysPath   string
+	idRsa      string
+	idRsaPub   string
+	keySize    int
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
 }
 
 // InitRSACrypt initializer of RSACrypt struct
@@ -38,6 +44,17 @@ func InitRSACrypt(path string, size int) *RSACrypt {
 	}
 }
 
+// KeysExist checks for the presence of private key in the directory
+func KeysExist(path string) bool {
+	_, err := os.Stat(filepath.Join(path, "id_rsa"))
+	return err == nil
+}
+
+// KeyFiles returns names of files with keys
+func KeyFiles() []string {
+	return []string{"id_rsa", "id_rsa.pub"}
+}
+
 // InitCrypto checks for the presence of keys in the directory,
 // if no keys are found - creates a pair personal and public keys
 func (r *RSACrypt) InitCrypto() error {
@@ -67,9 +84,36 @@ func (r *RSACrypt) InitCrypto() error {
 		}
 	}
 
+	return r.checkPair()
+}
+
+// checkPair checks that public key belongs to private key,
+// otherwise values would be encrypted with key that cant decrypt them
+func (r *RSACrypt) checkPair() error {
+	privateKey, privateKeyErr := r.readIDRsa()
+	if privateKeyErr != nil {
+		return privateKeyErr
+	}
+	publicKey, publicKeyErr := r.readIDRsaPub()
+	if publicKeyErr != nil {
+		return publicKeyErr
+	}
+	if !privateKey.PublicKey.Equal(publicKey) {
+		return ErrKeyPair
+	}
+	r.privateKey, r.publicKey = privateKey, publicKey
 	return nil
 }
 
+// PublicKey returns public key in DER format, used for key fingerprint
+func (r *RSACrypt) PublicKey() ([]byte, error) {
+	publicKey, publicKeyErr := r.getIDRsaPub()
+	if publicKeyErr != nil {
+		return nil, publicKeyErr
+	}
+	return x509.MarshalPKIXPublicKey(publicKey)
+}
+
 func (r *RSACrypt) generateKeyPair() (*rsa.PrivateKey, error) {
 	// generate key pair
 	keyPair, err := rsa.GenerateKey(rand.Reader, r.keySize)
@@ -93,18 +137,19 @@ func (r *RSACrypt) saveIDRsa(keyPair *rsa.PrivateKey) error {
 		Bytes: x509.MarshalPKCS1PrivateKey(keyPair),
 	}
 
-	// create file
-	f, err := os.Create(r.idRsa)
+	// create file, private key is readable only by owner
+	f, err := os.OpenFile(r.idRsa, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	err = pem.Encode(f, privateKeyBlock)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return f.Close()
 }
 
 func (r *RSACrypt) saveIDRsaPub(keyPair *rsa.PrivateKey) error {
@@ -120,19 +165,36 @@ func (r *RSACrypt) saveIDRsaPub(keyPair *rsa.PrivateKey) error {
 	}
 
 	// create file
-	f, err := os.Create(r.idRsaPub)
+	f, err := os.OpenFile(r.idRsaPub, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	err = pem.Encode(f, publicKeyBlock)
 	if err != nil {
 		return err
 	}
-	return nil
+	return f.Close()
 }
 
+// getIDRsa returns private key, it is read from file if crypto wasn't initialized
 func (r *RSACrypt) getIDRsa() (*rsa.PrivateKey, error) {
+	if r.privateKey != nil {
+		return r.privateKey, nil
+	}
+	return r.readIDRsa()
+}
+
+// getIDRsaPub returns public key, it is read from file if crypto wasn't initialized
+func (r *RSACrypt) getIDRsaPub() (*rsa.PublicKey, error) {
+	if r.publicKey != nil {
+		return r.publicKey, nil
+	}
+	return r.readIDRsaPub()
+}
+
+func (r *RSACrypt) readIDRsa() (*rsa.PrivateKey, error) {
 	keyData, err := os.ReadFile(r.idRsa)
 	if err != nil {
 		return nil, err
@@ -151,7 +213,7 @@ func (r *RSACrypt) getIDRsa() (*rsa.PrivateKey, error) {
 	return privateKey, nil
 }
 
-func (r *RSACrypt) getIDRsaPub() (*rsa.PublicKey, error) {
+func (r *RSACrypt) readIDRsaPub() (*rsa.PublicKey, error) {
 	keyData, err := os.ReadFile(r.idRsaPub)
 	if err != nil {
 		return nil, err
@@ -174,6 +236,57 @@ func (r *RSACrypt) getIDRsaPub() (*rsa.PublicKey, error) {
 	}
 }
 
+// ExportPrimes returns primes of private key, they are enough to restore the key pair
+func (r *RSACrypt) ExportPrimes() ([][]byte, error) {
+	privateKey, privateKeyErr := r.getIDRsa()
+	if privateKeyErr != nil {
+		return nil, privateKeyErr
+	}
+	primes := make([][]byte, 0, len(privateKey.Primes))
+	for _, prime := range privateKey.Primes {
+		primes = append(primes, prime.Bytes())
+	}
+	return primes, nil
+}
+
+// ImportPrimes restores key pair from primes of private key and saves it in keys folder
+func (r *RSACrypt) ImportPrimes(primes [][]byte) error {
+	if len(primes) < 2 {
+		return errors.New("fail restore id_rsa, at least two primes are required")
+	}
+	keyPair := &rsa.PrivateKey{PublicKey: rsa.PublicKey{N: big.NewInt(1), E: 65537}}
+	totient := big.NewInt(1)
+	one := big.NewInt(1)
+	for _, primeBytes := range primes {
+		prime := new(big.Int).SetBytes(primeBytes)
+		keyPair.Primes = append(keyPair.Primes, prime)
+		keyPair.N.Mul(keyPair.N, prime)
+		totient.Mul(totient, new(big.Int).Sub(prime, one))
+	}
+	keyPair.D = new(big.Int).ModInverse(big.NewInt(int64(keyPair.E)), totient)
+	if keyPair.D == nil {
+		return errors.New("fail restore id_rsa, invalid primes")
+	}
+	if validateErr := keyPair.Validate(); validateErr != nil {
+		return validateErr
+	}
+	keyPair.Precompute()
+
+	if _, err := os.Stat(r.keysPath); err != nil {
+		if mkdirErr := os.MkdirAll(r.keysPath, 0700); mkdirErr != nil {
+			return mkdirErr
+		}
+	}
+	if savePrivateErr := r.saveIDRsa(keyPair); savePrivateErr != nil {
+		return savePrivateErr
+	}
+	if savePubErr := r.saveIDRsaPub(keyPair); savePubErr != nil {
+		return savePubErr
+	}
+	r.privateKey, r.publicKey = keyPair, &keyPair.PublicKey
+	return nil
+}
+
 // Sign signs the file and returns the signature in base64
 func (r *RSACrypt) Sign(payload string) (string, error) {
 	// remove unwated characters and get sha256 hash of the payload
@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// envelopeSeparator separates sealed data key and sealed value of envelope,
+// it is not a base64 symbol, so envelopes differ from values encrypted by keys directly
+const envelopeSeparator = "."
+
+// dataKeySize size of random AES-256 key of envelope
+const dataKeySize = 32
+
+// minimal sizes of values encrypted by keys directly: RSA block of 1024 bits key
+// and overhead of anonymous X25519 box (ephemeral public key and tag)
+const (
+	minRSACipherSize = 128
+	minECCCipherSize = 48
+)
+
+var ErrEnvelope = errors.New("malformed envelope")
+
+// sealEnvelope encrypts payload of any size by random data key with AES-GCM,
+// data key is encrypted by keys of crypto, so size of payload is not limited by RSA block
+func sealEnvelope(workCrypto Crypto, payload string) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, randErr := rand.Read(dataKey); randErr != nil {
+		return "", randErr
+	}
+	aead, aeadErr := newAEAD(dataKey)
+	if aeadErr != nil {
+		return "", aeadErr
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, randErr := rand.Read(nonce); randErr != nil {
+		return "", randErr
+	}
+	sealedKey, sealedKeyErr := workCrypto.Encrypt(base64.StdEncoding.EncodeToString(dataKey))
+	if sealedKeyErr != nil {
+		return "", sealedKeyErr
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(payload), nil)
+	return sealedKey + envelopeSeparator + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openEnvelope decrypts data key of envelope by keys of crypto and opens value by it
+func openEnvelope(workCrypto Crypto, envelope string) (string, error) {
+	sealedKey, sealedValue, found := strings.Cut(envelope, envelopeSeparator)
+	if !found {
+		return "", ErrEnvelope
+	}
+	dataKey64, dataKeyErr := workCrypto.Decrypt(sealedKey)
+	if dataKeyErr != nil {
+		return "", dataKeyErr
+	}
+	dataKey, decodeErr := base64.StdEncoding.DecodeString(dataKey64)
+	if decodeErr != nil || len(dataKey) != dataKeySize {
+		return "", ErrEnvelope
+	}
+	sealed, decodeErr := base64.StdEncoding.DecodeString(sealedValue)
+	if decodeErr != nil {
+		return "", ErrEnvelope
+	}
+	aead, aeadErr := newAEAD(dataKey)
+	if aeadErr != nil {
+		return "", aeadErr
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrEnvelope
+	}
+	payload, openErr := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if openErr != nil {
+		return "", openErr
+	}
+	return string(payload), nil
+}
+
+// newAEAD makes AES-GCM cipher of data key
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, blockErr := aes.NewCipher(dataKey)
+	if blockErr != nil {
+		return nil, blockErr
+	}
+	return cipher.NewGCM(block)
+}
+
+// IsEncrypted reports whether value has form of value encrypted by Cryptorizer:
+// envelope or RSA / ECC cipher, tagged with known algorithm or untagged RSA cipher.
+// Values that are not encrypted (like saved before metadata encryption) could be identified by it
+func IsEncrypted(value string) bool {
+	algorithm, payload := ValueAlgorithm(value)
+	if algorithm != AlgorithmRSA && algorithm != AlgorithmECC {
+		return false
+	}
+	if sealedKey, sealedValue, found := strings.Cut(payload, envelopeSeparator); found {
+		_, valueErr := base64.StdEncoding.DecodeString(sealedValue)
+		return valueErr == nil && isCipher(algorithm, sealedKey)
+	}
+	return isCipher(algorithm, payload)
+}
+
+// isCipher checks that payload is base64 value of size of cipher of algorithm
+func isCipher(algorithm, payload string) bool {
+	cipherText, decodeErr := base64.StdEncoding.DecodeString(payload)
+	if decodeErr != nil {
+		return false
+	}
+	if algorithm == AlgorithmRSA {
+		return len(cipherText) >= minRSACipherSize && len(cipherText)%minRSACipherSize == 0
+	}
+	return len(cipherText) >= minECCCipherSize
+}
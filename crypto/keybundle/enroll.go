@@ -0,0 +1,86 @@
+package keybundle
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// DeviceKeySize size of ephemeral X25519 key of enrolled device
+const DeviceKeySize = 32
+
+var (
+	ErrDeviceKey  = errors.New("wrong public key of device")
+	ErrDeviceCode = errors.New("code doesnt match public key of device, enrollment could be substituted")
+	ErrSealed     = errors.New("fail to open sealed key bundle")
+)
+
+// DeviceKey ephemeral X25519 key pair of new device, it lives only while enrollment
+type DeviceKey struct {
+	Public  *[DeviceKeySize]byte
+	Private *[DeviceKeySize]byte
+}
+
+// NewDeviceKey generates ephemeral key pair for enrollment of new device
+func NewDeviceKey() (*DeviceKey, error) {
+	public, private, generateErr := box.GenerateKey(rand.Reader)
+	if generateErr != nil {
+		return nil, generateErr
+	}
+	return &DeviceKey{Public: public, Private: private}, nil
+}
+
+// Open decrypts key bundle sealed for the device
+func (k *DeviceKey) Open(sealed []byte) ([]byte, error) {
+	bundle, ok := box.OpenAnonymous(nil, sealed, k.Public, k.Private)
+	if !ok {
+		return nil, ErrSealed
+	}
+	return bundle, nil
+}
+
+// deviceCodeSize bytes of sha256 in device code, 80 bits leave no chance to find another key with the same code
+const deviceCodeSize = 10
+
+// DeviceCode returns short code of device public key: the first 80 bits of sha256 in base32, "XXXX-XXXX-XXXX-XXXX".
+// User compares it on both devices, so service can't substitute the key.
+func DeviceCode(public []byte) string {
+	hash := sha256.Sum256(public)
+	return groupCode(base32.StdEncoding.EncodeToString(hash[:deviceCodeSize]))
+}
+
+// NormalizeCode brings code typed by user to DeviceCode format
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != base32.StdEncoding.EncodedLen(deviceCodeSize) {
+		return code
+	}
+	return groupCode(code)
+}
+
+// groupCode splits code by groups of 4 characters
+func groupCode(code string) string {
+	groups := make([]string, 0, len(code)/4)
+	for i := 0; i < len(code); i += 4 {
+		groups = append(groups, code[i:i+4])
+	}
+	return strings.Join(groups, "-")
+}
+
+// Seal encrypts key bundle for device public key, the code typed by user is checked before,
+// so bundle is never sealed for a key substituted by service
+func Seal(bundle, public []byte, code string) ([]byte, error) {
+	if len(public) != DeviceKeySize {
+		return nil, ErrDeviceKey
+	}
+	if DeviceCode(public) != NormalizeCode(code) {
+		return nil, ErrDeviceCode
+	}
+	var publicKey [DeviceKeySize]byte
+	copy(publicKey[:], public)
+	return box.SealAnonymous(nil, bundle, &publicKey, rand.Reader)
+}
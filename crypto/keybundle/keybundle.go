@@ -0,0 +1,135 @@
+// Package keybundle packs private keys of the client and secret of files encryption
+// in one compact binary bundle and splits it in Shamir shares for backup.
+package keybundle
+
+import (
+	"AlexSarva/GophKeeper/crypto/cryptoecc"
+	"AlexSarva/GophKeeper/crypto/cryptorsa"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const bundleVersion = 1
+
+// record tags of bundle
+const (
+	tagRSAPrime byte = iota + 1
+	tagX25519
+	tagEd25519Seed
+	tagSecret
+)
+
+var (
+	ErrNoKeys   = errors.New("no keys to backup")
+	ErrBundle   = errors.New("wrong key bundle, check that shares are from one backup and their number reaches threshold")
+	ErrKeyExist = errors.New("keys already exist in keys folder")
+)
+
+// Export packs private keys of all algorithms from keys folder and secret of files encryption.
+// Bundle: version, records (tag, uint16 length, value), sha256 checksum (4 bytes)
+func Export(keysPath, secret string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(bundleVersion)
+	var keys bool
+
+	if cryptorsa.KeysExist(keysPath) {
+		primes, primesErr := cryptorsa.InitRSACrypt(keysPath, 1024).ExportPrimes()
+		if primesErr != nil {
+			return nil, primesErr
+		}
+		for _, prime := range primes {
+			writeRecord(&buf, tagRSAPrime, prime)
+		}
+		keys = true
+	}
+
+	if cryptoecc.KeysExist(keysPath) {
+		boxPrivate, signSeed, seedsErr := cryptoecc.InitECCCrypt(keysPath).ExportSeeds()
+		if seedsErr != nil {
+			return nil, seedsErr
+		}
+		writeRecord(&buf, tagX25519, boxPrivate)
+		writeRecord(&buf, tagEd25519Seed, signSeed)
+		keys = true
+	}
+
+	if !keys {
+		return nil, ErrNoKeys
+	}
+	if secret != "" {
+		writeRecord(&buf, tagSecret, []byte(secret))
+	}
+
+	checksum := sha256.Sum256(buf.Bytes())
+	buf.Write(checksum[:4])
+	return buf.Bytes(), nil
+}
+
+func writeRecord(buf *bytes.Buffer, tag byte, value []byte) {
+	buf.WriteByte(tag)
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(value)))
+	buf.Write(value)
+}
+
+// Import restores keys from bundle in keys folder and returns secret of files encryption,
+// existing keys are never overwritten
+func Import(bundle []byte, keysPath string) (string, error) {
+	if len(bundle) < 5 || bundle[0] != bundleVersion {
+		return "", ErrBundle
+	}
+	content, checksum := bundle[:len(bundle)-4], bundle[len(bundle)-4:]
+	contentHash := sha256.Sum256(content)
+	if !bytes.Equal(contentHash[:4], checksum) {
+		return "", ErrBundle
+	}
+
+	var primes [][]byte
+	var boxPrivate, signSeed []byte
+	var secret string
+	reader := bytes.NewReader(content[1:])
+	for reader.Len() > 0 {
+		tag, tagErr := reader.ReadByte()
+		if tagErr != nil {
+			return "", ErrBundle
+		}
+		var size uint16
+		if sizeErr := binary.Read(reader, binary.BigEndian, &size); sizeErr != nil {
+			return "", ErrBundle
+		}
+		value := make([]byte, size)
+		if _, readErr := io.ReadFull(reader, value); readErr != nil {
+			return "", ErrBundle
+		}
+		switch tag {
+		case tagRSAPrime:
+			primes = append(primes, value)
+		case tagX25519:
+			boxPrivate = value
+		case tagEd25519Seed:
+			signSeed = value
+		case tagSecret:
+			secret = string(value)
+		default:
+			return "", ErrBundle
+		}
+	}
+
+	if (primes != nil && cryptorsa.KeysExist(keysPath)) ||
+		((boxPrivate != nil || signSeed != nil) && cryptoecc.KeysExist(keysPath)) {
+		return "", ErrKeyExist
+	}
+	if primes != nil {
+		if importErr := cryptorsa.InitRSACrypt(keysPath, 1024).ImportPrimes(primes); importErr != nil {
+			return "", importErr
+		}
+	}
+	if boxPrivate != nil || signSeed != nil {
+		if importErr := cryptoecc.InitECCCrypt(keysPath).ImportSeeds(boxPrivate, signSeed); importErr != nil {
+			return "", importErr
+		}
+	}
+	return secret, nil
+}
@@ -0,0 +1,107 @@
+package keybundle
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupRecover(t *testing.T) {
+	keysPath := t.TempDir()
+	rsaCryptorizer, rsaErr := crypto.InitCryptorizer(keysPath, 1024, crypto.AlgorithmRSA)
+	assert.NoError(t, rsaErr)
+	eccCryptorizer, eccErr := crypto.InitCryptorizer(keysPath, 1024, crypto.AlgorithmECC)
+	assert.NoError(t, eccErr)
+	rsaCipher, _ := rsaCryptorizer.Encrypt("4405 1111 1000 1383")
+	eccCipher, _ := eccCryptorizer.Encrypt("dPQzakp9DMSW")
+
+	shares, splitErr := Split(keysPath, "file secret", 5, 3)
+	assert.NoError(t, splitErr)
+
+	first, parseErr := ParseShare(shares[0].Mnemonic())
+	assert.NoError(t, parseErr)
+	second, parseErr := ParseShare(shares[3].Compact())
+	assert.NoError(t, parseErr)
+	third, parseErr := ParseShare(strings.ToUpper(shares[4].Mnemonic()))
+	assert.NoError(t, parseErr)
+
+	recoverPath := t.TempDir()
+	secret, recoverErr := Recover([]Share{first, second, third}, recoverPath)
+	assert.NoError(t, recoverErr)
+	assert.Equal(t, "file secret", secret)
+
+	recovered, recoveredErr := crypto.InitCryptorizer(recoverPath, 1024, crypto.AlgorithmRSA)
+	assert.NoError(t, recoveredErr)
+	payload, decryptErr := recovered.Decrypt(rsaCipher)
+	assert.NoError(t, decryptErr)
+	assert.Equal(t, "4405 1111 1000 1383", payload)
+	payload, decryptErr = recovered.Decrypt(eccCipher)
+	assert.NoError(t, decryptErr)
+	assert.Equal(t, "dPQzakp9DMSW", payload)
+
+	_, existErr := Recover([]Share{first, second, third}, recoverPath)
+	assert.ErrorIs(t, existErr, ErrKeyExist)
+
+	_, notEnoughErr := Recover([]Share{first, second}, t.TempDir())
+	assert.ErrorIs(t, notEnoughErr, ErrNotEnough)
+	assert.EqualError(t, notEnoughErr, "not enough shares: need 3 shares, got 2")
+	_, repeatedErr := Recover([]Share{first, second, second}, t.TempDir())
+	assert.ErrorIs(t, repeatedErr, ErrNotEnough)
+	_, noSharesErr := Recover(nil, t.TempDir())
+	assert.ErrorIs(t, noSharesErr, ErrNotEnough)
+}
+
+func TestParseShareTypos(t *testing.T) {
+	share := Share{Threshold: 2, Data: []byte("share data with number\x01")}
+	words := strings.Fields(share.Mnemonic())
+
+	tests := []struct {
+		name string
+		text string
+		err  error
+	}{
+		{
+			name: "unknown word",
+			text: strings.Join(append([]string{"gopher"}, words[1:]...), " "),
+			err:  ErrUnknownWord,
+		},
+		{
+			name: "swapped words",
+			text: strings.Join(append(append(append([]string{}, words[:4]...), words[5], words[4]), words[6:]...), " "),
+			err:  ErrShareChecksum,
+		},
+		{
+			name: "missed words",
+			text: strings.Join(words[:len(words)-2], " "),
+			err:  ErrShareFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, parseErr := ParseShare(tt.text)
+			assert.ErrorIs(t, parseErr, tt.err)
+		})
+	}
+}
+
+func TestSealForDevice(t *testing.T) {
+	device, deviceErr := NewDeviceKey()
+	assert.NoError(t, deviceErr)
+	code := DeviceCode(device.Public[:])
+	assert.Len(t, code, 19)
+
+	sealed, sealErr := Seal([]byte("bundle"), device.Public[:], strings.ToLower(strings.ReplaceAll(code, "-", "")))
+	assert.NoError(t, sealErr)
+	bundle, openErr := device.Open(sealed)
+	assert.NoError(t, openErr)
+	assert.Equal(t, "bundle", string(bundle))
+
+	substituted, substitutedErr := NewDeviceKey()
+	assert.NoError(t, substitutedErr)
+	_, sealErr = Seal([]byte("bundle"), substituted.Public[:], code)
+	assert.ErrorIs(t, sealErr, ErrDeviceCode)
+	_, openErr = substituted.Open(sealed)
+	assert.ErrorIs(t, openErr, ErrSealed)
+}
@@ -0,0 +1,184 @@
+package keybundle
+
+import (
+	"AlexSarva/GophKeeper/crypto/shamir"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+const (
+	shareVersion = 1
+	// compactPrefix prefix of share in compact form, it is used in QR codes
+	compactPrefix = "gkshare:"
+	wordBits      = 11
+)
+
+var (
+	ErrShareChecksum = errors.New("share checksum mismatch, check it for typos")
+	ErrShareFormat   = errors.New("wrong share format")
+	ErrUnknownWord   = errors.New("unknown word in share")
+	ErrThreshold     = errors.New("shares have different thresholds")
+	ErrNotEnough     = errors.New("not enough shares")
+)
+
+var wordIndex = func() map[string]int {
+	index := make(map[string]int, len(wordlists.English))
+	for i, word := range wordlists.English {
+		index[word] = i
+	}
+	return index
+}()
+
+// Share one of Shamir shares of key bundle
+type Share struct {
+	Threshold int
+	Data      []byte
+}
+
+// Split makes key bundle and splits it in shares, any threshold of them restore keys
+func Split(keysPath, secret string, shares, threshold int) ([]Share, error) {
+	bundle, bundleErr := Export(keysPath, secret)
+	if bundleErr != nil {
+		return nil, bundleErr
+	}
+	parts, splitErr := shamir.Split(bundle, shares, threshold)
+	if splitErr != nil {
+		return nil, splitErr
+	}
+	res := make([]Share, 0, len(parts))
+	for _, part := range parts {
+		res = append(res, Share{Threshold: threshold, Data: part})
+	}
+	return res, nil
+}
+
+// Recover combines shares and restores keys in keys folder, returns secret of files encryption.
+// Shares with the same number are counted once, at least threshold of different shares are needed
+func Recover(shares []Share, keysPath string) (string, error) {
+	if len(shares) == 0 {
+		return "", fmt.Errorf("%w: no shares", ErrNotEnough)
+	}
+	threshold := shares[0].Threshold
+	parts := make([][]byte, 0, len(shares))
+	numbers := make(map[int]bool, len(shares))
+	for _, share := range shares {
+		if share.Threshold != threshold {
+			return "", ErrThreshold
+		}
+		if numbers[share.Number()] {
+			continue
+		}
+		numbers[share.Number()] = true
+		parts = append(parts, share.Data)
+	}
+	if len(parts) < threshold {
+		return "", fmt.Errorf("%w: need %d shares, got %d", ErrNotEnough, threshold, len(parts))
+	}
+	bundle, combineErr := shamir.Combine(parts)
+	if combineErr != nil {
+		return "", combineErr
+	}
+	return Import(bundle, keysPath)
+}
+
+// Number returns number of share
+func (s Share) Number() int {
+	if len(s.Data) == 0 {
+		return 0
+	}
+	return int(s.Data[len(s.Data)-1])
+}
+
+// payload: version, threshold, uint16 length of data, data, crc32 checksum
+func (s Share) payload() []byte {
+	payload := make([]byte, 4, len(s.Data)+8)
+	payload[0] = shareVersion
+	payload[1] = byte(s.Threshold)
+	binary.BigEndian.PutUint16(payload[2:], uint16(len(s.Data)))
+	payload = append(payload, s.Data...)
+	return binary.BigEndian.AppendUint32(payload, crc32.ChecksumIEEE(payload))
+}
+
+// Mnemonic returns share as list of words, every word encodes 11 bits
+func (s Share) Mnemonic() string {
+	payload := s.payload()
+	words := make([]string, 0, (len(payload)*8+wordBits-1)/wordBits)
+	var acc, bits int
+	for _, b := range payload {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= wordBits {
+			bits -= wordBits
+			words = append(words, wordlists.English[(acc>>bits)&(1<<wordBits-1)])
+		}
+		acc &= 1<<bits - 1
+	}
+	if bits > 0 {
+		words = append(words, wordlists.English[(acc<<(wordBits-bits))&(1<<wordBits-1)])
+	}
+	return strings.Join(words, " ")
+}
+
+// Compact returns share in short text form for QR codes
+func (s Share) Compact() string {
+	return compactPrefix + base64.RawURLEncoding.EncodeToString(s.payload())
+}
+
+// ParseShare parses share in mnemonic or compact form and checks its checksum
+func ParseShare(text string) (Share, error) {
+	text = strings.TrimSpace(text)
+	var payload []byte
+	if strings.HasPrefix(text, compactPrefix) {
+		var decodeErr error
+		payload, decodeErr = base64.RawURLEncoding.DecodeString(strings.TrimPrefix(text, compactPrefix))
+		if decodeErr != nil {
+			return Share{}, ErrShareFormat
+		}
+	} else {
+		var wordsErr error
+		payload, wordsErr = fromWords(strings.Fields(strings.ToLower(text)))
+		if wordsErr != nil {
+			return Share{}, wordsErr
+		}
+	}
+
+	if len(payload) < 8 || payload[0] != shareVersion {
+		return Share{}, ErrShareFormat
+	}
+	size := int(binary.BigEndian.Uint16(payload[2:4]))
+	if len(payload) < size+8 {
+		return Share{}, ErrShareFormat
+	}
+	payload = payload[:size+8]
+	checksum := binary.BigEndian.Uint32(payload[size+4:])
+	if crc32.ChecksumIEEE(payload[:size+4]) != checksum {
+		return Share{}, ErrShareChecksum
+	}
+	return Share{Threshold: int(payload[1]), Data: payload[4 : size+4]}, nil
+}
+
+// fromWords decodes words in bytes, padding bits of the last word are dropped
+func fromWords(words []string) ([]byte, error) {
+	payload := make([]byte, 0, len(words)*wordBits/8)
+	var acc, bits int
+	for i, word := range words {
+		value, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q (word %d)", ErrUnknownWord, word, i+1)
+		}
+		acc = acc<<wordBits | value
+		bits += wordBits
+		for bits >= 8 {
+			bits -= 8
+			payload = append(payload, byte(acc>>bits))
+		}
+		acc &= 1<<bits - 1
+	}
+	return payload, nil
+}
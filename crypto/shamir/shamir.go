@@ -0,0 +1,123 @@
+// Package shamir implements Shamir's secret sharing over GF(256):
+// secret is split into N shares, any K of them restore it, K-1 shares give no information about it.
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+var (
+	ErrParams = errors.New("threshold should be from 2 to number of shares, number of shares should be less than 256")
+	ErrShares = errors.New("at least two shares of the same length and with different numbers are required")
+)
+
+// exp and log tables of GF(256) with AES polynomial x^8 + x^4 + x^3 + x + 1 and generator 3
+var (
+	expTable [255]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		logTable[x] = byte(i)
+		// multiply by generator: x * 3 = x * 2 + x
+		double := x << 1
+		if x&0x80 != 0 {
+			double ^= 0x1b
+		}
+		x ^= double
+	}
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])+int(logTable[b]))%255]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])-int(logTable[b])+255)%255]
+}
+
+// Split splits secret into shares, any threshold of them restore the secret.
+// Every share is one byte longer than secret: the last byte is number of share
+func Split(secret []byte, shares, threshold int) ([][]byte, error) {
+	if threshold < 2 || threshold > shares || shares > 255 {
+		return nil, ErrParams
+	}
+
+	res := make([][]byte, shares)
+	for i := range res {
+		res[i] = make([]byte, len(secret)+1)
+		res[i][len(secret)] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, threshold)
+	for pos, value := range secret {
+		coefficients[0] = value
+		if _, randErr := rand.Read(coefficients[1:]); randErr != nil {
+			return nil, randErr
+		}
+		for i := range res {
+			res[i][pos] = evaluate(coefficients, byte(i+1))
+		}
+	}
+	return res, nil
+}
+
+// evaluate calculates polynomial value in x by Horner's method
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coefficients[i]
+	}
+	return y
+}
+
+// Combine restores secret from shares by Lagrange interpolation.
+// If shares are less than threshold, result will be wrong, so secret should contain its own checksum
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrShares
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrShares
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, ErrShares
+		}
+		x := share[size-1]
+		if x == 0 || seen[x] {
+			return nil, ErrShares
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, size-1)
+	for i, share := range shares {
+		// Lagrange basis polynomial of share in 0
+		basis := byte(1)
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			basis = mul(basis, div(xs[j], xs[i]^xs[j]))
+		}
+		for pos := range secret {
+			secret[pos] ^= mul(share[pos], basis)
+		}
+	}
+	return secret, nil
+}
@@ -0,0 +1,90 @@
+// Package events delivers changes of user elements to connected clients.
+// Events are published by service after successful changes and are fanned out
+// to subscriptions of the same user
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions of events
+const (
+	Created = "created"
+	Updated = "updated"
+	Deleted = "deleted"
+)
+
+// KindVault kind of event about replaced vault, all lists of user are changed
+const KindVault = "vault"
+
+// subscriptionBuffer number of events waiting for slow subscriber, newer events are dropped
+const subscriptionBuffer = 64
+
+// Event change of user element. Kind is info type of element: notes, cards, creds, files or vault
+type Event struct {
+	UserID uuid.UUID `json:"-"`
+	Action string    `json:"action"`
+	Kind   string    `json:"kind"`
+	ID     uuid.UUID `json:"id"`
+	Time   time.Time `json:"time"`
+}
+
+// Hub publishes events and subscribes clients on them
+type Hub interface {
+	// Publish sends event to subscriptions of its user
+	Publish(event Event)
+	// Subscribe returns events of user, cancel closes channel
+	Subscribe(userID uuid.UUID) (<-chan Event, func())
+}
+
+// Bus in-process hub, it delivers events only to subscriptions of this server instance
+type Bus struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan Event]struct{}
+}
+
+// NewBus initializer of Bus struct
+func NewBus() *Bus {
+	return &Bus{subs: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Publish sends event to subscriptions of its user without blocking:
+// if subscriber doesnt read events, they are dropped for it
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[event.UserID] {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns events of user, cancel should be called when subscriber is done
+func (b *Bus) Subscribe(userID uuid.UUID) (<-chan Event, func()) {
+	sub := make(chan Event, subscriptionBuffer)
+	b.mu.Lock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[chan Event]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs[userID], sub)
+			if len(b.subs[userID]) == 0 {
+				delete(b.subs, userID)
+			}
+			b.mu.Unlock()
+			close(sub)
+		})
+	}
+	return sub, cancel
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	user, other := uuid.New(), uuid.New()
+	userEvents, cancelUser := bus.Subscribe(user)
+	otherEvents, cancelOther := bus.Subscribe(other)
+	defer cancelOther()
+
+	event := Event{UserID: user, Action: Created, Kind: "notes", ID: uuid.New()}
+	bus.Publish(event)
+	assert.Equal(t, event, <-userEvents)
+	assert.Len(t, otherEvents, 0)
+
+	// slow subscriber doesnt block publisher
+	for i := 0; i < subscriptionBuffer+10; i++ {
+		bus.Publish(event)
+	}
+	assert.Len(t, userEvents, subscriptionBuffer)
+
+	cancelUser()
+	cancelUser()
+	bus.Publish(event)
+	for range userEvents {
+	}
+	_, ok := <-userEvents
+	assert.False(t, ok)
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// pgChannel channel of PostgreSQL notifications with events
+const pgChannel = "gophkeeper_events"
+
+// pgEvent payload of notification, it keeps user of event
+type pgEvent struct {
+	UserID uuid.UUID `json:"user_id"`
+	Action string    `json:"action"`
+	Kind   string    `json:"kind"`
+	ID     uuid.UUID `json:"id"`
+	Time   time.Time `json:"time"`
+}
+
+// PGRelay hub for several server instances: events are published by PostgreSQL NOTIFY
+// and every instance delivers them from LISTEN to its own subscriptions
+type PGRelay struct {
+	*Bus
+	database *sqlx.DB
+	listener *pq.Listener
+}
+
+// NewPGRelay connects to PostgreSQL and listens channel of events
+func NewPGRelay(config string) (*PGRelay, error) {
+	db, err := sqlx.Connect("postgres", config)
+	if err != nil {
+		return nil, err
+	}
+	listener := pq.NewListener(config, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("events listener:", err)
+		}
+	})
+	if listenErr := listener.Listen(pgChannel); listenErr != nil {
+		return nil, listenErr
+	}
+	return &PGRelay{
+		Bus:      NewBus(),
+		database: db,
+		listener: listener,
+	}, nil
+}
+
+// Publish notifies all server instances about event,
+// if notification fails event is delivered only by this instance
+func (r *PGRelay) Publish(event Event) {
+	payload, marshalErr := json.Marshal(pgEvent(event))
+	if marshalErr == nil {
+		_, notifyErr := r.database.Exec("select pg_notify($1, $2)", pgChannel, string(payload))
+		if notifyErr == nil {
+			return
+		}
+		log.Println("events notify:", notifyErr)
+	}
+	r.Bus.Publish(event)
+}
+
+// Run delivers notifications to subscriptions until context is done
+func (r *PGRelay) Run(ctx context.Context) {
+	defer func() {
+		if closeErr := r.listener.Close(); closeErr != nil {
+			log.Println(closeErr)
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-r.listener.Notify:
+			// nil notification is sent after reconnect, events of disconnected time are lost
+			if notification == nil {
+				continue
+			}
+			var event pgEvent
+			if unmarshalErr := json.Unmarshal([]byte(notification.Extra), &event); unmarshalErr != nil {
+				log.Println("events payload:", unmarshalErr)
+				continue
+			}
+			r.Bus.Publish(Event(event))
+		case <-time.After(90 * time.Second):
+			if pingErr := r.listener.Ping(); pingErr != nil {
+				log.Println("events listener:", pingErr)
+			}
+		}
+	}
+}
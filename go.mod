@@ -4,6 +4,10 @@ go 1.19
 
 require (
 	github.com/caarlos0/env/v6 v6.10.1
+	github.com/dgrijalva/jwt-go/v4 v4.0.0-preview1
+	github.com/fxamacker/cbor/v2 v2.4.0
+	github.com/gdamore/tcell/v2 v2.5.2
+	github.com/getkin/kin-openapi v0.110.0
 	github.com/go-chi/chi/v5 v5.0.7
 	github.com/go-chi/cors v1.2.1
 	github.com/google/uuid v1.3.0
@@ -11,27 +15,40 @@ require (
 	github.com/lib/pq v1.10.7
 	github.com/sarulabs/di v2.0.0+incompatible
 	github.com/stretchr/testify v1.8.1
+	github.com/tyler-smith/go-bip39 v1.1.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
 	golang.org/x/crypto v0.3.0
+	golang.org/x/term v0.2.0
+	google.golang.org/grpc v1.51.0
+	google.golang.org/protobuf v1.28.1
+	gopkg.in/eapache/go-resiliency.v1 v1.2.0
+	gopkg.in/h2non/gentleman-retry.v2 v2.0.1
+	gopkg.in/h2non/gentleman.v2 v2.0.5
+	rsc.io/qr v0.2.0
 )
 
 require (
 	code.rocketnine.space/tslocum/cbind v0.1.5 // indirect
 	code.rocketnine.space/tslocum/cview v1.5.8 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/dgrijalva/jwt-go/v4 v4.0.0-preview1 // indirect
 	github.com/gdamore/encoding v1.0.0 // indirect
-	github.com/gdamore/tcell/v2 v2.5.2 // indirect
+	github.com/go-openapi/jsonpointer v0.19.5 // indirect
+	github.com/go-openapi/swag v0.19.5 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/invopop/yaml v0.1.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/mailru/easyjson v0.0.0-20190626092158-b2ccc519800e // indirect
 	github.com/mattn/go-runewidth v0.0.14-0.20220323023645-f9d555329d96 // indirect
+	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rivo/tview v0.0.0-20221117065207-09f052e6ca98 // indirect
 	github.com/rivo/uniseg v0.4.2 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/net v0.2.0 // indirect
 	golang.org/x/sys v0.2.0 // indirect
-	golang.org/x/term v0.2.0 // indirect
 	golang.org/x/text v0.4.0 // indirect
-	gopkg.in/eapache/go-resiliency.v1 v1.2.0 // indirect
-	gopkg.in/h2non/gentleman-retry.v2 v2.0.1 // indirect
-	gopkg.in/h2non/gentleman.v2 v2.0.5 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
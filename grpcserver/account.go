@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ChangePassword changes password of user, other sessions of user are revoked
+func (s *KeeperServer) ChangePassword(ctx context.Context, req *keeperpb.PasswordChange) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	change := &models.PasswordChange{Password: req.GetPassword(), NewPassword: req.GetNewPassword()}
+	if changeErr := s.keeper.ChangePassword(userID, getSessionID(ctx), change, sessionClient(ctx)); changeErr != nil {
+		return nil, statusError(changeErr)
+	}
+	return empty, nil
+}
+
+// DeleteAccount deletes user with all elements after user is authenticated again
+func (s *KeeperServer) DeleteAccount(ctx context.Context, req *keeperpb.AccountDeletion) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	deletion := &models.AccountDeletion{Password: req.GetPassword(), Code: req.GetCode()}
+	if deleteErr := s.keeper.DeleteAccount(userID, deletion, sessionClient(ctx)); deleteErr != nil {
+		return nil, statusError(deleteErr)
+	}
+	return empty, nil
+}
+
+// VerifyEmail confirms email of user by code from message
+func (s *KeeperServer) VerifyEmail(_ context.Context, req *keeperpb.EmailVerification) (*emptypb.Empty, error) {
+	if verifyErr := s.keeper.VerifyEmail(&models.EmailVerification{Token: req.GetToken()}); verifyErr != nil {
+		return nil, statusError(verifyErr)
+	}
+	return empty, nil
+}
+
+// ResendVerification sends new code that confirms email of user
+func (s *KeeperServer) ResendVerification(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	if sendErr := s.keeper.ResendVerification(userID); sendErr != nil {
+		return nil, statusError(sendErr)
+	}
+	return empty, nil
+}
+
+// ForgotPassword sends code of password reset to email, result doesn't tell whether there is such user
+func (s *KeeperServer) ForgotPassword(_ context.Context, req *keeperpb.PasswordForgot) (*emptypb.Empty, error) {
+	if forgotErr := s.keeper.ForgotPassword(&models.PasswordForgot{Email: req.GetEmail()}); forgotErr != nil {
+		return nil, statusError(forgotErr)
+	}
+	return empty, nil
+}
+
+// ResetPassword sets new password by code of password reset, all sessions of user are revoked
+func (s *KeeperServer) ResetPassword(_ context.Context, req *keeperpb.PasswordReset) (*emptypb.Empty, error) {
+	reset := &models.PasswordReset{Token: req.GetToken(), NewPassword: req.GetNewPassword()}
+	if resetErr := s.keeper.ResetPassword(reset); resetErr != nil {
+		return nil, statusError(resetErr)
+	}
+	return empty, nil
+}
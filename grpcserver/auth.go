@@ -0,0 +1,139 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Register signs up new user, returns user with tokens of new session
+func (s *KeeperServer) Register(ctx context.Context, req *keeperpb.RegisterRequest) (*keeperpb.User, error) {
+	newUser, newUserErr := s.keeper.Register(models.User{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}, sessionClient(ctx))
+	if newUserErr != nil {
+		return nil, statusError(newUserErr)
+	}
+	return keeperpb.UserToPB(newUser), nil
+}
+
+// Login authenticates user by email and password, returns user with tokens of new session
+// or challenge of second step if user enabled two-factor authentication
+func (s *KeeperServer) Login(ctx context.Context, req *keeperpb.LoginRequest) (*keeperpb.User, error) {
+	userInfo, challenge, userInfoErr := s.keeper.Login(&models.UserLogin{Email: req.GetEmail(), Password: req.GetPassword()}, sessionClient(ctx))
+	if userInfoErr != nil {
+		return nil, statusError(userInfoErr)
+	}
+	if challenge != nil {
+		return keeperpb.ChallengeToPB(challenge), nil
+	}
+	return keeperpb.UserToPB(userInfo), nil
+}
+
+// RefreshToken exchanges single-use refresh token for new pair of tokens
+func (s *KeeperServer) RefreshToken(ctx context.Context, req *keeperpb.RefreshRequest) (*keeperpb.Tokens, error) {
+	tokens, tokensErr := s.keeper.Refresh(&models.TokenRefresh{RefreshToken: req.GetRefreshToken()}, sessionClient(ctx))
+	if tokensErr != nil {
+		return nil, statusError(tokensErr)
+	}
+	return keeperpb.TokensToPB(tokens), nil
+}
+
+// Logout revokes access token of request and refresh tokens of its login
+func (s *KeeperServer) Logout(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	token, tokenErr := bearerToken(ctx)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+	if logoutErr := s.keeper.Logout(token); logoutErr != nil {
+		return nil, statusError(logoutErr)
+	}
+	return empty, nil
+}
+
+// LogoutEverywhere revokes all access and refresh tokens of user
+func (s *KeeperServer) LogoutEverywhere(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	if logoutErr := s.keeper.LogoutEverywhere(userID); logoutErr != nil {
+		return nil, statusError(logoutErr)
+	}
+	return empty, nil
+}
+
+// GetMe returns information about user with fingerprint of registered key
+func (s *KeeperServer) GetMe(ctx context.Context, _ *emptypb.Empty) (*keeperpb.User, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	userInfo, userInfoErr := s.keeper.UserInfo(userID)
+	if userInfoErr != nil {
+		return nil, statusError(userInfoErr)
+	}
+	return keeperpb.UserToPB(userInfo), nil
+}
+
+// SetKey registers fingerprint of client public key on account
+func (s *KeeperServer) SetKey(ctx context.Context, req *keeperpb.SetKeyRequest) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	setErr := s.keeper.SetUserKey(userID, &models.UserKey{Fingerprint: req.GetFingerprint(), Previous: req.GetPrevious()})
+	if setErr != nil {
+		return nil, statusError(setErr)
+	}
+	return empty, nil
+}
+
+// ListSessions returns active sessions of user, session of call is marked as current
+func (s *KeeperServer) ListSessions(ctx context.Context, _ *emptypb.Empty) (*keeperpb.SessionList, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	sessions, sessionsErr := s.keeper.Sessions(userID, getSessionID(ctx))
+	if sessionsErr != nil {
+		return nil, statusError(sessionsErr)
+	}
+	return keeperpb.SessionsToPB(sessions), nil
+}
+
+// RevokeSession revokes session of user with all its tokens
+func (s *KeeperServer) RevokeSession(ctx context.Context, req *keeperpb.ElementID) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	sessionID, sessionIDErr := elementID(req.GetId())
+	if sessionIDErr != nil {
+		return nil, sessionIDErr
+	}
+	if revokeErr := s.keeper.RevokeSession(userID, sessionID); revokeErr != nil {
+		return nil, statusError(revokeErr)
+	}
+	return empty, nil
+}
+
+// ReplaceVault replaces all elements of user in one transaction
+func (s *KeeperServer) ReplaceVault(ctx context.Context, req *keeperpb.Vault) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	vault, vaultErr := keeperpb.VaultFromPB(req)
+	if vaultErr != nil {
+		return nil, requestError(vaultErr)
+	}
+	if replaceErr := s.keeper.ReplaceVault(userID, vault); replaceErr != nil {
+		return nil, statusError(replaceErr)
+	}
+	return empty, nil
+}
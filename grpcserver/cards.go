@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ListCards returns all cards of user
+func (s *KeeperServer) ListCards(ctx context.Context, _ *emptypb.Empty) (*keeperpb.CardList, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	cards, cardsErr := s.keeper.Cards(userID)
+	if cardsErr != nil {
+		return nil, statusError(cardsErr)
+	}
+	result := &keeperpb.CardList{}
+	for _, card := range cards {
+		result.Cards = append(result.Cards, keeperpb.CardToPB(card))
+	}
+	return result, nil
+}
+
+// GetCard returns card of user by id
+func (s *KeeperServer) GetCard(ctx context.Context, req *keeperpb.ElementID) (*keeperpb.Card, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	cardID, cardIDErr := elementID(req.GetId())
+	if cardIDErr != nil {
+		return nil, cardIDErr
+	}
+	card, cardErr := s.keeper.Card(userID, cardID)
+	if cardErr != nil {
+		return nil, statusError(cardErr)
+	}
+	return keeperpb.CardToPB(card), nil
+}
+
+// CreateCard adds card of user, id of card could be set by client
+func (s *KeeperServer) CreateCard(ctx context.Context, req *keeperpb.Card) (*keeperpb.Card, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	card, cardErr := keeperpb.NewCardFromPB(req)
+	if cardErr != nil {
+		return nil, requestError(cardErr)
+	}
+	newCard, newCardErr := s.keeper.NewCard(userID, &card)
+	if newCardErr != nil {
+		return nil, statusError(newCardErr)
+	}
+	return keeperpb.CardToPB(newCard), nil
+}
+
+// EditCard changes card of user, empty fields keep current values
+func (s *KeeperServer) EditCard(ctx context.Context, req *keeperpb.Card) (*keeperpb.Card, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	cardID, cardIDErr := elementID(req.GetId())
+	if cardIDErr != nil {
+		return nil, cardIDErr
+	}
+	editCard, editCardErr := keeperpb.NewCardFromPB(req)
+	if editCardErr != nil {
+		return nil, requestError(editCardErr)
+	}
+	newCard, newCardErr := s.keeper.EditCard(userID, cardID, editCard)
+	if newCardErr != nil {
+		return nil, statusError(newCardErr)
+	}
+	return keeperpb.CardToPB(newCard), nil
+}
+
+// DeleteCard removes card of user
+func (s *KeeperServer) DeleteCard(ctx context.Context, req *keeperpb.ElementID) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	cardID, cardIDErr := elementID(req.GetId())
+	if cardIDErr != nil {
+		return nil, cardIDErr
+	}
+	if delErr := s.keeper.DeleteCard(userID, cardID); delErr != nil {
+		return nil, statusError(delErr)
+	}
+	return empty, nil
+}
@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ListCreds returns all creds of user
+func (s *KeeperServer) ListCreds(ctx context.Context, _ *emptypb.Empty) (*keeperpb.CredList, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	creds, credsErr := s.keeper.Creds(userID)
+	if credsErr != nil {
+		return nil, statusError(credsErr)
+	}
+	result := &keeperpb.CredList{}
+	for _, cred := range creds {
+		result.Creds = append(result.Creds, keeperpb.CredToPB(cred))
+	}
+	return result, nil
+}
+
+// GetCred returns cred of user by id
+func (s *KeeperServer) GetCred(ctx context.Context, req *keeperpb.ElementID) (*keeperpb.Cred, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	credID, credIDErr := elementID(req.GetId())
+	if credIDErr != nil {
+		return nil, credIDErr
+	}
+	cred, credErr := s.keeper.Cred(userID, credID)
+	if credErr != nil {
+		return nil, statusError(credErr)
+	}
+	return keeperpb.CredToPB(cred), nil
+}
+
+// CreateCred adds cred of user, id of cred could be set by client
+func (s *KeeperServer) CreateCred(ctx context.Context, req *keeperpb.Cred) (*keeperpb.Cred, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	cred, credErr := keeperpb.NewCredFromPB(req)
+	if credErr != nil {
+		return nil, requestError(credErr)
+	}
+	newCred, newCredErr := s.keeper.NewCred(userID, &cred)
+	if newCredErr != nil {
+		return nil, statusError(newCredErr)
+	}
+	return keeperpb.CredToPB(newCred), nil
+}
+
+// EditCred changes cred of user, empty fields keep current values
+func (s *KeeperServer) EditCred(ctx context.Context, req *keeperpb.Cred) (*keeperpb.Cred, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	credID, credIDErr := elementID(req.GetId())
+	if credIDErr != nil {
+		return nil, credIDErr
+	}
+	editCred, editCredErr := keeperpb.NewCredFromPB(req)
+	if editCredErr != nil {
+		return nil, requestError(editCredErr)
+	}
+	newCred, newCredErr := s.keeper.EditCred(userID, credID, editCred)
+	if newCredErr != nil {
+		return nil, statusError(newCredErr)
+	}
+	return keeperpb.CredToPB(newCred), nil
+}
+
+// DeleteCred removes cred of user
+func (s *KeeperServer) DeleteCred(ctx context.Context, req *keeperpb.ElementID) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	credID, credIDErr := elementID(req.GetId())
+	if credIDErr != nil {
+		return nil, credIDErr
+	}
+	if delErr := s.keeper.DeleteCred(userID, credID); delErr != nil {
+		return nil, statusError(delErr)
+	}
+	return empty, nil
+}
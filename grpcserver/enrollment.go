@@ -0,0 +1,61 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// StartEnrollment saves ephemeral public key of new device, returned enrollment has code
+// that user enters on approving device
+func (s *KeeperServer) StartEnrollment(ctx context.Context, req *keeperpb.NewEnrollment) (*keeperpb.Enrollment, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	enrollment, enrollmentErr := s.keeper.StartEnrollment(userID, &models.NewEnrollment{DevicePublic: req.GetDevicePublic()})
+	if enrollmentErr != nil {
+		return nil, statusError(enrollmentErr)
+	}
+	return keeperpb.EnrollmentToPB(enrollment), nil
+}
+
+// GetEnrollment returns enrollment by code, new device polls it until sealed bundle appears
+func (s *KeeperServer) GetEnrollment(ctx context.Context, req *keeperpb.EnrollmentCode) (*keeperpb.Enrollment, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	enrollment, enrollmentErr := s.keeper.Enrollment(userID, req.GetCode())
+	if enrollmentErr != nil {
+		return nil, statusError(enrollmentErr)
+	}
+	return keeperpb.EnrollmentToPB(enrollment), nil
+}
+
+// ApproveEnrollment saves key bundle sealed for public key of new device
+func (s *KeeperServer) ApproveEnrollment(ctx context.Context, req *keeperpb.EnrollmentApprove) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	approve := &models.EnrollmentApprove{Bundle: req.GetBundle()}
+	if approveErr := s.keeper.ApproveEnrollment(userID, req.GetCode(), approve); approveErr != nil {
+		return nil, statusError(approveErr)
+	}
+	return empty, nil
+}
+
+// DeleteEnrollment removes enrollment of user
+func (s *KeeperServer) DeleteEnrollment(ctx context.Context, req *keeperpb.EnrollmentCode) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	if deleteErr := s.keeper.DeleteEnrollment(userID, req.GetCode()); deleteErr != nil {
+		return nil, statusError(deleteErr)
+	}
+	return empty, nil
+}
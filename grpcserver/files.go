@@ -0,0 +1,158 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var ErrNoFileInfo = errors.New("first chunk of file should contain file info")
+var ErrFileTooLarge = fmt.Errorf("file is larger than %d bytes", models.MaxFileSize)
+
+// chunkReceiver stream of file chunks from client
+type chunkReceiver interface {
+	Recv() (*keeperpb.FileChunk, error)
+}
+
+// receiveFile reads file info from the first chunk and content from the next ones,
+// content is nil if client sent only file info. Stream is aborted when content exceeds models.MaxFileSize
+func receiveFile(stream chunkReceiver) (*keeperpb.FileInfo, []byte, error) {
+	first, recvErr := stream.Recv()
+	if recvErr != nil {
+		return nil, nil, recvErr
+	}
+	info := first.GetInfo()
+	if info == nil {
+		return nil, nil, requestError(ErrNoFileInfo)
+	}
+	var data []byte
+	for {
+		chunk, chunkErr := stream.Recv()
+		if errors.Is(chunkErr, io.EOF) {
+			return info, data, nil
+		}
+		if chunkErr != nil {
+			return nil, nil, chunkErr
+		}
+		if len(data)+len(chunk.GetData()) > models.MaxFileSize {
+			return nil, nil, status.Error(codes.ResourceExhausted, ErrFileTooLarge.Error())
+		}
+		data = append(data, chunk.GetData()...)
+	}
+}
+
+// ListFiles returns all files of user without content
+func (s *KeeperServer) ListFiles(ctx context.Context, _ *emptypb.Empty) (*keeperpb.FileList, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	files, filesErr := s.keeper.Files(userID)
+	if filesErr != nil {
+		return nil, statusError(filesErr)
+	}
+	result := &keeperpb.FileList{}
+	for _, file := range files {
+		result.Files = append(result.Files, keeperpb.FileInfoToPB(file))
+	}
+	return result, nil
+}
+
+// DownloadFile sends file info and then file content by chunks
+func (s *KeeperServer) DownloadFile(req *keeperpb.ElementID, stream keeperpb.Keeper_DownloadFileServer) error {
+	userID, userIDErr := getUserID(stream.Context())
+	if userIDErr != nil {
+		return userIDErr
+	}
+	fileID, fileIDErr := elementID(req.GetId())
+	if fileIDErr != nil {
+		return fileIDErr
+	}
+	file, fileErr := s.keeper.File(userID, fileID)
+	if fileErr != nil {
+		return statusError(fileErr)
+	}
+
+	if sendErr := stream.Send(&keeperpb.FileChunk{Chunk: &keeperpb.FileChunk_Info{Info: keeperpb.FileInfoToPB(file)}}); sendErr != nil {
+		return sendErr
+	}
+	for start := 0; start < len(file.File); start += keeperpb.ChunkSize {
+		end := start + keeperpb.ChunkSize
+		if end > len(file.File) {
+			end = len(file.File)
+		}
+		if sendErr := stream.Send(&keeperpb.FileChunk{Chunk: &keeperpb.FileChunk_Data{Data: file.File[start:end]}}); sendErr != nil {
+			return sendErr
+		}
+	}
+	return nil
+}
+
+// CreateFile adds file of user from stream of chunks, id of file could be set by client
+func (s *KeeperServer) CreateFile(stream keeperpb.Keeper_CreateFileServer) error {
+	userID, userIDErr := getUserID(stream.Context())
+	if userIDErr != nil {
+		return userIDErr
+	}
+	info, data, recvErr := receiveFile(stream)
+	if recvErr != nil {
+		return recvErr
+	}
+	file, fileErr := keeperpb.NewFileFromPB(info, data)
+	if fileErr != nil {
+		return requestError(fileErr)
+	}
+	newFile, newFileErr := s.keeper.NewFile(userID, &file)
+	if newFileErr != nil {
+		return statusError(newFileErr)
+	}
+	return stream.SendAndClose(keeperpb.FileInfoToPB(newFile))
+}
+
+// EditFile changes file of user from stream of chunks, file without content chunks keeps current content
+func (s *KeeperServer) EditFile(stream keeperpb.Keeper_EditFileServer) error {
+	userID, userIDErr := getUserID(stream.Context())
+	if userIDErr != nil {
+		return userIDErr
+	}
+	info, data, recvErr := receiveFile(stream)
+	if recvErr != nil {
+		return recvErr
+	}
+	fileID, fileIDErr := elementID(info.GetId())
+	if fileIDErr != nil {
+		return fileIDErr
+	}
+	editFile, editFileErr := keeperpb.NewFileFromPB(info, data)
+	if editFileErr != nil {
+		return requestError(editFileErr)
+	}
+	newFile, newFileErr := s.keeper.EditFile(userID, fileID, &editFile)
+	if newFileErr != nil {
+		return statusError(newFileErr)
+	}
+	return stream.SendAndClose(keeperpb.FileInfoToPB(newFile))
+}
+
+// DeleteFile removes file of user
+func (s *KeeperServer) DeleteFile(ctx context.Context, req *keeperpb.ElementID) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	fileID, fileIDErr := elementID(req.GetId())
+	if fileIDErr != nil {
+		return nil, fileIDErr
+	}
+	if delErr := s.keeper.DeleteFile(userID, fileID); delErr != nil {
+		return nil, statusError(delErr)
+	}
+	return empty, nil
+}
@@ -0,0 +1,52 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chunkStream sends chunks of file from memory
+type chunkStream struct {
+	chunks []*keeperpb.FileChunk
+}
+
+func (s *chunkStream) Recv() (*keeperpb.FileChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func TestReceiveFile(t *testing.T) {
+	info := &keeperpb.FileChunk{Chunk: &keeperpb.FileChunk_Info{Info: &keeperpb.FileInfo{Title: "file"}}}
+	chunk := func(size int) *keeperpb.FileChunk {
+		return &keeperpb.FileChunk{Chunk: &keeperpb.FileChunk_Data{Data: make([]byte, size)}}
+	}
+
+	tests := []struct {
+		name   string
+		chunks []*keeperpb.FileChunk
+		size   int
+		code   codes.Code
+	}{
+		{name: "file", chunks: []*keeperpb.FileChunk{info, chunk(10), chunk(20)}, size: 30, code: codes.OK},
+		{name: "max size", chunks: []*keeperpb.FileChunk{info, chunk(models.MaxFileSize - 1), chunk(1)}, size: models.MaxFileSize, code: codes.OK},
+		{name: "too large", chunks: []*keeperpb.FileChunk{info, chunk(models.MaxFileSize), chunk(1)}, code: codes.ResourceExhausted},
+		{name: "without info", chunks: []*keeperpb.FileChunk{chunk(10)}, code: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, data, err := receiveFile(&chunkStream{chunks: tt.chunks})
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.size, len(data))
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package grpcserver implements gRPC API of GophKeeper over service layer
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/service"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var (
+	ErrNoAuth    = errors.New("no Bearer token")
+	ErrGetUserID = errors.New("cant get userID from ctx")
+	ErrCheckID   = errors.New("check ID please")
+)
+
+// userIDKey type uses to pass user ID throw context
+type userIDKey struct{}
+
+// sessionIDKey type uses to pass session ID throw context
+type sessionIDKey struct{}
+
+// publicMethods methods that don't require authorization
+var publicMethods = map[string]bool{
+	"/keeper.Keeper/Register":       true,
+	"/keeper.Keeper/Login":          true,
+	"/keeper.Keeper/LoginTwoFactor": true,
+	"/keeper.Keeper/RefreshToken":   true,
+	"/keeper.Keeper/VerifyEmail":    true,
+	"/keeper.Keeper/ForgotPassword": true,
+	"/keeper.Keeper/ResetPassword":  true,
+}
+
+// KeeperServer implementation of keeperpb.KeeperServer
+type KeeperServer struct {
+	keeperpb.UnimplementedKeeperServer
+	keeper *service.Service
+}
+
+// NewServer returns gRPC server with registered KeeperServer and authorization interceptors
+func NewServer(keeper *service.Service, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.MaxRecvMsgSize(keeperpb.MaxMessageSize),
+		grpc.ChainUnaryInterceptor(unaryAuth(keeper)),
+		grpc.ChainStreamInterceptor(streamAuth(keeper)),
+	)
+	server := grpc.NewServer(opts...)
+	keeperpb.RegisterKeeperServer(server, &KeeperServer{keeper: keeper})
+	return server
+}
+
+// statusError maps kind of service error to gRPC status code. Internal errors are logged
+// with correlation id and client gets only the id, so details of storage don't leak
+func statusError(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, service.ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, service.ErrRejected):
+		code = codes.FailedPrecondition
+	case errors.Is(err, service.ErrConflict):
+		code = codes.Aborted
+	case errors.Is(err, service.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, service.ErrUnauthenticated):
+		code = codes.Unauthenticated
+	case errors.Is(err, service.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, service.ErrThrottled):
+		code = codes.ResourceExhausted
+	default:
+		errorID := uuid.New()
+		log.Printf("grpc error %s: %v", errorID, err)
+		return status.Errorf(codes.Internal, "an internal server error, error id %s", errorID)
+	}
+	return status.Error(code, err.Error())
+}
+
+// bearerToken returns token from metadata "authorization: Bearer T"
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return "", status.Error(codes.Unauthenticated, service.ErrUnauthorized.Error()+": "+ErrNoAuth.Error())
+	}
+	return strings.TrimPrefix(values[0], "Bearer "), nil
+}
+
+// authenticate puts id of user from metadata "authorization: Bearer T" in context
+func authenticate(ctx context.Context, keeper *service.Service) (context.Context, error) {
+	token, tokenErr := bearerToken(ctx)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+	userID, sessionID, userIDErr := keeper.Authenticate(token)
+	if userIDErr != nil {
+		return nil, statusError(userIDErr)
+	}
+	return context.WithValue(context.WithValue(ctx, userIDKey{}, userID), sessionIDKey{}, sessionID), nil
+}
+
+func unaryAuth(keeper *service.Service) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		userCtx, authErr := authenticate(ctx, keeper)
+		if authErr != nil {
+			return nil, authErr
+		}
+		return handler(userCtx, req)
+	}
+}
+
+// authStream server stream with context of authenticated user
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns context of authenticated user
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamAuth(keeper *service.Service) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		userCtx, authErr := authenticate(stream.Context(), keeper)
+		if authErr != nil {
+			return authErr
+		}
+		return handler(srv, &authStream{ServerStream: stream, ctx: userCtx})
+	}
+}
+
+// getUserID returns user ID from context
+func getUserID(ctx context.Context) (uuid.UUID, error) {
+	userID, ok := ctx.Value(userIDKey{}).(uuid.UUID)
+	if !ok {
+		return uuid.UUID{}, status.Error(codes.Unauthenticated, ErrGetUserID.Error())
+	}
+	return userID, nil
+}
+
+// getSessionID returns session ID from context, token without session gives nil id
+func getSessionID(ctx context.Context) uuid.UUID {
+	sessionID, _ := ctx.Value(sessionIDKey{}).(uuid.UUID)
+	return sessionID
+}
+
+// sessionClient returns client of call from metadata and address of peer
+func sessionClient(ctx context.Context) *models.SessionClient {
+	md, _ := metadata.FromIncomingContext(ctx)
+	client := &models.SessionClient{
+		DeviceName:    firstValue(md, "x-device-name"),
+		ClientVersion: firstValue(md, "x-client-version"),
+		UserAgent:     firstValue(md, "user-agent"),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, splitErr := net.SplitHostPort(client.IP); splitErr == nil {
+			client.IP = host
+		}
+	}
+	return client
+}
+
+// firstValue returns first value of metadata key
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// elementID parses id of element in request, id is required
+func elementID(id string) (uuid.UUID, error) {
+	elemID, parseErr := keeperpb.ParseID(id)
+	if parseErr != nil || elemID == uuid.Nil {
+		return uuid.UUID{}, status.Error(codes.InvalidArgument, ErrCheckID.Error())
+	}
+	return elemID, nil
+}
+
+// requestError error of wrong message fields
+func requestError(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// empty is result of methods without response
+var empty = &emptypb.Empty{}
@@ -0,0 +1,37 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/service"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "invalid", err: service.ErrEmptyFields, code: codes.InvalidArgument},
+		{name: "conflict", err: service.ErrVersion, code: codes.Aborted},
+		{name: "not found", err: service.ErrNoNote, code: codes.NotFound},
+		{name: "unauthenticated", err: service.ErrUnauthorized, code: codes.Unauthenticated},
+		{name: "internal", err: errors.New("db is down"), code: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(statusError(tt.err))
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, st.Code())
+			if tt.code == codes.Internal {
+				assert.NotContains(t, st.Message(), tt.err.Error(), "details of internal errors are not sent")
+				return
+			}
+			assert.Equal(t, tt.err.Error(), st.Message())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ListNotes returns all notes of user
+func (s *KeeperServer) ListNotes(ctx context.Context, _ *emptypb.Empty) (*keeperpb.NoteList, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	notes, notesErr := s.keeper.Notes(userID)
+	if notesErr != nil {
+		return nil, statusError(notesErr)
+	}
+	result := &keeperpb.NoteList{}
+	for _, note := range notes {
+		result.Notes = append(result.Notes, keeperpb.NoteToPB(note))
+	}
+	return result, nil
+}
+
+// GetNote returns note of user by id
+func (s *KeeperServer) GetNote(ctx context.Context, req *keeperpb.ElementID) (*keeperpb.Note, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	noteID, noteIDErr := elementID(req.GetId())
+	if noteIDErr != nil {
+		return nil, noteIDErr
+	}
+	note, noteErr := s.keeper.Note(userID, noteID)
+	if noteErr != nil {
+		return nil, statusError(noteErr)
+	}
+	return keeperpb.NoteToPB(note), nil
+}
+
+// CreateNote adds note of user, id of note could be set by client
+func (s *KeeperServer) CreateNote(ctx context.Context, req *keeperpb.Note) (*keeperpb.Note, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	note, noteErr := keeperpb.NewNoteFromPB(req)
+	if noteErr != nil {
+		return nil, requestError(noteErr)
+	}
+	newNote, newNoteErr := s.keeper.NewNote(userID, &note)
+	if newNoteErr != nil {
+		return nil, statusError(newNoteErr)
+	}
+	return keeperpb.NoteToPB(newNote), nil
+}
+
+// EditNote changes note of user, empty fields keep current values
+func (s *KeeperServer) EditNote(ctx context.Context, req *keeperpb.Note) (*keeperpb.Note, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	noteID, noteIDErr := elementID(req.GetId())
+	if noteIDErr != nil {
+		return nil, noteIDErr
+	}
+	editNote, editNoteErr := keeperpb.NewNoteFromPB(req)
+	if editNoteErr != nil {
+		return nil, requestError(editNoteErr)
+	}
+	newNote, newNoteErr := s.keeper.EditNote(userID, noteID, editNote)
+	if newNoteErr != nil {
+		return nil, statusError(newNoteErr)
+	}
+	return keeperpb.NoteToPB(newNote), nil
+}
+
+// DeleteNote removes note of user
+func (s *KeeperServer) DeleteNote(ctx context.Context, req *keeperpb.ElementID) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	noteID, noteIDErr := elementID(req.GetId())
+	if noteIDErr != nil {
+		return nil, noteIDErr
+	}
+	if delErr := s.keeper.DeleteNote(userID, noteID); delErr != nil {
+		return nil, statusError(delErr)
+	}
+	return empty, nil
+}
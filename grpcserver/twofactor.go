@@ -0,0 +1,56 @@
+package grpcserver
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// LoginTwoFactor completes login by challenge with code of second factor, returns user with tokens of new session
+func (s *KeeperServer) LoginTwoFactor(ctx context.Context, req *keeperpb.TwoFactorLoginRequest) (*keeperpb.User, error) {
+	userInfo, userInfoErr := s.keeper.LoginTwoFactor(&models.TwoFactorLogin{Challenge: req.GetChallenge(), Code: req.GetCode()}, sessionClient(ctx))
+	if userInfoErr != nil {
+		return nil, statusError(userInfoErr)
+	}
+	return keeperpb.UserToPB(userInfo), nil
+}
+
+// SetupTwoFactor generates TOTP secret of user, it is enabled by ConfirmTwoFactor
+func (s *KeeperServer) SetupTwoFactor(ctx context.Context, _ *emptypb.Empty) (*keeperpb.TwoFactorSetup, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	setup, setupErr := s.keeper.SetupTwoFactor(userID)
+	if setupErr != nil {
+		return nil, statusError(setupErr)
+	}
+	return &keeperpb.TwoFactorSetup{Secret: setup.Secret, Uri: setup.URI}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication by code of authenticator app, returns recovery codes
+func (s *KeeperServer) ConfirmTwoFactor(ctx context.Context, req *keeperpb.TwoFactorCode) (*keeperpb.RecoveryCodes, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	codes, confirmErr := s.keeper.ConfirmTwoFactor(userID, &models.TwoFactorCode{Code: req.GetCode()}, sessionClient(ctx))
+	if confirmErr != nil {
+		return nil, statusError(confirmErr)
+	}
+	return &keeperpb.RecoveryCodes{Codes: codes.Codes}, nil
+}
+
+// DisableTwoFactor disables two-factor authentication by code of authenticator app or recovery code
+func (s *KeeperServer) DisableTwoFactor(ctx context.Context, req *keeperpb.TwoFactorCode) (*emptypb.Empty, error) {
+	userID, userIDErr := getUserID(ctx)
+	if userIDErr != nil {
+		return nil, userIDErr
+	}
+	if disableErr := s.keeper.DisableTwoFactor(userID, &models.TwoFactorCode{Code: req.GetCode()}, sessionClient(ctx)); disableErr != nil {
+		return nil, statusError(disableErr)
+	}
+	return empty, nil
+}
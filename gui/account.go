@@ -0,0 +1,71 @@
+package gui
+
+// changePasswordForm asks current password and new one, new password is typed twice
+func (gu *GUI) changePasswordForm() {
+	var password, newPassword, repeated string
+	gu.forms.accountForm.Clear(true)
+	gu.forms.accountForm.AddPasswordField("Current password", "", 20, rune(42), func(value string) {
+		password = value
+	})
+	gu.forms.accountForm.AddPasswordField("New password", "", 20, rune(42), func(value string) {
+		newPassword = value
+	})
+	gu.forms.accountForm.AddPasswordField("Repeat new password", "", 20, rune(42), func(value string) {
+		repeated = value
+	})
+	gu.forms.accountForm.AddButton("Change", func() {
+		if newPassword != repeated {
+			gu.errorModalRender("new passwords don't match", "Account")
+			return
+		}
+		if changeErr := gu.client.ChangePassword(password, newPassword); changeErr != nil {
+			gu.errorModalRender(changeErr.Error(), "Account")
+			return
+		}
+		if settingsErr := gu.settingsContent(); settingsErr != nil {
+			gu.errorModalRender(settingsErr.Error(), "Main")
+			return
+		}
+		gu.panels.SetCurrentPanel("Settings")
+	})
+	gu.forms.accountForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel("Settings")
+	})
+}
+
+// deleteAccountForm asks password and code of second factor, account is deleted after confirmation
+func (gu *GUI) deleteAccountForm() {
+	var password, code string
+	gu.forms.accountForm.Clear(true)
+	gu.forms.accountForm.AddPasswordField("Password", "", 20, rune(42), func(value string) {
+		password = value
+	})
+	gu.forms.accountForm.AddInputField("Two-factor code (if enabled)", "", 20, nil, func(value string) {
+		code = value
+	})
+	gu.forms.accountForm.AddButton("Delete", func() {
+		gu.deleteAccountModal(password, code)
+	})
+	gu.forms.accountForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel("Settings")
+	})
+}
+
+// deleteAccountModal asks to delete account, user is logged out after deletion
+func (gu *GUI) deleteAccountModal(password, code string) {
+	gu.constrains.confirm.ClearButtons()
+	gu.constrains.confirm.SetText("Delete account with all notes, cards, credentials and files?\nIt can't be undone")
+	gu.constrains.confirm.AddButtons([]string{"Delete", "Cancel"})
+	gu.constrains.confirm.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		if buttonLabel != "Delete" {
+			gu.panels.SetCurrentPanel("Account")
+			return
+		}
+		if deleteErr := gu.client.DeleteAccount(password, code); deleteErr != nil {
+			gu.errorModalRender(deleteErr.Error(), "Account")
+			return
+		}
+		gu.logout(func() error { return nil })
+	})
+	gu.panels.SetCurrentPanel("Confirm")
+}
@@ -6,6 +6,7 @@ import (
 
 	"code.rocketnine.space/tslocum/cview"
 	"github.com/gdamore/tcell/v2"
+	"github.com/google/uuid"
 )
 
 func (gu *GUI) welcomeContent() {
@@ -26,6 +27,14 @@ func (gu *GUI) welcomeContent() {
 		gu.panels.SetCurrentPanel("Login")
 	})
 
+	forgotItem := cview.NewListItem("Forgot Password")
+	forgotItem.SetSecondaryText("Reset password by code sent to email")
+	forgotItem.SetShortcut('3')
+	forgotItem.SetSelectedFunc(func() {
+		gu.forgotPasswordForm()
+		gu.panels.SetCurrentPanel("Account")
+	})
+
 	quitItem := cview.NewListItem("Quit")
 	quitItem.SetSecondaryText("Press to exit")
 	quitItem.SetShortcut('q')
@@ -35,6 +44,7 @@ func (gu *GUI) welcomeContent() {
 
 	gu.content.welcomeContent.AddItem(registerItem)
 	gu.content.welcomeContent.AddItem(loginItem)
+	gu.content.welcomeContent.AddItem(forgotItem)
 	gu.content.welcomeContent.AddItem(quitItem)
 
 	gu.content.welcomeContent.SetPadding(0, 0, 2, 0)
@@ -50,14 +60,29 @@ func (gu *GUI) loggedContent() {
 		gu.panels.SetCurrentPanel("Collection")
 	})
 
+	settingsItem := cview.NewListItem("Settings")
+	settingsItem.SetSecondaryText("Devices where you are logged in")
+	settingsItem.SetShortcut('s')
+	settingsItem.SetSelectedFunc(func() {
+		if settingsErr := gu.settingsContent(); settingsErr != nil {
+			gu.errorModalRender(settingsErr.Error(), "Main")
+			return
+		}
+		gu.panels.SetCurrentPanel("Settings")
+	})
+
 	logoutItem := cview.NewListItem("Log Out")
 	logoutItem.SetSecondaryText("Press to log out")
 	logoutItem.SetShortcut('2')
 	logoutItem.SetSelectedFunc(func() {
-		gu.welcomeContent()
-		gu.texts.changeAuthText("You are not logged in!", false)
-		//gu.client.UseToken("")
-		gu.panels.SetCurrentPanel("Main")
+		gu.logout(gu.client.Logout)
+	})
+
+	logoutAllItem := cview.NewListItem("Log Out Everywhere")
+	logoutAllItem.SetSecondaryText("Press to log out on all devices")
+	logoutAllItem.SetShortcut('3')
+	logoutAllItem.SetSelectedFunc(func() {
+		gu.logout(gu.client.LogoutEverywhere)
 	})
 
 	quitItem := cview.NewListItem("Quit")
@@ -68,7 +93,9 @@ func (gu *GUI) loggedContent() {
 	})
 
 	gu.content.welcomeContent.AddItem(collectItem)
+	gu.content.welcomeContent.AddItem(settingsItem)
 	gu.content.welcomeContent.AddItem(logoutItem)
+	gu.content.welcomeContent.AddItem(logoutAllItem)
 	gu.content.welcomeContent.AddItem(quitItem)
 
 	//welcome.ContextMenuList().SetItemEnabled(2, false)
@@ -77,6 +104,19 @@ func (gu *GUI) loggedContent() {
 
 }
 
+// logout revokes tokens in service and returns to main page,
+// tokens are forgotten by client even if service is unavailable
+func (gu *GUI) logout(revoke func() error) {
+	gu.stopEvents()
+	revokeErr := revoke()
+	gu.welcomeContent()
+	gu.texts.changeAuthText("You are not logged in!", false)
+	gu.panels.SetCurrentPanel("Main")
+	if revokeErr != nil {
+		gu.errorModalRender(revokeErr.Error(), "Main")
+	}
+}
+
 func (gu *GUI) collectionContent() {
 	gu.content.collectionContent.Clear()
 
@@ -150,7 +190,9 @@ func (gu *GUI) elementsContent(infoType string) error {
 
 	switch infoType {
 	case "notes":
-		el := elems.([]models.Note)
+		el := orderElements(gu.listOrder(infoType), elems.([]models.Note), func(elem models.Note) uuid.UUID {
+			return elem.ID
+		})
 		gu.content.notesContent.Clear()
 
 		if len(el) != 0 {
@@ -161,12 +203,13 @@ func (gu *GUI) elementsContent(infoType string) error {
 					date = fmt.Sprintf("%s (created: %s)", value.Changed.Time.Format("02 Jan 2006 15:04:05"), date)
 				}
 				item.SetSecondaryText(date)
+				markIntegrity(item, value.Integrity)
 				item.SetShortcut(rune(49 + index))
 				gu.content.notesContent.AddItem(item)
 			}
 		} else {
 			noContentItem := cview.NewListItem("No content")
-			noContentItem.SetSecondaryText("no content in database")
+			noContentItem.SetSecondaryText(gu.noContentText(infoType))
 			noContentItem.SetShortcut('x')
 			gu.content.notesContent.AddItem(noContentItem)
 		}
@@ -195,8 +238,11 @@ func (gu *GUI) elementsContent(infoType string) error {
 			gu.panels.SetCurrentPanel("Main")
 		})
 
+		searchItem, sortItem := gu.listControlItems(infoType)
+
 		gu.content.notesContent.AddItem(emptyItem)
-		gu.content.notesContent.AddItem(emptyItem)
+		gu.content.notesContent.AddItem(searchItem)
+		gu.content.notesContent.AddItem(sortItem)
 		gu.content.notesContent.AddItem(newItem)
 		gu.content.notesContent.AddItem(colItem)
 		gu.content.notesContent.AddItem(quitItem)
@@ -210,7 +256,9 @@ func (gu *GUI) elementsContent(infoType string) error {
 
 		return nil
 	case "cards":
-		el := elems.([]models.Card)
+		el := orderElements(gu.listOrder(infoType), elems.([]models.Card), func(elem models.Card) uuid.UUID {
+			return elem.ID
+		})
 		gu.content.cardsContent.Clear()
 
 		if len(el) != 0 {
@@ -221,12 +269,13 @@ func (gu *GUI) elementsContent(infoType string) error {
 					date = fmt.Sprintf("%s (created: %s)", value.Changed.Time.Format("02 Jan 2006 15:04:05"), date)
 				}
 				item.SetSecondaryText(date)
+				markIntegrity(item, value.Integrity)
 				item.SetShortcut(rune(49 + index))
 				gu.content.cardsContent.AddItem(item)
 			}
 		} else {
 			noContentItem := cview.NewListItem("No content")
-			noContentItem.SetSecondaryText("no content in database")
+			noContentItem.SetSecondaryText(gu.noContentText(infoType))
 			noContentItem.SetShortcut('x')
 			gu.content.cardsContent.AddItem(noContentItem)
 		}
@@ -255,8 +304,11 @@ func (gu *GUI) elementsContent(infoType string) error {
 			gu.panels.SetCurrentPanel("Main")
 		})
 
+		searchItem, sortItem := gu.listControlItems(infoType)
+
 		gu.content.cardsContent.AddItem(emptyItem)
-		gu.content.cardsContent.AddItem(emptyItem)
+		gu.content.cardsContent.AddItem(searchItem)
+		gu.content.cardsContent.AddItem(sortItem)
 		gu.content.cardsContent.AddItem(newItem)
 		gu.content.cardsContent.AddItem(colItem)
 		gu.content.cardsContent.AddItem(quitItem)
@@ -270,7 +322,9 @@ func (gu *GUI) elementsContent(infoType string) error {
 
 		return nil
 	case "creds":
-		el := elems.([]models.Cred)
+		el := orderElements(gu.listOrder(infoType), elems.([]models.Cred), func(elem models.Cred) uuid.UUID {
+			return elem.ID
+		})
 		gu.content.credsContent.Clear()
 
 		if len(el) != 0 {
@@ -281,12 +335,13 @@ func (gu *GUI) elementsContent(infoType string) error {
 					date = fmt.Sprintf("%s (created: %s)", value.Changed.Time.Format("02 Jan 2006 15:04:05"), date)
 				}
 				item.SetSecondaryText(date)
+				markIntegrity(item, value.Integrity)
 				item.SetShortcut(rune(49 + index))
 				gu.content.credsContent.AddItem(item)
 			}
 		} else {
 			noContentItem := cview.NewListItem("No content")
-			noContentItem.SetSecondaryText("no content in database")
+			noContentItem.SetSecondaryText(gu.noContentText(infoType))
 			noContentItem.SetShortcut('x')
 			gu.content.credsContent.AddItem(noContentItem)
 		}
@@ -315,8 +370,11 @@ func (gu *GUI) elementsContent(infoType string) error {
 			gu.panels.SetCurrentPanel("Main")
 		})
 
+		searchItem, sortItem := gu.listControlItems(infoType)
+
 		gu.content.credsContent.AddItem(emptyItem)
-		gu.content.credsContent.AddItem(emptyItem)
+		gu.content.credsContent.AddItem(searchItem)
+		gu.content.credsContent.AddItem(sortItem)
 		gu.content.credsContent.AddItem(newItem)
 		gu.content.credsContent.AddItem(colItem)
 		gu.content.credsContent.AddItem(quitItem)
@@ -329,7 +387,9 @@ func (gu *GUI) elementsContent(infoType string) error {
 		})
 		return nil
 	case "files":
-		el := elems.([]models.File)
+		el := orderElements(gu.listOrder(infoType), elems.([]models.File), func(elem models.File) uuid.UUID {
+			return elem.ID
+		})
 		gu.content.filesContent.Clear()
 
 		if len(el) != 0 {
@@ -340,12 +400,13 @@ func (gu *GUI) elementsContent(infoType string) error {
 					date = fmt.Sprintf("%s (created: %s)", value.Changed.Time.Format("02 Jan 2006 15:04:05"), date)
 				}
 				item.SetSecondaryText(date)
+				markIntegrity(item, value.Integrity)
 				item.SetShortcut(rune(49 + index))
 				gu.content.filesContent.AddItem(item)
 			}
 		} else {
 			noContentItem := cview.NewListItem("No content")
-			noContentItem.SetSecondaryText("no content in database")
+			noContentItem.SetSecondaryText(gu.noContentText(infoType))
 			noContentItem.SetShortcut('x')
 			gu.content.filesContent.AddItem(noContentItem)
 		}
@@ -374,8 +435,11 @@ func (gu *GUI) elementsContent(infoType string) error {
 			gu.panels.SetCurrentPanel("Main")
 		})
 
+		searchItem, sortItem := gu.listControlItems(infoType)
+
 		gu.content.filesContent.AddItem(emptyItem)
-		gu.content.filesContent.AddItem(emptyItem)
+		gu.content.filesContent.AddItem(searchItem)
+		gu.content.filesContent.AddItem(sortItem)
 		gu.content.filesContent.AddItem(newItem)
 		gu.content.filesContent.AddItem(colItem)
 		gu.content.filesContent.AddItem(quitItem)
@@ -439,7 +503,7 @@ func (gu *GUI) generateNote(note *models.Note) {
 
 	gu.layouts.elementPage.AddItem(textPrimitive(note.Title, tcell.ColorKhaki, 1), 0, 0, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(gu.content.elementMenuContent, 1, 0, 2, 1, 0, 0, true)
-	gu.layouts.elementPage.AddItem(textPrimitive("ID: "+note.ID.String(), tcell.ColorDarkSalmon, 1), 0, 1, 1, 1, 0, 0, false)
+	gu.layouts.elementPage.AddItem(idPrimitive(note.ID, note.Integrity), 0, 1, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(elementTextPrimitive("Text: "+note.Note), 1, 1, 1, 1, 0, 0, true)
 	gu.layouts.elementPage.AddItem(textPrimitive(date, tcell.ColorDarkOrange, 1), 2, 1, 1, 1, 0, 0, false)
 }
@@ -496,7 +560,7 @@ func (gu *GUI) generateCard(card *models.Card) {
 
 	gu.layouts.elementPage.AddItem(textPrimitive(card.Title, tcell.ColorKhaki, 1), 0, 0, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(gu.content.elementMenuContent, 1, 0, 2, 1, 0, 0, true)
-	gu.layouts.elementPage.AddItem(textPrimitive("ID: "+card.ID.String(), tcell.ColorDarkSalmon, 1), 0, 1, 1, 1, 0, 0, false)
+	gu.layouts.elementPage.AddItem(idPrimitive(card.ID, card.Integrity), 0, 1, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(elementTextPrimitive(text), 1, 1, 1, 1, 0, 0, true)
 	gu.layouts.elementPage.AddItem(textPrimitive(date, tcell.ColorDarkOrange, 1), 2, 1, 1, 1, 0, 0, false)
 }
@@ -553,7 +617,7 @@ func (gu *GUI) generateCred(cred *models.Cred) {
 
 	gu.layouts.elementPage.AddItem(textPrimitive(cred.Title, tcell.ColorKhaki, 1), 0, 0, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(gu.content.elementMenuContent, 1, 0, 2, 1, 0, 0, true)
-	gu.layouts.elementPage.AddItem(textPrimitive("ID: "+cred.ID.String(), tcell.ColorDarkSalmon, 1), 0, 1, 1, 1, 0, 0, false)
+	gu.layouts.elementPage.AddItem(idPrimitive(cred.ID, cred.Integrity), 0, 1, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(elementTextPrimitive(text), 1, 1, 1, 1, 0, 0, true)
 	gu.layouts.elementPage.AddItem(textPrimitive(date, tcell.ColorDarkOrange, 1), 2, 1, 1, 1, 0, 0, false)
 }
@@ -619,7 +683,7 @@ func (gu *GUI) generateFile(file *models.File) {
 
 	gu.layouts.elementPage.AddItem(textPrimitive(file.Title, tcell.ColorKhaki, 1), 0, 0, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(gu.content.elementMenuContent, 1, 0, 2, 1, 0, 0, true)
-	gu.layouts.elementPage.AddItem(textPrimitive("ID: "+file.ID.String(), tcell.ColorDarkSalmon, 1), 0, 1, 1, 1, 0, 0, false)
+	gu.layouts.elementPage.AddItem(idPrimitive(file.ID, file.Integrity), 0, 1, 1, 1, 0, 0, false)
 	gu.layouts.elementPage.AddItem(elementTextPrimitive(text), 1, 1, 1, 1, 0, 0, true)
 	gu.layouts.elementPage.AddItem(textPrimitive(date, tcell.ColorDarkOrange, 1), 2, 1, 1, 1, 0, 0, false)
 }
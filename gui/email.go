@@ -0,0 +1,93 @@
+package gui
+
+// forgotPasswordForm asks email, service sends code of password reset to it
+func (gu *GUI) forgotPasswordForm() {
+	var email string
+	gu.forms.accountForm.Clear(true)
+	gu.forms.accountForm.AddInputField("Email", "", 20, nil, func(value string) {
+		email = value
+	})
+	gu.forms.accountForm.AddButton("Send code", func() {
+		if forgotErr := gu.client.ForgotPassword(email); forgotErr != nil {
+			gu.errorModalRender(forgotErr.Error(), "Account")
+			return
+		}
+		gu.resetPasswordForm()
+	})
+	gu.forms.accountForm.AddButton("I have code", func() {
+		gu.resetPasswordForm()
+	})
+	gu.forms.accountForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel("Main")
+	})
+}
+
+// resetPasswordForm asks code from message and new password, new password is typed twice
+func (gu *GUI) resetPasswordForm() {
+	var token, newPassword, repeated string
+	gu.forms.accountForm.Clear(true)
+	gu.forms.accountForm.AddInputField("Code from email", "", 24, nil, func(value string) {
+		token = value
+	})
+	gu.forms.accountForm.AddPasswordField("New password", "", 20, rune(42), func(value string) {
+		newPassword = value
+	})
+	gu.forms.accountForm.AddPasswordField("Repeat new password", "", 20, rune(42), func(value string) {
+		repeated = value
+	})
+	gu.forms.accountForm.AddButton("Reset", func() {
+		if newPassword != repeated {
+			gu.errorModalRender("new passwords don't match", "Account")
+			return
+		}
+		if resetErr := gu.client.ResetPassword(token, newPassword); resetErr != nil {
+			gu.errorModalRender(resetErr.Error(), "Account")
+			return
+		}
+		gu.messageModal("Password is changed, log in with new password", "Main")
+	})
+	gu.forms.accountForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel("Main")
+	})
+}
+
+// verifyEmailForm asks code from message that confirms email, new code could be sent
+func (gu *GUI) verifyEmailForm() {
+	var token string
+	gu.forms.accountForm.Clear(true)
+	gu.forms.accountForm.AddInputField("Code from email", "", 24, nil, func(value string) {
+		token = value
+	})
+	gu.forms.accountForm.AddButton("Confirm", func() {
+		if verifyErr := gu.client.VerifyEmail(token); verifyErr != nil {
+			gu.errorModalRender(verifyErr.Error(), "Account")
+			return
+		}
+		if settingsErr := gu.settingsContent(); settingsErr != nil {
+			gu.errorModalRender(settingsErr.Error(), "Main")
+			return
+		}
+		gu.messageModal("Email is confirmed", "Settings")
+	})
+	gu.forms.accountForm.AddButton("Send new code", func() {
+		if sendErr := gu.client.ResendVerification(); sendErr != nil {
+			gu.errorModalRender(sendErr.Error(), "Account")
+			return
+		}
+		gu.messageModal("New code is sent, check your email", "Account")
+	})
+	gu.forms.accountForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel("Settings")
+	})
+}
+
+// messageModal shows result of action, returnPage is shown after it
+func (gu *GUI) messageModal(text string, returnPage string) {
+	gu.constrains.confirm.ClearButtons()
+	gu.constrains.confirm.SetText(text)
+	gu.constrains.confirm.AddButtons([]string{"OK"})
+	gu.constrains.confirm.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		gu.panels.SetCurrentPanel(returnPage)
+	})
+	gu.panels.SetCurrentPanel("Confirm")
+}
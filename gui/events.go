@@ -0,0 +1,44 @@
+package gui
+
+import (
+	"AlexSarva/GophKeeper/events"
+	"context"
+	"log"
+)
+
+// watchEvents refreshes shown list of elements when it is changed by another client of user,
+// previous watch is stopped
+func (gu *GUI) watchEvents() {
+	gu.stopEvents()
+	ctx, cancel := context.WithCancel(context.Background())
+	gu.cancelEvents = cancel
+	go func() {
+		watchErr := gu.client.WatchEvents(ctx, func(event events.Event) {
+			gu.app.QueueUpdateDraw(func() {
+				gu.applyEvent(event.Kind)
+			})
+		})
+		if watchErr != nil {
+			log.Println(watchErr)
+		}
+	}()
+}
+
+// stopEvents stops watch of events, it is used on log out
+func (gu *GUI) stopEvents() {
+	if gu.cancelEvents != nil {
+		gu.cancelEvents()
+		gu.cancelEvents = nil
+	}
+}
+
+// applyEvent refreshes list of changed kind if it is shown, replaced vault refreshes any shown list.
+// Hidden lists are loaded when they are opened
+func (gu *GUI) applyEvent(kind string) {
+	front, _ := gu.panels.GetFrontPanel()
+	for infoType, panel := range listPanels {
+		if panel == front && (kind == infoType || kind == events.KindVault) {
+			gu.refreshList(infoType)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package gui
 import (
 	"AlexSarva/GophKeeper/models"
 	"AlexSarva/GophKeeper/utils"
+	"AlexSarva/GophKeeper/workclient"
+	"errors"
 	"io"
 	"os"
 	"path"
@@ -30,9 +32,13 @@ func (gu *GUI) registerForm() {
 		}
 		gu.texts.changeAuthText("", true)
 		gu.client.UseToken(user.Token)
+		gu.watchEvents()
 		gu.collectionContent()
 		gu.loggedContent()
 		gu.panels.SetCurrentPanel("Collection")
+		if keyErr := gu.client.KeyError(); keyErr != nil {
+			gu.errorModalRender(keyErr.Error(), "Collection")
+		}
 	})
 	gu.forms.registerForm.AddButton("Back", func() {
 		//pages.SwitchToPage("Menu")
@@ -51,22 +57,36 @@ func (gu *GUI) loginForm() {
 	})
 	gu.forms.loginForm.AddButton("Login", func() {
 		user, userErr := gu.client.Login(&login)
+		if errors.Is(userErr, workclient.ErrTwoFactorRequired) {
+			gu.twoFactorLoginForm()
+			gu.panels.SetCurrentPanel("Code")
+			return
+		}
 		if userErr != nil {
 			gu.texts.changeAuthText(userErr.Error(), false)
 			gu.errorModalRender(userErr.Error(), "Main")
 			return
 		}
-		gu.client.UseToken(user.Token)
-		gu.texts.changeAuthText("", true)
-		gu.collectionContent()
-		gu.loggedContent()
-		gu.panels.SetCurrentPanel("Collection")
+		gu.loggedIn(user)
 	})
 	gu.forms.loginForm.AddButton("Back", func() {
 		gu.panels.SetCurrentPanel("Main")
 	})
 }
 
+// loggedIn shows collection of user after login
+func (gu *GUI) loggedIn(user *models.User) {
+	gu.client.UseToken(user.Token)
+	gu.watchEvents()
+	gu.texts.changeAuthText("", true)
+	gu.collectionContent()
+	gu.loggedContent()
+	gu.panels.SetCurrentPanel("Collection")
+	if keyErr := gu.client.KeyError(); keyErr != nil {
+		gu.errorModalRender(keyErr.Error(), "Collection")
+	}
+}
+
 func (gu *GUI) newNoteForm() {
 	var note models.NewNote
 	gu.forms.newNoteForm.Clear(true)
@@ -106,6 +126,7 @@ func (gu *GUI) editNoteForm(note *models.Note) {
 		editNote.Note = text
 	})
 	gu.forms.editNoteForm.AddButton("Save", func() {
+		editNote.Version = note.Version + 1
 		_, elemErr := gu.client.EditElement("notes", &editNote, note.ID)
 		if elemErr != nil {
 			gu.errorModalRender(elemErr.Error(), "EditNote")
@@ -228,6 +249,7 @@ func (gu *GUI) editCardForm(card *models.Card) {
 		editCard.Notes = note
 	})
 	gu.forms.editCardForm.AddButton("Save", func() {
+		editCard.Version = card.Version + 1
 		_, elemErr := gu.client.EditElement("cards", &editCard, card.ID)
 		if elemErr != nil {
 			gu.errorModalRender(elemErr.Error(), "EditCard")
@@ -292,6 +314,7 @@ func (gu *GUI) editCredForm(cred *models.Cred) {
 		editCred.Passwd = passwd
 	})
 	gu.forms.editCredForm.AddButton("Save", func() {
+		editCred.Version = cred.Version + 1
 		_, elemErr := gu.client.EditElement("creds", &editCred, cred.ID)
 		if elemErr != nil {
 			gu.errorModalRender(elemErr.Error(), "NewCred")
@@ -407,6 +430,7 @@ func (gu *GUI) editFileForm(file *models.File) {
 		} else {
 			editClientFile.File = file.File
 		}
+		editClientFile.Version = file.Version + 1
 		_, elemErr := gu.client.EditElement("files", &editClientFile, file.ID)
 		if elemErr != nil {
 			gu.errorModalRender(elemErr.Error(), "EditFile")
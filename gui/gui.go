@@ -3,6 +3,7 @@ package gui
 import (
 	"AlexSarva/GophKeeper/models"
 	"AlexSarva/GophKeeper/workclient"
+	"context"
 	"log"
 
 	"code.rocketnine.space/tslocum/cview"
@@ -20,6 +21,9 @@ type GUI struct {
 	forms      *forms
 	texts      *texts
 	constrains *constrains
+	lists      *listStates
+	// cancelEvents stops watch of changes made by other clients
+	cancelEvents context.CancelFunc
 }
 
 // InitGUI initialize GUI, cfg should provide information about service address,
@@ -47,6 +51,7 @@ func InitGUI(cfg *models.GUIConfig) *GUI {
 		forms:      workForms,
 		texts:      workTexts,
 		constrains: workConstrains,
+		lists:      initListStates(),
 	}
 }
 
@@ -80,6 +85,14 @@ func (gu *GUI) Render() {
 	gu.layouts.filesPage.AddItem(gu.content.filesContent, 1, 0, 2, 1, 0, 0, true)
 	gu.layouts.filesPage.AddItem(textPrimitive("", tcell.ColorBlue, 1), 0, 1, 3, 1, 0, 0, false)
 
+	// settings page
+	gu.layouts.settingsPage.AddItem(gu.content.settingsContent, 1, 0, 2, 1, 0, 0, true)
+	gu.layouts.settingsPage.AddItem(textPrimitive("", tcell.ColorBlue, 1), 0, 1, 3, 1, 0, 0, false)
+
+	// two-factor setup page
+	gu.layouts.twoFactorPage.AddItem(gu.forms.setupForm, 1, 0, 1, 1, 0, 0, true)
+	gu.layouts.twoFactorPage.AddItem(gu.texts.twoFactorText, 0, 1, 2, 1, 0, 0, false)
+
 	gu.panels.AddPanel("Main", gu.layouts.mainPage, true, true)
 	gu.panels.AddPanel("Register", gu.forms.registerForm, true, false)
 	gu.panels.AddPanel("Login", gu.forms.loginForm, true, false)
@@ -96,13 +109,19 @@ func (gu *GUI) Render() {
 	gu.panels.AddPanel("Cards", gu.layouts.cardsPage, true, false)
 	gu.panels.AddPanel("Credentials", gu.layouts.credsPage, true, false)
 	gu.panels.AddPanel("Files", gu.layouts.filesPage, true, false)
+	gu.panels.AddPanel("Settings", gu.layouts.settingsPage, true, false)
+	gu.panels.AddPanel("TwoFactorSetup", gu.layouts.twoFactorPage, true, false)
+	gu.panels.AddPanel("Code", gu.forms.codeForm, true, false)
+	gu.panels.AddPanel("Account", gu.forms.accountForm, true, false)
 	gu.panels.AddPanel("Note", gu.layouts.elementPage, true, false)
 	gu.panels.AddPanel("File", gu.layouts.elementPage, true, false)
 	gu.panels.AddPanel("Card", gu.layouts.elementPage, true, false)
 	gu.panels.AddPanel("Cred", gu.layouts.elementPage, true, false)
 	gu.panels.AddPanel("Mistake", gu.constrains.constrain, false, false)
 	gu.panels.AddPanel("FileHandler", gu.constrains.fileHandler, false, false)
+	gu.panels.AddPanel("Confirm", gu.constrains.confirm, false, false)
 	gu.panels.AddPanel("GetFile", gu.forms.getFileForm, true, false)
+	gu.panels.AddPanel("Search", gu.forms.searchForm, true, false)
 }
 
 // Run starts the GUI
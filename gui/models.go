@@ -8,14 +8,17 @@ import (
 type constrains struct {
 	constrain   *cview.Modal
 	fileHandler *cview.Modal
+	confirm     *cview.Modal
 }
 
 func initConstrains() *constrains {
 	constrain := cview.NewModal()
 	fileHandler := cview.NewModal()
+	confirm := cview.NewModal()
 	return &constrains{
 		constrain:   constrain,
 		fileHandler: fileHandler,
+		confirm:     confirm,
 	}
 }
 
@@ -27,6 +30,8 @@ type layouts struct {
 	cardsPage      *cview.Grid
 	filesPage      *cview.Grid
 	credsPage      *cview.Grid
+	settingsPage   *cview.Grid
+	twoFactorPage  *cview.Grid
 }
 
 func initLayouts() *layouts {
@@ -78,6 +83,20 @@ func initLayouts() *layouts {
 	filesGrid.SetGap(1, 0)
 	filesGrid.AddItem(textPrimitive("Files: ", tcell.ColorBlue, 1), 0, 0, 1, 1, 0, 0, false)
 
+	settingsGrid := cview.NewGrid()
+	settingsGrid.SetColumns(70, 0)
+	settingsGrid.SetRows(1, 1, 0)
+	settingsGrid.SetBorders(true)
+	settingsGrid.SetGap(1, 0)
+	settingsGrid.AddItem(textPrimitive("Settings: sessions, two-factor authentication and account", tcell.ColorBlue, 1), 0, 0, 1, 1, 0, 0, false)
+
+	twoFactorGrid := cview.NewGrid()
+	twoFactorGrid.SetColumns(45, 0)
+	twoFactorGrid.SetRows(1, 0)
+	twoFactorGrid.SetBorders(true)
+	twoFactorGrid.SetGap(1, 0)
+	twoFactorGrid.AddItem(textPrimitive("Two-factor authentication", tcell.ColorBlue, 1), 0, 0, 1, 1, 0, 0, false)
+
 	return &layouts{
 		mainPage:       mainGrid,
 		collectionPage: collectionGrid,
@@ -86,6 +105,8 @@ func initLayouts() *layouts {
 		cardsPage:      cardsGrid,
 		filesPage:      filesGrid,
 		credsPage:      credsGrid,
+		settingsPage:   settingsGrid,
+		twoFactorPage:  twoFactorGrid,
 	}
 }
 
@@ -97,6 +118,7 @@ type content struct {
 	cardsContent       *cview.List
 	credsContent       *cview.List
 	filesContent       *cview.List
+	settingsContent    *cview.List
 }
 
 func initContent() *content {
@@ -107,6 +129,7 @@ func initContent() *content {
 	cardsContent := cview.NewList()
 	credsContent := cview.NewList()
 	filesContent := cview.NewList()
+	settingsContent := cview.NewList()
 	return &content{
 		welcomeContent:     welcomeContent,
 		collectionContent:  collectionContent,
@@ -115,6 +138,7 @@ func initContent() *content {
 		cardsContent:       cardsContent,
 		credsContent:       credsContent,
 		filesContent:       filesContent,
+		settingsContent:    settingsContent,
 	}
 }
 
@@ -130,6 +154,10 @@ type forms struct {
 	newFileForm  *cview.Form
 	editFileForm *cview.Form
 	getFileForm  *cview.Form
+	searchForm   *cview.Form
+	codeForm     *cview.Form
+	setupForm    *cview.Form
+	accountForm  *cview.Form
 }
 
 func initForms() *forms {
@@ -144,6 +172,10 @@ func initForms() *forms {
 	newFileForm := cview.NewForm()
 	editFileForm := cview.NewForm()
 	getFileForm := cview.NewForm()
+	searchForm := cview.NewForm()
+	codeForm := cview.NewForm()
+	setupForm := cview.NewForm()
+	accountForm := cview.NewForm()
 	return &forms{
 		registerForm: registerForm,
 		loginForm:    loginForm,
@@ -156,17 +188,24 @@ func initForms() *forms {
 		newFileForm:  newFileForm,
 		editFileForm: editFileForm,
 		getFileForm:  getFileForm,
+		searchForm:   searchForm,
+		codeForm:     codeForm,
+		setupForm:    setupForm,
+		accountForm:  accountForm,
 	}
 }
 
 type texts struct {
-	authText *cview.TextView
+	authText      *cview.TextView
+	twoFactorText *cview.TextView
 }
 
 func initTexts() *texts {
 	authText := cview.NewTextView()
+	twoFactorText := cview.NewTextView()
 	return &texts{
-		authText: authText,
+		authText:      authText,
+		twoFactorText: twoFactorText,
 	}
 }
 
@@ -180,3 +219,16 @@ func (t *texts) changeAuthText(text string, auth bool) {
 	t.authText.SetText("You are successfully logged in!")
 
 }
+
+// listStates search queries and sort orders of elements lists by info type
+type listStates struct {
+	queries map[string]string
+	sorts   map[string]string
+}
+
+func initListStates() *listStates {
+	return &listStates{
+		queries: make(map[string]string),
+		sorts:   make(map[string]string),
+	}
+}
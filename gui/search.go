@@ -0,0 +1,106 @@
+package gui
+
+import (
+	"AlexSarva/GophKeeper/workclient"
+	"fmt"
+
+	"code.rocketnine.space/tslocum/cview"
+	"github.com/google/uuid"
+)
+
+// listPanels names of panels with elements lists by info type
+var listPanels = map[string]string{
+	"notes": "Notes",
+	"cards": "Cards",
+	"creds": "Credentials",
+	"files": "Files",
+}
+
+// listOrder returns ids of elements of selected type in order of client-side index,
+// elements that don't match search query are skipped
+func (gu *GUI) listOrder(infoType string) []uuid.UUID {
+	entries := gu.client.Search(infoType, gu.lists.queries[infoType], gu.lists.sorts[infoType])
+	order := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		order = append(order, entry.ID)
+	}
+	return order
+}
+
+// orderElements returns elements in selected order
+func orderElements[T any](order []uuid.UUID, elems []T, id func(T) uuid.UUID) []T {
+	byID := make(map[uuid.UUID]T, len(elems))
+	for _, elem := range elems {
+		byID[id(elem)] = elem
+	}
+	res := make([]T, 0, len(order))
+	for _, elemID := range order {
+		if elem, ok := byID[elemID]; ok {
+			res = append(res, elem)
+		}
+	}
+	return res
+}
+
+func (gu *GUI) noContentText(infoType string) string {
+	if query := gu.lists.queries[infoType]; query != "" {
+		return fmt.Sprintf("nothing found by '%s'", query)
+	}
+	return "no content in database"
+}
+
+// listControlItems makes search and sort items of elements list
+func (gu *GUI) listControlItems(infoType string) (*cview.ListItem, *cview.ListItem) {
+	searchItem := cview.NewListItem("Search")
+	searchItem.SetSecondaryText("search by title")
+	if query := gu.lists.queries[infoType]; query != "" {
+		searchItem.SetSecondaryText(fmt.Sprintf("search by title (current: %s)", query))
+	}
+	searchItem.SetShortcut('s')
+	searchItem.SetSelectedFunc(func() {
+		gu.searchForm(infoType)
+		gu.panels.SetCurrentPanel("Search")
+	})
+
+	sortBy, nextSort := workclient.SortByDate, workclient.SortByTitle
+	if gu.lists.sorts[infoType] == workclient.SortByTitle {
+		sortBy, nextSort = workclient.SortByTitle, workclient.SortByDate
+	}
+	sortItem := cview.NewListItem("Sort by " + nextSort)
+	sortItem.SetSecondaryText("sorted by " + sortBy + " now")
+	sortItem.SetShortcut('o')
+	sortItem.SetSelectedFunc(func() {
+		gu.lists.sorts[infoType] = nextSort
+		gu.refreshList(infoType)
+	})
+
+	return searchItem, sortItem
+}
+
+// refreshList renders elements list of selected type again and shows it
+func (gu *GUI) refreshList(infoType string) {
+	if contentErr := gu.elementsContent(infoType); contentErr != nil {
+		gu.errorModalRender(contentErr.Error(), listPanels[infoType])
+		return
+	}
+	gu.panels.SetCurrentPanel(listPanels[infoType])
+}
+
+func (gu *GUI) searchForm(infoType string) {
+	query := gu.lists.queries[infoType]
+	gu.forms.searchForm.Clear(true)
+	gu.forms.searchForm.AddInputField("Title", query, 25, nil, func(text string) {
+		query = text
+	})
+	gu.forms.searchForm.AddButton("Search", func() {
+		gu.lists.queries[infoType] = query
+		gu.refreshList(infoType)
+	})
+	gu.forms.searchForm.AddButton("Reset", func() {
+		gu.lists.queries[infoType] = ""
+		gu.refreshList(infoType)
+	})
+	gu.forms.searchForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel(listPanels[infoType])
+	})
+}
@@ -0,0 +1,165 @@
+package gui
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"fmt"
+
+	"code.rocketnine.space/tslocum/cview"
+)
+
+// settingsContent lists sessions of user, selected session could be revoked.
+// Two-factor authentication is enabled and disabled here, email is confirmed, password is changed
+// and account is deleted here
+func (gu *GUI) settingsContent() error {
+	sessions, sessionsErr := gu.client.Sessions()
+	if sessionsErr != nil {
+		return sessionsErr
+	}
+	user, userErr := gu.client.Me()
+	if userErr != nil {
+		return userErr
+	}
+	gu.content.settingsContent.Clear()
+
+	for index, session := range sessions {
+		item := cview.NewListItem(sessionTitle(session))
+		item.SetSecondaryText(fmt.Sprintf("%s, last seen %s (logged in: %s)", session.IP,
+			session.LastSeen.Local().Format("02 Jan 2006 15:04:05"), session.Created.Local().Format("02 Jan 2006 15:04:05")))
+		if index < 9 {
+			item.SetShortcut(rune(49 + index))
+		}
+		gu.content.settingsContent.AddItem(item)
+	}
+
+	emptyItem := cview.NewListItem("")
+
+	refreshItem := cview.NewListItem("Refresh")
+	refreshItem.SetSecondaryText("Reload sessions")
+	refreshItem.SetShortcut('r')
+	refreshItem.SetSelectedFunc(func() {
+		if settingsErr := gu.settingsContent(); settingsErr != nil {
+			gu.errorModalRender(settingsErr.Error(), "Settings")
+		}
+	})
+
+	enableItem := cview.NewListItem("Enable Two-Factor Authentication")
+	enableItem.SetSecondaryText("Ask code of authenticator app on login")
+	enableItem.SetShortcut('e')
+	enableItem.SetSelectedFunc(func() {
+		if setupErr := gu.setupTwoFactor(); setupErr != nil {
+			gu.errorModalRender(setupErr.Error(), "Settings")
+			return
+		}
+		gu.panels.SetCurrentPanel("TwoFactorSetup")
+	})
+
+	disableItem := cview.NewListItem("Disable Two-Factor Authentication")
+	disableItem.SetSecondaryText("Log in by email and password only")
+	disableItem.SetShortcut('d')
+	disableItem.SetSelectedFunc(func() {
+		gu.disableTwoFactorForm()
+		gu.panels.SetCurrentPanel("Code")
+	})
+
+	emailItem := cview.NewListItem("Confirm Email")
+	emailItem.SetSecondaryText(fmt.Sprintf("Enter code sent to %s", cview.Escape(user.Email)))
+	if user.EmailVerified != nil {
+		emailItem.SetSecondaryText(fmt.Sprintf("%s is confirmed %s", cview.Escape(user.Email),
+			user.EmailVerified.Local().Format("02 Jan 2006 15:04:05")))
+	}
+	emailItem.SetShortcut('v')
+	emailItem.SetSelectedFunc(func() {
+		if user.EmailVerified != nil {
+			return
+		}
+		gu.verifyEmailForm()
+		gu.panels.SetCurrentPanel("Account")
+	})
+
+	passwordItem := cview.NewListItem("Change Password")
+	passwordItem.SetSecondaryText("Other devices have to log in again")
+	passwordItem.SetShortcut('p')
+	passwordItem.SetSelectedFunc(func() {
+		gu.changePasswordForm()
+		gu.panels.SetCurrentPanel("Account")
+	})
+
+	deleteItem := cview.NewListItem("Delete Account")
+	deleteItem.SetSecondaryText("Remove account with all notes, cards, credentials and files")
+	deleteItem.SetShortcut('x')
+	deleteItem.SetSelectedFunc(func() {
+		gu.deleteAccountForm()
+		gu.panels.SetCurrentPanel("Account")
+	})
+
+	quitItem := cview.NewListItem("To Main")
+	quitItem.SetSecondaryText("Go to main menu")
+	quitItem.SetShortcut('m')
+	quitItem.SetSelectedFunc(func() {
+		gu.panels.SetCurrentPanel("Main")
+	})
+
+	gu.content.settingsContent.AddItem(emptyItem)
+	gu.content.settingsContent.AddItem(refreshItem)
+	gu.content.settingsContent.AddItem(enableItem)
+	gu.content.settingsContent.AddItem(disableItem)
+	gu.content.settingsContent.AddItem(emailItem)
+	gu.content.settingsContent.AddItem(passwordItem)
+	gu.content.settingsContent.AddItem(deleteItem)
+	gu.content.settingsContent.AddItem(quitItem)
+
+	gu.content.settingsContent.SetSelectedFunc(func(index int, element *cview.ListItem) {
+		if index < len(sessions) {
+			gu.revokeSessionModal(sessions[index])
+		}
+	})
+	return nil
+}
+
+// revokeSessionModal asks to revoke session, revoke of current session logs out
+func (gu *GUI) revokeSessionModal(session models.Session) {
+	gu.constrains.confirm.ClearButtons()
+	text := fmt.Sprintf("Revoke session of %s?\nDevice has to log in again", sessionTitle(session))
+	if session.Current {
+		text = "Revoke session of this device?\nYou will be logged out"
+	}
+	gu.constrains.confirm.SetText(text)
+	gu.constrains.confirm.AddButtons([]string{"Revoke", "Cancel"})
+	gu.constrains.confirm.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		if buttonLabel != "Revoke" {
+			gu.panels.SetCurrentPanel("Settings")
+			return
+		}
+		if session.Current {
+			gu.logout(gu.client.Logout)
+			return
+		}
+		if revokeErr := gu.client.RevokeSession(session.ID); revokeErr != nil {
+			gu.errorModalRender(revokeErr.Error(), "Settings")
+			return
+		}
+		if settingsErr := gu.settingsContent(); settingsErr != nil {
+			gu.errorModalRender(settingsErr.Error(), "Main")
+			return
+		}
+		gu.panels.SetCurrentPanel("Settings")
+	})
+	gu.panels.SetCurrentPanel("Confirm")
+}
+
+// sessionTitle returns device and client of session, they are escaped because they come from other clients
+func sessionTitle(session models.Session) string {
+	title := cview.Escape(session.DeviceName)
+	if title == "" {
+		title = "unknown device"
+	}
+	if session.ClientVersion != "" {
+		title = fmt.Sprintf("%s, client %s", title, cview.Escape(session.ClientVersion))
+	} else if session.UserAgent != "" {
+		title = fmt.Sprintf("%s, %s", title, cview.Escape(session.UserAgent))
+	}
+	if session.Current {
+		title = "[green]" + title + " (this device)[-]"
+	}
+	return title
+}
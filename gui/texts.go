@@ -1,8 +1,11 @@
 package gui
 
 import (
+	"AlexSarva/GophKeeper/models"
+
 	"code.rocketnine.space/tslocum/cview"
 	"github.com/gdamore/tcell/v2"
+	"github.com/google/uuid"
 )
 
 func textPrimitive(text string, color tcell.Color, align int) cview.Primitive {
@@ -40,3 +43,23 @@ func (gu *GUI) authMessage() {
 	gu.texts.authText.SetTextAlign(1)
 	gu.texts.authText.SetText("You are not logged in!")
 }
+
+// idPrimitive shows ID of element or integrity warning in red if its signature check failed
+// or element is unsigned, as signature could be stripped by somebody else
+func idPrimitive(id uuid.UUID, integrity models.Integrity) cview.Primitive {
+	switch integrity {
+	case models.IntegrityFailed, models.IntegrityRollback, models.IntegrityUnsigned:
+		return textPrimitive("WARNING! "+integrity.Warning(), tcell.ColorRed, 1)
+	default:
+		return textPrimitive("ID: "+id.String(), tcell.ColorDarkSalmon, 1)
+	}
+}
+
+// markIntegrity marks list item of element in red if its signature check failed or element is unsigned
+func markIntegrity(item *cview.ListItem, integrity models.Integrity) {
+	switch integrity {
+	case models.IntegrityFailed, models.IntegrityRollback, models.IntegrityUnsigned:
+		item.SetMainText("[red](!) " + item.GetMainText() + "[-]")
+		item.SetSecondaryText(integrity.Warning())
+	}
+}
@@ -0,0 +1,92 @@
+package gui
+
+import (
+	"AlexSarva/GophKeeper/utils/qrtext"
+	"AlexSarva/GophKeeper/workclient"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// twoFactorLoginForm asks code of second factor after password is checked
+func (gu *GUI) twoFactorLoginForm() {
+	var code string
+	gu.forms.codeForm.Clear(true)
+	gu.forms.codeForm.AddInputField("Two-factor code", "", 20, nil, func(value string) {
+		code = value
+	})
+	gu.forms.codeForm.AddButton("Login", func() {
+		user, userErr := gu.client.LoginTwoFactor(code)
+		if userErr != nil {
+			gu.texts.changeAuthText(userErr.Error(), false)
+			if errors.Is(userErr, workclient.ErrChallenge) {
+				gu.errorModalRender(userErr.Error(), "Login")
+				return
+			}
+			gu.errorModalRender(userErr.Error(), "Code")
+			return
+		}
+		gu.loggedIn(user)
+	})
+	gu.forms.codeForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel("Login")
+	})
+}
+
+// setupTwoFactor shows QR code of new TOTP secret, two-factor authentication is enabled by code of authenticator app
+func (gu *GUI) setupTwoFactor() error {
+	setup, setupErr := gu.client.SetupTwoFactor()
+	if setupErr != nil {
+		return setupErr
+	}
+	qr, qrErr := qrtext.Render(setup.URI)
+	if qrErr != nil {
+		return qrErr
+	}
+	gu.texts.twoFactorText.SetText(fmt.Sprintf("Scan QR code by authenticator app:\n\n%s\nor add account by key: %s", qr, setup.Secret))
+
+	var code string
+	gu.forms.setupForm.Clear(true)
+	gu.forms.setupForm.AddInputField("Code", "", 20, nil, func(value string) {
+		code = value
+	})
+	gu.forms.setupForm.AddButton("Enable", func() {
+		codes, confirmErr := gu.client.ConfirmTwoFactor(code)
+		if confirmErr != nil {
+			gu.errorModalRender(confirmErr.Error(), "TwoFactorSetup")
+			return
+		}
+		gu.texts.twoFactorText.SetText(fmt.Sprintf("Two-factor authentication is enabled.\n\n"+
+			"Save recovery codes, every code replaces code of authenticator app once.\n"+
+			"They are shown only now:\n\n%s", strings.Join(codes.Codes, "\n")))
+		gu.forms.setupForm.Clear(true)
+		gu.forms.setupForm.AddButton("Done", func() {
+			gu.texts.twoFactorText.SetText("")
+			gu.panels.SetCurrentPanel("Settings")
+		})
+	})
+	gu.forms.setupForm.AddButton("Cancel", func() {
+		gu.texts.twoFactorText.SetText("")
+		gu.panels.SetCurrentPanel("Settings")
+	})
+	return nil
+}
+
+// disableTwoFactorForm asks code of second factor to disable two-factor authentication
+func (gu *GUI) disableTwoFactorForm() {
+	var code string
+	gu.forms.codeForm.Clear(true)
+	gu.forms.codeForm.AddInputField("Two-factor code", "", 20, nil, func(value string) {
+		code = value
+	})
+	gu.forms.codeForm.AddButton("Disable", func() {
+		if disableErr := gu.client.DisableTwoFactor(code); disableErr != nil {
+			gu.errorModalRender(disableErr.Error(), "Code")
+			return
+		}
+		gu.panels.SetCurrentPanel("Settings")
+	})
+	gu.forms.codeForm.AddButton("Back", func() {
+		gu.panels.SetCurrentPanel("Settings")
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// PostAccessToken - create personal access token method
+//
+// Handler POST /api/v1/users/me/tokens
+//
+// Authorization: "Bearer T"
+//
+// Request format:
+//
+//	{
+//	  "name": "<name>",
+//	  "scopes": ["creds:read", "notes:write"],
+//	  "items": ["<id of element>"],
+//	  "expires": "<time, not later than a year>"
+//	}
+//
+// Token grants reading or writing of kinds of elements, it is restricted to items if they are set.
+// Token is returned only in response of this request.
+//
+// Possible response codes:
+// 201 - token successfully created;
+// 400 - invalid request format, unknown scope or wrong expiry;
+// 401 - invalid auth;
+// 403 - request is made by personal access token;
+// 500 - an internal server error.
+func PostAccessToken(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var newToken models.NewAccessToken
+		readBodyErr := readBodyInStruct(r, &newToken)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		token, tokenErr := keeper.CreateAccessToken(userID, &newToken)
+		if tokenErr != nil {
+			serviceErrorResponse(w, r, tokenErr)
+			return
+		}
+
+		resultResponse(w, token, accepted(r), http.StatusCreated)
+	}
+}
+
+// GetAccessTokens - personal access tokens of user method
+//
+// Handler GET /api/v1/users/me/tokens
+//
+// Authorization: "Bearer T"
+//
+// Returns tokens that are not expired with their scopes and last used time, tokens themselves are not returned.
+//
+// Possible response codes:
+// 200 - tokens of user;
+// 401 - invalid auth;
+// 403 - request is made by personal access token;
+// 500 - an internal server error.
+func GetAccessTokens(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		tokens, tokensErr := keeper.AccessTokens(userID)
+		if tokensErr != nil {
+			serviceErrorResponse(w, r, tokensErr)
+			return
+		}
+
+		resultResponse(w, tokens, accepted(r), http.StatusOK)
+	}
+}
+
+// DeleteAccessToken - revoke personal access token method
+//
+// Handler DELETE /api/v1/users/me/tokens/{id}
+//
+// Authorization: "Bearer T"
+//
+// Possible response codes:
+// 200 - token successfully revoked;
+// 400 - invalid request format;
+// 401 - invalid auth;
+// 403 - request is made by personal access token;
+// 404 - no such token;
+// 500 - an internal server error.
+func DeleteAccessToken(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		tokenID, tokenIDErr := uuid.Parse(chi.URLParam(r, "id"))
+		if tokenIDErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
+			return
+		}
+
+		revokeErr := keeper.RevokeAccessToken(userID, tokenID)
+		if revokeErr != nil {
+			serviceErrorResponse(w, r, revokeErr)
+			return
+		}
+
+		resultResponse(w, "successful revoked", accepted(r), http.StatusOK)
+	}
+}
+
+// permittedItems removes elements that personal access token of request is not restricted to,
+// lists of requests with JWT are not changed
+func permittedItems[T any](ctx context.Context, elems []T, id func(T) uuid.UUID) []T {
+	token := getAccessToken(ctx)
+	if token == nil || len(token.Items) == 0 {
+		return elems
+	}
+	permitted := make([]T, 0, len(elems))
+	for _, elem := range elems {
+		if token.Allows(id(elem)) {
+			permitted = append(permitted, elem)
+		}
+	}
+	return permitted
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"net/http"
+)
+
+// ChangePassword - change password of user method
+//
+// Handler POST /api/v1/users/me/password
+//
+// Authorization: "Bearer T"
+//
+// Request format:
+//
+//	{"password": "<current password>",
+//	"new_password": "<new password>"}
+//
+// Other sessions of user are revoked, session of request stays.
+//
+// Possible response codes:
+// 200 - password successfully changed;
+// 400 - invalid request format;
+// 401 - invalid auth;
+// 403 - current password is wrong;
+// 409 - password is changed meanwhile;
+// 417 - new password is too weak;
+// 429 - too many wrong passwords, request could be repeated after Retry-After seconds;
+// 500 - an internal server error.
+func ChangePassword(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		var change models.PasswordChange
+		readBodyErr := readBodyInStruct(r, &change)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		changeErr := keeper.ChangePassword(userID, getSessionID(ctx), &change, sessionClient(r))
+		if changeErr != nil {
+			serviceErrorResponse(w, r, changeErr)
+			return
+		}
+
+		resultResponse(w, "password changed", accepted(r), http.StatusOK)
+	}
+}
+
+// DeleteAccount - delete account of user method
+//
+// Handler DELETE /api/v1/users/me
+//
+// Authorization: "Bearer T"
+//
+// Request format:
+//
+//	{"password": "<current password>",
+//	"code": "<code of authenticator app or recovery code, if two-factor authentication is enabled>"}
+//
+// Removes user with all notes, cards, creds and files, all sessions of user are ended.
+// Data of user is removed at once, data left by a failed removal is removed by the background
+// purge, that runs every 10 minutes, before the account itself is removed.
+//
+// Possible response codes:
+// 200 - account successfully deleted;
+// 400 - invalid request format;
+// 401 - invalid auth;
+// 403 - password or code is wrong;
+// 429 - too many wrong passwords, request could be repeated after Retry-After seconds;
+// 500 - an internal server error.
+func DeleteAccount(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		var deletion models.AccountDeletion
+		readBodyErr := readBodyInStruct(r, &deletion)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		deleteErr := keeper.DeleteAccount(userID, &deletion, sessionClient(r))
+		if deleteErr != nil {
+			serviceErrorResponse(w, r, deleteErr)
+			return
+		}
+
+		resultResponse(w, "account deleted", accepted(r), http.StatusOK)
+	}
+}
@@ -1,30 +1,22 @@
 package handlers
 
 import (
-	"AlexSarva/GophKeeper/authorizer"
-	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
-	"AlexSarva/GophKeeper/storage"
-	"errors"
-	"fmt"
-	"log"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
 	"net/http"
-	"net/mail"
-	"strings"
-
-	"github.com/google/uuid"
 )
 
 // UserRegistration - user registration method
 //
 // Handler POST /api/v1/register
 //
-// Registration is performed by a pair of login/password.
-// Each login must be set.
+// Registration is performed by a pair of email/password.
+// Each email must be set.
 // After successful registration, automatic user authentication is required.
 // post message should contain such body:
 //
-//	"login": "<login>",
+//	"username": "<username>",
 //	"email": "<email>",
 //	"password": "<password>"
 //
@@ -32,52 +24,24 @@ import (
 // 201 - user successfully registered and authenticated;
 // 400 - invalid request format;
 // 409 - login is already taken;
-// 417 - if login or password not valid;
+// 417 - if email or password not valid;
 // 500 - an internal server error.
-func UserRegistration(database *app.Storage) http.HandlerFunc {
+func UserRegistration(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var user models.User
 		readBodyErr := readBodyInStruct(r, &user)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
-			return
-		}
-		if user.Email == "" || user.Password == "" || user.Username == "" {
-			errorMessageResponse(w, "empty fields error", "application/json", http.StatusBadRequest)
-			return
-		}
-
-		strongPassErr := database.PasswordChecker.VerifyPassword(user.Password)
-		if strongPassErr != nil {
-			errorMessageResponse(w, strongPassErr.Error(), "application/json", http.StatusExpectationFailed)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 
-		_, emailCheckErr := mail.ParseAddress(user.Email)
-		if emailCheckErr != nil {
-			errorMessageResponse(w, emailCheckErr.Error(), "application/json", http.StatusExpectationFailed)
-			return
-		}
-
-		user.ID = uuid.New()
-		newUser, newUserErr := database.Authorizer.SignUp(user)
-
+		newUser, newUserErr := keeper.Register(user, sessionClient(r))
 		if newUserErr != nil {
-			if errors.Is(newUserErr, storage.ErrDuplicatePK) {
-				errorMessageResponse(w, ErrLoginExist.Error(), "application/json", http.StatusConflict)
-				return
-			}
-
-			if errors.Is(newUserErr, authorizer.ErrHashPassword) || errors.Is(newUserErr, authorizer.ErrGenerateToken) {
-				errorMessageResponse(w, newUserErr.Error(), "application/json", http.StatusBadRequest)
-				return
-			}
-
-			errorMessageResponse(w, newUserErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newUserErr)
 			return
 		}
 
-		resultResponse(w, newUser, "application/json", http.StatusCreated)
+		resultResponse(w, newUser, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -85,63 +49,135 @@ func UserRegistration(database *app.Storage) http.HandlerFunc {
 //
 // Handler POST /api/v1/login
 //
-// Authentication is performed by a login/password pair.
+// Authentication is performed by a email/password pair.
 // Request format:
 //
-//	{"login": "<login>",
+//	{"email": "<email>",
 //	"password": "<password>"}
 //
+// Returns user with short-lived access token and refresh token.
+// Every login starts new session, headers X-Device-Name and X-Client-Version describe device of session.
+// If user enabled two-factor authentication, returns challenge that is completed by POST /api/v1/login/2fa.
+//
 // Possible response codes:
 // 200 - user successfully authenticated;
+// 202 - password is correct, code of second factor is required;
 // 400 - invalid request format;
-// 401 - invalid login/password pair;
+// 401 - invalid email/password pair;
 // 500 - an internal server error.
-func UserAuthentication(database *app.Storage) http.HandlerFunc {
+func UserAuthentication(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		var user models.UserLogin
 		readBodyErr := readBodyInStruct(r, &user)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 
-		userInfo, userInfoErr := database.Authorizer.SignIn(&user)
+		userInfo, challenge, userInfoErr := keeper.Login(&user, sessionClient(r))
 		if userInfoErr != nil {
-			if errors.Is(userInfoErr, authorizer.ErrNoUserExists) {
-				errorMessageResponse(w, userInfoErr.Error(), "application/json", http.StatusUnauthorized)
-				return
-			}
-			if errors.Is(userInfoErr, authorizer.ErrComparePassword) {
-				errorMessageResponse(w, userInfoErr.Error(), "application/json", http.StatusUnauthorized)
-				return
-			}
-			errorMessageResponse(w, userInfoErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, userInfoErr)
+			return
+		}
+		if challenge != nil {
+			resultResponse(w, challenge, accepted(r), http.StatusAccepted)
 			return
 		}
-		userInfo.Password = ""
 
-		log.Println(userInfo.Token)
+		resultResponse(w, userInfo, accepted(r), http.StatusOK)
+	}
+}
 
-		jwt := strings.Split(userInfo.Token, " ")[1]
+// RefreshToken - exchange refresh token for new pair of tokens method
+//
+// Handler POST /api/v1/token/refresh
+//
+//	"refresh_token": "<refresh token>"
+//
+// Refresh token could be used only once, reuse of spent token revokes all tokens of the login.
+//
+// Possible response codes:
+// 200 - returns new pair of tokens;
+// 400 - invalid request format;
+// 401 - refresh token is invalid, expired or reused;
+// 500 - an internal server error.
+func RefreshToken(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var refresh models.TokenRefresh
+		readBodyErr := readBodyInStruct(r, &refresh)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		tokens, tokensErr := keeper.Refresh(&refresh, sessionClient(r))
+		if tokensErr != nil {
+			serviceErrorResponse(w, r, tokensErr)
+			return
+		}
+
+		resultResponse(w, tokens, accepted(r), http.StatusOK)
+	}
+}
 
-		_, userIDErr := database.Authorizer.ParseToken(jwt)
+// Logout - logout method
+//
+// Handler POST /api/v1/logout
+//
+// Authorization: "Bearer T"
+//
+// Revokes access token of request and refresh tokens of its login.
+//
+// Possible response codes:
+// 200 - successfully logged out;
+// 401 - invalid auth;
+// 500 - an internal server error.
+func Logout(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, tokenErr := getToken(r)
+		if tokenErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		logoutErr := keeper.Logout(token)
+		if logoutErr != nil {
+			serviceErrorResponse(w, r, logoutErr)
+			return
+		}
+
+		resultResponse(w, "successful logout", accepted(r), http.StatusOK)
+	}
+}
+
+// LogoutEverywhere - logout on all devices method
+//
+// Handler POST /api/v1/logout/all
+//
+// Authorization: "Bearer T"
+//
+// Revokes all access and refresh tokens of user, including token of request.
+//
+// Possible response codes:
+// 200 - successfully logged out everywhere;
+// 401 - invalid auth;
+// 500 - an internal server error.
+func LogoutEverywhere(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
 		if userIDErr != nil {
-			if strings.Contains(userIDErr.Error(), "token is expired") {
-				userNewInfo, userInfErr := database.Authorizer.RenewToken(*userInfo)
-				if userInfErr != nil {
-					errorMessageResponse(w, userInfErr.Error(), "application/json", http.StatusInternalServerError)
-					return
-				}
-				userNewInfo.Password = ""
-				resultResponse(w, userNewInfo, "application/json", http.StatusOK)
-			}
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
 
-			errorMessageResponse(w, fmt.Sprint(ErrUnauthorized, ": ", userIDErr), "application/json", http.StatusForbidden)
+		logoutErr := keeper.LogoutEverywhere(userID)
+		if logoutErr != nil {
+			serviceErrorResponse(w, r, logoutErr)
 			return
 		}
 
-		resultResponse(w, userInfo, "application/json", http.StatusOK)
+		resultResponse(w, "successful logout", accepted(r), http.StatusOK)
 	}
 }
 
@@ -151,27 +187,68 @@ func UserAuthentication(database *app.Storage) http.HandlerFunc {
 //
 // Authorization: "Bearer T"
 //
+// Returns user information with fingerprint of the key registered by client.
+//
 // Possible response codes:
 // 200 - load user information;
 // 400 - invalid request format;
 // 401 - invalid auth;
 // 500 - an internal server error.
-func GetUserInfo(database *app.Storage) http.HandlerFunc {
+func GetUserInfo(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		userInfo, userInfoErr := database.Admin.GetUserInfo(userID)
+		userInfo, userInfoErr := keeper.UserInfo(userID)
 		if userInfoErr != nil {
-			errorMessageResponse(w, userInfoErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, userInfoErr)
+			return
+		}
+
+		resultResponse(w, userInfo, accepted(r), http.StatusOK)
+	}
+}
+
+// SetUserKey - register fingerprint of client public key method
+//
+// Handler PUT /api/v1/users/me/key
+//
+// Authorization: "Bearer T"
+//
+//	"fingerprint": "<fingerprint of public key>",
+//	"previous": "<registered fingerprint, required to change it after keys rotation>"
+//
+// Possible response codes:
+// 200 - fingerprint successfully registered;
+// 400 - invalid request format;
+// 401 - invalid auth;
+// 409 - another fingerprint is registered and previous one doesnt match it;
+// 500 - an internal server error.
+func SetUserKey(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var userKey models.UserKey
+		readBodyErr := readBodyInStruct(r, &userKey)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		setErr := keeper.SetUserKey(userID, &userKey)
+		if setErr != nil {
+			serviceErrorResponse(w, r, setErr)
 			return
 		}
 
-		userInfo.Password = ""
-		resultResponse(w, userInfo, "application/json", http.StatusOK)
+		resultResponse(w, "successful registered", accepted(r), http.StatusOK)
 	}
 }
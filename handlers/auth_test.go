@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"AlexSarva/GophKeeper/constant"
+	"AlexSarva/GophKeeper/events"
 	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/service"
 	"AlexSarva/GophKeeper/utils"
 	"bytes"
 	"fmt"
@@ -36,7 +38,7 @@ func TestUserAuth(t *testing.T) {
 		Email:    fmt.Sprintf("%s@gmail.com", utils.LoginGenerator(7)),
 		Password: "dPQzaKPD99v",
 	}
-	user, addUserErr := database.Authorizer.SignUp(tmpUser)
+	user, addUserErr := database.Authorizer.SignUp(tmpUser, &models.SessionClient{})
 	if addUserErr != nil {
 		log.Println(addUserErr)
 	}
@@ -224,7 +226,7 @@ func TestUserAuth(t *testing.T) {
 		},
 	}
 	//var token string
-	Handler := *CustomHandler(&database)
+	Handler := *CustomHandler(&database, service.NewService(&database, events.NewBus()))
 	ts := httptest.NewServer(&Handler)
 
 	defer ts.Close()
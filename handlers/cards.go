@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
-	"AlexSarva/GophKeeper/storage"
-	"errors"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -15,44 +14,42 @@ import (
 //
 // Handler POST /api/v1/info/cards
 //
+//	"id": "<id generated by client>",
 //	"title": "<title>",
 //	"card_number": "<card_number>",
 //	"card_owner": "<card_owner>",
 //	"card_exp": "<card_exp>",
-//	"notes": "<notes>"
+//	"notes": "<notes>",
+//	"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - credit card successfully added;
 // 400 - invalid request format;
 // 401 - problem from authentication;
+// 409 - credit card with such id already exists;
 // 500 - an internal server error.
-func PostCard(database *app.Storage) http.HandlerFunc {
+func PostCard(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var card models.NewCard
 		readBodyErr := readBodyInStruct(r, &card)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
-			return
-		}
-		card.UserID = userID
-		if card.CardNumber == "" || card.CardOwner == "" || card.CardExp == "" {
-			errorMessageResponse(w, "empty fields error", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		newCard, newCardErr := database.Database.NewCard(&card)
+		newCard, newCardErr := keeper.NewCard(userID, &card)
 		if newCardErr != nil {
-			errorMessageResponse(w, newCardErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newCardErr)
 			return
 		}
 
-		resultResponse(w, newCard, "application/json", http.StatusCreated)
+		resultResponse(w, newCard, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -66,26 +63,27 @@ func PostCard(database *app.Storage) http.HandlerFunc {
 // 400 - invalid request format;
 // 401 - problem from authentication;
 // 500 - an internal server error.
-func GetCardList(database *app.Storage) http.HandlerFunc {
+func GetCardList(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		cards, notesErr := database.Database.AllCards(userID)
-		if notesErr != nil {
-			errorMessageResponse(w, notesErr.Error(), "application/json", http.StatusInternalServerError)
+		cards, cardsErr := keeper.Cards(userID)
+		if cardsErr != nil {
+			serviceErrorResponse(w, r, cardsErr)
 			return
 		}
+		cards = permittedItems(ctx, cards, func(elem models.Card) uuid.UUID { return elem.ID })
 		if len(cards) == 0 {
-			errorMessageResponse(w, "no values", "application/json", http.StatusNoContent)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
-		resultResponse(w, cards, "application/json", http.StatusOK)
+		resultResponse(w, cards, accepted(r), http.StatusOK)
 	}
 }
 
@@ -98,35 +96,29 @@ func GetCardList(database *app.Storage) http.HandlerFunc {
 // 204 - no values in database;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such credit card in database;
+// 404 - no such credit card in database;
 // 500 - an internal server error.
-func GetCard(database *app.Storage) http.HandlerFunc {
+func GetCard(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		cardIDStr := chi.URLParam(r, "id")
-		cardUUID, cardUUIDErr := uuid.Parse(cardIDStr)
+		cardUUID, cardUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if cardUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		card, notesErr := database.Database.GetCard(cardUUID, userID)
-		if notesErr != nil {
-			if errors.Is(notesErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, notesErr.Error(), "application/json", http.StatusInternalServerError)
+		card, cardErr := keeper.Card(userID, cardUUID)
+		if cardErr != nil {
+			serviceErrorResponse(w, r, cardErr)
 			return
 		}
-		resultResponse(w, card, "application/json", http.StatusOK)
+		resultResponse(w, card, accepted(r), http.StatusOK)
 	}
 }
 
@@ -138,82 +130,45 @@ func GetCard(database *app.Storage) http.HandlerFunc {
 //	"card_number": "<card_number>",
 //	"card_owner": "<card_owner>",
 //	"card_exp": "<card_exp>",
-//	"notes": "<notes>"
+//	"notes": "<notes>",
+//	"version": <current version + 1>,
+//	"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - credit card information successfully changed;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such credit card in database;
+// 404 - no such credit card in database;
+// 409 - version conflict;
 // 500 - an internal server error.
-func EditCard(database *app.Storage) http.HandlerFunc {
+func EditCard(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var editCard models.NewCard
 		readBodyErr := readBodyInStruct(r, &editCard)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		cardIDStr := chi.URLParam(r, "id")
-		cardUUID, cardUUIDErr := uuid.Parse(cardIDStr)
+		cardUUID, cardUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if cardUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
-			return
-		}
-
-		card, notesErr := database.Database.GetCard(cardUUID, userID)
-		if notesErr != nil {
-			if errors.Is(notesErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such card in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, notesErr.Error(), "application/json", http.StatusInternalServerError)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		if editCard.Title == "" {
-			editCard.Title = card.Title
-		}
-
-		if editCard.CardNumber == "" {
-			editCard.CardNumber = card.CardNumber
-		}
-
-		if editCard.CardOwner == "" {
-			editCard.CardOwner = card.CardOwner
-		}
-
-		if editCard.CardExp == "" {
-			editCard.CardExp = card.CardExp
-		}
-
-		if editCard.Notes == "" {
-			editCard.Notes = card.Notes
-		}
-
-		editCard.ID = card.ID
-		editCard.UserID = userID
-
-		newCard, newCardErr := database.Database.EditCard(editCard)
+		newCard, newCardErr := keeper.EditCard(userID, cardUUID, editCard)
 		if newCardErr != nil {
-			if errors.Is(newCardErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such card in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, newCardErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newCardErr)
 			return
 		}
 
-		resultResponse(w, newCard, "application/json", http.StatusCreated)
+		resultResponse(w, newCard, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -225,34 +180,29 @@ func EditCard(database *app.Storage) http.HandlerFunc {
 // 200 - successful deleted;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such credit card in database;
+// 404 - no such credit card in database;
 // 500 - an internal server error.
-func DeleteCard(database *app.Storage) http.HandlerFunc {
+func DeleteCard(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		cardIDStr := chi.URLParam(r, "id")
-		cardUUID, cardUUIDErr := uuid.Parse(cardIDStr)
+		cardUUID, cardUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if cardUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		delErr := database.Database.DeleteCard(cardUUID, userID)
+		delErr := keeper.DeleteCard(userID, cardUUID)
 		if delErr != nil {
-			if errors.Is(delErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-			errorMessageResponse(w, delErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, delErr)
 			return
 		}
 
-		resultResponse(w, "successful deleted", "application/json", http.StatusOK)
+		resultResponse(w, "successful deleted", accepted(r), http.StatusOK)
 	}
 }
@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
-	"AlexSarva/GophKeeper/storage"
-	"errors"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -15,44 +14,41 @@ import (
 //
 // Handler POST /api/v1/info/creds
 //
+//	"id": "<id generated by client>",
 //	"title": "<title>",
 //	"login": "<login>",
-//	"password": "<password>",
-//	"notes": "<notes>"
+//	"passwd": "<password>",
+//	"notes": "<notes>",
+//	"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - credential successfully added;
 // 400 - invalid request format;
 // 401 - problem from authentication;
+// 409 - credential with such id already exists;
 // 500 - an internal server error.
-func PostCred(database *app.Storage) http.HandlerFunc {
+func PostCred(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var cred models.NewCred
 		readBodyErr := readBodyInStruct(r, &cred)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
-		cred.UserID = userID
 
-		if cred.Login == "" || cred.Passwd == "" {
-			errorMessageResponse(w, "empty fields error", "application/json", http.StatusBadRequest)
-			return
-		}
-
-		newCred, newCredErr := database.Database.NewCred(&cred)
+		newCred, newCredErr := keeper.NewCred(userID, &cred)
 		if newCredErr != nil {
-			errorMessageResponse(w, newCredErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newCredErr)
 			return
 		}
 
-		resultResponse(w, newCred, "application/json", http.StatusCreated)
+		resultResponse(w, newCred, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -66,26 +62,27 @@ func PostCred(database *app.Storage) http.HandlerFunc {
 // 400 - invalid request format;
 // 401 - problem from authentication;
 // 500 - an internal server error.
-func GetCredList(database *app.Storage) http.HandlerFunc {
+func GetCredList(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		creds, credsErr := database.Database.AllCreds(userID)
+		creds, credsErr := keeper.Creds(userID)
 		if credsErr != nil {
-			errorMessageResponse(w, credsErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, credsErr)
 			return
 		}
+		creds = permittedItems(ctx, creds, func(elem models.Cred) uuid.UUID { return elem.ID })
 		if len(creds) == 0 {
-			errorMessageResponse(w, "no values", "application/json", http.StatusNoContent)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
-		resultResponse(w, creds, "application/json", http.StatusOK)
+		resultResponse(w, creds, accepted(r), http.StatusOK)
 	}
 }
 
@@ -98,35 +95,29 @@ func GetCredList(database *app.Storage) http.HandlerFunc {
 // 204 - no values in database;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such credential in database;
+// 404 - no such credential in database;
 // 500 - an internal server error.
-func GetCred(database *app.Storage) http.HandlerFunc {
+func GetCred(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		credIDStr := chi.URLParam(r, "id")
-		credUUID, credUUIDErr := uuid.Parse(credIDStr)
+		credUUID, credUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if credUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		cred, credErr := database.Database.GetCred(credUUID, userID)
+		cred, credErr := keeper.Cred(userID, credUUID)
 		if credErr != nil {
-			if errors.Is(credErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, credErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, credErr)
 			return
 		}
-		resultResponse(w, cred, "application/json", http.StatusOK)
+		resultResponse(w, cred, accepted(r), http.StatusOK)
 	}
 }
 
@@ -136,79 +127,46 @@ func GetCred(database *app.Storage) http.HandlerFunc {
 //
 //	"title": "<title>",
 //	"login": "<login>",
-//	"password": "<password>",
-//	"notes": "<notes>"
+//	"passwd": "<password>",
+//	"notes": "<notes>",
+//	"version": <current version + 1>,
+//	"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - credential information successfully changed;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such credential in database;
+// 404 - no such credential in database;
+// 409 - version conflict;
 // 500 - an internal server error.
-func EditCred(database *app.Storage) http.HandlerFunc {
+func EditCred(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var editCred models.NewCred
 		readBodyErr := readBodyInStruct(r, &editCred)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		credIDStr := chi.URLParam(r, "id")
-		credUUID, credUUIDErr := uuid.Parse(credIDStr)
+		credUUID, credUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if credUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
-			return
-		}
-
-		cred, credErr := database.Database.GetCred(credUUID, userID)
-		if credErr != nil {
-			if errors.Is(credErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such cred in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, credErr.Error(), "application/json", http.StatusInternalServerError)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		if editCred.Title == "" {
-			editCred.Title = cred.Title
-		}
-
-		if editCred.Login == "" {
-			editCred.Login = cred.Login
-		}
-
-		if editCred.Passwd == "" {
-			editCred.Passwd = cred.Passwd
-		}
-
-		if editCred.Notes == "" {
-			editCred.Notes = cred.Notes
-		}
-
-		editCred.ID = cred.ID
-		editCred.UserID = userID
-
-		newCred, newCredErr := database.Database.EditCred(editCred)
+		newCred, newCredErr := keeper.EditCred(userID, credUUID, editCred)
 		if newCredErr != nil {
-			if errors.Is(newCredErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such cred in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, newCredErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newCredErr)
 			return
 		}
 
-		resultResponse(w, newCred, "application/json", http.StatusCreated)
+		resultResponse(w, newCred, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -220,34 +178,29 @@ func EditCred(database *app.Storage) http.HandlerFunc {
 // 200 - successful deleted;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such credential in database;
+// 404 - no such credential in database;
 // 500 - an internal server error.
-func DeleteCred(database *app.Storage) http.HandlerFunc {
+func DeleteCred(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		credIDStr := chi.URLParam(r, "id")
-		credUUID, credUUIDErr := uuid.Parse(credIDStr)
+		credUUID, credUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if credUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		delErr := database.Database.DeleteCred(credUUID, userID)
+		delErr := keeper.DeleteCred(userID, credUUID)
 		if delErr != nil {
-			if errors.Is(delErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-			errorMessageResponse(w, delErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, delErr)
 			return
 		}
 
-		resultResponse(w, "successful deleted", "application/json", http.StatusOK)
+		resultResponse(w, "successful deleted", accepted(r), http.StatusOK)
 	}
 }
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"net/http"
+)
+
+// VerifyEmail - confirm email of user method
+//
+// Handler POST /api/v1/email/verify
+//
+// Request format:
+//
+//	{"token": "<code from message>"}
+//
+// Possible response codes:
+// 200 - email successfully confirmed;
+// 400 - invalid request format;
+// 417 - code is invalid, expired or already used;
+// 500 - an internal server error.
+func VerifyEmail(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var verification models.EmailVerification
+		readBodyErr := readBodyInStruct(r, &verification)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		verifyErr := keeper.VerifyEmail(&verification)
+		if verifyErr != nil {
+			serviceErrorResponse(w, r, verifyErr)
+			return
+		}
+
+		resultResponse(w, "email confirmed", accepted(r), http.StatusOK)
+	}
+}
+
+// ResendVerification - send new code that confirms email of user method
+//
+// Handler POST /api/v1/users/me/email/verify
+//
+// Authorization: "Bearer T"
+//
+// Possible response codes:
+// 202 - message with code is sent;
+// 401 - invalid auth;
+// 409 - email is already confirmed;
+// 429 - message is sent recently, request could be repeated after Retry-After seconds;
+// 500 - an internal server error.
+func ResendVerification(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		sendErr := keeper.ResendVerification(userID)
+		if sendErr != nil {
+			serviceErrorResponse(w, r, sendErr)
+			return
+		}
+
+		resultResponse(w, "message with code is sent", accepted(r), http.StatusAccepted)
+	}
+}
+
+// ForgotPassword - send code of password reset method
+//
+// Handler POST /api/v1/password/forgot
+//
+// Request format:
+//
+//	{"email": "<email of user>"}
+//
+// Response is the same whether there is such user or not.
+//
+// Possible response codes:
+// 202 - message with code is sent if there is such user;
+// 400 - invalid request format;
+// 417 - email is invalid;
+// 500 - an internal server error.
+func ForgotPassword(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var forgot models.PasswordForgot
+		readBodyErr := readBodyInStruct(r, &forgot)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		forgotErr := keeper.ForgotPassword(&forgot)
+		if forgotErr != nil {
+			serviceErrorResponse(w, r, forgotErr)
+			return
+		}
+
+		resultResponse(w, "message with code is sent if there is such user", accepted(r), http.StatusAccepted)
+	}
+}
+
+// ResetPassword - set new password by code of password reset method
+//
+// Handler POST /api/v1/password/reset
+//
+// Request format:
+//
+//	{"token": "<code from message>",
+//	"new_password": "<new password>"}
+//
+// All sessions of user are ended.
+//
+// Possible response codes:
+// 200 - password successfully changed;
+// 400 - invalid request format;
+// 409 - password is changed meanwhile;
+// 417 - code is invalid, expired or already used, or new password is too weak;
+// 500 - an internal server error.
+func ResetPassword(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var reset models.PasswordReset
+		readBodyErr := readBodyInStruct(r, &reset)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		resetErr := keeper.ResetPassword(&reset)
+		if resetErr != nil {
+			serviceErrorResponse(w, r, resetErr)
+			return
+		}
+
+		resultResponse(w, "password changed", accepted(r), http.StatusOK)
+	}
+}
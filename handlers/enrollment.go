@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PostEnrollment - start enrollment of new device method
+//
+// Handler POST /api/v1/enrollments
+//
+//	"device_public": "<ephemeral X25519 public key of new device in base64>"
+//
+// Returns enrollment with code that user enters on approving device.
+//
+// Possible response codes:
+// 201 - enrollment successfully started;
+// 400 - invalid request format;
+// 401 - problem from authentication;
+// 409 - enrollment with such key already exists;
+// 500 - an internal server error.
+func PostEnrollment(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var newEnrollment models.NewEnrollment
+		readBodyErr := readBodyInStruct(r, &newEnrollment)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		enrollment, enrollmentErr := keeper.StartEnrollment(userID, &newEnrollment)
+		if enrollmentErr != nil {
+			serviceErrorResponse(w, r, enrollmentErr)
+			return
+		}
+
+		resultResponse(w, enrollment, accepted(r), http.StatusCreated)
+	}
+}
+
+// GetEnrollment - get enrollment by code method
+//
+// Handler GET /api/v1/enrollments/{code}
+//
+// Approving device gets public key of new device,
+// new device polls it until sealed bundle appears.
+//
+// Possible response codes:
+// 200 - returns enrollment;
+// 401 - problem from authentication;
+// 404 - no such enrollment or it is expired;
+// 500 - an internal server error.
+func GetEnrollment(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		enrollment, enrollmentErr := keeper.Enrollment(userID, chi.URLParam(r, "code"))
+		if enrollmentErr != nil {
+			serviceErrorResponse(w, r, enrollmentErr)
+			return
+		}
+
+		resultResponse(w, enrollment, accepted(r), http.StatusOK)
+	}
+}
+
+// ApproveEnrollment - approve enrollment method
+//
+// Handler PUT /api/v1/enrollments/{code}
+//
+//	"bundle": "<key bundle sealed for public key of new device in base64>"
+//
+// Possible response codes:
+// 200 - enrollment successfully approved;
+// 400 - invalid request format;
+// 401 - problem from authentication;
+// 404 - no such enrollment, it is expired or already approved;
+// 500 - an internal server error.
+func ApproveEnrollment(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var approve models.EnrollmentApprove
+		readBodyErr := readBodyInStruct(r, &approve)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		approveErr := keeper.ApproveEnrollment(userID, chi.URLParam(r, "code"), &approve)
+		if approveErr != nil {
+			serviceErrorResponse(w, r, approveErr)
+			return
+		}
+
+		resultResponse(w, "successful approved", accepted(r), http.StatusOK)
+	}
+}
+
+// DeleteEnrollment - delete enrollment method
+//
+// Handler DELETE /api/v1/enrollments/{code}
+//
+// Possible response codes:
+// 200 - successful deleted;
+// 401 - problem from authentication;
+// 404 - no such enrollment;
+// 500 - an internal server error.
+func DeleteEnrollment(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		delErr := keeper.DeleteEnrollment(userID, chi.URLParam(r, "code"))
+		if delErr != nil {
+			serviceErrorResponse(w, r, delErr)
+			return
+		}
+
+		resultResponse(w, "successful deleted", accepted(r), http.StatusOK)
+	}
+}
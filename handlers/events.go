@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// eventsPing interval of comments that keep stream alive through proxies
+	eventsPing = 20 * time.Second
+	// eventsLifetime stream is closed before timeouts of server, clients reconnect after retry delay
+	eventsLifetime = 50 * time.Second
+	// eventsRetry reconnect delay of clients in milliseconds
+	eventsRetry = 2000
+)
+
+// GetEvents - stream of element changes by Server-Sent Events
+//
+// Handler GET /api/v1/events
+//
+// Every event has name of action and JSON data:
+//
+//	event: created|updated|deleted
+//	data: {"action": "<action>", "kind": "notes|cards|creds|files|vault", "id": "<id>", "time": "<time>"}
+//
+// Possible response codes:
+// 200 - stream of events;
+// 401 - problem from authentication;
+// 500 - streaming is not supported.
+func GetEvents(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			internalErrorResponse(w, r, ErrStreaming)
+			return
+		}
+
+		changes, cancel := keeper.Subscribe(userID)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if _, writeErr := fmt.Fprintf(w, "retry: %d\n\n", eventsRetry); writeErr != nil {
+			return
+		}
+		flusher.Flush()
+
+		ping := time.NewTicker(eventsPing)
+		defer ping.Stop()
+		lifetime := time.NewTimer(eventsLifetime)
+		defer lifetime.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-lifetime.C:
+				return
+			case <-ping.C:
+				if _, writeErr := fmt.Fprint(w, ": ping\n\n"); writeErr != nil {
+					return
+				}
+			case event, open := <-changes:
+				if !open {
+					return
+				}
+				data, marshalErr := json.Marshal(event)
+				if marshalErr != nil {
+					log.Println(marshalErr)
+					continue
+				}
+				if _, writeErr := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Action, data); writeErr != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
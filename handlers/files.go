@@ -1,18 +1,22 @@
 package handlers
 
 import (
-	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
-	"AlexSarva/GophKeeper/storage"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
+var ErrFileTooLarge = fmt.Errorf("file is larger than %d bytes", models.MaxFileSize)
+
 // PostFile - add file method
 //
 // Handler POST /api/v1/info/files
@@ -20,42 +24,39 @@ import (
 //		"title": "<title>",
 //		"file_name": "<file_name>",
 //	 "file": <binary file content>",
-//		"notes": "<note>"
+//		"notes": "<note>",
+//		"id": "<id generated by client>",
+//		"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - file successfully added;
 // 400 - invalid request format;
 // 401 - problem from authentication;
+// 409 - file with such id already exists;
+// 413 - file is larger than models.MaxFileSize;
 // 500 - an internal server error.
-func PostFile(database *app.Storage) http.HandlerFunc {
+func PostFile(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		title := r.URL.Query().Get("title")
 		if title == "" {
-			errorMessageResponse(w, "dont have parameter 'title' in request", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "dont have parameter 'title' in request")
 			return
 		}
 		filename := r.URL.Query().Get("filename")
 		if filename == "" {
-			errorMessageResponse(w, "dont have parameter 'filename' in request", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "dont have parameter 'filename' in request")
 			return
 		}
 		notes := r.URL.Query().Get("notes")
 		var file models.NewFile
-		defer func(Body io.ReadCloser) {
-			err := Body.Close()
-			if err != nil {
-				errorMessageResponse(w, err.Error(), "application/json", http.StatusBadRequest)
-				return
-			}
-		}(r.Body)
-		buf, err := io.ReadAll(r.Body)
-		if err != nil {
-			log.Fatal("request", err)
+		buf, ok := readFileBody(w, r)
+		if !ok {
+			return
 		}
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 		file.File = buf
@@ -64,15 +65,23 @@ func PostFile(database *app.Storage) http.HandlerFunc {
 		if notes != "" {
 			file.Notes = notes
 		}
-		file.UserID = userID
+		if idStr := r.URL.Query().Get("id"); idStr != "" {
+			fileUUID, fileUUIDErr := uuid.Parse(idStr)
+			if fileUUIDErr != nil {
+				errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
+				return
+			}
+			file.ID = fileUUID
+		}
+		file.Signature = r.URL.Query().Get("signature")
 
-		newFile, newFileErr := database.Database.NewFile(&file)
+		newFile, newFileErr := keeper.NewFile(userID, &file)
 		if newFileErr != nil {
-			errorMessageResponse(w, newFileErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newFileErr)
 			return
 		}
 
-		resultResponse(w, newFile, "application/json", http.StatusCreated)
+		resultResponse(w, newFile, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -86,26 +95,27 @@ func PostFile(database *app.Storage) http.HandlerFunc {
 // 400 - invalid request format;
 // 401 - problem from authentication;
 // 500 - an internal server error.
-func GetFileList(database *app.Storage) http.HandlerFunc {
+func GetFileList(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		files, filesErr := database.Database.AllFiles(userID)
+		files, filesErr := keeper.Files(userID)
 		if filesErr != nil {
-			errorMessageResponse(w, filesErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, filesErr)
 			return
 		}
+		files = permittedItems(ctx, files, func(elem models.File) uuid.UUID { return elem.ID })
 		if len(files) == 0 {
-			errorMessageResponse(w, "no values", "application/json", http.StatusNoContent)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
-		resultResponse(w, files, "application/json", http.StatusOK)
+		resultResponse(w, files, accepted(r), http.StatusOK)
 	}
 }
 
@@ -118,35 +128,30 @@ func GetFileList(database *app.Storage) http.HandlerFunc {
 // 204 - no values in database;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such file in database;
+// 404 - no such file in database;
 // 500 - an internal server error.
-func GetFile(database *app.Storage) http.HandlerFunc {
+func GetFile(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
 		fileIDStr := chi.URLParam(r, "id")
 		fileUUID, fileUUIDErr := uuid.Parse(fileIDStr)
 		if fileUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		file, fileErr := database.Database.GetFile(fileUUID, userID)
+		file, fileErr := keeper.File(userID, fileUUID)
 		if fileErr != nil {
-			if errors.Is(fileErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, fileErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, fileErr)
 			return
 		}
-		resultResponse(w, file, "application/json", http.StatusOK)
+		resultResponse(w, file, accepted(r), http.StatusOK)
 	}
 }
 
@@ -157,38 +162,44 @@ func GetFile(database *app.Storage) http.HandlerFunc {
 //		"title": "<title>",
 //		"file_name": "<file_name>",
 //	 "file": <binary file content>",
-//		"notes": "<note>"
+//		"notes": "<note>",
+//		"version": <current version + 1>,
+//		"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - note information successfully changed;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such file in database;
+// 404 - no such file in database;
+// 409 - version conflict;
+// 413 - file is larger than models.MaxFileSize;
 // 500 - an internal server error.
-func EditFile(database *app.Storage) http.HandlerFunc {
+func EditFile(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		title := r.URL.Query().Get("title")
 		if title == "" {
-			errorMessageResponse(w, "dont have parameter 'title' in request", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "dont have parameter 'title' in request")
 			return
 		}
 		filename := r.URL.Query().Get("filename")
 		if filename == "" {
-			errorMessageResponse(w, "dont have parameter 'filename' in request", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "dont have parameter 'filename' in request")
 			return
 		}
 		notes := r.URL.Query().Get("notes")
-		var editFile models.NewFile
-		defer func(Body io.ReadCloser) {
-			err := Body.Close()
-			if err != nil {
-				errorMessageResponse(w, err.Error(), "application/json", http.StatusBadRequest)
+		var version int
+		if versionStr := r.URL.Query().Get("version"); versionStr != "" {
+			var versionErr error
+			version, versionErr = strconv.Atoi(versionStr)
+			if versionErr != nil {
+				errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "wrong parameter 'version' in request")
 				return
 			}
-		}(r.Body)
-		buf, err := io.ReadAll(r.Body)
-		if err != nil {
-			log.Fatal("request", err)
+		}
+		var editFile models.NewFile
+		buf, ok := readFileBody(w, r)
+		if !ok {
+			return
 		}
 		editFile.File = buf
 		editFile.Title = title
@@ -199,51 +210,27 @@ func EditFile(database *app.Storage) http.HandlerFunc {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
 		fileIDStr := chi.URLParam(r, "id")
 		fileUUID, fileUUIDErr := uuid.Parse(fileIDStr)
 		if fileUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		file, fileErr := database.Database.GetFile(fileUUID, userID)
-		if fileErr != nil {
-			if errors.Is(fileErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such file in db", "application/json", http.StatusConflict)
-				return
-			}
+		editFile.Version = version
+		editFile.Signature = r.URL.Query().Get("signature")
 
-			errorMessageResponse(w, fileErr.Error(), "application/json", http.StatusInternalServerError)
-			return
-		}
-
-		if editFile.File == nil {
-			editFile.File = file.File
-		}
-
-		if editFile.Notes == "" {
-			editFile.Notes = file.Notes
-		}
-
-		editFile.ID = file.ID
-		editFile.UserID = userID
-
-		newFile, newFileErr := database.Database.EditFile(&editFile)
+		newFile, newFileErr := keeper.EditFile(userID, fileUUID, &editFile)
 		if newFileErr != nil {
-			if errors.Is(newFileErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such file in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, newFileErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newFileErr)
 			return
 		}
 
-		resultResponse(w, newFile, "application/json", http.StatusCreated)
+		resultResponse(w, newFile, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -255,34 +242,51 @@ func EditFile(database *app.Storage) http.HandlerFunc {
 // 200 - successful deleted;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such file in database;
+// 404 - no such file in database;
 // 500 - an internal server error.
-func DeleteFile(database *app.Storage) http.HandlerFunc {
+func DeleteFile(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
 		fileIDStr := chi.URLParam(r, "id")
 		fileUUID, fileUUIDErr := uuid.Parse(fileIDStr)
 		if fileUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		delErr := database.Database.DeleteFile(fileUUID, userID)
+		delErr := keeper.DeleteFile(userID, fileUUID)
 		if delErr != nil {
-			if errors.Is(delErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-			errorMessageResponse(w, delErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, delErr)
 			return
 		}
 
-		resultResponse(w, "successful deleted", "application/json", http.StatusOK)
+		resultResponse(w, "successful deleted", accepted(r), http.StatusOK)
+	}
+}
+
+// readFileBody reads content of file from body of request, content is limited by models.MaxFileSize.
+// Error response is written if content is not read
+func readFileBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			log.Println(err)
+		}
+	}(r.Body)
+	buf, err := io.ReadAll(http.MaxBytesReader(w, r.Body, models.MaxFileSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			errorResponse(w, r, http.StatusRequestEntityTooLarge, problem.CodeFileTooLarge, ErrFileTooLarge.Error())
+			return nil, false
+		}
+		errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, err.Error())
+		return nil, false
 	}
+	return buf, true
 }
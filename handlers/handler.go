@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"AlexSarva/GophKeeper/codec"
 	"AlexSarva/GophKeeper/constant"
 	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,17 +27,15 @@ import (
 var (
 	ErrJSONWrite    = errors.New("problem in writing json")
 	ErrJSONRequest  = errors.New("wrong type provided for fields")
-	ErrLoginExist   = errors.New("login is already busy")
-	ErrUnauthorized = errors.New("user unauthorized")
+	ErrUnauthorized = service.ErrUnauthorized
+	ErrStreaming    = errors.New("streaming is not supported")
 )
 
-// errorMessageResponse additional respond generator
-// useful in case of error handling in outputting results to respond
-func errorMessageResponse(w http.ResponseWriter, message, ContentType string, httpStatusCode int) {
-	w.Header().Set("Content-Type", ContentType)
-	w.WriteHeader(httpStatusCode)
-	resp := map[string]string{"message": message}
-	jsonResp, jsonRespErr := json.Marshal(resp)
+// writeProblem writes problem details in respond
+func writeProblem(w http.ResponseWriter, p *problem.Problem) {
+	w.Header().Set("Content-Type", problem.ContentType)
+	w.WriteHeader(p.Status)
+	jsonResp, jsonRespErr := json.Marshal(p)
 	if jsonRespErr != nil {
 		log.Println(jsonRespErr)
 	}
@@ -42,22 +45,80 @@ func errorMessageResponse(w http.ResponseWriter, message, ContentType string, ht
 	}
 }
 
-// resultResponse additional result response generator
+// errorResponse responds with problem of code, request id is given by middleware.RequestID
+func errorResponse(w http.ResponseWriter, r *http.Request, httpStatusCode int, code, detail string) {
+	p := problem.New(httpStatusCode, code, detail)
+	p.Instance = r.URL.Path
+	p.RequestID = middleware.GetReqID(r.Context())
+	writeProblem(w, p)
+}
+
+// internalErrorResponse logs error and responds without its details
+func internalErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("request %s: %v", middleware.GetReqID(r.Context()), err)
+	errorResponse(w, r, http.StatusInternalServerError, problem.CodeInternal, "")
+}
+
+// accepted returns content type of response negotiated by Accept header of request
+func accepted(r *http.Request) string {
+	return codec.Negotiate(r.Header.Get("Accept")).ContentType()
+}
+
+// serviceErrorResponse responds with status code of service error kind and its code,
+// errors out of service catalogue are internal
+func serviceErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) || serviceErrorStatus(err) == http.StatusInternalServerError {
+		internalErrorResponse(w, r, err)
+		return
+	}
+	if retryAfter := serviceErr.RetryAfter(); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	errorResponse(w, r, serviceErrorStatus(err), serviceErr.Code(), serviceErr.Error())
+}
+
+// serviceErrorStatus maps kind of service error to http status code
+func serviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrRejected):
+		return http.StatusExpectationFailed
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrThrottled):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// resultResponse additional result response generator, data is encoded by content type
 func resultResponse(w http.ResponseWriter, data interface{}, ContentType string, httpStatusCode int) {
-	jsonResp, jsonRespErr := json.Marshal(data)
-	if jsonRespErr != nil {
-		errorMessageResponse(w, ErrJSONWrite.Error(), "application/json", http.StatusBadRequest)
+	resp, respErr := codec.ForContentType(ContentType).Marshal(data)
+	if respErr != nil {
+		log.Println(ErrJSONWrite, respErr)
+		writeProblem(w, problem.New(http.StatusInternalServerError, problem.CodeInternal, ""))
 		return
 	}
 	w.Header().Set("Content-Type", ContentType)
+	w.Header().Add("Vary", "Accept")
 	w.WriteHeader(httpStatusCode)
-	_, writeErr := w.Write(jsonResp)
+	_, writeErr := w.Write(resp)
 	if writeErr != nil {
 		log.Println("something wrong happens", writeErr)
 	}
 }
 
-// readBodyInStruct read compressed and usual request in struct
+// readBodyInStruct read compressed and usual request in struct,
+// body is decoded by its content type: JSON, MessagePack or CBOR
 func readBodyInStruct(r *http.Request, data interface{}) error {
 	// GZIP decode
 	var body io.ReadCloser
@@ -92,9 +153,7 @@ func readBodyInStruct(r *http.Request, data interface{}) error {
 	}
 
 	var unmarshalErr *json.UnmarshalTypeError
-	decoder := json.NewDecoder(body)
-	decoder.DisallowUnknownFields()
-	errDecode := decoder.Decode(&data)
+	errDecode := codec.ForContentType(r.Header.Get("Content-Type")).DecodeStrict(body, data)
 
 	if errDecode != nil {
 		if errors.Is(errDecode, unmarshalErr) {
@@ -106,7 +165,7 @@ func readBodyInStruct(r *http.Request, data interface{}) error {
 }
 
 // gzipContentTypes request types that support data compression
-var gzipContentTypes = "application/x-gzip, application/javascript, application/json, text/css, text/html, text/plain, text/xml"
+var gzipContentTypes = "application/x-gzip, application/javascript, application/json, application/msgpack, application/cbor, text/css, text/html, text/plain, text/xml"
 
 func customAllowOriginFunc(_ *http.Request, origin string) bool {
 	cfg := constant.GlobalContainer.Get("server-config").(models.ServerConfig)
@@ -129,19 +188,23 @@ func ping(w http.ResponseWriter, _ *http.Request) {
 
 // CustomHandler - the main_admin_test handler of the server
 // contains middlewares and all routes
-func CustomHandler(database *app.Storage) *chi.Mux {
+func CustomHandler(database *app.Storage, keeper *service.Service) *chi.Mux {
+	_, openAPIRouter, openAPIErr := loadOpenAPI()
+	if openAPIErr != nil {
+		log.Fatalln(openAPIErr)
+	}
 	r := chi.NewRouter()
 	r.Use(cors.Handler(cors.Options{
 		AllowOriginFunc: customAllowOriginFunc,
 		//AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", IdempotencyHeader, DeviceNameHeader, ClientVersionHeader},
+		ExposedHeaders:   []string{"Link", "Idempotent-Replayed", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(realIP(database.TrustedProxies))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
@@ -152,62 +215,98 @@ func CustomHandler(database *app.Storage) *chi.Mux {
 	r.Mount("/debug", middleware.Profiler())
 	//
 	r.Put("/ping", ping)
+	r.Get("/.well-known/jwks.json", GetJWKS(database))
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Post("/register", UserRegistration(database))
-		r.Post("/login", UserAuthentication(database))
+		r.Use(validateRequest(openAPIRouter))
+		r.Get("/openapi.json", GetOpenAPI())
+		r.Post("/register", UserRegistration(keeper))
+		r.Post("/login", UserAuthentication(keeper))
+		r.Post("/login/2fa", TwoFactorAuthentication(keeper))
+		r.Post("/token/refresh", RefreshToken(keeper))
+		r.Post("/email/verify", VerifyEmail(keeper))
+		r.Post("/password/forgot", ForgotPassword(keeper))
+		r.Post("/password/reset", ResetPassword(keeper))
+		r.With(userIdentification(keeper), idempotency(database.Idempotency, database.IdempotencyWindow)).
+			Post("/logout", Logout(keeper))
+		r.With(userIdentification(keeper), idempotency(database.Idempotency, database.IdempotencyWindow)).
+			Post("/logout/all", LogoutEverywhere(keeper))
 
 		r.Route("/users", func(r chi.Router) {
-			r.Use(userIdentification(database))
-			r.Get("/me", GetUserInfo(database))
+			r.Use(userIdentification(keeper))
+			r.Use(idempotency(database.Idempotency, database.IdempotencyWindow))
+			r.Get("/me", GetUserInfo(keeper))
+			r.Delete("/me", DeleteAccount(keeper))
+			r.Post("/me/password", ChangePassword(keeper))
+			r.Post("/me/email/verify", ResendVerification(keeper))
+			r.Put("/me/key", SetUserKey(keeper))
+			r.Get("/me/sessions", GetSessions(keeper))
+			r.Delete("/me/sessions/{id}", DeleteSession(keeper))
+			r.Get("/me/tokens", GetAccessTokens(keeper))
+			r.Post("/me/tokens", PostAccessToken(keeper))
+			r.Delete("/me/tokens/{id}", DeleteAccessToken(keeper))
+			r.Post("/me/2fa", SetupTwoFactor(keeper))
+			r.Post("/me/2fa/confirm", ConfirmTwoFactor(keeper))
+			r.Post("/me/2fa/disable", DisableTwoFactor(keeper))
+		})
+
+		r.With(userIdentification(keeper)).Get("/events", GetEvents(keeper))
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(trustedSubnet(database.TrustedSubnet))
+			r.Get("/lockouts", GetLockouts(keeper))
+			r.Delete("/lockouts/{id}", DeleteLockout(keeper))
+		})
+
+		r.Route("/enrollments", func(r chi.Router) {
+			r.Use(userIdentification(keeper))
+			r.Use(idempotency(database.Idempotency, database.IdempotencyWindow))
+			r.Post("/", PostEnrollment(keeper))
+			r.Get("/{code}", GetEnrollment(keeper))
+			r.Put("/{code}", ApproveEnrollment(keeper))
+			r.Delete("/{code}", DeleteEnrollment(keeper))
 		})
 
 		r.Route("/info", func(r chi.Router) {
-			r.Use(userIdentification(database))
+			r.Use(userIdentification(keeper))
+			r.Use(idempotency(database.Idempotency, database.IdempotencyWindow))
 			r.Route("/notes", func(r chi.Router) {
-				r.Get("/", GetNoteList(database))
-				r.Post("/", PostNote(database))
-				r.Get("/{id}", GetNote(database))
-				r.Patch("/{id}", EditNote(database))
-				r.Delete("/{id}", DeleteNote(database))
+				r.Get("/", GetNoteList(keeper))
+				r.Post("/", PostNote(keeper))
+				r.Get("/{id}", GetNote(keeper))
+				r.Patch("/{id}", EditNote(keeper))
+				r.Delete("/{id}", DeleteNote(keeper))
 			})
 			r.Route("/cards", func(r chi.Router) {
-				r.Get("/", GetCardList(database))
-				r.Post("/", PostCard(database))
-				r.Get("/{id}", GetCard(database))
-				r.Patch("/{id}", EditCard(database))
-				r.Delete("/{id}", DeleteCard(database))
+				r.Get("/", GetCardList(keeper))
+				r.Post("/", PostCard(keeper))
+				r.Get("/{id}", GetCard(keeper))
+				r.Patch("/{id}", EditCard(keeper))
+				r.Delete("/{id}", DeleteCard(keeper))
 			})
 			r.Route("/creds", func(r chi.Router) {
-				r.Get("/", GetCredList(database))
-				r.Post("/", PostCred(database))
-				r.Get("/{id}", GetCred(database))
-				r.Patch("/{id}", EditCred(database))
-				r.Delete("/{id}", DeleteCred(database))
+				r.Get("/", GetCredList(keeper))
+				r.Post("/", PostCred(keeper))
+				r.Get("/{id}", GetCred(keeper))
+				r.Patch("/{id}", EditCred(keeper))
+				r.Delete("/{id}", DeleteCred(keeper))
 			})
 			r.Route("/files", func(r chi.Router) {
-				r.Get("/", GetFileList(database))
-				r.Post("/", PostFile(database))
-				r.Get("/{id}", GetFile(database))
-				r.Patch("/{id}", EditFile(database))
-				r.Delete("/{id}", DeleteFile(database))
+				r.Get("/", GetFileList(keeper))
+				r.Post("/", PostFile(keeper))
+				r.Get("/{id}", GetFile(keeper))
+				r.Patch("/{id}", EditFile(keeper))
+				r.Delete("/{id}", DeleteFile(keeper))
 			})
+			r.Put("/vault", ReplaceVault(keeper))
 		})
 	})
 
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusBadRequest)
-		_, nfErr := w.Write([]byte("route does not exist"))
-		if nfErr != nil {
-			log.Println(nfErr)
-		}
+		errorResponse(w, r, http.StatusNotFound, problem.CodeRouteNotFound, "route does not exist")
 	})
 
 	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusBadRequest)
-		_, naErr := w.Write([]byte("sorry, this method are not allowed."))
-		if naErr != nil {
-			log.Println(naErr)
-		}
+		errorResponse(w, r, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "sorry, this method are not allowed.")
 	})
 	return r
 }
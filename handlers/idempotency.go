@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/storage"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// IdempotencyHeader header with key of request, retries of request with the same key replay its response
+const IdempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKey max length of idempotency key
+const maxIdempotencyKey = 255
+
+// maxBufferedBody max size of request body kept in memory after hashing, larger bodies as uploads
+// of files are spooled to temporary file
+const maxBufferedBody = 64 << 10
+
+var ErrIdempotencyKey = errors.New("idempotency key should be from 1 to 255 characters")
+
+// idempotency saves responses of POST and PATCH requests with idempotency key and replays them
+// on retries of requests, so timed out request could be sent again without second write.
+// Responses with 5xx codes are not saved, such requests are executed again
+func idempotency(store storage.Idempotency, window time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyHeader)
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKey {
+				errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, ErrIdempotencyKey.Error())
+				return
+			}
+			userID, userIDErr := getUserID(r.Context())
+			if userIDErr != nil {
+				errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+				return
+			}
+			hash, hashErr := requestHash(w, r)
+			if hashErr != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(hashErr, &tooLarge) {
+					errorResponse(w, r, http.StatusRequestEntityTooLarge, problem.CodeFileTooLarge, ErrFileTooLarge.Error())
+					return
+				}
+				errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, hashErr.Error())
+				return
+			}
+			if spooled, ok := r.Body.(*spooledBody); ok {
+				defer spooled.remove()
+			}
+
+			record := &models.IdempotentResponse{UserID: userID, Key: key, RequestHash: hash}
+			saved, reserveErr := store.ReserveIdempotencyKey(record, window)
+			if reserveErr != nil {
+				internalErrorResponse(w, r, reserveErr)
+				return
+			}
+			if saved != nil {
+				switch {
+				case saved.RequestHash != hash:
+					errorResponse(w, r, http.StatusUnprocessableEntity, problem.CodeIdempotencyReused, "idempotency key is used by another request")
+				case saved.Status == 0:
+					w.Header().Set("Retry-After", "1")
+					errorResponse(w, r, http.StatusConflict, problem.CodeRequestInProgress, "request with this idempotency key is in progress")
+				default:
+					replayResponse(w, saved)
+				}
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			// key is released if handler panics or fails, so request could be retried
+			defer func() {
+				if completed {
+					return
+				}
+				if releaseErr := store.ReleaseIdempotencyKey(userID, key); releaseErr != nil {
+					log.Println(releaseErr)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+			if recorder.status >= http.StatusInternalServerError {
+				return
+			}
+			record.Status = recorder.status
+			record.ContentType = recorder.Header().Get("Content-Type")
+			record.Body = recorder.body.Bytes()
+			if completeErr := store.CompleteIdempotencyKey(record); completeErr != nil {
+				log.Println(completeErr)
+				return
+			}
+			completed = true
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// requestHash returns hash of method, URI, negotiated type of response and body of request,
+// so saved response is not replayed in another encoding. Body is hashed while it is read,
+// it is limited by models.MaxFileSize and restored for handlers from memory or temporary file
+func requestHash(w http.ResponseWriter, r *http.Request) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n" + accepted(r) + "\n"))
+	body := io.TeeReader(http.MaxBytesReader(w, r.Body, models.MaxFileSize), h)
+	var head bytes.Buffer
+	n, readErr := io.CopyN(&head, body, maxBufferedBody+1)
+	if readErr != nil && readErr != io.EOF {
+		return "", readErr
+	}
+	if n <= maxBufferedBody {
+		r.Body = io.NopCloser(&head)
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	spooled, spoolErr := spoolBody(io.MultiReader(&head, body))
+	if spoolErr != nil {
+		return "", spoolErr
+	}
+	r.Body = spooled
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// spooledBody body of request in temporary file, file is removed after request is handled
+type spooledBody struct {
+	*os.File
+}
+
+// remove closes temporary file, if handler has not closed it, and removes it
+func (b *spooledBody) remove() {
+	if closeErr := b.File.Close(); closeErr != nil && !errors.Is(closeErr, os.ErrClosed) {
+		log.Println(closeErr)
+	}
+	if removeErr := os.Remove(b.Name()); removeErr != nil {
+		log.Println(removeErr)
+	}
+}
+
+// spoolBody writes body to temporary file and returns it from start
+func spoolBody(body io.Reader) (*spooledBody, error) {
+	f, createErr := os.CreateTemp("", "keeper-request-*")
+	if createErr != nil {
+		return nil, createErr
+	}
+	spooled := &spooledBody{File: f}
+	if _, copyErr := io.Copy(f, body); copyErr != nil {
+		spooled.remove()
+		return nil, copyErr
+	}
+	if _, seekErr := f.Seek(0, io.SeekStart); seekErr != nil {
+		spooled.remove()
+		return nil, seekErr
+	}
+	return spooled, nil
+}
+
+// replayResponse writes saved response again
+func replayResponse(w http.ResponseWriter, saved *models.IdempotentResponse) {
+	if saved.ContentType != "" {
+		w.Header().Set("Content-Type", saved.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(saved.Status)
+	if _, writeErr := w.Write(saved.Body); writeErr != nil {
+		log.Println("something wrong happens", writeErr)
+	}
+}
+
+// responseRecorder passes response to client and keeps its status and body
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	rr.status = statusCode
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotency keeps records of idempotency keys in memory
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	records map[string]models.IdempotentResponse
+}
+
+func (m *memoryIdempotency) ReserveIdempotencyKey(record *models.IdempotentResponse, _ time.Duration) (*models.IdempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := record.UserID.String() + record.Key
+	if saved, ok := m.records[id]; ok {
+		return &saved, nil
+	}
+	m.records[id] = *record
+	return nil, nil
+}
+
+func (m *memoryIdempotency) CompleteIdempotencyKey(record *models.IdempotentResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[record.UserID.String()+record.Key] = *record
+	return nil
+}
+
+func (m *memoryIdempotency) ReleaseIdempotencyKey(userID uuid.UUID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, userID.String()+key)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	store := &memoryIdempotency{records: make(map[string]models.IdempotentResponse)}
+	writes := 0
+	status := http.StatusCreated
+	handler := idempotency(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writes++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		method   string
+		key      string
+		body     string
+		accept   string
+		status   int
+		code     int
+		writes   int
+		replayed bool
+	}{
+		{name: "without key", method: http.MethodPost, body: "a", code: http.StatusCreated, writes: 1},
+		{name: "first request", method: http.MethodPost, key: "k1", body: "a", code: http.StatusCreated, writes: 2},
+		{name: "retry", method: http.MethodPost, key: "k1", body: "a", code: http.StatusCreated, writes: 2, replayed: true},
+		{name: "another body", method: http.MethodPost, key: "k1", body: "b", code: http.StatusUnprocessableEntity, writes: 2},
+		{name: "another accept", method: http.MethodPost, key: "k1", body: "a", accept: "application/cbor", code: http.StatusUnprocessableEntity, writes: 2},
+		{name: "get is not saved", method: http.MethodGet, key: "k1", code: http.StatusCreated, writes: 3},
+		{name: "failed request", method: http.MethodPatch, key: "k2", body: "a", status: http.StatusInternalServerError, code: http.StatusInternalServerError, writes: 4},
+		{name: "retry of failed request", method: http.MethodPatch, key: "k2", body: "a", code: http.StatusCreated, writes: 5},
+		{name: "long key", method: http.MethodPost, key: strings.Repeat("k", maxIdempotencyKey+1), code: http.StatusBadRequest, writes: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = http.StatusCreated
+			if tt.status != 0 {
+				status = tt.status
+			}
+			request := httptest.NewRequest(tt.method, "/api/v1/info/notes", strings.NewReader(tt.body))
+			request = request.WithContext(context.WithValue(request.Context(), keyPrincipalID, userID))
+			if tt.key != "" {
+				request.Header.Set(IdempotencyHeader, tt.key)
+			}
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.writes, writes)
+			if tt.replayed {
+				assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+				assert.JSONEq(t, `{"id": 1}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := &memoryIdempotency{records: make(map[string]models.IdempotentResponse)}
+	userID := uuid.New()
+	_, reserveErr := store.ReserveIdempotencyKey(&models.IdempotentResponse{UserID: userID, Key: "k", RequestHash: mustHash(t, "a")}, time.Hour)
+	require.NoError(t, reserveErr)
+
+	handler := idempotency(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request in progress is executed again")
+	}))
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/info/notes", strings.NewReader("a"))
+	request = request.WithContext(context.WithValue(request.Context(), keyPrincipalID, userID))
+	request.Header.Set(IdempotencyHeader, "k")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
+
+func TestRequestHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		spooled bool
+		err     bool
+	}{
+		{name: "small body", size: 10},
+		{name: "max buffered body", size: maxBufferedBody},
+		{name: "upload", size: maxBufferedBody + 1, spooled: true},
+		{name: "too large", size: models.MaxFileSize + 1, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.Repeat("a", tt.size)
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/info/notes", strings.NewReader(body))
+			hash, hashErr := requestHash(httptest.NewRecorder(), request)
+			if tt.err {
+				var tooLarge *http.MaxBytesError
+				assert.ErrorAs(t, hashErr, &tooLarge)
+				return
+			}
+			require.NoError(t, hashErr)
+			spooled, ok := request.Body.(*spooledBody)
+			assert.Equal(t, tt.spooled, ok)
+			restored, readErr := io.ReadAll(request.Body)
+			require.NoError(t, readErr)
+			assert.Equal(t, body, string(restored))
+			assert.Equal(t, mustHash(t, body), hash)
+			if ok {
+				spooled.remove()
+				_, statErr := os.Stat(spooled.Name())
+				assert.ErrorIs(t, statErr, os.ErrNotExist)
+			}
+		})
+	}
+}
+
+func mustHash(t *testing.T, body string) string {
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/info/notes", strings.NewReader(body))
+	hash, hashErr := requestHash(httptest.NewRecorder(), request)
+	require.NoError(t, hashErr)
+	if spooled, ok := request.Body.(*spooledBody); ok {
+		spooled.remove()
+	}
+	return hash
+}
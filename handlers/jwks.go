@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/internal/app"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// jwksMaxAge time while clients could cache JWKS, new keys are published longer before they sign tokens
+const jwksMaxAge = 15 * time.Minute
+
+// GetJWKS - public keys of JWT signatures method
+//
+// Handler GET /.well-known/jwks.json
+//
+// Returns JSON Web Key Set (RFC 7517) with keys that verify tokens of service and keys
+// that will sign tokens soon, so other services verify tokens without shared secret.
+//
+// Possible response codes:
+// 200 - returns key set.
+func GetJWKS(database *app.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, marshalErr := json.Marshal(database.Authorizer.KeyRing().JWKS())
+		if marshalErr != nil {
+			internalErrorResponse(w, r, marshalErr)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+		w.WriteHeader(http.StatusOK)
+		if _, writeErr := w.Write(resp); writeErr != nil {
+			log.Println("something wrong happens", writeErr)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetLockouts - lockouts of logins method
+//
+// Handler GET /api/v1/admin/lockouts
+//
+// Available only from trusted subnet. Returns recent lockouts of logins from addresses or to accounts,
+// query parameter active=true returns only lockouts that are not cleared and not expired.
+//
+// Possible response codes:
+// 200 - lockouts, the newest are the first;
+// 400 - invalid request format;
+// 403 - request is not from trusted subnet;
+// 500 - an internal server error.
+func GetLockouts(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		active := r.URL.Query().Get("active") == "true"
+
+		lockouts, lockoutsErr := keeper.Lockouts(active)
+		if lockoutsErr != nil {
+			serviceErrorResponse(w, r, lockoutsErr)
+			return
+		}
+
+		resultResponse(w, lockouts, accepted(r), http.StatusOK)
+	}
+}
+
+// DeleteLockout - clear lockout method
+//
+// Handler DELETE /api/v1/admin/lockouts/{id}
+//
+// Available only from trusted subnet. Clears lockout and failed attempts of its address or account,
+// so logins are allowed at once.
+//
+// Possible response codes:
+// 200 - lockout is cleared;
+// 400 - invalid request format;
+// 403 - request is not from trusted subnet;
+// 404 - no such lockout that is not cleared;
+// 500 - an internal server error.
+func DeleteLockout(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, idErr := uuid.Parse(chi.URLParam(r, "id"))
+		if idErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
+			return
+		}
+
+		clearErr := keeper.ClearLockout(id)
+		if clearErr != nil {
+			serviceErrorResponse(w, r, clearErr)
+			return
+		}
+
+		resultResponse(w, "successful cleared", accepted(r), http.StatusOK)
+	}
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
-	"AlexSarva/GophKeeper/internal/app"
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
 	"AlexSarva/GophKeeper/utils"
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,6 +24,8 @@ type JWTUserID string
 
 const (
 	keyPrincipalID JWTUserID = "user.id"
+	keySessionID   JWTUserID = "session.id"
+	keyAccessToken JWTUserID = "access.token"
 )
 
 // checkContent checking content-length and content-type in basic methods of requests
@@ -31,24 +36,22 @@ func checkContent(next http.Handler) http.Handler {
 			if len(headerContentType) != 0 {
 				contentLength, contentLengthErr := strconv.Atoi(headerContentType)
 				if contentLengthErr != nil {
-					errorMessageResponse(w, "problem in Content-Length", "application/json", http.StatusBadRequest)
+					errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "problem in Content-Length")
 					return
 				}
 				if contentLength != 0 {
-					errorMessageResponse(w, "content-length is not equal 0", "application/json", http.StatusBadRequest)
+					errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "content-length is not equal 0")
 					return
 				}
 			}
 		}
 
-		log.Println(r.Header)
-
 		splittedPath := strings.Split(r.URL.Path, "/")
 		lastElems := splittedPath[len(splittedPath)-2:]
 		if (r.Method == "POST" || r.Method == "PATCH") && !utils.StringInSlice("files", lastElems) {
 			headerContentType := r.Header.Get("Content-Type")
-			if !strings.Contains("application/json, application/x-gzip", headerContentType) {
-				errorMessageResponse(w, "Content Type is not application/json or application/x-gzip", "application/json", http.StatusBadRequest)
+			if !strings.Contains("application/json, application/x-gzip, application/msgpack, application/cbor", headerContentType) {
+				errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "Content Type is not application/json, application/msgpack, application/cbor or application/x-gzip")
 				return
 			}
 		}
@@ -57,23 +60,112 @@ func checkContent(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
-// userIdentification get user-id and permissions from authorization token
-func userIdentification(database *app.Storage) func(next http.Handler) http.Handler {
+// realIP replaces address of request by address of client from X-Real-IP or X-Forwarded-For headers,
+// headers are honoured only if request comes from trusted proxy. Otherwise clients could spoof address
+// to pass trusted subnet or to avoid throttle of logins
+func realIP(proxies []*net.IPNet) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if peer := remoteIP(r.RemoteAddr); peer != nil && inSubnets(peer, proxies) {
+				if client := forwardedIP(r, proxies); client != nil {
+					r.RemoteAddr = client.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// forwardedIP returns address of client from forwarding headers, X-Forwarded-For is read from the right
+// and addresses of trusted proxies are skipped, because addresses on the left are set by client
+func forwardedIP(r *http.Request, proxies []*net.IPNet) net.IP {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return nil
+			}
+			if !inSubnets(ip, proxies) || i == 0 {
+				return ip
+			}
+		}
+	}
+	return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
+
+// remoteIP returns address of host:port, address without port is accepted too
+func remoteIP(addr string) net.IP {
+	host, _, splitErr := net.SplitHostPort(addr)
+	if splitErr != nil {
+		host = addr
+	}
+	return net.ParseIP(host)
+}
+
+// inSubnets reports whether address belongs to one of subnets
+func inSubnets(ip net.IP, subnets []*net.IPNet) bool {
+	for _, subnet := range subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedSubnet allows requests only from trusted subnet, address is set by realIP.
+// Nothing is trusted if subnet is not set
+func trustedSubnet(subnet *net.IPNet) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r.RemoteAddr)
+			if subnet == nil || ip == nil || !subnet.Contains(ip) {
+				errorResponse(w, r, http.StatusForbidden, problem.CodeUntrustedNetwork, "request is not from trusted subnet")
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// userIdentification get user-id and permissions from authorization token.
+// Personal access token is accepted only by routes of elements which kind and id it grants
+func userIdentification(keeper *service.Service) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			jwt, jwtErr := getToken(r)
 			if jwtErr != nil {
-				errorMessageResponse(w, fmt.Sprint(ErrUnauthorized, ": ", jwtErr), "application/json", http.StatusUnauthorized)
+				errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, fmt.Sprint(ErrUnauthorized, ": ", jwtErr))
 				return
 			}
 
-			userID, userIDErr := database.Authorizer.ParseToken(jwt)
+			if authorizer.IsAccessToken(jwt) {
+				token, tokenErr := keeper.AuthenticateAccessToken(jwt)
+				if tokenErr != nil {
+					serviceErrorResponse(w, r, tokenErr)
+					return
+				}
+				kind, id, ok := elementRoute(r)
+				if !ok || !token.Permits(kind, r.Method != http.MethodGet && r.Method != http.MethodHead, id) {
+					serviceErrorResponse(w, r, service.ErrScopeDenied)
+					return
+				}
+				ctx := context.WithValue(r.Context(), keyPrincipalID, token.UserID)
+				ctx = context.WithValue(ctx, keyAccessToken, token)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			userID, sessionID, userIDErr := keeper.Authenticate(jwt)
 			if userIDErr != nil {
-				errorMessageResponse(w, fmt.Sprint(ErrUnauthorized, ": ", userIDErr), "application/json", http.StatusUnauthorized)
+				serviceErrorResponse(w, r, userIDErr)
 				return
 			}
 
 			ctx := context.WithValue(r.Context(), keyPrincipalID, userID)
+			ctx = context.WithValue(ctx, keySessionID, sessionID)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		}
@@ -81,6 +173,24 @@ func userIdentification(database *app.Storage) func(next http.Handler) http.Hand
 	}
 }
 
+// elementRoute returns kind of elements and id of element from path /api/v1/info/<kind>/<id>,
+// id is nil for list of elements. Routes that are not about elements of one kind are not ok
+func elementRoute(r *http.Request) (string, uuid.UUID, bool) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 4 || len(parts) > 5 || parts[0] != "api" || parts[2] != "info" ||
+		!utils.StringInSlice(parts[3], models.AccessKinds) {
+		return "", uuid.Nil, false
+	}
+	if len(parts) == 4 {
+		return parts[3], uuid.Nil, true
+	}
+	id, idErr := uuid.Parse(parts[4])
+	if idErr != nil {
+		return "", uuid.Nil, false
+	}
+	return parts[3], id, true
+}
+
 // getUserID returns user ID from context
 func getUserID(ctx context.Context) (uuid.UUID, error) {
 	userID, ok := ctx.Value(keyPrincipalID).(uuid.UUID)
@@ -89,3 +199,15 @@ func getUserID(ctx context.Context) (uuid.UUID, error) {
 	}
 	return userID, nil
 }
+
+// getAccessToken returns personal access token of request from context, it is nil for JWT
+func getAccessToken(ctx context.Context) *models.AccessToken {
+	token, _ := ctx.Value(keyAccessToken).(*models.AccessToken)
+	return token
+}
+
+// getSessionID returns id of session from context, token without session gives nil id
+func getSessionID(ctx context.Context) uuid.UUID {
+	sessionID, _ := ctx.Value(keySessionID).(uuid.UUID)
+	return sessionID
+}
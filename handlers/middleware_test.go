@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	_, proxies, parseErr := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, parseErr)
+	tests := []struct {
+		name      string
+		remote    string
+		realIP    string
+		forwarded string
+		want      string
+	}{
+		{name: "direct client", remote: "203.0.113.7:5000", want: "203.0.113.7:5000"},
+		{name: "spoofed header of direct client", remote: "203.0.113.7:5000", realIP: "10.1.1.1", forwarded: "10.1.1.1", want: "203.0.113.7:5000"},
+		{name: "real ip behind proxy", remote: "10.0.0.2:5000", realIP: "198.51.100.1", want: "198.51.100.1"},
+		{name: "forwarded behind proxy", remote: "10.0.0.2:5000", forwarded: "192.0.2.9, 198.51.100.1", want: "198.51.100.1"},
+		{name: "chain of proxies", remote: "10.0.0.2:5000", forwarded: "198.51.100.1, 10.0.0.3", want: "198.51.100.1"},
+		{name: "broken header behind proxy", remote: "10.0.0.2:5000", forwarded: "unknown", want: "10.0.0.2:5000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := realIP([]*net.IPNet{proxies})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+			request := httptest.NewRequest("GET", "/api/v1/admin/lockouts", nil)
+			request.RemoteAddr = tt.remote
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if tt.forwarded != "" {
+				request.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
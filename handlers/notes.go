@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
-	"AlexSarva/GophKeeper/storage"
-	"errors"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -15,41 +14,39 @@ import (
 //
 // Handler POST /api/v1/info/notes
 //
+//	"id": "<id generated by client>",
 //	"title": "<title>",
-//	"note": "<note>"
+//	"note": "<note>",
+//	"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - note successfully added;
 // 400 - invalid request format;
 // 401 - problem from authentication;
+// 409 - note with such id already exists;
 // 500 - an internal server error.
-func PostNote(database *app.Storage) http.HandlerFunc {
+func PostNote(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var note models.NewNote
 		readBodyErr := readBodyInStruct(r, &note)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
-			return
-		}
-		note.UserID = userID
-		if note.Note == "" {
-			errorMessageResponse(w, "empty fields error", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		newNote, newNoteErr := database.Database.NewNote(&note)
+		newNote, newNoteErr := keeper.NewNote(userID, &note)
 		if newNoteErr != nil {
-			errorMessageResponse(w, newNoteErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newNoteErr)
 			return
 		}
 
-		resultResponse(w, newNote, "application/json", http.StatusCreated)
+		resultResponse(w, newNote, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -63,26 +60,27 @@ func PostNote(database *app.Storage) http.HandlerFunc {
 // 400 - invalid request format;
 // 401 - problem from authentication;
 // 500 - an internal server error.
-func GetNoteList(database *app.Storage) http.HandlerFunc {
+func GetNoteList(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		notes, notesErr := database.Database.AllNotes(userID)
+		notes, notesErr := keeper.Notes(userID)
 		if notesErr != nil {
-			errorMessageResponse(w, notesErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, notesErr)
 			return
 		}
+		notes = permittedItems(ctx, notes, func(elem models.Note) uuid.UUID { return elem.ID })
 		if len(notes) == 0 {
-			errorMessageResponse(w, "no values", "application/json", http.StatusNoContent)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
-		resultResponse(w, notes, "application/json", http.StatusOK)
+		resultResponse(w, notes, accepted(r), http.StatusOK)
 	}
 }
 
@@ -95,35 +93,29 @@ func GetNoteList(database *app.Storage) http.HandlerFunc {
 // 204 - no values in database;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such note in database;
+// 404 - no such note in database;
 // 500 - an internal server error.
-func GetNote(database *app.Storage) http.HandlerFunc {
+func GetNote(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		noteIDStr := chi.URLParam(r, "id")
-		noteUUID, noteUUIDErr := uuid.Parse(noteIDStr)
+		noteUUID, noteUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if noteUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		note, notesErr := database.Database.GetNote(noteUUID, userID)
-		if notesErr != nil {
-			if errors.Is(notesErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, notesErr.Error(), "application/json", http.StatusInternalServerError)
+		note, noteErr := keeper.Note(userID, noteUUID)
+		if noteErr != nil {
+			serviceErrorResponse(w, r, noteErr)
 			return
 		}
-		resultResponse(w, note, "application/json", http.StatusOK)
+		resultResponse(w, note, accepted(r), http.StatusOK)
 	}
 }
 
@@ -132,70 +124,45 @@ func GetNote(database *app.Storage) http.HandlerFunc {
 // Handler PATCH /api/v1/info/notes/{id}
 //
 //	"title": "<title>",
-//	"note": "<note>"
+//	"note": "<note>",
+//	"version": <current version + 1>,
+//	"signature": "<signature>"
 //
 // Possible response codes:
 // 201 - note information successfully changed;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such note in database;
+// 404 - no such note in database;
+// 409 - version conflict;
 // 500 - an internal server error.
-func EditNote(database *app.Storage) http.HandlerFunc {
+func EditNote(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var editNote models.NewNote
 		readBodyErr := readBodyInStruct(r, &editNote)
 		if readBodyErr != nil {
-			errorMessageResponse(w, readBodyErr.Error(), "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
 			return
 		}
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		noteIDStr := chi.URLParam(r, "id")
-		noteUUID, noteUUIDErr := uuid.Parse(noteIDStr)
+		noteUUID, noteUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if noteUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		note, notesErr := database.Database.GetNote(noteUUID, userID)
-		if notesErr != nil {
-			if errors.Is(notesErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, notesErr.Error(), "application/json", http.StatusInternalServerError)
-			return
-		}
-
-		if editNote.Title == "" {
-			editNote.Title = note.Title
-		}
-
-		if editNote.Note == "" {
-			editNote.Note = note.Note
-		}
-
-		editNote.ID = note.ID
-		editNote.UserID = userID
-
-		newNote, newNoteErr := database.Database.EditNote(editNote)
+		newNote, newNoteErr := keeper.EditNote(userID, noteUUID, editNote)
 		if newNoteErr != nil {
-			if errors.Is(newNoteErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-
-			errorMessageResponse(w, newNoteErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, newNoteErr)
 			return
 		}
 
-		resultResponse(w, newNote, "application/json", http.StatusCreated)
+		resultResponse(w, newNote, accepted(r), http.StatusCreated)
 	}
 }
 
@@ -207,34 +174,29 @@ func EditNote(database *app.Storage) http.HandlerFunc {
 // 200 - successful deleted;
 // 400 - invalid request format;
 // 401 - problem from authentication;
-// 409 - no such note in database;
+// 404 - no such note in database;
 // 500 - an internal server error.
-func DeleteNote(database *app.Storage) http.HandlerFunc {
+func DeleteNote(keeper *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, userIDErr := getUserID(ctx)
 		if userIDErr != nil {
-			errorMessageResponse(w, ErrUnauthorized.Error()+": "+userIDErr.Error(), "application/json", http.StatusUnauthorized)
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
 			return
 		}
 
-		noteIDStr := chi.URLParam(r, "id")
-		noteUUID, noteUUIDErr := uuid.Parse(noteIDStr)
+		noteUUID, noteUUIDErr := uuid.Parse(chi.URLParam(r, "id"))
 		if noteUUIDErr != nil {
-			errorMessageResponse(w, "Check ID please", "application/json", http.StatusBadRequest)
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
 			return
 		}
 
-		delErr := database.Database.DeleteNote(noteUUID, userID)
+		delErr := keeper.DeleteNote(userID, noteUUID)
 		if delErr != nil {
-			if errors.Is(delErr, storage.ErrNoValues) {
-				errorMessageResponse(w, "no such note in db", "application/json", http.StatusConflict)
-				return
-			}
-			errorMessageResponse(w, delErr.Error(), "application/json", http.StatusInternalServerError)
+			serviceErrorResponse(w, r, delErr)
 			return
 		}
 
-		resultResponse(w, "successful deleted", "application/json", http.StatusOK)
+		resultResponse(w, "successful deleted", accepted(r), http.StatusOK)
 	}
 }
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/problem"
+	_ "embed"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// openAPISpec OpenAPI document of REST API, it is the contract of handlers
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// loadOpenAPI parses OpenAPI document and makes router over its operations
+func loadOpenAPI() (*openapi3.T, routers.Router, error) {
+	// errors of validation dont dump schemas, they are returned to clients
+	openapi3.SchemaErrorDetailsDisabled = true
+	doc, docErr := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if docErr != nil {
+		return nil, nil, docErr
+	}
+	router, routerErr := legacy.NewRouter(doc)
+	if routerErr != nil {
+		return nil, nil, routerErr
+	}
+	return doc, router, nil
+}
+
+// GetOpenAPI - OpenAPI document method
+//
+// Handler GET /api/v1/openapi.json
+//
+// Possible response codes:
+// 200 - returns OpenAPI document.
+func GetOpenAPI() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, writeErr := w.Write(openAPISpec); writeErr != nil {
+			log.Println("something wrong happens", writeErr)
+		}
+	}
+}
+
+// validateRequest checks path, query and JSON body of request by OpenAPI document.
+// Requests out of document are left to router, authentication is checked by userIdentification.
+// Bodies in binary encodings, compressed bodies and file contents are checked by handlers
+func validateRequest(router routers.Router) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, routeErr := router.FindRoute(r)
+			if routeErr != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					ExcludeRequestBody: !isJSONBody(r),
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			if validateErr := openapi3filter.ValidateRequest(r.Context(), input); validateErr != nil {
+				errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, validateErr.Error())
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// isJSONBody reports whether request has uncompressed JSON body
+func isJSONBody(r *http.Request) bool {
+	if r.Header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, parseErr := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return parseErr == nil && mediaType == "application/json"
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/events"
+	"AlexSarva/GophKeeper/internal/app"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/service"
+	"AlexSarva/GophKeeper/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc, _, loadErr := loadOpenAPI()
+	require.NoError(t, loadErr)
+	assert.NoError(t, doc.Validate(context.Background()))
+}
+
+// TestOpenAPIRoutes checks that every route of API is described in OpenAPI document and vice versa
+func TestOpenAPIRoutes(t *testing.T) {
+	doc, _, loadErr := loadOpenAPI()
+	require.NoError(t, loadErr)
+	var documented []string
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			documented = append(documented, method+" /api/v1"+path)
+		}
+	}
+
+	database := &app.Storage{}
+	handler := CustomHandler(database, service.NewService(database, events.NewBus()))
+	var routed []string
+	walkErr := chi.Walk(handler, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.HasPrefix(route, "/api/v1/") {
+			routed = append(routed, method+" "+strings.TrimSuffix(route, "/"))
+		}
+		return nil
+	})
+	require.NoError(t, walkErr)
+
+	sort.Strings(documented)
+	sort.Strings(routed)
+	assert.Equal(t, documented, routed)
+}
+
+// TestOpenAPIContract sends requests through handlers and checks responses by OpenAPI document
+func TestOpenAPIContract(t *testing.T) {
+	doc, router, loadErr := loadOpenAPI()
+	require.NoError(t, loadErr)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	keys, keysErr := authorizer.NewKeyRing(&memoryKeys{}, authorizer.KeyRingParams{
+		Algorithm: authorizer.AlgorithmEdDSA,
+		Overlap:   time.Hour,
+	})
+	require.NoError(t, keysErr)
+	database := &app.Storage{
+		Database:   newMemoryDB(),
+		Authorizer: authorizer.NewAuthorizer(nil, nil, nil, keys, time.Hour, time.Hour),
+	}
+	handler := CustomHandler(database, service.NewService(database, events.NewBus()))
+	token, tokenErr := keys.Sign(jwt.MapClaims{
+		"user_id": uuid.New().String(),
+		"jti":     uuid.New().String(),
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, tokenErr)
+
+	noteID, cardID, credID, fileID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		ctype  string
+		code   int
+	}{
+		{name: "openapi", method: "GET", path: "/openapi.json", code: 200},
+		{name: "unauthorized", method: "GET", path: "/info/notes", code: 401},
+		{name: "empty notes", method: "GET", path: "/info/notes", code: 204},
+		{name: "add note", method: "POST", path: "/info/notes", body: fmt.Sprintf(`{"id": "%s", "title": "t", "note": "n", "signature": "s"}`, noteID), code: 201},
+		{name: "unknown field", method: "POST", path: "/info/notes", body: `{"title": "t", "note": "n", "login": "l"}`, code: 400},
+		{name: "wrong id", method: "GET", path: "/info/notes/123", code: 400},
+		{name: "notes", method: "GET", path: "/info/notes", code: 200},
+		{name: "note", method: "GET", path: "/info/notes/" + noteID.String(), code: 200},
+		{name: "edit note", method: "PATCH", path: "/info/notes/" + noteID.String(), body: `{"note": "changed", "version": 2}`, code: 201},
+		{name: "version conflict", method: "PATCH", path: "/info/notes/" + noteID.String(), body: `{"note": "changed", "version": 2}`, code: 409},
+		{name: "delete note", method: "DELETE", path: "/info/notes/" + noteID.String(), code: 200},
+		{name: "deleted note", method: "GET", path: "/info/notes/" + noteID.String(), code: 404},
+		{name: "add card", method: "POST", path: "/info/cards", body: fmt.Sprintf(`{"id": "%s", "title": "t", "card_number": "4405 1111 1000 1383", "card_owner": "o", "card_exp": "12/25"}`, cardID), code: 201},
+		{name: "cards", method: "GET", path: "/info/cards", code: 200},
+		{name: "edit card", method: "PATCH", path: "/info/cards/" + cardID.String(), body: `{"notes": "n"}`, code: 201},
+		{name: "delete card", method: "DELETE", path: "/info/cards/" + cardID.String(), code: 200},
+		{name: "add cred", method: "POST", path: "/info/creds", body: fmt.Sprintf(`{"id": "%s", "title": "t", "login": "l", "passwd": "p"}`, credID), code: 201},
+		{name: "cred", method: "GET", path: "/info/creds/" + credID.String(), code: 200},
+		{name: "edit cred", method: "PATCH", path: "/info/creds/" + credID.String(), body: `{"passwd": "q", "version": 2}`, code: 201},
+		{name: "add file", method: "POST", path: "/info/files?title=t&filename=f.txt&id=" + fileID.String(), body: "content", ctype: "application/octet-stream", code: 201},
+		{name: "file without title", method: "POST", path: "/info/files?filename=f.txt", body: "content", ctype: "application/octet-stream", code: 400},
+		{name: "files", method: "GET", path: "/info/files", code: 200},
+		{name: "edit file", method: "PATCH", path: "/info/files/" + fileID.String() + "?title=t&filename=f.txt&version=2", ctype: "application/octet-stream", code: 201},
+		{name: "replace vault", method: "PUT", path: "/info/vault", body: `{"notes": null, "cards": [], "creds": [], "files": []}`, code: 200},
+		{name: "delete file", method: "DELETE", path: "/info/files/" + fileID.String(), code: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "/api/v1"+tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				ctype := tt.ctype
+				if ctype == "" {
+					ctype = "application/json"
+				}
+				request.Header.Set("Content-Type", ctype)
+			}
+			if tt.name != "unauthorized" {
+				request.Header.Set("Authorization", "Bearer "+token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+			result := w.Result()
+			defer result.Body.Close()
+			resBody, readErr := io.ReadAll(result.Body)
+			require.NoError(t, readErr)
+			require.Equal(t, tt.code, result.StatusCode, string(resBody))
+
+			// request body is read by handler, route is found by request without it
+			request.Body = io.NopCloser(strings.NewReader(tt.body))
+			route, pathParams, routeErr := router.FindRoute(request)
+			require.NoError(t, routeErr)
+			validateErr := openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    request,
+					PathParams: pathParams,
+					Route:      route,
+				},
+				Status: result.StatusCode,
+				Header: result.Header,
+				Body:   io.NopCloser(bytes.NewReader(resBody)),
+			})
+			assert.NoError(t, validateErr)
+		})
+	}
+}
+
+// memoryKeys storage of signing keys in memory
+type memoryKeys struct {
+	keys []models.SigningKey
+}
+
+func (m *memoryKeys) SigningKeys() ([]models.SigningKey, error) {
+	return m.keys, nil
+}
+
+func (m *memoryKeys) AddSigningKey(key *models.SigningKey, _, _ time.Duration) error {
+	m.keys = append(m.keys, *key)
+	return nil
+}
+
+// memoryDB storage of elements in memory, elements of all users are kept together
+type memoryDB struct {
+	notes map[uuid.UUID]models.Note
+	cards map[uuid.UUID]models.Card
+	creds map[uuid.UUID]models.Cred
+	files map[uuid.UUID]models.File
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{
+		notes: make(map[uuid.UUID]models.Note),
+		cards: make(map[uuid.UUID]models.Card),
+		creds: make(map[uuid.UUID]models.Cred),
+		files: make(map[uuid.UUID]models.File),
+	}
+}
+
+func (m *memoryDB) Ping() bool {
+	return true
+}
+
+func (m *memoryDB) NewNote(note *models.NewNote) (models.Note, error) {
+	newNote := models.Note{ID: note.ID, Title: note.Title, Note: note.Note, Version: note.Version, Signature: note.Signature, Created: time.Now()}
+	m.notes[note.ID] = newNote
+	return newNote, nil
+}
+
+func (m *memoryDB) AllNotes(_ uuid.UUID) ([]models.Note, error) {
+	return values(m.notes), nil
+}
+
+func (m *memoryDB) GetNote(noteID uuid.UUID, _ uuid.UUID) (models.Note, error) {
+	return get(m.notes, noteID)
+}
+
+func (m *memoryDB) EditNote(note models.NewNote) (models.Note, error) {
+	current, getErr := get(m.notes, note.ID)
+	if getErr != nil {
+		return models.Note{}, getErr
+	}
+	current.Title, current.Note, current.Version, current.Signature = note.Title, note.Note, note.Version, note.Signature
+	current.Changed = changed()
+	m.notes[note.ID] = current
+	return current, nil
+}
+
+func (m *memoryDB) DeleteNote(noteID uuid.UUID, _ uuid.UUID) error {
+	return remove(m.notes, noteID)
+}
+
+func (m *memoryDB) NewCard(card *models.NewCard) (models.Card, error) {
+	newCard := models.Card{ID: card.ID, Title: card.Title, CardNumber: card.CardNumber, CardOwner: card.CardOwner, CardExp: card.CardExp, Notes: card.Notes, Version: card.Version, Signature: card.Signature, Created: time.Now()}
+	m.cards[card.ID] = newCard
+	return newCard, nil
+}
+
+func (m *memoryDB) AllCards(_ uuid.UUID) ([]models.Card, error) {
+	return values(m.cards), nil
+}
+
+func (m *memoryDB) GetCard(cardID uuid.UUID, _ uuid.UUID) (models.Card, error) {
+	return get(m.cards, cardID)
+}
+
+func (m *memoryDB) EditCard(card models.NewCard) (models.Card, error) {
+	current, getErr := get(m.cards, card.ID)
+	if getErr != nil {
+		return models.Card{}, getErr
+	}
+	current.Title, current.CardNumber, current.CardOwner, current.CardExp, current.Notes = card.Title, card.CardNumber, card.CardOwner, card.CardExp, card.Notes
+	current.Version, current.Signature, current.Changed = card.Version, card.Signature, changed()
+	m.cards[card.ID] = current
+	return current, nil
+}
+
+func (m *memoryDB) DeleteCard(cardID uuid.UUID, _ uuid.UUID) error {
+	return remove(m.cards, cardID)
+}
+
+func (m *memoryDB) NewCred(cred *models.NewCred) (models.Cred, error) {
+	newCred := models.Cred{ID: cred.ID, Title: cred.Title, Login: cred.Login, Passwd: cred.Passwd, Notes: cred.Notes, Version: cred.Version, Signature: cred.Signature, Created: time.Now()}
+	m.creds[cred.ID] = newCred
+	return newCred, nil
+}
+
+func (m *memoryDB) AllCreds(_ uuid.UUID) ([]models.Cred, error) {
+	return values(m.creds), nil
+}
+
+func (m *memoryDB) GetCred(credID uuid.UUID, _ uuid.UUID) (models.Cred, error) {
+	return get(m.creds, credID)
+}
+
+func (m *memoryDB) EditCred(cred models.NewCred) (models.Cred, error) {
+	current, getErr := get(m.creds, cred.ID)
+	if getErr != nil {
+		return models.Cred{}, getErr
+	}
+	current.Title, current.Login, current.Passwd, current.Notes = cred.Title, cred.Login, cred.Passwd, cred.Notes
+	current.Version, current.Signature, current.Changed = cred.Version, cred.Signature, changed()
+	m.creds[cred.ID] = current
+	return current, nil
+}
+
+func (m *memoryDB) DeleteCred(credID uuid.UUID, _ uuid.UUID) error {
+	return remove(m.creds, credID)
+}
+
+func (m *memoryDB) NewFile(file *models.NewFile) (models.File, error) {
+	newFile := models.File{ID: file.ID, Title: file.Title, File: file.File, FileName: file.FileName, Notes: file.Notes, Version: file.Version, Signature: file.Signature, Created: time.Now()}
+	m.files[file.ID] = newFile
+	return newFile, nil
+}
+
+func (m *memoryDB) AllFiles(_ uuid.UUID) ([]models.File, error) {
+	return values(m.files), nil
+}
+
+func (m *memoryDB) GetFile(fileID uuid.UUID, _ uuid.UUID) (models.File, error) {
+	return get(m.files, fileID)
+}
+
+func (m *memoryDB) EditFile(file *models.NewFile) (models.File, error) {
+	current, getErr := get(m.files, file.ID)
+	if getErr != nil {
+		return models.File{}, getErr
+	}
+	current.Title, current.File, current.FileName, current.Notes = file.Title, file.File, file.FileName, file.Notes
+	current.Version, current.Signature, current.Changed = file.Version, file.Signature, changed()
+	m.files[file.ID] = current
+	return current, nil
+}
+
+func (m *memoryDB) DeleteFile(fileID uuid.UUID, _ uuid.UUID) error {
+	return remove(m.files, fileID)
+}
+
+func (m *memoryDB) ReplaceVault(_ uuid.UUID, _ *models.Vault) error {
+	return nil
+}
+
+func (m *memoryDB) DeleteUserData(_ uuid.UUID) error {
+	return nil
+}
+
+func values[T any](elems map[uuid.UUID]T) []T {
+	res := make([]T, 0, len(elems))
+	for _, elem := range elems {
+		res = append(res, elem)
+	}
+	return res
+}
+
+func get[T any](elems map[uuid.UUID]T, id uuid.UUID) (T, error) {
+	elem, ok := elems[id]
+	if !ok {
+		return elem, storage.ErrNoValues
+	}
+	return elem, nil
+}
+
+func remove[T any](elems map[uuid.UUID]T, id uuid.UUID) error {
+	if _, ok := elems[id]; !ok {
+		return storage.ErrNoValues
+	}
+	delete(elems, id)
+	return nil
+}
+
+// changed returns change time of element, it is scanned like value from database
+func changed() *models.NullTime {
+	var changedTime models.NullTime
+	_ = changedTime.Scan(time.Now())
+	return &changedTime
+}
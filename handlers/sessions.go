@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// DeviceNameHeader header with name of device, it is saved in session on login
+	DeviceNameHeader = "X-Device-Name"
+	// ClientVersionHeader header with version of client, it is saved in session on login
+	ClientVersionHeader = "X-Client-Version"
+)
+
+// sessionClient returns client of request, address is set by realIP
+func sessionClient(r *http.Request) *models.SessionClient {
+	ip, _, splitErr := net.SplitHostPort(r.RemoteAddr)
+	if splitErr != nil {
+		ip = r.RemoteAddr
+	}
+	return &models.SessionClient{
+		DeviceName:    r.Header.Get(DeviceNameHeader),
+		ClientVersion: r.Header.Get(ClientVersionHeader),
+		IP:            ip,
+		UserAgent:     r.UserAgent(),
+	}
+}
+
+// GetSessions - active sessions of user method
+//
+// Handler GET /api/v1/users/me/sessions
+//
+// Authorization: "Bearer T"
+//
+// Returns devices where user is logged in, session of request is marked as current.
+//
+// Possible response codes:
+// 200 - sessions of user;
+// 401 - invalid auth;
+// 500 - an internal server error.
+func GetSessions(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		sessions, sessionsErr := keeper.Sessions(userID, getSessionID(ctx))
+		if sessionsErr != nil {
+			serviceErrorResponse(w, r, sessionsErr)
+			return
+		}
+
+		resultResponse(w, sessions, accepted(r), http.StatusOK)
+	}
+}
+
+// DeleteSession - revoke session method
+//
+// Handler DELETE /api/v1/users/me/sessions/{id}
+//
+// Authorization: "Bearer T"
+//
+// Revokes session with all its tokens, device of session has to log in again.
+//
+// Possible response codes:
+// 200 - session successfully revoked;
+// 400 - invalid request format;
+// 401 - invalid auth;
+// 404 - no such active session;
+// 500 - an internal server error.
+func DeleteSession(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		sessionID, sessionIDErr := uuid.Parse(chi.URLParam(r, "id"))
+		if sessionIDErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidID, "check ID please")
+			return
+		}
+
+		revokeErr := keeper.RevokeSession(userID, sessionID)
+		if revokeErr != nil {
+			serviceErrorResponse(w, r, revokeErr)
+			return
+		}
+
+		resultResponse(w, "successful revoked", accepted(r), http.StatusOK)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"net/http"
+)
+
+// TwoFactorAuthentication - second step of login method
+//
+// Handler POST /api/v1/login/2fa
+//
+// Completes login of user with two-factor authentication.
+// Request format:
+//
+//	{"challenge": "<challenge of login>",
+//	"code": "<code of authenticator app or recovery code>"}
+//
+// Every code could be used only once, as well as challenge.
+//
+// Possible response codes:
+// 200 - user successfully authenticated;
+// 400 - invalid request format;
+// 401 - challenge is invalid, expired or used, code is invalid;
+// 500 - an internal server error.
+func TwoFactorAuthentication(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var login models.TwoFactorLogin
+		readBodyErr := readBodyInStruct(r, &login)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		userInfo, userInfoErr := keeper.LoginTwoFactor(&login, sessionClient(r))
+		if userInfoErr != nil {
+			serviceErrorResponse(w, r, userInfoErr)
+			return
+		}
+
+		resultResponse(w, userInfo, accepted(r), http.StatusOK)
+	}
+}
+
+// SetupTwoFactor - start enabling of two-factor authentication method
+//
+// Handler POST /api/v1/users/me/2fa
+//
+// Authorization: "Bearer T"
+//
+// Returns new TOTP secret with otpauth URI for authenticator app,
+// two-factor authentication is enabled after confirmation by code.
+//
+// Possible response codes:
+// 201 - secret is generated;
+// 401 - invalid auth;
+// 409 - two-factor authentication is already enabled;
+// 500 - an internal server error.
+func SetupTwoFactor(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		setup, setupErr := keeper.SetupTwoFactor(userID)
+		if setupErr != nil {
+			serviceErrorResponse(w, r, setupErr)
+			return
+		}
+
+		resultResponse(w, setup, accepted(r), http.StatusCreated)
+	}
+}
+
+// ConfirmTwoFactor - enable two-factor authentication method
+//
+// Handler POST /api/v1/users/me/2fa/confirm
+//
+// Authorization: "Bearer T"
+//
+//	"code": "<code of authenticator app>"
+//
+// Returns one-time recovery codes, they are shown only once.
+//
+// Possible response codes:
+// 200 - two-factor authentication is enabled;
+// 400 - invalid request format;
+// 401 - invalid auth;
+// 403 - code is invalid;
+// 409 - two-factor authentication is already enabled or not set up;
+// 429 - too many wrong codes, request could be repeated after Retry-After seconds;
+// 500 - an internal server error.
+func ConfirmTwoFactor(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		var code models.TwoFactorCode
+		readBodyErr := readBodyInStruct(r, &code)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		codes, confirmErr := keeper.ConfirmTwoFactor(userID, &code, sessionClient(r))
+		if confirmErr != nil {
+			serviceErrorResponse(w, r, confirmErr)
+			return
+		}
+
+		resultResponse(w, codes, accepted(r), http.StatusOK)
+	}
+}
+
+// DisableTwoFactor - disable two-factor authentication method
+//
+// Handler POST /api/v1/users/me/2fa/disable
+//
+// Authorization: "Bearer T"
+//
+//	"code": "<code of authenticator app or recovery code>"
+//
+// Possible response codes:
+// 200 - two-factor authentication is disabled;
+// 400 - invalid request format;
+// 401 - invalid auth;
+// 403 - code is invalid;
+// 409 - two-factor authentication is not enabled;
+// 429 - too many wrong codes, request could be repeated after Retry-After seconds;
+// 500 - an internal server error.
+func DisableTwoFactor(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, userIDErr := getUserID(r.Context())
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		var code models.TwoFactorCode
+		readBodyErr := readBodyInStruct(r, &code)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+
+		disableErr := keeper.DisableTwoFactor(userID, &code, sessionClient(r))
+		if disableErr != nil {
+			serviceErrorResponse(w, r, disableErr)
+			return
+		}
+
+		resultResponse(w, "two-factor authentication disabled", accepted(r), http.StatusOK)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/service"
+	"net/http"
+)
+
+// ReplaceVault - replace all elements method
+//
+// Handler PUT /api/v1/info/vault
+//
+// Used by client after keys rotation, all elements are changed in one transaction.
+// Vault should contain all elements of user, every element should be signed with the next version.
+//
+//	"notes": [<note>, ...],
+//	"cards": [<card>, ...],
+//	"creds": [<cred>, ...],
+//	"files": [<file>, ...]
+//
+// Possible response codes:
+// 200 - all elements successfully replaced;
+// 400 - invalid request format;
+// 401 - problem from authentication;
+// 404 - some element doesnt exist in database or has another version, nothing changed;
+// 409 - vault doesnt contain exactly all elements of user, nothing changed;
+// 500 - an internal server error.
+func ReplaceVault(keeper *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var vault models.Vault
+		readBodyErr := readBodyInStruct(r, &vault)
+		if readBodyErr != nil {
+			errorResponse(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, readBodyErr.Error())
+			return
+		}
+		ctx := r.Context()
+		userID, userIDErr := getUserID(ctx)
+		if userIDErr != nil {
+			errorResponse(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, ErrUnauthorized.Error())
+			return
+		}
+
+		replaceErr := keeper.ReplaceVault(userID, &vault)
+		if replaceErr != nil {
+			serviceErrorResponse(w, r, replaceErr)
+			return
+		}
+
+		resultResponse(w, "successful replaced", accepted(r), http.StatusOK)
+	}
+}
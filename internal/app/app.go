@@ -3,21 +3,47 @@ package app
 import (
 	"AlexSarva/GophKeeper/authorizer"
 	"AlexSarva/GophKeeper/constant"
+	"AlexSarva/GophKeeper/mailer"
 	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/passhash"
 	"AlexSarva/GophKeeper/storage"
 	"AlexSarva/GophKeeper/storage/admin"
+	"AlexSarva/GophKeeper/storage/atrest"
 	"AlexSarva/GophKeeper/storage/storagepg"
 	"AlexSarva/GophKeeper/utils"
+	"context"
 	"log"
+	"net"
+	"strings"
 	"time"
 )
 
+const (
+	// accessTokenTTL lifetime of access token, clients refresh it by refresh token
+	accessTokenTTL = 15 * time.Minute
+	// refreshTokenTTL lifetime of refresh token, user logs in again after it
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// revocationsSync interval of loading revocations of tokens made by other servers
+	revocationsSync = 30 * time.Second
+	// keysSync interval of loading keys of JWT signatures added by other servers and checking rotation
+	keysSync = time.Minute
+	// keyPublish time while new key of JWT signatures is in JWKS before it signs tokens,
+	// it is longer than cache lifetime of JWKS
+	keyPublish = time.Hour
+)
+
 // Storage interface for different types of databases
 type Storage struct {
-	Database        storage.Database
-	Admin           *admin.Admin
-	Authorizer      *authorizer.Authorizer
-	PasswordChecker *utils.PasswordChecker
+	Database          storage.Database
+	Admin             *admin.Admin
+	Idempotency       storage.Idempotency
+	IdempotencyWindow time.Duration
+	Authorizer        *authorizer.Authorizer
+	PasswordChecker   *utils.PasswordChecker
+	// TrustedSubnet subnet of admins, admin routes are closed if it is nil
+	TrustedSubnet *net.IPNet
+	// TrustedProxies subnets of reverse proxies, forwarding headers are honoured only from them
+	TrustedProxies []*net.IPNet
 }
 
 // NewStorage generate new instance of database
@@ -25,17 +51,95 @@ func NewStorage() *Storage {
 
 	cfg := constant.GlobalContainer.Get("server-config").(models.ServerConfig)
 
-	mainStorage := storagepg.PostgresDBConn(cfg.Database)
-	adminStorage := admin.NewAdminDBConnection(cfg.AdminDatabase)
-	auth := authorizer.NewAuthorizer(adminStorage, []byte(cfg.Secret), 72*time.Hour)
+	var sealer *atrest.Sealer
+	if cfg.AtRestKeys != "" {
+		provider, providerErr := atrest.LoadFileKeys(cfg.AtRestKeys)
+		if providerErr != nil {
+			log.Fatalln(providerErr)
+		}
+		sealer = atrest.NewSealer(provider)
+	}
+
+	mainStorage := storagepg.PostgresDBConn(cfg.Database, sealer)
+	adminStorage := admin.NewAdminDBConnection(cfg.AdminDatabase, sealer)
+	if sealer != nil {
+		log.Println("Using at-rest encryption")
+		go atrest.RunRewrap(context.Background(), sealer, time.Minute, mainStorage, adminStorage)
+	}
+	hasher, hasherErr := passhash.New(passhash.Params{
+		Algorithm:     cfg.PasswordHash,
+		BcryptCost:    cfg.BcryptCost,
+		Argon2Time:    uint32(cfg.Argon2Time),
+		Argon2Memory:  uint32(cfg.Argon2Memory),
+		Argon2Threads: uint8(cfg.Argon2Threads),
+	})
+	if hasherErr != nil {
+		log.Fatalln(hasherErr)
+	}
+	keys, keysErr := authorizer.NewKeyRing(adminStorage, KeyRingParams(&cfg))
+	if keysErr != nil {
+		log.Fatalln(keysErr)
+	}
+	go keys.Sync(context.Background(), keysSync)
+	auth := authorizer.NewAuthorizer(adminStorage, hasher, newMailSender(&cfg), keys, accessTokenTTL, refreshTokenTTL)
+	go auth.Revocations().Sync(context.Background(), revocationsSync)
 	passwordChecker := utils.InitPasswordChecker(8, true, true, false)
+	var trustedSubnet *net.IPNet
+	if cfg.TrustedSubnet != "" {
+		var subnetErr error
+		_, trustedSubnet, subnetErr = net.ParseCIDR(cfg.TrustedSubnet)
+		if subnetErr != nil {
+			log.Fatalln(subnetErr)
+		}
+	}
+	var trustedProxies []*net.IPNet
+	for _, cidr := range strings.Fields(cfg.TrustedProxies) {
+		_, proxies, proxiesErr := net.ParseCIDR(cidr)
+		if proxiesErr != nil {
+			log.Fatalln(proxiesErr)
+		}
+		trustedProxies = append(trustedProxies, proxies)
+	}
 
 	log.Println("Using PostgreSQL Database")
 
 	return &Storage{
-		Database:        mainStorage,
-		Admin:           adminStorage,
-		Authorizer:      auth,
-		PasswordChecker: passwordChecker,
+		Database:          mainStorage,
+		Admin:             adminStorage,
+		Idempotency:       adminStorage,
+		IdempotencyWindow: cfg.IdempotencyWindow,
+		Authorizer:        auth,
+		PasswordChecker:   passwordChecker,
+		TrustedSubnet:     trustedSubnet,
+		TrustedProxies:    trustedProxies,
+	}
+}
+
+// KeyRingParams returns parameters of key ring of JWT signatures from config,
+// replaced keys verify tokens while access tokens and challenges of two-factor login live
+func KeyRingParams(cfg *models.ServerConfig) authorizer.KeyRingParams {
+	return authorizer.KeyRingParams{
+		Algorithm: cfg.JWTAlgorithm,
+		Rotation:  cfg.JWTRotation,
+		Publish:   keyPublish,
+		Overlap:   accessTokenTTL,
+	}
+}
+
+// newMailSender returns sender of messages to users by SMTP server of config,
+// messages are written to mail file or log if SMTP server is not set
+func newMailSender(cfg *models.ServerConfig) *mailer.Sender {
+	templates, templatesErr := mailer.LoadTemplates(cfg.MailTemplates)
+	if templatesErr != nil {
+		log.Fatalln(templatesErr)
+	}
+	if cfg.SMTPAddress == "" {
+		log.Println("SMTP server is not set, messages to users are not sent")
+		return mailer.NewSender(mailer.NewFileMailer(cfg.MailFile), templates)
+	}
+	smtpMailer, smtpErr := mailer.NewSMTPMailer(cfg.SMTPAddress, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	if smtpErr != nil {
+		log.Fatalln(smtpErr)
 	}
+	return mailer.NewSender(smtpMailer, templates)
 }
@@ -0,0 +1,494 @@
+package keeperpb
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// ChunkSize size of file content in one chunk of stream
+	ChunkSize = 64 << 10
+	// MaxMessageSize max size of message, vault with files is sent by one message
+	MaxMessageSize = 64 << 20
+)
+
+// ParseID parses id of element, empty id is uuid.Nil
+func ParseID(id string) (uuid.UUID, error) {
+	if id == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(id)
+}
+
+func timeToPB(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timeFromPB(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func changedFromPB(ts *timestamppb.Timestamp) *models.NullTime {
+	if ts == nil {
+		return nil
+	}
+	return &models.NullTime{Time: ts.AsTime(), Valid: true}
+}
+
+// UserToPB converts user to message
+func UserToPB(user *models.User) *User {
+	pb := &User{
+		Id:             user.ID.String(),
+		Username:       user.Username,
+		Email:          user.Email,
+		Token:          user.Token,
+		TokenExpires:   timeToPB(user.TokenExp),
+		KeyFingerprint: user.KeyFingerprint,
+		RefreshToken:   user.RefreshToken,
+	}
+	if user.RefreshExp != nil {
+		pb.RefreshExpires = timeToPB(*user.RefreshExp)
+	}
+	if user.EmailVerified != nil {
+		pb.EmailVerified = timeToPB(*user.EmailVerified)
+	}
+	return pb
+}
+
+// UserFromPB converts message to user
+func UserFromPB(user *User) (*models.User, error) {
+	id, idErr := ParseID(user.GetId())
+	if idErr != nil {
+		return nil, idErr
+	}
+	userInfo := &models.User{
+		ID:             id,
+		Username:       user.GetUsername(),
+		Email:          user.GetEmail(),
+		Token:          user.GetToken(),
+		TokenExp:       timeFromPB(user.GetTokenExpires()),
+		KeyFingerprint: user.GetKeyFingerprint(),
+		RefreshToken:   user.GetRefreshToken(),
+	}
+	if user.GetRefreshExpires() != nil {
+		refreshExp := timeFromPB(user.GetRefreshExpires())
+		userInfo.RefreshExp = &refreshExp
+	}
+	if user.GetEmailVerified() != nil {
+		emailVerified := timeFromPB(user.GetEmailVerified())
+		userInfo.EmailVerified = &emailVerified
+	}
+	return userInfo, nil
+}
+
+// ChallengeToPB converts challenge of two-factor login to user message without tokens
+func ChallengeToPB(challenge *models.TwoFactorChallenge) *User {
+	return &User{Challenge: challenge.Challenge, ChallengeExpires: timeToPB(challenge.Expires)}
+}
+
+// ChallengeFromPB returns challenge of two-factor login from user message, nil if login is completed
+func ChallengeFromPB(user *User) *models.TwoFactorChallenge {
+	if user.GetChallenge() == "" {
+		return nil
+	}
+	return &models.TwoFactorChallenge{Challenge: user.GetChallenge(), Expires: timeFromPB(user.GetChallengeExpires())}
+}
+
+// TokensToPB converts pair of tokens to message
+func TokensToPB(tokens *models.Tokens) *Tokens {
+	return &Tokens{
+		Token:          tokens.Token,
+		TokenExpires:   timeToPB(tokens.TokenExp),
+		RefreshToken:   tokens.RefreshToken,
+		RefreshExpires: timeToPB(tokens.RefreshExp),
+	}
+}
+
+// TokensFromPB converts message to pair of tokens
+func TokensFromPB(tokens *Tokens) *models.Tokens {
+	return &models.Tokens{
+		Token:        tokens.GetToken(),
+		TokenExp:     timeFromPB(tokens.GetTokenExpires()),
+		RefreshToken: tokens.GetRefreshToken(),
+		RefreshExp:   timeFromPB(tokens.GetRefreshExpires()),
+	}
+}
+
+// SessionsToPB converts sessions of user to message
+func SessionsToPB(sessions []models.Session) *SessionList {
+	pb := &SessionList{Sessions: make([]*Session, 0, len(sessions))}
+	for _, session := range sessions {
+		pb.Sessions = append(pb.Sessions, &Session{
+			Id:            session.ID.String(),
+			DeviceName:    session.DeviceName,
+			ClientVersion: session.ClientVersion,
+			Ip:            session.IP,
+			UserAgent:     session.UserAgent,
+			Created:       timeToPB(session.Created),
+			LastSeen:      timeToPB(session.LastSeen),
+			Current:       session.Current,
+		})
+	}
+	return pb
+}
+
+// SessionsFromPB converts message to sessions of user
+func SessionsFromPB(pb *SessionList) ([]models.Session, error) {
+	sessions := make([]models.Session, 0, len(pb.GetSessions()))
+	for _, session := range pb.GetSessions() {
+		id, idErr := ParseID(session.GetId())
+		if idErr != nil {
+			return nil, idErr
+		}
+		sessions = append(sessions, models.Session{
+			ID:            id,
+			DeviceName:    session.GetDeviceName(),
+			ClientVersion: session.GetClientVersion(),
+			IP:            session.GetIp(),
+			UserAgent:     session.GetUserAgent(),
+			Created:       timeFromPB(session.GetCreated()),
+			LastSeen:      timeFromPB(session.GetLastSeen()),
+			Current:       session.GetCurrent(),
+		})
+	}
+	return sessions, nil
+}
+
+// EnrollmentToPB converts enrollment of new device to message
+func EnrollmentToPB(enrollment *models.Enrollment) *Enrollment {
+	return &Enrollment{
+		Id:           enrollment.ID.String(),
+		Code:         enrollment.Code,
+		DevicePublic: enrollment.DevicePublic,
+		Bundle:       enrollment.Bundle,
+		Created:      timeToPB(enrollment.Created),
+		Expires:      timeToPB(enrollment.Expires),
+	}
+}
+
+// EnrollmentFromPB converts message to enrollment of new device
+func EnrollmentFromPB(pb *Enrollment) (*models.Enrollment, error) {
+	id, idErr := ParseID(pb.GetId())
+	if idErr != nil {
+		return nil, idErr
+	}
+	return &models.Enrollment{
+		ID:           id,
+		Code:         pb.GetCode(),
+		DevicePublic: pb.GetDevicePublic(),
+		Bundle:       pb.GetBundle(),
+		Created:      timeFromPB(pb.GetCreated()),
+		Expires:      timeFromPB(pb.GetExpires()),
+	}, nil
+}
+
+// NoteToPB converts stored note to message
+func NoteToPB(note models.Note) *Note {
+	return &Note{
+		Id:        note.ID.String(),
+		Title:     note.Title,
+		Note:      note.Note,
+		Version:   int64(note.Version),
+		Signature: note.Signature,
+		Created:   timeToPB(note.Created),
+		Changed:   timeToPB(note.Changed.Get()),
+	}
+}
+
+// NoteFromPB converts message to stored note
+func NoteFromPB(note *Note) (models.Note, error) {
+	id, idErr := ParseID(note.GetId())
+	if idErr != nil {
+		return models.Note{}, idErr
+	}
+	return models.Note{
+		ID:        id,
+		Title:     note.GetTitle(),
+		Note:      note.GetNote(),
+		Version:   int(note.GetVersion()),
+		Signature: note.GetSignature(),
+		Created:   timeFromPB(note.GetCreated()),
+		Changed:   changedFromPB(note.GetChanged()),
+	}, nil
+}
+
+// NewNoteToPB converts posted note to message
+func NewNoteToPB(note *models.NewNote) *Note {
+	return &Note{
+		Id:        note.ID.String(),
+		Title:     note.Title,
+		Note:      note.Note,
+		Version:   int64(note.Version),
+		Signature: note.Signature,
+	}
+}
+
+// NewNoteFromPB converts message to posted note
+func NewNoteFromPB(note *Note) (models.NewNote, error) {
+	id, idErr := ParseID(note.GetId())
+	if idErr != nil {
+		return models.NewNote{}, idErr
+	}
+	return models.NewNote{
+		ID:        id,
+		Title:     note.GetTitle(),
+		Note:      note.GetNote(),
+		Version:   int(note.GetVersion()),
+		Signature: note.GetSignature(),
+	}, nil
+}
+
+// CardToPB converts stored card to message
+func CardToPB(card models.Card) *Card {
+	return &Card{
+		Id:         card.ID.String(),
+		Title:      card.Title,
+		CardNumber: card.CardNumber,
+		CardOwner:  card.CardOwner,
+		CardExp:    card.CardExp,
+		Notes:      card.Notes,
+		Version:    int64(card.Version),
+		Signature:  card.Signature,
+		Created:    timeToPB(card.Created),
+		Changed:    timeToPB(card.Changed.Get()),
+	}
+}
+
+// CardFromPB converts message to stored card
+func CardFromPB(card *Card) (models.Card, error) {
+	id, idErr := ParseID(card.GetId())
+	if idErr != nil {
+		return models.Card{}, idErr
+	}
+	return models.Card{
+		ID:         id,
+		Title:      card.GetTitle(),
+		CardNumber: card.GetCardNumber(),
+		CardOwner:  card.GetCardOwner(),
+		CardExp:    card.GetCardExp(),
+		Notes:      card.GetNotes(),
+		Version:    int(card.GetVersion()),
+		Signature:  card.GetSignature(),
+		Created:    timeFromPB(card.GetCreated()),
+		Changed:    changedFromPB(card.GetChanged()),
+	}, nil
+}
+
+// NewCardToPB converts posted card to message
+func NewCardToPB(card *models.NewCard) *Card {
+	return &Card{
+		Id:         card.ID.String(),
+		Title:      card.Title,
+		CardNumber: card.CardNumber,
+		CardOwner:  card.CardOwner,
+		CardExp:    card.CardExp,
+		Notes:      card.Notes,
+		Version:    int64(card.Version),
+		Signature:  card.Signature,
+	}
+}
+
+// NewCardFromPB converts message to posted card
+func NewCardFromPB(card *Card) (models.NewCard, error) {
+	id, idErr := ParseID(card.GetId())
+	if idErr != nil {
+		return models.NewCard{}, idErr
+	}
+	return models.NewCard{
+		ID:         id,
+		Title:      card.GetTitle(),
+		CardNumber: card.GetCardNumber(),
+		CardOwner:  card.GetCardOwner(),
+		CardExp:    card.GetCardExp(),
+		Notes:      card.GetNotes(),
+		Version:    int(card.GetVersion()),
+		Signature:  card.GetSignature(),
+	}, nil
+}
+
+// CredToPB converts stored cred to message
+func CredToPB(cred models.Cred) *Cred {
+	return &Cred{
+		Id:        cred.ID.String(),
+		Title:     cred.Title,
+		Login:     cred.Login,
+		Passwd:    cred.Passwd,
+		Notes:     cred.Notes,
+		Version:   int64(cred.Version),
+		Signature: cred.Signature,
+		Created:   timeToPB(cred.Created),
+		Changed:   timeToPB(cred.Changed.Get()),
+	}
+}
+
+// CredFromPB converts message to stored cred
+func CredFromPB(cred *Cred) (models.Cred, error) {
+	id, idErr := ParseID(cred.GetId())
+	if idErr != nil {
+		return models.Cred{}, idErr
+	}
+	return models.Cred{
+		ID:        id,
+		Title:     cred.GetTitle(),
+		Login:     cred.GetLogin(),
+		Passwd:    cred.GetPasswd(),
+		Notes:     cred.GetNotes(),
+		Version:   int(cred.GetVersion()),
+		Signature: cred.GetSignature(),
+		Created:   timeFromPB(cred.GetCreated()),
+		Changed:   changedFromPB(cred.GetChanged()),
+	}, nil
+}
+
+// NewCredToPB converts posted cred to message
+func NewCredToPB(cred *models.NewCred) *Cred {
+	return &Cred{
+		Id:        cred.ID.String(),
+		Title:     cred.Title,
+		Login:     cred.Login,
+		Passwd:    cred.Passwd,
+		Notes:     cred.Notes,
+		Version:   int64(cred.Version),
+		Signature: cred.Signature,
+	}
+}
+
+// NewCredFromPB converts message to posted cred
+func NewCredFromPB(cred *Cred) (models.NewCred, error) {
+	id, idErr := ParseID(cred.GetId())
+	if idErr != nil {
+		return models.NewCred{}, idErr
+	}
+	return models.NewCred{
+		ID:        id,
+		Title:     cred.GetTitle(),
+		Login:     cred.GetLogin(),
+		Passwd:    cred.GetPasswd(),
+		Notes:     cred.GetNotes(),
+		Version:   int(cred.GetVersion()),
+		Signature: cred.GetSignature(),
+	}, nil
+}
+
+// FileInfoToPB converts stored file to message without content
+func FileInfoToPB(file models.File) *FileInfo {
+	return &FileInfo{
+		Id:        file.ID.String(),
+		Title:     file.Title,
+		FileName:  file.FileName,
+		Notes:     file.Notes,
+		Version:   int64(file.Version),
+		Signature: file.Signature,
+		Created:   timeToPB(file.Created),
+		Changed:   timeToPB(file.Changed.Get()),
+	}
+}
+
+// FileFromPB converts message and content to stored file
+func FileFromPB(info *FileInfo, data []byte) (models.File, error) {
+	id, idErr := ParseID(info.GetId())
+	if idErr != nil {
+		return models.File{}, idErr
+	}
+	return models.File{
+		ID:        id,
+		Title:     info.GetTitle(),
+		File:      data,
+		FileName:  info.GetFileName(),
+		Notes:     info.GetNotes(),
+		Version:   int(info.GetVersion()),
+		Signature: info.GetSignature(),
+		Created:   timeFromPB(info.GetCreated()),
+		Changed:   changedFromPB(info.GetChanged()),
+	}, nil
+}
+
+// NewFileToPB converts posted file to message without content
+func NewFileToPB(file *models.NewFile) *FileInfo {
+	return &FileInfo{
+		Id:        file.ID.String(),
+		Title:     file.Title,
+		FileName:  file.FileName,
+		Notes:     file.Notes,
+		Version:   int64(file.Version),
+		Signature: file.Signature,
+	}
+}
+
+// NewFileFromPB converts message and content to posted file
+func NewFileFromPB(info *FileInfo, data []byte) (models.NewFile, error) {
+	id, idErr := ParseID(info.GetId())
+	if idErr != nil {
+		return models.NewFile{}, idErr
+	}
+	return models.NewFile{
+		ID:        id,
+		Title:     info.GetTitle(),
+		FileName:  info.GetFileName(),
+		File:      data,
+		Notes:     info.GetNotes(),
+		Version:   int(info.GetVersion()),
+		Signature: info.GetSignature(),
+	}, nil
+}
+
+// VaultToPB converts all elements of user to message
+func VaultToPB(vault *models.Vault) *Vault {
+	result := &Vault{}
+	for _, note := range vault.Notes {
+		result.Notes = append(result.Notes, NoteToPB(note))
+	}
+	for _, card := range vault.Cards {
+		result.Cards = append(result.Cards, CardToPB(card))
+	}
+	for _, cred := range vault.Creds {
+		result.Creds = append(result.Creds, CredToPB(cred))
+	}
+	for _, file := range vault.Files {
+		result.Files = append(result.Files, &File{Info: FileInfoToPB(file), Data: file.File})
+	}
+	return result
+}
+
+// VaultFromPB converts message to all elements of user
+func VaultFromPB(vault *Vault) (*models.Vault, error) {
+	result := &models.Vault{}
+	for _, pbNote := range vault.GetNotes() {
+		note, noteErr := NoteFromPB(pbNote)
+		if noteErr != nil {
+			return nil, noteErr
+		}
+		result.Notes = append(result.Notes, note)
+	}
+	for _, pbCard := range vault.GetCards() {
+		card, cardErr := CardFromPB(pbCard)
+		if cardErr != nil {
+			return nil, cardErr
+		}
+		result.Cards = append(result.Cards, card)
+	}
+	for _, pbCred := range vault.GetCreds() {
+		cred, credErr := CredFromPB(pbCred)
+		if credErr != nil {
+			return nil, credErr
+		}
+		result.Creds = append(result.Creds, cred)
+	}
+	for _, pbFile := range vault.GetFiles() {
+		file, fileErr := FileFromPB(pbFile.GetInfo(), pbFile.GetData())
+		if fileErr != nil {
+			return nil, fileErr
+		}
+		result.Files = append(result.Files, file)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package keeperpb
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertVault(t *testing.T) {
+	created := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	vault := &models.Vault{
+		Notes: []models.Note{{ID: uuid.New(), Title: "title", Note: "note", Version: 2, Signature: "sign", Created: created,
+			Changed: &models.NullTime{Time: created.Add(time.Hour), Valid: true}}},
+		Cards: []models.Card{{ID: uuid.New(), CardNumber: "4111111111111111", CardOwner: "OWNER", CardExp: "12/25", Version: 1, Created: created}},
+		Creds: []models.Cred{{ID: uuid.New(), Login: "login", Passwd: "passwd", Notes: "notes", Version: 1, Created: created}},
+		Files: []models.File{{ID: uuid.New(), Title: "title", FileName: "file.txt", File: []byte("content"), Version: 3, Created: created}},
+	}
+
+	converted, convertErr := VaultFromPB(VaultToPB(vault))
+	assert.NoError(t, convertErr)
+	assert.Equal(t, vault, converted)
+}
+
+func TestConvertTokens(t *testing.T) {
+	expires := time.Date(2022, 12, 1, 10, 15, 0, 0, time.UTC)
+	refreshExpires := expires.Add(30 * 24 * time.Hour)
+	tokens := &models.Tokens{Token: "Bearer access", TokenExp: expires, RefreshToken: "refresh", RefreshExp: refreshExpires}
+	assert.Equal(t, tokens, TokensFromPB(TokensToPB(tokens)))
+
+	user := &models.User{ID: uuid.New(), Username: "user", Email: "user@example.com", Token: tokens.Token, TokenExp: expires,
+		RefreshToken: tokens.RefreshToken, RefreshExp: &refreshExpires}
+	converted, convertErr := UserFromPB(UserToPB(user))
+	assert.NoError(t, convertErr)
+	assert.Equal(t, user, converted)
+	assert.Nil(t, ChallengeFromPB(UserToPB(user)))
+
+	challenge := &models.TwoFactorChallenge{Challenge: "challenge", Expires: expires}
+	assert.Equal(t, challenge, ChallengeFromPB(ChallengeToPB(challenge)))
+}
+
+func TestConvertSessions(t *testing.T) {
+	created := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	sessions := []models.Session{
+		{ID: uuid.New(), DeviceName: "laptop", ClientVersion: "1.2.0", IP: "10.0.0.1", UserAgent: "keeper", Created: created,
+			LastSeen: created.Add(time.Hour), Current: true},
+		{ID: uuid.New(), DeviceName: "phone", Created: created, LastSeen: created},
+	}
+	converted, convertErr := SessionsFromPB(SessionsToPB(sessions))
+	assert.NoError(t, convertErr)
+	assert.Equal(t, sessions, converted)
+}
+
+func TestConvertEnrollment(t *testing.T) {
+	created := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	enrollment := &models.Enrollment{ID: uuid.New(), Code: "ABCD-EFGH-IJKL-MNOP", DevicePublic: []byte("public"),
+		Bundle: []byte("bundle"), Created: created, Expires: created.Add(10 * time.Minute)}
+	converted, convertErr := EnrollmentFromPB(EnrollmentToPB(enrollment))
+	assert.NoError(t, convertErr)
+	assert.Equal(t, enrollment, converted)
+}
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		want    uuid.UUID
+		wantErr bool
+	}{
+		{name: "empty", id: "", want: uuid.Nil},
+		{name: "valid", id: "0b5c7a7e-7b89-4a8b-9c0e-1f1b2a3c4d5e", want: uuid.MustParse("0b5c7a7e-7b89-4a8b-9c0e-1f1b2a3c4d5e")},
+		{name: "invalid", id: "1234", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, idErr := ParseID(tt.id)
+			if tt.wantErr {
+				assert.Error(t, idErr)
+				return
+			}
+			assert.NoError(t, idErr)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// Package keeperpb contains protobuf messages and gRPC service of GophKeeper
+// with conversions between them and models
+package keeperpb
+
+//go:generate buf generate
@@ -0,0 +1,1790 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: keeper.proto
+
+package keeperpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// KeeperClient is the client API for Keeper service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KeeperClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*User, error)
+	// Login returns only challenge in User if user enabled two-factor authentication,
+	// login is completed by LoginTwoFactor
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*User, error)
+	LoginTwoFactor(ctx context.Context, in *TwoFactorLoginRequest, opts ...grpc.CallOption) (*User, error)
+	RefreshToken(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*Tokens, error)
+	Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// LogoutEverywhere revokes all tokens of user on every device
+	LogoutEverywhere(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetMe(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*User, error)
+	SetKey(ctx context.Context, in *SetKeyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListSessions returns devices where user is logged in, session of call is marked as current
+	ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SessionList, error)
+	// RevokeSession revokes session with all its tokens, id of session is in ElementID
+	RevokeSession(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// SetupTwoFactor generates TOTP secret, it is enabled by ConfirmTwoFactor
+	SetupTwoFactor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TwoFactorSetup, error)
+	// ConfirmTwoFactor enables two-factor authentication, recovery codes are returned only once
+	ConfirmTwoFactor(ctx context.Context, in *TwoFactorCode, opts ...grpc.CallOption) (*RecoveryCodes, error)
+	DisableTwoFactor(ctx context.Context, in *TwoFactorCode, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ChangePassword changes password of user, other sessions of user are revoked
+	ChangePassword(ctx context.Context, in *PasswordChange, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeleteAccount deletes user with all elements, code is required if two-factor authentication is enabled
+	DeleteAccount(ctx context.Context, in *AccountDeletion, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// VerifyEmail confirms email of user by code from message
+	VerifyEmail(ctx context.Context, in *EmailVerification, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ResendVerification sends new code that confirms email of user
+	ResendVerification(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ForgotPassword sends code of password reset to email, result is the same whether there is such user or not
+	ForgotPassword(ctx context.Context, in *PasswordForgot, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ResetPassword sets new password by code of password reset, all sessions of user are revoked
+	ResetPassword(ctx context.Context, in *PasswordReset, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// StartEnrollment saves ephemeral public key of new device, enrollment has code that user enters on approving device
+	StartEnrollment(ctx context.Context, in *NewEnrollment, opts ...grpc.CallOption) (*Enrollment, error)
+	// GetEnrollment returns enrollment by code, new device polls it until sealed bundle appears
+	GetEnrollment(ctx context.Context, in *EnrollmentCode, opts ...grpc.CallOption) (*Enrollment, error)
+	// ApproveEnrollment saves key bundle sealed for public key of new device
+	ApproveEnrollment(ctx context.Context, in *EnrollmentApprove, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteEnrollment(ctx context.Context, in *EnrollmentCode, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListNotes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NoteList, error)
+	GetNote(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*Note, error)
+	CreateNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error)
+	EditNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error)
+	DeleteNote(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CardList, error)
+	GetCard(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*Card, error)
+	CreateCard(ctx context.Context, in *Card, opts ...grpc.CallOption) (*Card, error)
+	EditCard(ctx context.Context, in *Card, opts ...grpc.CallOption) (*Card, error)
+	DeleteCard(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListCreds(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CredList, error)
+	GetCred(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*Cred, error)
+	CreateCred(ctx context.Context, in *Cred, opts ...grpc.CallOption) (*Cred, error)
+	EditCred(ctx context.Context, in *Cred, opts ...grpc.CallOption) (*Cred, error)
+	DeleteCred(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListFiles returns files without content
+	ListFiles(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FileList, error)
+	// DownloadFile streams file info in the first chunk and content in the next ones
+	DownloadFile(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (Keeper_DownloadFileClient, error)
+	// CreateFile and EditFile take file info in the first chunk and content in the next ones,
+	// edited file without content chunks keeps current content
+	CreateFile(ctx context.Context, opts ...grpc.CallOption) (Keeper_CreateFileClient, error)
+	EditFile(ctx context.Context, opts ...grpc.CallOption) (Keeper_EditFileClient, error)
+	DeleteFile(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ReplaceVault(ctx context.Context, in *Vault, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type keeperClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKeeperClient(cc grpc.ClientConnInterface) KeeperClient {
+	return &keeperClient{cc}
+}
+
+func (c *keeperClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/Register", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/Login", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) LoginTwoFactor(ctx context.Context, in *TwoFactorLoginRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/LoginTwoFactor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) RefreshToken(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*Tokens, error) {
+	out := new(Tokens)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/RefreshToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) Logout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/Logout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) LogoutEverywhere(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/LogoutEverywhere", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetMe(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/GetMe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) SetKey(ctx context.Context, in *SetKeyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/SetKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ListSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SessionList, error) {
+	out := new(SessionList)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) RevokeSession(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/RevokeSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) SetupTwoFactor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TwoFactorSetup, error) {
+	out := new(TwoFactorSetup)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/SetupTwoFactor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ConfirmTwoFactor(ctx context.Context, in *TwoFactorCode, opts ...grpc.CallOption) (*RecoveryCodes, error) {
+	out := new(RecoveryCodes)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ConfirmTwoFactor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DisableTwoFactor(ctx context.Context, in *TwoFactorCode, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/DisableTwoFactor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ChangePassword(ctx context.Context, in *PasswordChange, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ChangePassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeleteAccount(ctx context.Context, in *AccountDeletion, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/DeleteAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) VerifyEmail(ctx context.Context, in *EmailVerification, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/VerifyEmail", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ResendVerification(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ResendVerification", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ForgotPassword(ctx context.Context, in *PasswordForgot, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ForgotPassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ResetPassword(ctx context.Context, in *PasswordReset, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ResetPassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) StartEnrollment(ctx context.Context, in *NewEnrollment, opts ...grpc.CallOption) (*Enrollment, error) {
+	out := new(Enrollment)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/StartEnrollment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetEnrollment(ctx context.Context, in *EnrollmentCode, opts ...grpc.CallOption) (*Enrollment, error) {
+	out := new(Enrollment)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/GetEnrollment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ApproveEnrollment(ctx context.Context, in *EnrollmentApprove, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ApproveEnrollment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeleteEnrollment(ctx context.Context, in *EnrollmentCode, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/DeleteEnrollment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ListNotes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NoteList, error) {
+	out := new(NoteList)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ListNotes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetNote(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*Note, error) {
+	out := new(Note)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/GetNote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) CreateNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error) {
+	out := new(Note)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/CreateNote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) EditNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error) {
+	out := new(Note)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/EditNote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeleteNote(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/DeleteNote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ListCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CardList, error) {
+	out := new(CardList)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ListCards", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetCard(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*Card, error) {
+	out := new(Card)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/GetCard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) CreateCard(ctx context.Context, in *Card, opts ...grpc.CallOption) (*Card, error) {
+	out := new(Card)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/CreateCard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) EditCard(ctx context.Context, in *Card, opts ...grpc.CallOption) (*Card, error) {
+	out := new(Card)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/EditCard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeleteCard(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/DeleteCard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ListCreds(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CredList, error) {
+	out := new(CredList)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ListCreds", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetCred(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*Cred, error) {
+	out := new(Cred)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/GetCred", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) CreateCred(ctx context.Context, in *Cred, opts ...grpc.CallOption) (*Cred, error) {
+	out := new(Cred)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/CreateCred", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) EditCred(ctx context.Context, in *Cred, opts ...grpc.CallOption) (*Cred, error) {
+	out := new(Cred)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/EditCred", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeleteCred(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/DeleteCred", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ListFiles(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FileList, error) {
+	out := new(FileList)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ListFiles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DownloadFile(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (Keeper_DownloadFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Keeper_ServiceDesc.Streams[0], "/keeper.Keeper/DownloadFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keeperDownloadFileClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Keeper_DownloadFileClient interface {
+	Recv() (*FileChunk, error)
+	grpc.ClientStream
+}
+
+type keeperDownloadFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *keeperDownloadFileClient) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keeperClient) CreateFile(ctx context.Context, opts ...grpc.CallOption) (Keeper_CreateFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Keeper_ServiceDesc.Streams[1], "/keeper.Keeper/CreateFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keeperCreateFileClient{stream}
+	return x, nil
+}
+
+type Keeper_CreateFileClient interface {
+	Send(*FileChunk) error
+	CloseAndRecv() (*FileInfo, error)
+	grpc.ClientStream
+}
+
+type keeperCreateFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *keeperCreateFileClient) Send(m *FileChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *keeperCreateFileClient) CloseAndRecv() (*FileInfo, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(FileInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keeperClient) EditFile(ctx context.Context, opts ...grpc.CallOption) (Keeper_EditFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Keeper_ServiceDesc.Streams[2], "/keeper.Keeper/EditFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keeperEditFileClient{stream}
+	return x, nil
+}
+
+type Keeper_EditFileClient interface {
+	Send(*FileChunk) error
+	CloseAndRecv() (*FileInfo, error)
+	grpc.ClientStream
+}
+
+type keeperEditFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *keeperEditFileClient) Send(m *FileChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *keeperEditFileClient) CloseAndRecv() (*FileInfo, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(FileInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keeperClient) DeleteFile(ctx context.Context, in *ElementID, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/DeleteFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ReplaceVault(ctx context.Context, in *Vault, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/keeper.Keeper/ReplaceVault", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KeeperServer is the server API for Keeper service.
+// All implementations must embed UnimplementedKeeperServer
+// for forward compatibility
+type KeeperServer interface {
+	Register(context.Context, *RegisterRequest) (*User, error)
+	// Login returns only challenge in User if user enabled two-factor authentication,
+	// login is completed by LoginTwoFactor
+	Login(context.Context, *LoginRequest) (*User, error)
+	LoginTwoFactor(context.Context, *TwoFactorLoginRequest) (*User, error)
+	RefreshToken(context.Context, *RefreshRequest) (*Tokens, error)
+	Logout(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// LogoutEverywhere revokes all tokens of user on every device
+	LogoutEverywhere(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	GetMe(context.Context, *emptypb.Empty) (*User, error)
+	SetKey(context.Context, *SetKeyRequest) (*emptypb.Empty, error)
+	// ListSessions returns devices where user is logged in, session of call is marked as current
+	ListSessions(context.Context, *emptypb.Empty) (*SessionList, error)
+	// RevokeSession revokes session with all its tokens, id of session is in ElementID
+	RevokeSession(context.Context, *ElementID) (*emptypb.Empty, error)
+	// SetupTwoFactor generates TOTP secret, it is enabled by ConfirmTwoFactor
+	SetupTwoFactor(context.Context, *emptypb.Empty) (*TwoFactorSetup, error)
+	// ConfirmTwoFactor enables two-factor authentication, recovery codes are returned only once
+	ConfirmTwoFactor(context.Context, *TwoFactorCode) (*RecoveryCodes, error)
+	DisableTwoFactor(context.Context, *TwoFactorCode) (*emptypb.Empty, error)
+	// ChangePassword changes password of user, other sessions of user are revoked
+	ChangePassword(context.Context, *PasswordChange) (*emptypb.Empty, error)
+	// DeleteAccount deletes user with all elements, code is required if two-factor authentication is enabled
+	DeleteAccount(context.Context, *AccountDeletion) (*emptypb.Empty, error)
+	// VerifyEmail confirms email of user by code from message
+	VerifyEmail(context.Context, *EmailVerification) (*emptypb.Empty, error)
+	// ResendVerification sends new code that confirms email of user
+	ResendVerification(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// ForgotPassword sends code of password reset to email, result is the same whether there is such user or not
+	ForgotPassword(context.Context, *PasswordForgot) (*emptypb.Empty, error)
+	// ResetPassword sets new password by code of password reset, all sessions of user are revoked
+	ResetPassword(context.Context, *PasswordReset) (*emptypb.Empty, error)
+	// StartEnrollment saves ephemeral public key of new device, enrollment has code that user enters on approving device
+	StartEnrollment(context.Context, *NewEnrollment) (*Enrollment, error)
+	// GetEnrollment returns enrollment by code, new device polls it until sealed bundle appears
+	GetEnrollment(context.Context, *EnrollmentCode) (*Enrollment, error)
+	// ApproveEnrollment saves key bundle sealed for public key of new device
+	ApproveEnrollment(context.Context, *EnrollmentApprove) (*emptypb.Empty, error)
+	DeleteEnrollment(context.Context, *EnrollmentCode) (*emptypb.Empty, error)
+	ListNotes(context.Context, *emptypb.Empty) (*NoteList, error)
+	GetNote(context.Context, *ElementID) (*Note, error)
+	CreateNote(context.Context, *Note) (*Note, error)
+	EditNote(context.Context, *Note) (*Note, error)
+	DeleteNote(context.Context, *ElementID) (*emptypb.Empty, error)
+	ListCards(context.Context, *emptypb.Empty) (*CardList, error)
+	GetCard(context.Context, *ElementID) (*Card, error)
+	CreateCard(context.Context, *Card) (*Card, error)
+	EditCard(context.Context, *Card) (*Card, error)
+	DeleteCard(context.Context, *ElementID) (*emptypb.Empty, error)
+	ListCreds(context.Context, *emptypb.Empty) (*CredList, error)
+	GetCred(context.Context, *ElementID) (*Cred, error)
+	CreateCred(context.Context, *Cred) (*Cred, error)
+	EditCred(context.Context, *Cred) (*Cred, error)
+	DeleteCred(context.Context, *ElementID) (*emptypb.Empty, error)
+	// ListFiles returns files without content
+	ListFiles(context.Context, *emptypb.Empty) (*FileList, error)
+	// DownloadFile streams file info in the first chunk and content in the next ones
+	DownloadFile(*ElementID, Keeper_DownloadFileServer) error
+	// CreateFile and EditFile take file info in the first chunk and content in the next ones,
+	// edited file without content chunks keeps current content
+	CreateFile(Keeper_CreateFileServer) error
+	EditFile(Keeper_EditFileServer) error
+	DeleteFile(context.Context, *ElementID) (*emptypb.Empty, error)
+	ReplaceVault(context.Context, *Vault) (*emptypb.Empty, error)
+	mustEmbedUnimplementedKeeperServer()
+}
+
+// UnimplementedKeeperServer must be embedded to have forward compatible implementations.
+type UnimplementedKeeperServer struct {
+}
+
+func (UnimplementedKeeperServer) Register(context.Context, *RegisterRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedKeeperServer) Login(context.Context, *LoginRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedKeeperServer) LoginTwoFactor(context.Context, *TwoFactorLoginRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginTwoFactor not implemented")
+}
+func (UnimplementedKeeperServer) RefreshToken(context.Context, *RefreshRequest) (*Tokens, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedKeeperServer) Logout(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedKeeperServer) LogoutEverywhere(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogoutEverywhere not implemented")
+}
+func (UnimplementedKeeperServer) GetMe(context.Context, *emptypb.Empty) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMe not implemented")
+}
+func (UnimplementedKeeperServer) SetKey(context.Context, *SetKeyRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetKey not implemented")
+}
+func (UnimplementedKeeperServer) ListSessions(context.Context, *emptypb.Empty) (*SessionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedKeeperServer) RevokeSession(context.Context, *ElementID) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedKeeperServer) SetupTwoFactor(context.Context, *emptypb.Empty) (*TwoFactorSetup, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetupTwoFactor not implemented")
+}
+func (UnimplementedKeeperServer) ConfirmTwoFactor(context.Context, *TwoFactorCode) (*RecoveryCodes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTwoFactor not implemented")
+}
+func (UnimplementedKeeperServer) DisableTwoFactor(context.Context, *TwoFactorCode) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTwoFactor not implemented")
+}
+func (UnimplementedKeeperServer) ChangePassword(context.Context, *PasswordChange) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedKeeperServer) DeleteAccount(context.Context, *AccountDeletion) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
+func (UnimplementedKeeperServer) VerifyEmail(context.Context, *EmailVerification) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyEmail not implemented")
+}
+func (UnimplementedKeeperServer) ResendVerification(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResendVerification not implemented")
+}
+func (UnimplementedKeeperServer) ForgotPassword(context.Context, *PasswordForgot) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForgotPassword not implemented")
+}
+func (UnimplementedKeeperServer) ResetPassword(context.Context, *PasswordReset) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetPassword not implemented")
+}
+func (UnimplementedKeeperServer) StartEnrollment(context.Context, *NewEnrollment) (*Enrollment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartEnrollment not implemented")
+}
+func (UnimplementedKeeperServer) GetEnrollment(context.Context, *EnrollmentCode) (*Enrollment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEnrollment not implemented")
+}
+func (UnimplementedKeeperServer) ApproveEnrollment(context.Context, *EnrollmentApprove) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveEnrollment not implemented")
+}
+func (UnimplementedKeeperServer) DeleteEnrollment(context.Context, *EnrollmentCode) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteEnrollment not implemented")
+}
+func (UnimplementedKeeperServer) ListNotes(context.Context, *emptypb.Empty) (*NoteList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNotes not implemented")
+}
+func (UnimplementedKeeperServer) GetNote(context.Context, *ElementID) (*Note, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNote not implemented")
+}
+func (UnimplementedKeeperServer) CreateNote(context.Context, *Note) (*Note, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateNote not implemented")
+}
+func (UnimplementedKeeperServer) EditNote(context.Context, *Note) (*Note, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditNote not implemented")
+}
+func (UnimplementedKeeperServer) DeleteNote(context.Context, *ElementID) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNote not implemented")
+}
+func (UnimplementedKeeperServer) ListCards(context.Context, *emptypb.Empty) (*CardList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCards not implemented")
+}
+func (UnimplementedKeeperServer) GetCard(context.Context, *ElementID) (*Card, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCard not implemented")
+}
+func (UnimplementedKeeperServer) CreateCard(context.Context, *Card) (*Card, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCard not implemented")
+}
+func (UnimplementedKeeperServer) EditCard(context.Context, *Card) (*Card, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditCard not implemented")
+}
+func (UnimplementedKeeperServer) DeleteCard(context.Context, *ElementID) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCard not implemented")
+}
+func (UnimplementedKeeperServer) ListCreds(context.Context, *emptypb.Empty) (*CredList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCreds not implemented")
+}
+func (UnimplementedKeeperServer) GetCred(context.Context, *ElementID) (*Cred, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCred not implemented")
+}
+func (UnimplementedKeeperServer) CreateCred(context.Context, *Cred) (*Cred, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCred not implemented")
+}
+func (UnimplementedKeeperServer) EditCred(context.Context, *Cred) (*Cred, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditCred not implemented")
+}
+func (UnimplementedKeeperServer) DeleteCred(context.Context, *ElementID) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCred not implemented")
+}
+func (UnimplementedKeeperServer) ListFiles(context.Context, *emptypb.Empty) (*FileList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFiles not implemented")
+}
+func (UnimplementedKeeperServer) DownloadFile(*ElementID, Keeper_DownloadFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
+}
+func (UnimplementedKeeperServer) CreateFile(Keeper_CreateFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateFile not implemented")
+}
+func (UnimplementedKeeperServer) EditFile(Keeper_EditFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method EditFile not implemented")
+}
+func (UnimplementedKeeperServer) DeleteFile(context.Context, *ElementID) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFile not implemented")
+}
+func (UnimplementedKeeperServer) ReplaceVault(context.Context, *Vault) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplaceVault not implemented")
+}
+func (UnimplementedKeeperServer) mustEmbedUnimplementedKeeperServer() {}
+
+// UnsafeKeeperServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KeeperServer will
+// result in compilation errors.
+type UnsafeKeeperServer interface {
+	mustEmbedUnimplementedKeeperServer()
+}
+
+func RegisterKeeperServer(s grpc.ServiceRegistrar, srv KeeperServer) {
+	s.RegisterService(&Keeper_ServiceDesc, srv)
+}
+
+func _Keeper_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_LoginTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TwoFactorLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).LoginTwoFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/LoginTwoFactor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).LoginTwoFactor(ctx, req.(*TwoFactorLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/RefreshToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).RefreshToken(ctx, req.(*RefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/Logout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).Logout(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_LogoutEverywhere_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).LogoutEverywhere(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/LogoutEverywhere",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).LogoutEverywhere(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetMe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetMe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/GetMe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetMe(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_SetKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).SetKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/SetKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).SetKey(ctx, req.(*SetKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ListSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/RevokeSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).RevokeSession(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_SetupTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).SetupTwoFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/SetupTwoFactor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).SetupTwoFactor(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ConfirmTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TwoFactorCode)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ConfirmTwoFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ConfirmTwoFactor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ConfirmTwoFactor(ctx, req.(*TwoFactorCode))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DisableTwoFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TwoFactorCode)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DisableTwoFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/DisableTwoFactor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DisableTwoFactor(ctx, req.(*TwoFactorCode))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PasswordChange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ChangePassword(ctx, req.(*PasswordChange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountDeletion)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/DeleteAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteAccount(ctx, req.(*AccountDeletion))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_VerifyEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmailVerification)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).VerifyEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/VerifyEmail",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).VerifyEmail(ctx, req.(*EmailVerification))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ResendVerification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ResendVerification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ResendVerification",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ResendVerification(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ForgotPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PasswordForgot)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ForgotPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ForgotPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ForgotPassword(ctx, req.(*PasswordForgot))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ResetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PasswordReset)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ResetPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ResetPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ResetPassword(ctx, req.(*PasswordReset))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_StartEnrollment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewEnrollment)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).StartEnrollment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/StartEnrollment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).StartEnrollment(ctx, req.(*NewEnrollment))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetEnrollment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollmentCode)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetEnrollment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/GetEnrollment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetEnrollment(ctx, req.(*EnrollmentCode))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ApproveEnrollment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollmentApprove)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ApproveEnrollment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ApproveEnrollment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ApproveEnrollment(ctx, req.(*EnrollmentApprove))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeleteEnrollment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollmentCode)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteEnrollment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/DeleteEnrollment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteEnrollment(ctx, req.(*EnrollmentCode))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ListNotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ListNotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ListNotes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ListNotes(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/GetNote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetNote(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_CreateNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Note)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).CreateNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/CreateNote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).CreateNote(ctx, req.(*Note))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_EditNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Note)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).EditNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/EditNote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).EditNote(ctx, req.(*Note))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeleteNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/DeleteNote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteNote(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ListCards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ListCards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ListCards",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ListCards(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/GetCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetCard(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_CreateCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Card)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).CreateCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/CreateCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).CreateCard(ctx, req.(*Card))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_EditCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Card)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).EditCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/EditCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).EditCard(ctx, req.(*Card))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeleteCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/DeleteCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteCard(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ListCreds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ListCreds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ListCreds",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ListCreds(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetCred_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetCred(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/GetCred",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetCred(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_CreateCred_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Cred)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).CreateCred(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/CreateCred",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).CreateCred(ctx, req.(*Cred))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_EditCred_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Cred)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).EditCred(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/EditCred",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).EditCred(ctx, req.(*Cred))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeleteCred_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteCred(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/DeleteCred",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteCred(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ListFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ListFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ListFiles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ListFiles(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ElementID)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeeperServer).DownloadFile(m, &keeperDownloadFileServer{stream})
+}
+
+type Keeper_DownloadFileServer interface {
+	Send(*FileChunk) error
+	grpc.ServerStream
+}
+
+type keeperDownloadFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *keeperDownloadFileServer) Send(m *FileChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Keeper_CreateFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(KeeperServer).CreateFile(&keeperCreateFileServer{stream})
+}
+
+type Keeper_CreateFileServer interface {
+	SendAndClose(*FileInfo) error
+	Recv() (*FileChunk, error)
+	grpc.ServerStream
+}
+
+type keeperCreateFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *keeperCreateFileServer) SendAndClose(m *FileInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *keeperCreateFileServer) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Keeper_EditFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(KeeperServer).EditFile(&keeperEditFileServer{stream})
+}
+
+type Keeper_EditFileServer interface {
+	SendAndClose(*FileInfo) error
+	Recv() (*FileChunk, error)
+	grpc.ServerStream
+}
+
+type keeperEditFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *keeperEditFileServer) SendAndClose(m *FileInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *keeperEditFileServer) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Keeper_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElementID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/DeleteFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteFile(ctx, req.(*ElementID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ReplaceVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vault)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ReplaceVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.Keeper/ReplaceVault",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ReplaceVault(ctx, req.(*Vault))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Keeper_ServiceDesc is the grpc.ServiceDesc for Keeper service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Keeper_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keeper.Keeper",
+	HandlerType: (*KeeperServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _Keeper_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _Keeper_Login_Handler,
+		},
+		{
+			MethodName: "LoginTwoFactor",
+			Handler:    _Keeper_LoginTwoFactor_Handler,
+		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _Keeper_RefreshToken_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _Keeper_Logout_Handler,
+		},
+		{
+			MethodName: "LogoutEverywhere",
+			Handler:    _Keeper_LogoutEverywhere_Handler,
+		},
+		{
+			MethodName: "GetMe",
+			Handler:    _Keeper_GetMe_Handler,
+		},
+		{
+			MethodName: "SetKey",
+			Handler:    _Keeper_SetKey_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _Keeper_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _Keeper_RevokeSession_Handler,
+		},
+		{
+			MethodName: "SetupTwoFactor",
+			Handler:    _Keeper_SetupTwoFactor_Handler,
+		},
+		{
+			MethodName: "ConfirmTwoFactor",
+			Handler:    _Keeper_ConfirmTwoFactor_Handler,
+		},
+		{
+			MethodName: "DisableTwoFactor",
+			Handler:    _Keeper_DisableTwoFactor_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _Keeper_ChangePassword_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _Keeper_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "VerifyEmail",
+			Handler:    _Keeper_VerifyEmail_Handler,
+		},
+		{
+			MethodName: "ResendVerification",
+			Handler:    _Keeper_ResendVerification_Handler,
+		},
+		{
+			MethodName: "ForgotPassword",
+			Handler:    _Keeper_ForgotPassword_Handler,
+		},
+		{
+			MethodName: "ResetPassword",
+			Handler:    _Keeper_ResetPassword_Handler,
+		},
+		{
+			MethodName: "StartEnrollment",
+			Handler:    _Keeper_StartEnrollment_Handler,
+		},
+		{
+			MethodName: "GetEnrollment",
+			Handler:    _Keeper_GetEnrollment_Handler,
+		},
+		{
+			MethodName: "ApproveEnrollment",
+			Handler:    _Keeper_ApproveEnrollment_Handler,
+		},
+		{
+			MethodName: "DeleteEnrollment",
+			Handler:    _Keeper_DeleteEnrollment_Handler,
+		},
+		{
+			MethodName: "ListNotes",
+			Handler:    _Keeper_ListNotes_Handler,
+		},
+		{
+			MethodName: "GetNote",
+			Handler:    _Keeper_GetNote_Handler,
+		},
+		{
+			MethodName: "CreateNote",
+			Handler:    _Keeper_CreateNote_Handler,
+		},
+		{
+			MethodName: "EditNote",
+			Handler:    _Keeper_EditNote_Handler,
+		},
+		{
+			MethodName: "DeleteNote",
+			Handler:    _Keeper_DeleteNote_Handler,
+		},
+		{
+			MethodName: "ListCards",
+			Handler:    _Keeper_ListCards_Handler,
+		},
+		{
+			MethodName: "GetCard",
+			Handler:    _Keeper_GetCard_Handler,
+		},
+		{
+			MethodName: "CreateCard",
+			Handler:    _Keeper_CreateCard_Handler,
+		},
+		{
+			MethodName: "EditCard",
+			Handler:    _Keeper_EditCard_Handler,
+		},
+		{
+			MethodName: "DeleteCard",
+			Handler:    _Keeper_DeleteCard_Handler,
+		},
+		{
+			MethodName: "ListCreds",
+			Handler:    _Keeper_ListCreds_Handler,
+		},
+		{
+			MethodName: "GetCred",
+			Handler:    _Keeper_GetCred_Handler,
+		},
+		{
+			MethodName: "CreateCred",
+			Handler:    _Keeper_CreateCred_Handler,
+		},
+		{
+			MethodName: "EditCred",
+			Handler:    _Keeper_EditCred_Handler,
+		},
+		{
+			MethodName: "DeleteCred",
+			Handler:    _Keeper_DeleteCred_Handler,
+		},
+		{
+			MethodName: "ListFiles",
+			Handler:    _Keeper_ListFiles_Handler,
+		},
+		{
+			MethodName: "DeleteFile",
+			Handler:    _Keeper_DeleteFile_Handler,
+		},
+		{
+			MethodName: "ReplaceVault",
+			Handler:    _Keeper_ReplaceVault_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DownloadFile",
+			Handler:       _Keeper_DownloadFile_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CreateFile",
+			Handler:       _Keeper_CreateFile_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "EditFile",
+			Handler:       _Keeper_EditFile_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "keeper.proto",
+}
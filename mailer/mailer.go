@@ -0,0 +1,70 @@
+// Package mailer sends messages to users: confirmations of email and links of password reset.
+// Messages are sent by SMTP, without SMTP server they are written to file or log
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrHeader = errors.New("header of message contains line break")
+
+// Message plain text message to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends messages
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// FileMailer writes messages to file instead of sending them, it is used in development and tests.
+// Messages are written to log if path is empty
+type FileMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileMailer initializer of FileMailer, messages are appended to file of path
+func NewFileMailer(path string) *FileMailer {
+	return &FileMailer{path: path}
+}
+
+// Send appends message to file with time of sending
+func (m *FileMailer) Send(msg *Message) error {
+	if headerErr := checkHeaders(msg); headerErr != nil {
+		return headerErr
+	}
+	text := fmt.Sprintf("Date: %s\nTo: %s\nSubject: %s\n\n%s\n", time.Now().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+	if m.path == "" {
+		log.Printf("mail is not sent, SMTP is not configured:\n%s", text)
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, openErr := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if openErr != nil {
+		return openErr
+	}
+	if _, writeErr := f.WriteString(text + "\n"); writeErr != nil {
+		f.Close()
+		return writeErr
+	}
+	return f.Close()
+}
+
+// checkHeaders rejects recipient and subject with line breaks, they could inject headers
+func checkHeaders(msg *Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return ErrHeader
+	}
+	return nil
+}
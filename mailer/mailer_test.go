@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	templates, loadErr := LoadTemplates("")
+	require.NoError(t, loadErr)
+	data := &TemplateData{Username: "alex", Email: "alex@example.com", Token: "abcd-efgh", Expires: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		subject string
+	}{
+		{name: TemplateVerification, subject: "Confirm your email in GophKeeper"},
+		{name: TemplatePasswordReset, subject: "Reset your GophKeeper password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, renderErr := templates.Render(tt.name, data)
+			require.NoError(t, renderErr)
+			assert.Equal(t, "alex@example.com", msg.To)
+			assert.Equal(t, tt.subject, msg.Subject)
+			assert.Contains(t, msg.Body, "abcd-efgh")
+			assert.True(t, strings.HasPrefix(msg.Body, "Hello, alex!"))
+		})
+	}
+}
+
+func TestLoadCustomTemplates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "verification.tmpl"), []byte("Subject: Welcome\n\nCode {{.Token}}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password_reset.tmpl"), []byte("no subject\n\nCode {{.Token}}\n"), 0o600))
+	templates, loadErr := LoadTemplates(dir)
+	require.NoError(t, loadErr)
+
+	msg, renderErr := templates.Render(TemplateVerification, &TemplateData{Token: "1234"})
+	require.NoError(t, renderErr)
+	assert.Equal(t, "Welcome", msg.Subject)
+	assert.Equal(t, "Code 1234\n", msg.Body)
+
+	_, renderErr = templates.Render(TemplatePasswordReset, &TemplateData{Token: "1234"})
+	assert.ErrorIs(t, renderErr, ErrTemplate)
+}
+
+func TestCompose(t *testing.T) {
+	msg := &Message{To: "alex@example.com", Subject: "Привет", Body: "line one\nline two"}
+	raw := string(compose("GophKeeper <noreply@example.com>", msg, time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)))
+
+	header, body, found := strings.Cut(raw, "\r\n\r\n")
+	require.True(t, found)
+	assert.Contains(t, header, "From: GophKeeper <noreply@example.com>\r\n")
+	assert.Contains(t, header, "To: alex@example.com\r\n")
+	assert.Contains(t, header, "Subject: =?utf-8?q?")
+	assert.Contains(t, header, "Date: Thu, 01 Dec 2022 10:00:00 +0000")
+	assert.Equal(t, "line one\r\nline two\r\n", body)
+}
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.txt")
+	mailer := NewFileMailer(path)
+	require.NoError(t, mailer.Send(&Message{To: "alex@example.com", Subject: "First", Body: "one"}))
+	require.NoError(t, mailer.Send(&Message{To: "alex@example.com", Subject: "Second", Body: "two"}))
+
+	written, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	assert.Contains(t, string(written), "Subject: First\n\none\n")
+	assert.Contains(t, string(written), "Subject: Second\n\ntwo\n")
+
+	injected := mailer.Send(&Message{To: "alex@example.com\r\nBcc: eve@example.com", Subject: "Third"})
+	assert.ErrorIs(t, injected, ErrHeader)
+}
+
+// memoryMailer keeps sent messages
+type memoryMailer struct {
+	sent []*Message
+}
+
+func (m *memoryMailer) Send(msg *Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestSender(t *testing.T) {
+	templates, loadErr := LoadTemplates("")
+	require.NoError(t, loadErr)
+	mailer := &memoryMailer{}
+	sender := NewSender(mailer, templates)
+
+	require.NoError(t, sender.Send(TemplatePasswordReset, &TemplateData{Username: "alex", Email: "alex@example.com", Token: "abcd"}))
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, "alex@example.com", mailer.sent[0].To)
+
+	assert.Error(t, sender.Send("unknown", &TemplateData{Email: "alex@example.com"}))
+	assert.Len(t, mailer.sent, 1)
+}
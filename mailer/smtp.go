@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPMailer sends messages by SMTP server, server authenticates sender if username is set
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer initializer of SMTPMailer, addr is host:port of SMTP server
+func NewSMTPMailer(addr, username, password, from string) (*SMTPMailer, error) {
+	host, _, splitErr := net.SplitHostPort(addr)
+	if splitErr != nil {
+		return nil, splitErr
+	}
+	if _, fromErr := mail.ParseAddress(from); fromErr != nil {
+		return nil, fmt.Errorf("sender address: %w", fromErr)
+	}
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{addr: addr, auth: auth, from: from}, nil
+}
+
+// Send sends message, STARTTLS is used if server supports it
+func (m *SMTPMailer) Send(msg *Message) error {
+	if headerErr := checkHeaders(msg); headerErr != nil {
+		return headerErr
+	}
+	from, _ := mail.ParseAddress(m.from)
+	to, toErr := mail.ParseAddress(msg.To)
+	if toErr != nil {
+		return toErr
+	}
+	return smtp.SendMail(m.addr, m.auth, from.Address, []string{to.Address}, compose(m.from, msg, time.Now()))
+}
+
+// compose returns message in RFC 5322 format, subject is encoded because it could be not ASCII
+func compose(from string, msg *Message, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	buf.WriteString(body)
+	if !strings.HasSuffix(body, "\r\n") {
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,97 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Names of templates of messages
+const (
+	TemplateVerification  = "verification"
+	TemplatePasswordReset = "password_reset"
+)
+
+var ErrTemplate = errors.New("template of message should start with Subject line")
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// TemplateData data of templates of messages
+type TemplateData struct {
+	Username string
+	Email    string
+	Token    string
+	Expires  time.Time
+}
+
+// Templates templates of messages, the first line of template is "Subject: ..."
+// and the rest after empty line is body of message
+type Templates struct {
+	templates *template.Template
+}
+
+// LoadTemplates returns default templates, templates of dir with the same names replace them.
+// Templates are not replaced if dir is empty
+func LoadTemplates(dir string) (*Templates, error) {
+	templates, parseErr := template.ParseFS(defaultTemplates, "templates/*.tmpl")
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if dir != "" {
+		custom, globErr := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if globErr != nil {
+			return nil, globErr
+		}
+		for _, path := range custom {
+			text, readErr := os.ReadFile(path)
+			if readErr != nil {
+				return nil, readErr
+			}
+			if _, customErr := templates.New(filepath.Base(path)).Parse(string(text)); customErr != nil {
+				return nil, customErr
+			}
+		}
+	}
+	return &Templates{templates: templates}, nil
+}
+
+// Render returns message of template to email of data
+func (t *Templates) Render(name string, data *TemplateData) (*Message, error) {
+	var buf bytes.Buffer
+	if execErr := t.templates.ExecuteTemplate(&buf, name+".tmpl", data); execErr != nil {
+		return nil, execErr
+	}
+	header, body, found := strings.Cut(buf.String(), "\n\n")
+	header = strings.TrimSpace(header)
+	if !found || !strings.HasPrefix(header, "Subject:") {
+		return nil, fmt.Errorf("%w: %s", ErrTemplate, name)
+	}
+	return &Message{To: data.Email, Subject: strings.TrimSpace(strings.TrimPrefix(header, "Subject:")), Body: strings.TrimLeft(body, "\n")}, nil
+}
+
+// Sender renders messages by templates and sends them by mailer
+type Sender struct {
+	mailer    Mailer
+	templates *Templates
+}
+
+// NewSender initializer of Sender
+func NewSender(mailer Mailer, templates *Templates) *Sender {
+	return &Sender{mailer: mailer, templates: templates}
+}
+
+// Send renders message of template and sends it to email of data
+func (s *Sender) Send(name string, data *TemplateData) error {
+	msg, renderErr := s.templates.Render(name, data)
+	if renderErr != nil {
+		return renderErr
+	}
+	return s.mailer.Send(msg)
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessKinds kinds of elements that personal access tokens grant, scope of kind is "<kind>:read" or "<kind>:write"
+var AccessKinds = []string{"notes", "cards", "creds", "files"}
+
+// Scopes list of scopes of personal access token, it is stored as space-separated text
+type Scopes []string
+
+// Scan implements the Scanner interface.
+func (s *Scopes) Scan(value interface{}) error {
+	text, err := scanText(value)
+	if err != nil {
+		return err
+	}
+	*s = strings.Fields(text)
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+// ItemIDs list of ids of elements, it is stored as space-separated text
+type ItemIDs []uuid.UUID
+
+// Scan implements the Scanner interface.
+func (ids *ItemIDs) Scan(value interface{}) error {
+	text, err := scanText(value)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(text)
+	parsed := make(ItemIDs, 0, len(fields))
+	for _, field := range fields {
+		id, parseErr := uuid.Parse(field)
+		if parseErr != nil {
+			return parseErr
+		}
+		parsed = append(parsed, id)
+	}
+	*ids = parsed
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (ids ItemIDs) Value() (driver.Value, error) {
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = id.String()
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// scanText returns text of database value
+func scanText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("unexpected type %T of text value", value)
+	}
+}
+
+// AccessToken represents personal access token of user for automation, only hash of token is stored.
+// Token grants scopes of kinds of elements, it is restricted to elements of Items if they are set
+type AccessToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"-" db:"user_id"`
+	Name      string     `json:"name" db:"name"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	Scopes    Scopes     `json:"scopes" db:"scopes"`
+	Items     ItemIDs    `json:"items,omitempty" db:"items"`
+	Created   time.Time  `json:"created" db:"created"`
+	Expires   time.Time  `json:"expires" db:"expires"`
+	LastUsed  *time.Time `json:"last_used,omitempty" db:"last_used"`
+	// Token is set only after token is created, it is not shown again
+	Token string `json:"token,omitempty" db:"-"`
+}
+
+// NewAccessToken represents request of new personal access token
+type NewAccessToken struct {
+	Name    string      `json:"name"`
+	Scopes  []string    `json:"scopes"`
+	Items   []uuid.UUID `json:"items,omitempty"`
+	Expires time.Time   `json:"expires"`
+}
+
+// Permits reports whether token grants reading or writing elements of kind.
+// Nil id means list or new element of kind, token restricted to items permits only reading of lists,
+// lists are filtered by Allows then
+func (t *AccessToken) Permits(kind string, write bool, id uuid.UUID) bool {
+	granted := false
+	for _, scope := range t.Scopes {
+		if scope == kind+":write" || (!write && scope == kind+":read") {
+			granted = true
+			break
+		}
+	}
+	if !granted || len(t.Items) == 0 {
+		return granted
+	}
+	if id == uuid.Nil {
+		return !write
+	}
+	return t.Allows(id)
+}
+
+// Allows reports whether element is available to token, every element is available if token is not restricted to items
+func (t *AccessToken) Allows(id uuid.UUID) bool {
+	if len(t.Items) == 0 {
+		return true
+	}
+	for _, item := range t.Items {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokenPermits(t *testing.T) {
+	item, other := uuid.New(), uuid.New()
+	tests := []struct {
+		name  string
+		token AccessToken
+		kind  string
+		write bool
+		id    uuid.UUID
+		want  bool
+	}{
+		{name: "read by read scope", token: AccessToken{Scopes: Scopes{"creds:read"}}, kind: "creds", id: item, want: true},
+		{name: "write by read scope", token: AccessToken{Scopes: Scopes{"creds:read"}}, kind: "creds", write: true, id: item},
+		{name: "read by write scope", token: AccessToken{Scopes: Scopes{"notes:write"}}, kind: "notes", want: true},
+		{name: "another kind", token: AccessToken{Scopes: Scopes{"creds:write"}}, kind: "cards", id: item},
+		{name: "restricted item", token: AccessToken{Scopes: Scopes{"creds:write"}, Items: ItemIDs{item}}, kind: "creds", write: true, id: item, want: true},
+		{name: "item out of restriction", token: AccessToken{Scopes: Scopes{"creds:read"}, Items: ItemIDs{item}}, kind: "creds", id: other},
+		{name: "list of restricted token", token: AccessToken{Scopes: Scopes{"creds:read"}, Items: ItemIDs{item}}, kind: "creds", want: true},
+		{name: "new element by restricted token", token: AccessToken{Scopes: Scopes{"creds:write"}, Items: ItemIDs{item}}, kind: "creds", write: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.token.Permits(tt.kind, tt.write, tt.id))
+		})
+	}
+}
+
+func TestAccessTokenColumns(t *testing.T) {
+	ids := ItemIDs{uuid.New(), uuid.New()}
+	value, valueErr := ids.Value()
+	require.NoError(t, valueErr)
+	var scanned ItemIDs
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, ids, scanned)
+
+	var scopes Scopes
+	require.NoError(t, scopes.Scan("creds:read notes:write"))
+	assert.Equal(t, Scopes{"creds:read", "notes:write"}, scopes)
+	assert.Error(t, scanned.Scan(42))
+}
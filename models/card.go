@@ -24,19 +24,24 @@ type Card struct {
 	CardOwner  string    `json:"card_owner" db:"card_owner"`
 	CardExp    string    `json:"card_exp" db:"card_exp"`
 	Notes      string    `json:"notes,omitempty" db:"notes"`
+	Version    int       `json:"version" db:"version"`
+	Signature  string    `json:"signature,omitempty" db:"signature"`
+	Integrity  Integrity `json:"-" db:"-"`
 	Created    time.Time `json:"created" db:"created"`
 	Changed    *nullTime `json:"changed,omitempty" db:"changed"`
 }
 
 // NewCard represents credit card information that posted by user in service
 type NewCard struct {
-	ID         uuid.UUID
+	ID         uuid.UUID `json:"id"`
 	UserID     uuid.UUID `json:"user_id" db:"user_id"`
 	Title      string    `json:"title" db:"title"`
 	CardNumber string    `json:"card_number" db:"card_number"`
 	CardOwner  string    `json:"card_owner" db:"card_owner"`
 	CardExp    string    `json:"card_exp" db:"card_exp"`
 	Notes      string    `json:"notes,omitempty" db:"notes"`
+	Version    int       `json:"version" db:"version"`
+	Signature  string    `json:"signature,omitempty" db:"signature"`
 }
 
 // CheckValid format logic check values of fields
@@ -57,42 +62,92 @@ func (nc *NewCard) CheckValid() error {
 	return nil
 }
 
-// Encrypt cipher values (card number, card owner and expiration date)
+// Encrypt cipher values (title, card number, card owner, expiration date and notes)
 func (nc *NewCard) Encrypt(cryptorizer *crypto.Cryptorizer) error {
-	cryptCardNum, cryptCardNumErr := cryptorizer.Cryptorizer.Encrypt(nc.CardNumber)
+	cryptTitle, cryptTitleErr := cryptorizer.Encrypt(nc.Title)
+	if cryptTitleErr != nil {
+		return cryptTitleErr
+	}
+	cryptNotes, cryptNotesErr := cryptorizer.Encrypt(nc.Notes)
+	if cryptNotesErr != nil {
+		return cryptNotesErr
+	}
+	cryptCardNum, cryptCardNumErr := cryptorizer.Encrypt(nc.CardNumber)
 	if cryptCardNumErr != nil {
 		return cryptCardNumErr
 	}
-	cryptCardOwner, cryptCardOwnerErr := cryptorizer.Cryptorizer.Encrypt(nc.CardOwner)
+	cryptCardOwner, cryptCardOwnerErr := cryptorizer.Encrypt(nc.CardOwner)
 	if cryptCardOwnerErr != nil {
 		return cryptCardOwnerErr
 	}
-	cryptCardExp, cryptCardExpErr := cryptorizer.Cryptorizer.Encrypt(nc.CardExp)
+	cryptCardExp, cryptCardExpErr := cryptorizer.Encrypt(nc.CardExp)
 	if cryptCardExpErr != nil {
 		return cryptCardExpErr
 	}
+	nc.Title = cryptTitle
 	nc.CardNumber = cryptCardNum
 	nc.CardOwner = cryptCardOwner
 	nc.CardExp = cryptCardExp
+	nc.Notes = cryptNotes
 	return nil
 }
 
-// Decrypt decipher values (card number, card owner and expiration date)
+// Decrypt decipher values (title, card number, card owner, expiration date and notes)
 func (c *Card) Decrypt(cryptorizer *crypto.Cryptorizer) error {
-	decryptCardNum, decryptCardNumErr := cryptorizer.Cryptorizer.Decrypt(c.CardNumber)
+	decryptCardNum, decryptCardNumErr := cryptorizer.Decrypt(c.CardNumber)
 	if decryptCardNumErr != nil {
 		return decryptCardNumErr
 	}
-	decryptCardOwner, decryptCardOwnerErr := cryptorizer.Cryptorizer.Decrypt(c.CardOwner)
+	decryptCardOwner, decryptCardOwnerErr := cryptorizer.Decrypt(c.CardOwner)
 	if decryptCardOwnerErr != nil {
 		return decryptCardOwnerErr
 	}
-	decryptCardExp, decryptCardExpErr := cryptorizer.Cryptorizer.Decrypt(c.CardExp)
+	decryptCardExp, decryptCardExpErr := cryptorizer.Decrypt(c.CardExp)
 	if decryptCardExpErr != nil {
 		return decryptCardExpErr
 	}
+	decryptTitle, decryptTitleErr := decryptMeta(cryptorizer, c.Title)
+	if decryptTitleErr != nil {
+		return decryptTitleErr
+	}
+	decryptNotes, decryptNotesErr := decryptMeta(cryptorizer, c.Notes)
+	if decryptNotesErr != nil {
+		return decryptNotesErr
+	}
+	c.Title = decryptTitle
 	c.CardNumber = decryptCardNum
 	c.CardOwner = decryptCardOwner
 	c.CardExp = decryptCardExp
+	c.Notes = decryptNotes
+	return nil
+}
+
+// cardPayload makes payload for signature of encrypted cards
+func cardPayload(id uuid.UUID, version int, title, cardNumber, cardOwner, cardExp, notes string) string {
+	return signPayload(id, "cards", version, []byte(title), []byte(cardNumber), []byte(cardOwner), []byte(cardExp), []byte(notes))
+}
+
+// Sign signs encrypted values, it should be called after Encrypt
+func (nc *NewCard) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(cardPayload(nc.ID, nc.Version, nc.Title, nc.CardNumber, nc.CardOwner, nc.CardExp, nc.Notes))
+	if signErr != nil {
+		return signErr
+	}
+	nc.Signature = signature
+	return nil
+}
+
+// Sign signs encrypted values, used for re-sign with new keys
+func (c *Card) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(cardPayload(c.ID, c.Version, c.Title, c.CardNumber, c.CardOwner, c.CardExp, c.Notes))
+	if signErr != nil {
+		return signErr
+	}
+	c.Signature = signature
 	return nil
 }
+
+// Verify checks signature of encrypted values and sets integrity status, it should be called before Decrypt
+func (c *Card) Verify(cryptorizer *crypto.Cryptorizer) {
+	c.Integrity = verifyIntegrity(cryptorizer, cardPayload(c.ID, c.Version, c.Title, c.CardNumber, c.CardOwner, c.CardExp, c.Notes), c.Signature)
+}
@@ -5,17 +5,46 @@ import (
 	"errors"
 	"log"
 	"os"
+	"time"
 )
 
 // ServerConfig  start parameters for lunch the server
 type ServerConfig struct {
 	ServerAddress string `env:"SERVER_ADDRESS" envDefault:"localhost:8080" json:"server_address"`
+	GRPCAddress   string `env:"GRPC_ADDRESS" json:"grpc_address"`
 	Database      string `env:"DATABASE_DSN" json:"database_dsn"`
 	AdminDatabase string `env:"ADMIN_DATABASE_DSN" json:"admin_database_dsn"`
+	// Secret is not used, tokens are signed by keys of key ring
 	Secret        string `env:"SECRET" json:"secret"`
 	CORS          string `env:"CORS" json:"cors"`
 	EnableHTTPS   bool   `env:"ENABLE_HTTPS" json:"enable_https"`
 	TrustedSubnet string `env:"TRUSTED_SUBNET" json:"trusted_subnet"`
+	AtRestKeys    string `env:"AT_REST_KEYS" json:"at_rest_keys"`
+	EventsNotify  bool   `env:"EVENTS_NOTIFY" json:"events_notify"`
+	// TrustedProxies subnets of reverse proxies in CIDR notation separated by spaces,
+	// address of client is taken from X-Real-IP and X-Forwarded-For only behind them
+	TrustedProxies string `env:"TRUSTED_PROXIES" json:"trusted_proxies"`
+	// IdempotencyWindow time while responses of requests with idempotency keys are replayed
+	IdempotencyWindow time.Duration `env:"IDEMPOTENCY_WINDOW" json:"idempotency_window"`
+	// PasswordHash algorithm of new password hashes: argon2id or bcrypt, old hashes are upgraded on login
+	PasswordHash string `env:"PASSWORD_HASH" json:"password_hash"`
+	BcryptCost   int    `env:"BCRYPT_COST" json:"bcrypt_cost"`
+	Argon2Time   uint   `env:"ARGON2_TIME" json:"argon2_time"`
+	// Argon2Memory memory of argon2id in KiB
+	Argon2Memory  uint `env:"ARGON2_MEMORY" json:"argon2_memory"`
+	Argon2Threads uint `env:"ARGON2_THREADS" json:"argon2_threads"`
+	// SMTPAddress host:port of SMTP server, messages are written to MailFile or log if it is empty
+	SMTPAddress  string `env:"SMTP_ADDRESS" json:"smtp_address"`
+	SMTPUsername string `env:"SMTP_USERNAME" json:"smtp_username"`
+	SMTPPassword string `env:"SMTP_PASSWORD" json:"smtp_password"`
+	MailFrom     string `env:"MAIL_FROM" json:"mail_from"`
+	MailFile     string `env:"MAIL_FILE" json:"mail_file"`
+	// MailTemplates directory of templates of messages that replace built-in ones
+	MailTemplates string `env:"MAIL_TEMPLATES" json:"mail_templates"`
+	// JWTAlgorithm algorithm of new keys of JWT signatures: EdDSA or RS256
+	JWTAlgorithm string `env:"JWT_ALGORITHM" json:"jwt_algorithm"`
+	// JWTRotation age of active key of JWT signatures when it is replaced, keys are rotated only by command if it is zero
+	JWTRotation time.Duration `env:"JWT_ROTATION" json:"jwt_rotation"`
 }
 
 // GUIConfig  start parameters for lunch the GUI
@@ -23,7 +52,16 @@ type GUIConfig struct {
 	ServerAddress string `json:"server_address"`
 	KeysPath      string `json:"keys_path"`
 	KeysSize      int    `json:"keys_size"`
+	Algorithm     string `json:"algorithm"`
 	Secret        string `json:"secret"`
+	Agent         string `json:"agent"`
+	Transport     string `json:"transport"`
+	GRPCAddress   string `json:"grpc_address"`
+	GRPCTLS       bool   `json:"grpc_tls"`
+	// DeviceName name of device in sessions of user, host name is used by default
+	DeviceName string `json:"device_name"`
+	// Version version of client, it is set by build
+	Version string `json:"-"`
 }
 
 // JSONConfig config file in json format
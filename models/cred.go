@@ -9,51 +9,106 @@ import (
 
 // Cred represents credentials (login / password) information that stored in database
 type Cred struct {
-	ID      uuid.UUID `json:"id" db:"id"`
-	Title   string    `json:"title" db:"title"`
-	Login   string    `json:"login" db:"login"`
-	Passwd  string    `json:"passwd" db:"passwd"`
-	Notes   string    `json:"notes,omitempty" db:"notes"`
-	Created time.Time `json:"created" db:"created"`
-	Changed *nullTime `json:"changed,omitempty" db:"changed"`
+	ID        uuid.UUID `json:"id" db:"id"`
+	Title     string    `json:"title" db:"title"`
+	Login     string    `json:"login" db:"login"`
+	Passwd    string    `json:"passwd" db:"passwd"`
+	Notes     string    `json:"notes,omitempty" db:"notes"`
+	Version   int       `json:"version" db:"version"`
+	Signature string    `json:"signature,omitempty" db:"signature"`
+	Integrity Integrity `json:"-" db:"-"`
+	Created   time.Time `json:"created" db:"created"`
+	Changed   *nullTime `json:"changed,omitempty" db:"changed"`
 }
 
 // NewCred represents credentials (login / password) that posted by user in service
 type NewCred struct {
-	ID     uuid.UUID
-	UserID uuid.UUID `json:"user_id" db:"user_id"`
-	Title  string    `json:"title" db:"title"`
-	Login  string    `json:"login" db:"login"`
-	Passwd string    `json:"passwd" db:"passwd"`
-	Notes  string    `json:"notes,omitempty" db:"notes"`
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Title     string    `json:"title" db:"title"`
+	Login     string    `json:"login" db:"login"`
+	Passwd    string    `json:"passwd" db:"passwd"`
+	Notes     string    `json:"notes,omitempty" db:"notes"`
+	Version   int       `json:"version" db:"version"`
+	Signature string    `json:"signature,omitempty" db:"signature"`
 }
 
-// Encrypt cipher values (login / password)
+// Encrypt cipher values (title, login / password and notes)
 func (nc *NewCred) Encrypt(cryptorizer *crypto.Cryptorizer) error {
-	cryptLogin, cryptLoginErr := cryptorizer.Cryptorizer.Encrypt(nc.Login)
+	cryptTitle, cryptTitleErr := cryptorizer.Encrypt(nc.Title)
+	if cryptTitleErr != nil {
+		return cryptTitleErr
+	}
+	cryptNotes, cryptNotesErr := cryptorizer.Encrypt(nc.Notes)
+	if cryptNotesErr != nil {
+		return cryptNotesErr
+	}
+	cryptLogin, cryptLoginErr := cryptorizer.Encrypt(nc.Login)
 	if cryptLoginErr != nil {
 		return cryptLoginErr
 	}
-	cryptPasswd, cryptPasswdErr := cryptorizer.Cryptorizer.Encrypt(nc.Passwd)
+	cryptPasswd, cryptPasswdErr := cryptorizer.Encrypt(nc.Passwd)
 	if cryptPasswdErr != nil {
 		return cryptPasswdErr
 	}
+	nc.Title = cryptTitle
 	nc.Login = cryptLogin
 	nc.Passwd = cryptPasswd
+	nc.Notes = cryptNotes
 	return nil
 }
 
-// Decrypt decipher values (login / password)
+// Decrypt decipher values (title, login / password and notes)
 func (c *Cred) Decrypt(cryptorizer *crypto.Cryptorizer) error {
-	decryptLogin, decryptLoginErr := cryptorizer.Cryptorizer.Decrypt(c.Login)
+	decryptLogin, decryptLoginErr := cryptorizer.Decrypt(c.Login)
 	if decryptLoginErr != nil {
 		return decryptLoginErr
 	}
-	decryptPasswd, decryptPasswdErr := cryptorizer.Cryptorizer.Decrypt(c.Passwd)
+	decryptPasswd, decryptPasswdErr := cryptorizer.Decrypt(c.Passwd)
 	if decryptPasswdErr != nil {
 		return decryptPasswdErr
 	}
+	decryptTitle, decryptTitleErr := decryptMeta(cryptorizer, c.Title)
+	if decryptTitleErr != nil {
+		return decryptTitleErr
+	}
+	decryptNotes, decryptNotesErr := decryptMeta(cryptorizer, c.Notes)
+	if decryptNotesErr != nil {
+		return decryptNotesErr
+	}
+	c.Title = decryptTitle
 	c.Login = decryptLogin
 	c.Passwd = decryptPasswd
+	c.Notes = decryptNotes
+	return nil
+}
+
+// credPayload makes payload for signature of encrypted creds
+func credPayload(id uuid.UUID, version int, title, login, passwd, notes string) string {
+	return signPayload(id, "creds", version, []byte(title), []byte(login), []byte(passwd), []byte(notes))
+}
+
+// Sign signs encrypted values, it should be called after Encrypt
+func (nc *NewCred) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(credPayload(nc.ID, nc.Version, nc.Title, nc.Login, nc.Passwd, nc.Notes))
+	if signErr != nil {
+		return signErr
+	}
+	nc.Signature = signature
+	return nil
+}
+
+// Sign signs encrypted values, used for re-sign with new keys
+func (c *Cred) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(credPayload(c.ID, c.Version, c.Title, c.Login, c.Passwd, c.Notes))
+	if signErr != nil {
+		return signErr
+	}
+	c.Signature = signature
 	return nil
 }
+
+// Verify checks signature of encrypted values and sets integrity status, it should be called before Decrypt
+func (c *Cred) Verify(cryptorizer *crypto.Cryptorizer) {
+	c.Integrity = verifyIntegrity(cryptorizer, credPayload(c.ID, c.Version, c.Title, c.Login, c.Passwd, c.Notes), c.Signature)
+}
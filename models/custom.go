@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// NullTime nullable time of element change, it is used by storages that fill elements themselves
+type NullTime = nullTime
+
 type nullTime struct {
 	Time  time.Time `json:"time"`
 	Valid bool      `json:"valid"` // Valid is true if Time is not NULL
@@ -23,3 +26,11 @@ func (nt *nullTime) Value() (driver.Value, error) {
 	}
 	return nt.Time, nil
 }
+
+// Get returns time value or zero time if it is NULL
+func (nt *nullTime) Get() time.Time {
+	if nt == nil || !nt.Valid {
+		return time.Time{}
+	}
+	return nt.Time
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Purposes of email tokens
+const (
+	EmailTokenVerify = "verify"
+	EmailTokenReset  = "reset"
+)
+
+// EmailToken represents single-use token sent to email of user, only hash of token is stored
+type EmailToken struct {
+	TokenHash string     `db:"token_hash"`
+	UserID    uuid.UUID  `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	Created   time.Time  `db:"created"`
+	Expires   time.Time  `db:"expires"`
+	Used      *time.Time `db:"used"`
+}
+
+// EmailVerification represents code from message that confirms email of user
+type EmailVerification struct {
+	Token string `json:"token"`
+}
+
+// PasswordForgot represents request of password reset, code is sent to email
+type PasswordForgot struct {
+	Email string `json:"email"`
+}
+
+// PasswordReset represents new password with code from message of password reset
+type PasswordReset struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
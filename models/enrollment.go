@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Enrollment represents request of new device for keys of user,
+// bundle is sealed for ephemeral public key of device, so service never sees keys
+type Enrollment struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	UserID       uuid.UUID `json:"-" db:"user_id"`
+	Code         string    `json:"code" db:"code"`
+	DevicePublic []byte    `json:"device_public" db:"device_public"`
+	Bundle       []byte    `json:"bundle,omitempty" db:"bundle"`
+	Created      time.Time `json:"created" db:"created"`
+	Expires      time.Time `json:"expires" db:"expires"`
+}
+
+// NewEnrollment represents public key that posted by new device in service
+type NewEnrollment struct {
+	DevicePublic []byte `json:"device_public"`
+}
+
+// EnrollmentApprove represents key bundle that sealed for new device by approving device
+type EnrollmentApprove struct {
+	Bundle []byte `json:"bundle"`
+}
@@ -1,45 +1,118 @@
 package models
 
 import (
+	"AlexSarva/GophKeeper/crypto"
 	"AlexSarva/GophKeeper/crypto/cryptoblock"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// MaxFileSize max size of file content, uploads over REST and gRPC are limited by it
+const MaxFileSize = 32 << 20
+
 // File represents file information that stored in database
 type File struct {
-	ID       uuid.UUID `json:"id" db:"id"`
-	Title    string    `json:"title" db:"title"`
-	File     []byte    `json:"file" db:"file"`
-	FileName string    `json:"file_name" db:"file_name"`
-	Notes    string    `json:"notes,omitempty" db:"notes"`
-	Created  time.Time `json:"created" db:"created"`
-	Changed  *nullTime `json:"changed,omitempty" db:"changed"`
+	ID        uuid.UUID `json:"id" db:"id"`
+	Title     string    `json:"title" db:"title"`
+	File      []byte    `json:"file" db:"file"`
+	FileName  string    `json:"file_name" db:"file_name"`
+	Notes     string    `json:"notes,omitempty" db:"notes"`
+	Version   int       `json:"version" db:"version"`
+	Signature string    `json:"signature,omitempty" db:"signature"`
+	Integrity Integrity `json:"-" db:"-"`
+	Created   time.Time `json:"created" db:"created"`
+	Changed   *nullTime `json:"changed,omitempty" db:"changed"`
 }
 
 // NewFile represents file information that posted by user in service
 type NewFile struct {
-	ID       uuid.UUID
-	UserID   uuid.UUID `json:"user_id" db:"user_id"`
-	Title    string    `json:"title" db:"title"`
-	FileName string    `json:"file_name" db:"file_name"`
-	File     []byte    `json:"file" db:"file"`
-	Notes    string    `json:"notes,omitempty" db:"notes"`
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Title     string    `json:"title" db:"title"`
+	FileName  string    `json:"file_name" db:"file_name"`
+	File      []byte    `json:"file" db:"file"`
+	Notes     string    `json:"notes,omitempty" db:"notes"`
+	Version   int       `json:"version" db:"version"`
+	Signature string    `json:"signature,omitempty" db:"signature"`
 }
 
-// Encrypt cipher values (file content)
-func (nf *NewFile) Encrypt(symmCrypt *cryptoblock.AEADCrypto) {
-	cryptFile := symmCrypt.Encrypt(nf.File)
+// Encrypt cipher values (file content with sym crypt, title, file name and notes with cryptorizer)
+func (nf *NewFile) Encrypt(cryptorizer *crypto.Cryptorizer, symmCrypt cryptoblock.SymCrypto) error {
+	cryptTitle, cryptTitleErr := cryptorizer.Encrypt(nf.Title)
+	if cryptTitleErr != nil {
+		return cryptTitleErr
+	}
+	cryptFileName, cryptFileNameErr := cryptorizer.Encrypt(nf.FileName)
+	if cryptFileNameErr != nil {
+		return cryptFileNameErr
+	}
+	cryptNotes, cryptNotesErr := cryptorizer.Encrypt(nf.Notes)
+	if cryptNotesErr != nil {
+		return cryptNotesErr
+	}
+	cryptFile, cryptFileErr := symmCrypt.Encrypt(nf.File)
+	if cryptFileErr != nil {
+		return cryptFileErr
+	}
+	nf.Title = cryptTitle
+	nf.FileName = cryptFileName
+	nf.Notes = cryptNotes
 	nf.File = cryptFile
+	return nil
 }
 
-// Decrypt decipher values (file content)
-func (f *File) Decrypt(symCrypt *cryptoblock.AEADCrypto) error {
+// Decrypt decipher values (file content with sym crypt, title, file name and notes with cryptorizer)
+func (f *File) Decrypt(cryptorizer *crypto.Cryptorizer, symCrypt cryptoblock.SymCrypto) error {
 	cryptFile, cryptFileErr := symCrypt.Decrypt(f.File)
 	if cryptFileErr != nil {
 		return cryptFileErr
 	}
+	decryptTitle, decryptTitleErr := decryptMeta(cryptorizer, f.Title)
+	if decryptTitleErr != nil {
+		return decryptTitleErr
+	}
+	decryptFileName, decryptFileNameErr := decryptMeta(cryptorizer, f.FileName)
+	if decryptFileNameErr != nil {
+		return decryptFileNameErr
+	}
+	decryptNotes, decryptNotesErr := decryptMeta(cryptorizer, f.Notes)
+	if decryptNotesErr != nil {
+		return decryptNotesErr
+	}
+	f.Title = decryptTitle
+	f.FileName = decryptFileName
+	f.Notes = decryptNotes
 	f.File = cryptFile
 	return nil
 }
+
+// filePayload makes payload for signature of encrypted files
+func filePayload(id uuid.UUID, version int, title, fileName string, content []byte, notes string) string {
+	return signPayload(id, "files", version, []byte(title), []byte(fileName), content, []byte(notes))
+}
+
+// Sign signs encrypted values, it should be called after Encrypt
+func (nf *NewFile) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(filePayload(nf.ID, nf.Version, nf.Title, nf.FileName, nf.File, nf.Notes))
+	if signErr != nil {
+		return signErr
+	}
+	nf.Signature = signature
+	return nil
+}
+
+// Sign signs encrypted values, used for re-sign with new keys
+func (f *File) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(filePayload(f.ID, f.Version, f.Title, f.FileName, f.File, f.Notes))
+	if signErr != nil {
+		return signErr
+	}
+	f.Signature = signature
+	return nil
+}
+
+// Verify checks signature of encrypted values and sets integrity status, it should be called before Decrypt
+func (f *File) Verify(cryptorizer *crypto.Cryptorizer) {
+	f.Integrity = verifyIntegrity(cryptorizer, filePayload(f.ID, f.Version, f.Title, f.FileName, f.File, f.Notes), f.Signature)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotentResponse represents response of request with idempotency key,
+// it is replayed on retries of the request. Status is 0 while request is in progress
+type IdempotentResponse struct {
+	UserID      uuid.UUID `db:"user_id"`
+	Key         string    `db:"key"`
+	RequestHash string    `db:"request_hash"`
+	Status      int       `db:"status"`
+	ContentType string    `db:"content_type"`
+	Body        []byte    `db:"body"`
+	// KeyID id of at-rest key of sealed body, it is empty for plaintext body
+	KeyID   string    `db:"key_id"`
+	Created time.Time `db:"created"`
+}
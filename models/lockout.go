@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of login throttling
+const (
+	ThrottleIP      = "ip"
+	ThrottleAccount = "account"
+)
+
+// LoginFailure represents failed login attempts from address or to account,
+// counter is reset after successful login or when failures are old
+type LoginFailure struct {
+	Kind        string     `db:"kind"`
+	Subject     string     `db:"subject"`
+	Failures    int        `db:"failures"`
+	LastFailure time.Time  `db:"last_failure"`
+	LockedUntil *time.Time `db:"locked_until"`
+}
+
+// Lockout represents temporary lock of logins from address or to account after too many failures,
+// admins inspect lockouts and clear them
+type Lockout struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Kind        string     `json:"kind" db:"kind"`
+	Subject     string     `json:"subject" db:"subject"`
+	Failures    int        `json:"failures" db:"failures"`
+	Created     time.Time  `json:"created" db:"created"`
+	LockedUntil time.Time  `json:"locked_until" db:"locked_until"`
+	Cleared     *time.Time `json:"cleared,omitempty" db:"cleared"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "AlexSarva/GophKeeper/crypto"
+
+// decryptMeta deciphers metadata value (title, file name or notes),
+// values identified as saved before metadata encryption are returned as is.
+// Encrypted values that cant be deciphered are errors
+func decryptMeta(cryptorizer *crypto.Cryptorizer, value string) (string, error) {
+	if value == "" || !crypto.IsEncrypted(value) {
+		return value, nil
+	}
+	return cryptorizer.Decrypt(value)
+}
@@ -9,37 +9,82 @@ import (
 
 // Note represents notes information that stored in database
 type Note struct {
-	ID      uuid.UUID `json:"id" db:"id"`
-	Title   string    `json:"title" db:"title"`
-	Note    string    `json:"note" db:"note"`
-	Created time.Time `json:"created" db:"created"`
-	Changed *nullTime `json:"changed,omitempty" db:"changed"`
+	ID        uuid.UUID `json:"id" db:"id"`
+	Title     string    `json:"title" db:"title"`
+	Note      string    `json:"note" db:"note"`
+	Version   int       `json:"version" db:"version"`
+	Signature string    `json:"signature,omitempty" db:"signature"`
+	Integrity Integrity `json:"-" db:"-"`
+	Created   time.Time `json:"created" db:"created"`
+	Changed   *nullTime `json:"changed,omitempty" db:"changed"`
 }
 
 // NewNote represents notes information that posted by user in service
 type NewNote struct {
-	ID     uuid.UUID
-	UserID uuid.UUID `json:"user_id" db:"user_id"`
-	Title  string    `json:"title" db:"title"`
-	Note   string    `json:"note" db:"note"`
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Title     string    `json:"title" db:"title"`
+	Note      string    `json:"note" db:"note"`
+	Version   int       `json:"version" db:"version"`
+	Signature string    `json:"signature,omitempty" db:"signature"`
 }
 
-// Encrypt cipher values (note text)
+// Encrypt cipher values (title and note text)
 func (nn *NewNote) Encrypt(cryptorizer *crypto.Cryptorizer) error {
-	cryptNote, cryptNoteNumErr := cryptorizer.Cryptorizer.Encrypt(nn.Note)
+	cryptTitle, cryptTitleErr := cryptorizer.Encrypt(nn.Title)
+	if cryptTitleErr != nil {
+		return cryptTitleErr
+	}
+	cryptNote, cryptNoteNumErr := cryptorizer.Encrypt(nn.Note)
 	if cryptNoteNumErr != nil {
 		return cryptNoteNumErr
 	}
+	nn.Title = cryptTitle
 	nn.Note = cryptNote
 	return nil
 }
 
-// Decrypt decipher values (note text)
+// Decrypt decipher values (title and note text)
 func (n *Note) Decrypt(cryptorizer *crypto.Cryptorizer) error {
-	decryptNote, decryptNoteNumErr := cryptorizer.Cryptorizer.Decrypt(n.Note)
+	decryptNote, decryptNoteNumErr := cryptorizer.Decrypt(n.Note)
 	if decryptNoteNumErr != nil {
 		return decryptNoteNumErr
 	}
+	decryptTitle, decryptTitleErr := decryptMeta(cryptorizer, n.Title)
+	if decryptTitleErr != nil {
+		return decryptTitleErr
+	}
+	n.Title = decryptTitle
 	n.Note = decryptNote
 	return nil
 }
+
+// notePayload makes payload for signature of encrypted notes
+func notePayload(id uuid.UUID, version int, title, note string) string {
+	return signPayload(id, "notes", version, []byte(title), []byte(note))
+}
+
+// Sign signs encrypted values, it should be called after Encrypt
+func (nn *NewNote) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(notePayload(nn.ID, nn.Version, nn.Title, nn.Note))
+	if signErr != nil {
+		return signErr
+	}
+	nn.Signature = signature
+	return nil
+}
+
+// Sign signs encrypted values, used for re-sign with new keys
+func (n *Note) Sign(cryptorizer *crypto.Cryptorizer) error {
+	signature, signErr := cryptorizer.Sign(notePayload(n.ID, n.Version, n.Title, n.Note))
+	if signErr != nil {
+		return signErr
+	}
+	n.Signature = signature
+	return nil
+}
+
+// Verify checks signature of encrypted values and sets integrity status, it should be called before Decrypt
+func (n *Note) Verify(cryptorizer *crypto.Cryptorizer) {
+	n.Integrity = verifyIntegrity(cryptorizer, notePayload(n.ID, n.Version, n.Title, n.Note), n.Signature)
+}
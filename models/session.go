@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session represents login of user on device, it lives while its refresh tokens are valid.
+// Id of session is id of family of refresh tokens issued after login
+type Session struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"-" db:"user_id"`
+	DeviceName    string     `json:"device_name" db:"device_name"`
+	ClientVersion string     `json:"client_version" db:"client_version"`
+	IP            string     `json:"ip" db:"ip"`
+	UserAgent     string     `json:"user_agent" db:"user_agent"`
+	Created       time.Time  `json:"created" db:"created"`
+	LastSeen      time.Time  `json:"last_seen" db:"last_seen"`
+	Revoked       *time.Time `json:"-" db:"revoked"`
+	// Current is set for session of request
+	Current bool `json:"current" db:"-"`
+}
+
+// SessionClient represents client that logs in, it is saved in new session
+type SessionClient struct {
+	DeviceName    string
+	ClientVersion string
+	IP            string
+	UserAgent     string
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Integrity result of element signature check, it is made by client on every read
+type Integrity string
+
+// Integrity statuses
+const (
+	IntegrityOK       Integrity = "ok"
+	IntegrityUnsigned Integrity = "unsigned"
+	IntegrityFailed   Integrity = "failed"
+	IntegrityRollback Integrity = "rollback"
+)
+
+// Warning returns message for user if element could be changed by somebody else
+func (i Integrity) Warning() string {
+	switch i {
+	case IntegrityFailed:
+		return "signature check failed, element was changed outside of your client"
+	case IntegrityRollback:
+		return "element is older than the last version seen by your client"
+	case IntegrityUnsigned:
+		return "element has no signature, it could be changed outside of your client, check and edit it to sign"
+	default:
+		return ""
+	}
+}
+
+// signPayload makes payload for signature of element: hash of its id, type, version
+// and hashes of all encrypted fields, so ciphertexts can't be moved between elements or versions
+func signPayload(id uuid.UUID, infoType string, version int, fields ...[]byte) string {
+	parts := []string{id.String(), infoType, strconv.Itoa(version)}
+	for _, field := range fields {
+		fieldHash := sha256.Sum256(field)
+		parts = append(parts, hex.EncodeToString(fieldHash[:]))
+	}
+	payloadHash := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(payloadHash[:])
+}
+
+// verifyIntegrity checks signature of element payload
+func verifyIntegrity(cryptorizer *crypto.Cryptorizer, payload, signature string) Integrity {
+	if signature == "" {
+		return IntegrityUnsigned
+	}
+	if !cryptorizer.Verify(payload, signature) {
+		return IntegrityFailed
+	}
+	return IntegrityOK
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteIntegrity(t *testing.T) {
+	cryptorizer, cryptorizerErr := crypto.InitCryptorizer(t.TempDir(), 1024, crypto.AlgorithmECC)
+	assert.NoError(t, cryptorizerErr)
+
+	newNote := NewNote{ID: uuid.New(), Title: "bank", Note: "pin 1234", Version: 1}
+	assert.NoError(t, newNote.Encrypt(cryptorizer))
+	assert.NoError(t, newNote.Sign(cryptorizer))
+	other := NewNote{ID: uuid.New(), Title: "mail", Note: "qwerty", Version: 1}
+	assert.NoError(t, other.Encrypt(cryptorizer))
+
+	stored := func() Note {
+		return Note{ID: newNote.ID, Title: newNote.Title, Note: newNote.Note, Version: newNote.Version, Signature: newNote.Signature}
+	}
+	tests := []struct {
+		name   string
+		change func(note *Note)
+		want   Integrity
+	}{
+		{
+			name:   "untouched note",
+			change: func(note *Note) {},
+			want:   IntegrityOK,
+		},
+		{
+			name:   "ciphertext from another note",
+			change: func(note *Note) { note.Note = other.Note },
+			want:   IntegrityFailed,
+		},
+		{
+			name:   "another id",
+			change: func(note *Note) { note.ID = other.ID },
+			want:   IntegrityFailed,
+		},
+		{
+			name:   "another version",
+			change: func(note *Note) { note.Version = 2 },
+			want:   IntegrityFailed,
+		},
+		{
+			name:   "unsigned note",
+			change: func(note *Note) { note.Signature = "" },
+			want:   IntegrityUnsigned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := stored()
+			tt.change(&note)
+			note.Verify(cryptorizer)
+			assert.Equal(t, tt.want, note.Integrity)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// SigningKey represents key of JWT signatures, private key is in PKCS #8 form.
+// Key signs tokens from activation until next key is activated and verifies them until it retires
+type SigningKey struct {
+	ID         string     `db:"id"`
+	Algorithm  string     `db:"algorithm"`
+	PrivateKey []byte     `db:"private_key"`
+	KeyID      string     `db:"key_id"`
+	Created    time.Time  `db:"created"`
+	Activates  time.Time  `db:"activates"`
+	Retires    *time.Time `db:"retires"`
+}
+
+// JWK represents public key of JWT signatures in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKSet represents set of public keys that verify tokens of service
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tokens represents pair of short-lived access token and single-use refresh token
+type Tokens struct {
+	Token        string    `json:"token"`
+	TokenExp     time.Time `json:"token_expires"`
+	RefreshToken string    `json:"refresh_token"`
+	RefreshExp   time.Time `json:"refresh_expires"`
+}
+
+// TokenRefresh represents refresh token that client exchanges for new pair of tokens
+type TokenRefresh struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken represents stored refresh token, only hash of token is stored.
+// Tokens issued after one login form family, reuse of spent token revokes the whole family
+type RefreshToken struct {
+	ID        uuid.UUID  `db:"id"`
+	FamilyID  uuid.UUID  `db:"family_id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	Created   time.Time  `db:"created"`
+	Expires   time.Time  `db:"expires"`
+	Used      *time.Time `db:"used"`
+	Revoked   bool       `db:"revoked"`
+}
+
+// RevokedToken represents access token that is revoked before it expires
+type RevokedToken struct {
+	JTI     uuid.UUID `db:"jti"`
+	UserID  uuid.UUID `db:"user_id"`
+	Expires time.Time `db:"expires"`
+}
+
+// UserRevocation represents time before which all access tokens of user are revoked
+type UserRevocation struct {
+	UserID     uuid.UUID `db:"id"`
+	ValidAfter time.Time `db:"tokens_valid_after"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactor represents TOTP secret of user, two-factor authentication is enabled after confirmation.
+// Secret is sealed if at-rest encryption is enabled
+type TwoFactor struct {
+	UserID   uuid.UUID  `db:"user_id"`
+	Secret   []byte     `db:"secret"`
+	KeyID    string     `db:"key_id"`
+	Enabled  *time.Time `db:"enabled"`
+	LastStep int64      `db:"last_step"`
+}
+
+// TwoFactorSetup represents new TOTP secret, authenticator app adds account by URI or its QR code
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorCode represents code from authenticator app or recovery code
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+// RecoveryCodes represents one-time codes that replace codes of authenticator app,
+// they are shown only once after two-factor authentication is enabled
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// TwoFactorChallenge represents short-lived token of login whose password is checked,
+// login is completed by this token with code of second factor
+type TwoFactorChallenge struct {
+	Challenge string    `json:"challenge"`
+	Expires   time.Time `json:"challenge_expires"`
+}
+
+// TwoFactorLogin represents second step of login
+type TwoFactorLogin struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
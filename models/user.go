@@ -8,12 +8,18 @@ import (
 
 // User represents user information in service
 type User struct {
-	ID       uuid.UUID `json:"id" db:"id"`
-	Username string    `json:"username" db:"username"`
-	Email    string    `json:"email" db:"email"`
-	Password string    `json:"password,omitempty" db:"passwd"`
-	Token    string    `json:"token" db:"token"`
-	TokenExp time.Time `json:"token_expires" db:"token_expires"`
+	ID             uuid.UUID `json:"id" db:"id"`
+	Username       string    `json:"username" db:"username"`
+	Email          string    `json:"email" db:"email"`
+	Password       string    `json:"password,omitempty" db:"passwd"`
+	Token          string    `json:"token" db:"-"`
+	TokenExp       time.Time `json:"token_expires" db:"-"`
+	KeyFingerprint string    `json:"key_fingerprint,omitempty" db:"key_fingerprint"`
+	// EmailVerified time when user confirmed email, it is nil until email is confirmed
+	EmailVerified *time.Time `json:"email_verified,omitempty" db:"email_verified"`
+	// RefreshToken is set only after registration and login
+	RefreshToken string     `json:"refresh_token,omitempty" db:"-"`
+	RefreshExp   *time.Time `json:"refresh_expires,omitempty" db:"-"`
 }
 
 // UserRegister represents information than used for register user in service
@@ -28,3 +34,23 @@ type UserLogin struct {
 	Email    string `json:"email" db:"email"`
 	Password string `json:"password" db:"passwd"`
 }
+
+// UserKey represents fingerprint of public key that client registers on account,
+// previous fingerprint is required to change it after keys rotation
+type UserKey struct {
+	Fingerprint string `json:"fingerprint"`
+	Previous    string `json:"previous,omitempty"`
+}
+
+// PasswordChange represents change of password, current password is checked before
+type PasswordChange struct {
+	Password    string `json:"password"`
+	NewPassword string `json:"new_password"`
+}
+
+// AccountDeletion represents confirmation of account deletion by password,
+// code of second factor is required if two-factor authentication is enabled
+type AccountDeletion struct {
+	Password string `json:"password"`
+	Code     string `json:"code,omitempty"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Vault represents all user information at once,
+// used for replace every element after re-encryption with new keys
+type Vault struct {
+	Notes []Note `json:"notes"`
+	Cards []Card `json:"cards"`
+	Creds []Cred `json:"creds"`
+	Files []File `json:"files"`
+}
@@ -0,0 +1,176 @@
+// Package passhash hashes passwords of users by bcrypt or Argon2id. Hashes are self-describing:
+// bcrypt hashes keep their cost and Argon2id hashes are kept in PHC string format
+// $argon2id$v=19$m=65536,t=3,p=2$salt$hash, so hashes made by old parameters are still verified
+// and could be upgraded to current parameters
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithms of password hashes
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+const (
+	// saltSize size of random salt of Argon2id hash
+	saltSize = 16
+	// keySize size of Argon2id hash
+	keySize = 32
+)
+
+var ErrAlgorithm = errors.New("unknown password hash algorithm")
+var ErrParams = errors.New("invalid password hash parameters")
+var ErrFormat = errors.New("invalid password hash format")
+
+// Params algorithm of new hashes and its parameters, memory of Argon2id is in KiB
+type Params struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+// DefaultParams parameters recommended by OWASP for Argon2id and bcrypt
+var DefaultParams = Params{
+	Algorithm:     Argon2id,
+	BcryptCost:    12,
+	Argon2Time:    3,
+	Argon2Memory:  64 * 1024,
+	Argon2Threads: 2,
+}
+
+// Hasher hashes passwords by current parameters and verifies hashes made by any parameters
+type Hasher struct {
+	params Params
+}
+
+// New initializer of Hasher, parameters weaker than defaults of bcrypt are rejected
+func New(params Params) (*Hasher, error) {
+	switch params.Algorithm {
+	case Bcrypt:
+		if params.BcryptCost < bcrypt.DefaultCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("%w: bcrypt cost should be from %d to %d", ErrParams, bcrypt.DefaultCost, bcrypt.MaxCost)
+		}
+	case Argon2id:
+		if params.Argon2Time < 1 || params.Argon2Threads < 1 || params.Argon2Memory < 8*uint32(params.Argon2Threads) {
+			return nil, fmt.Errorf("%w: argon2id needs at least 1 pass, 1 thread and 8 KiB of memory per thread", ErrParams)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithm, params.Algorithm)
+	}
+	return &Hasher{params: params}, nil
+}
+
+// Hash returns self-describing hash of password by current parameters
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.params.Algorithm == Bcrypt {
+		hash, hashErr := bcrypt.GenerateFromPassword([]byte(password), h.params.BcryptCost)
+		if hashErr != nil {
+			return "", hashErr
+		}
+		return string(hash), nil
+	}
+	salt := make([]byte, saltSize)
+	if _, randErr := rand.Read(salt); randErr != nil {
+		return "", randErr
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Argon2Time, h.params.Argon2Memory, h.params.Argon2Threads, keySize)
+	return encodeArgon2(argon2Hash{
+		time:    h.params.Argon2Time,
+		memory:  h.params.Argon2Memory,
+		threads: h.params.Argon2Threads,
+		salt:    salt,
+		key:     key,
+	}), nil
+}
+
+// Verify checks password by hash, rehash reports whether matched hash is made
+// by other algorithm or parameters than current ones and should be replaced
+func (h *Hasher) Verify(password, encoded string) (ok, rehash bool, err error) {
+	switch {
+	case strings.HasPrefix(encoded, "$2"):
+		cost, costErr := bcrypt.Cost([]byte(encoded))
+		if costErr != nil {
+			return false, false, fmt.Errorf("%w: %s", ErrFormat, costErr)
+		}
+		compareErr := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if compareErr != nil {
+			if errors.Is(compareErr, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, false, nil
+			}
+			return false, false, fmt.Errorf("%w: %s", ErrFormat, compareErr)
+		}
+		return true, h.params.Algorithm != Bcrypt || cost != h.params.BcryptCost, nil
+	case strings.HasPrefix(encoded, "$"+Argon2id+"$"):
+		hash, decodeErr := decodeArgon2(encoded)
+		if decodeErr != nil {
+			return false, false, decodeErr
+		}
+		key := argon2.IDKey([]byte(password), hash.salt, hash.time, hash.memory, hash.threads, uint32(len(hash.key)))
+		if subtle.ConstantTimeCompare(key, hash.key) != 1 {
+			return false, false, nil
+		}
+		return true, h.params.Algorithm != Argon2id ||
+			hash.time != h.params.Argon2Time ||
+			hash.memory != h.params.Argon2Memory ||
+			hash.threads != h.params.Argon2Threads ||
+			len(hash.salt) != saltSize || len(hash.key) != keySize, nil
+	default:
+		return false, false, ErrFormat
+	}
+}
+
+// argon2Hash parsed Argon2id hash
+type argon2Hash struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// encodeArgon2 returns hash in PHC string format, salt and key are in base64 without padding
+func encodeArgon2(hash argon2Hash) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", Argon2id, argon2.Version,
+		hash.memory, hash.time, hash.threads,
+		base64.RawStdEncoding.EncodeToString(hash.salt),
+		base64.RawStdEncoding.EncodeToString(hash.key))
+}
+
+// decodeArgon2 parses hash in PHC string format
+func decodeArgon2(encoded string) (*argon2Hash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != Argon2id {
+		return nil, ErrFormat
+	}
+	var version int
+	if _, versionErr := fmt.Sscanf(parts[2], "v=%d", &version); versionErr != nil || version != argon2.Version {
+		return nil, ErrFormat
+	}
+	var hash argon2Hash
+	if _, paramsErr := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.memory, &hash.time, &hash.threads); paramsErr != nil {
+		return nil, ErrFormat
+	}
+	if hash.time < 1 || hash.threads < 1 {
+		return nil, ErrFormat
+	}
+	var saltErr, keyErr error
+	hash.salt, saltErr = base64.RawStdEncoding.DecodeString(parts[4])
+	hash.key, keyErr = base64.RawStdEncoding.DecodeString(parts[5])
+	if saltErr != nil || keyErr != nil || len(hash.salt) == 0 || len(hash.key) == 0 {
+		return nil, ErrFormat
+	}
+	return &hash, nil
+}
@@ -0,0 +1,116 @@
+package passhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2 cheap parameters of Argon2id, they are allowed only to keep tests fast
+var fastArgon2 = Params{Algorithm: Argon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		err    error
+	}{
+		{name: "defaults", params: DefaultParams},
+		{name: "bcrypt", params: Params{Algorithm: Bcrypt, BcryptCost: bcrypt.DefaultCost}},
+		{name: "weak bcrypt", params: Params{Algorithm: Bcrypt, BcryptCost: 4}, err: ErrParams},
+		{name: "no argon2 threads", params: Params{Algorithm: Argon2id, Argon2Time: 1, Argon2Memory: 64}, err: ErrParams},
+		{name: "unknown algorithm", params: Params{Algorithm: "md5"}, err: ErrAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.params)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestHashVerify(t *testing.T) {
+	for _, params := range []Params{fastArgon2, {Algorithm: Bcrypt, BcryptCost: bcrypt.DefaultCost}} {
+		t.Run(params.Algorithm, func(t *testing.T) {
+			hasher, hasherErr := New(params)
+			require.NoError(t, hasherErr)
+			hash, hashErr := hasher.Hash("correct horse")
+			require.NoError(t, hashErr)
+
+			ok, rehash, verifyErr := hasher.Verify("correct horse", hash)
+			require.NoError(t, verifyErr)
+			assert.True(t, ok)
+			assert.False(t, rehash)
+
+			ok, _, verifyErr = hasher.Verify("battery staple", hash)
+			require.NoError(t, verifyErr)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestArgon2Format(t *testing.T) {
+	hasher, hasherErr := New(fastArgon2)
+	require.NoError(t, hasherErr)
+	hash, hashErr := hasher.Hash("correct horse")
+	require.NoError(t, hashErr)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+	second, _ := hasher.Hash("correct horse")
+	assert.NotEqual(t, hash, second, "salt should be random")
+}
+
+func TestRehash(t *testing.T) {
+	legacy, legacyErr := bcrypt.GenerateFromPassword([]byte("correct horse"), 4)
+	require.NoError(t, legacyErr)
+	weakArgon2, _ := New(fastArgon2)
+	weakHash, _ := weakArgon2.Hash("correct horse")
+
+	strongerArgon2 := fastArgon2
+	strongerArgon2.Argon2Time = 2
+	tests := []struct {
+		name   string
+		params Params
+		hash   string
+		rehash bool
+	}{
+		{name: "legacy bcrypt to argon2id", params: fastArgon2, hash: string(legacy), rehash: true},
+		{name: "legacy bcrypt to bcrypt", params: Params{Algorithm: Bcrypt, BcryptCost: bcrypt.DefaultCost}, hash: string(legacy), rehash: true},
+		{name: "argon2id to bcrypt", params: Params{Algorithm: Bcrypt, BcryptCost: bcrypt.DefaultCost}, hash: weakHash, rehash: true},
+		{name: "argon2id to stronger argon2id", params: strongerArgon2, hash: weakHash, rehash: true},
+		{name: "same argon2id", params: fastArgon2, hash: weakHash, rehash: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, hasherErr := New(tt.params)
+			require.NoError(t, hasherErr)
+			ok, rehash, verifyErr := hasher.Verify("correct horse", tt.hash)
+			require.NoError(t, verifyErr)
+			assert.True(t, ok)
+			assert.Equal(t, tt.rehash, rehash)
+		})
+	}
+}
+
+func TestVerifyInvalidHash(t *testing.T) {
+	hasher, _ := New(fastArgon2)
+	tests := []string{
+		"",
+		"plaintext",
+		"$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5",
+		"$2a$04$short",
+	}
+	for _, hash := range tests {
+		t.Run(hash, func(t *testing.T) {
+			ok, _, err := hasher.Verify("password", hash)
+			assert.ErrorIs(t, err, ErrFormat)
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package problem contains error model of REST API: RFC 7807 problem details with stable codes.
+// Clients should rely on codes, titles and details are for people
+package problem
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ContentType content type of problem details
+const ContentType = "application/problem+json"
+
+// typePrefix prefix of problem type URI, code of error completes it
+const typePrefix = "urn:gophkeeper:problem:"
+
+// Codes of errors
+const (
+	CodeInvalidRequest     = "invalid_request"
+	CodeInvalidID          = "invalid_id"
+	CodeEmptyFields        = "empty_fields"
+	CodeNoFingerprint      = "no_fingerprint"
+	CodeRejected           = "rejected"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeTokenInvalid       = "token_invalid"
+	CodeRefreshInvalid     = "refresh_token_invalid"
+	CodeRefreshReused      = "refresh_token_reused"
+	CodeTwoFactorInvalid   = "two_factor_code_invalid"
+	CodeChallengeInvalid   = "two_factor_challenge_invalid"
+	CodeTwoFactorEnabled   = "two_factor_enabled"
+	CodeTwoFactorDisabled  = "two_factor_disabled"
+	CodeTooManyAttempts    = "too_many_attempts"
+	CodeEmailTokenInvalid  = "email_token_invalid"
+	CodeEmailVerified      = "email_verified"
+	CodeMailCooldown       = "mail_cooldown"
+	CodeUntrustedNetwork   = "untrusted_network"
+	CodeScopeDenied        = "scope_denied"
+	CodeLoginExists        = "login_exists"
+	CodeElementExists      = "element_exists"
+	CodeEnrollmentExists   = "enrollment_exists"
+	CodeVersionConflict    = "version_conflict"
+	CodeKeyMismatch        = "key_mismatch"
+	CodeFileTooLarge       = "file_too_large"
+	CodeIdempotencyReused  = "idempotency_key_reused"
+	CodeRequestInProgress  = "request_in_progress"
+	CodeNotFound           = "not_found"
+	CodeRouteNotFound      = "route_not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeInternal           = "internal"
+)
+
+// titles short summaries of errors by code
+var titles = map[string]string{
+	CodeInvalidRequest:     "Invalid request",
+	CodeInvalidID:          "Invalid id",
+	CodeEmptyFields:        "Required fields are empty",
+	CodeNoFingerprint:      "Fingerprint is missing",
+	CodeRejected:           "Request rejected",
+	CodeUnauthorized:       "Unauthorized",
+	CodeInvalidCredentials: "Invalid email or password",
+	CodeTokenInvalid:       "Token is invalid",
+	CodeRefreshInvalid:     "Refresh token is invalid or expired",
+	CodeRefreshReused:      "Refresh token is reused",
+	CodeTwoFactorInvalid:   "Two-factor code is invalid",
+	CodeChallengeInvalid:   "Two-factor challenge is invalid or expired",
+	CodeTwoFactorEnabled:   "Two-factor authentication is already enabled",
+	CodeTwoFactorDisabled:  "Two-factor authentication is not enabled",
+	CodeTooManyAttempts:    "Too many failed login attempts",
+	CodeEmailTokenInvalid:  "Email code is invalid or expired",
+	CodeEmailVerified:      "Email is already confirmed",
+	CodeMailCooldown:       "Message is sent recently",
+	CodeUntrustedNetwork:   "Request is not from trusted subnet",
+	CodeScopeDenied:        "Token doesn't grant access to this route",
+	CodeLoginExists:        "Login is already taken",
+	CodeElementExists:      "Element already exists",
+	CodeEnrollmentExists:   "Enrollment already exists",
+	CodeVersionConflict:    "Version conflict",
+	CodeKeyMismatch:        "Another key is registered",
+	CodeFileTooLarge:       "File is too large",
+	CodeIdempotencyReused:  "Idempotency key is used by another request",
+	CodeRequestInProgress:  "Request with the same idempotency key is in progress",
+	CodeNotFound:           "Not found",
+	CodeRouteNotFound:      "Route does not exist",
+	CodeMethodNotAllowed:   "Method is not allowed",
+	CodeInternal:           "Internal server error",
+}
+
+// Problem problem details of failed request
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// New makes problem of code, title is taken from catalogue
+func New(status int, code, detail string) *Problem {
+	title, ok := titles[code]
+	if !ok {
+		title = http.StatusText(status)
+	}
+	return &Problem{
+		Type:   typePrefix + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Error returns detail of problem or its title
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+// Is reports whether target is problem with the same code
+func (p *Problem) Is(target error) bool {
+	t, ok := target.(*Problem)
+	return ok && t.Code == p.Code
+}
+
+// String returns problem with code and request id, it is used in logs
+func (p *Problem) String() string {
+	return fmt.Sprintf("%d %s (request %s): %s", p.Status, p.Code, p.RequestID, p.Error())
+}
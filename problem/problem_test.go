@@ -0,0 +1,60 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		detail string
+		title  string
+		err    string
+	}{
+		{name: "known code", status: http.StatusNotFound, code: CodeNotFound, detail: "no such note in db", title: "Not found", err: "no such note in db"},
+		{name: "without detail", status: http.StatusInternalServerError, code: CodeInternal, title: "Internal server error", err: "Internal server error"},
+		{name: "unknown code", status: http.StatusTeapot, code: "teapot", title: "I'm a teapot", err: "I'm a teapot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(tt.status, tt.code, tt.detail)
+			assert.Equal(t, "urn:gophkeeper:problem:"+tt.code, p.Type)
+			assert.Equal(t, tt.title, p.Title)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.err, p.Error())
+		})
+	}
+}
+
+func TestProblemJSON(t *testing.T) {
+	p := New(http.StatusConflict, CodeVersionConflict, "reload it")
+	p.Instance = "/api/v1/info/notes/1"
+	p.RequestID = "host/abc-000001"
+	data, marshalErr := json.Marshal(p)
+	require.NoError(t, marshalErr)
+	assert.JSONEq(t, `{"type": "urn:gophkeeper:problem:version_conflict", "title": "Version conflict", "status": 409,
+		"detail": "reload it", "instance": "/api/v1/info/notes/1", "code": "version_conflict", "request_id": "host/abc-000001"}`, string(data))
+
+	internal, marshalErr := json.Marshal(New(http.StatusInternalServerError, CodeInternal, ""))
+	require.NoError(t, marshalErr)
+	assert.NotContains(t, string(internal), "detail")
+}
+
+func TestProblemIs(t *testing.T) {
+	err := fmt.Errorf("edit note: %w", New(http.StatusConflict, CodeVersionConflict, "reload it"))
+	assert.True(t, errors.Is(err, New(http.StatusConflict, CodeVersionConflict, "")))
+	assert.False(t, errors.Is(err, New(http.StatusNotFound, CodeNotFound, "")))
+
+	var p *Problem
+	require.True(t, errors.As(err, &p))
+	assert.Equal(t, CodeVersionConflict, p.Code)
+}
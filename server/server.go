@@ -2,21 +2,32 @@ package server
 
 import (
 	"AlexSarva/GophKeeper/constant"
+	"AlexSarva/GophKeeper/events"
+	"AlexSarva/GophKeeper/grpcserver"
 	"AlexSarva/GophKeeper/handlers"
 	"AlexSarva/GophKeeper/internal/app"
 	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/service"
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
+// purgeInterval interval of removing data of deleted accounts until accounts are removed
+const purgeInterval = 10 * time.Minute
+
 // Server implementation of custom server
 type Server struct {
 	httpServer *http.Server
+	grpcServer *grpc.Server
 	cfg        *models.ServerConfig
 	db         *app.Storage
 }
@@ -26,7 +37,9 @@ func NewServer() *Server {
 
 	cfg := constant.GlobalContainer.Get("server-config").(models.ServerConfig)
 	db := *app.NewStorage()
-	handler := handlers.CustomHandler(&db)
+	keeper := service.NewService(&db, newEventsHub(&cfg))
+	go keeper.PurgeDeletedAccounts(context.Background(), purgeInterval)
+	handler := handlers.CustomHandler(&db, keeper)
 	server := http.Server{
 		Addr:         cfg.ServerAddress,
 		Handler:      handler,
@@ -35,11 +48,43 @@ func NewServer() *Server {
 	}
 	return &Server{
 		httpServer: &server,
+		grpcServer: newGRPCServer(&cfg, keeper),
 		cfg:        &cfg,
 		db:         &db,
 	}
 }
 
+// newEventsHub returns hub of element changes, events are shared between instances
+// by PostgreSQL LISTEN/NOTIFY if it is enabled
+func newEventsHub(cfg *models.ServerConfig) events.Hub {
+	if !cfg.EventsNotify {
+		return events.NewBus()
+	}
+	relay, relayErr := events.NewPGRelay(cfg.Database)
+	if relayErr != nil {
+		log.Fatalf("Failed to listen events: %+v", relayErr)
+	}
+	log.Println("Using PostgreSQL LISTEN/NOTIFY for events")
+	go relay.Run(context.Background())
+	return relay
+}
+
+// newGRPCServer returns gRPC server if its address is set, it uses certificates of web-server with HTTPS
+func newGRPCServer(cfg *models.ServerConfig, keeper *service.Service) *grpc.Server {
+	if cfg.GRPCAddress == "" {
+		return nil
+	}
+	var opts []grpc.ServerOption
+	if cfg.EnableHTTPS {
+		creds, credsErr := credentials.NewServerTLSFromFile("./certs/server.crt", "./certs/server.key")
+		if credsErr != nil {
+			log.Fatalf("Failed to load TLS certificates: %+v", credsErr)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	return grpcserver.NewServer(keeper, opts...)
+}
+
 // Run method that starts the server
 func (a *Server) Run() error {
 	addr := a.httpServer.Addr
@@ -65,6 +110,9 @@ func (a *Server) Run() error {
 			// ошибки закрытия Listener
 			log.Printf("Server Shutdown: %v", err)
 		}
+		if a.grpcServer != nil {
+			a.grpcServer.GracefulStop()
+		}
 		// сообщаем основному потоку,
 		// что все сетевые соединения обработаны и закрыты
 		close(idleConnsClosed)
@@ -86,6 +134,19 @@ func (a *Server) Run() error {
 		}()
 	}
 
+	if a.grpcServer != nil {
+		listener, listenErr := net.Listen("tcp", a.cfg.GRPCAddress)
+		if listenErr != nil {
+			log.Fatalf("Failed to listen gRPC: %+v", listenErr)
+		}
+		log.Printf("gRPC server started at %s", a.cfg.GRPCAddress)
+		go func() {
+			if err := a.grpcServer.Serve(listener); err != nil {
+				log.Fatalf("Failed to serve gRPC: %+v", err)
+			}
+		}()
+	}
+
 	<-idleConnsClosed
 	//
 	ctx, shutdown := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoAccessToken = newError(ErrNotFound, problem.CodeNotFound, "no such personal access token")
+	ErrScopeDenied   = newError(ErrForbidden, problem.CodeScopeDenied, "personal access token doesn't grant access to this route")
+	ErrPersonalToken = newError(ErrForbidden, problem.CodeScopeDenied, "personal access tokens are accepted only by routes of notes, cards, creds and files of REST API")
+)
+
+// CreateAccessToken creates personal access token of user with scopes, token is shown only in result
+func (s *Service) CreateAccessToken(userID uuid.UUID, newToken *models.NewAccessToken) (*models.AccessToken, error) {
+	if newToken.Name == "" || len(newToken.Scopes) == 0 || newToken.Expires.IsZero() {
+		return nil, ErrEmptyFields
+	}
+	token, tokenErr := s.database.Authorizer.CreateAccessToken(userID, newToken)
+	if tokenErr != nil {
+		switch {
+		case errors.Is(tokenErr, authorizer.ErrAccessTokenName):
+			return nil, ErrEmptyFields
+		case errors.Is(tokenErr, authorizer.ErrAccessTokenScope), errors.Is(tokenErr, authorizer.ErrAccessTokenExpiry):
+			return nil, wrapError(ErrInvalid, problem.CodeInvalidRequest, tokenErr)
+		default:
+			return nil, tokenErr
+		}
+	}
+	return token, nil
+}
+
+// AccessTokens returns personal access tokens of user that are not expired
+func (s *Service) AccessTokens(userID uuid.UUID) ([]models.AccessToken, error) {
+	return s.database.Authorizer.AccessTokens(userID)
+}
+
+// RevokeAccessToken revokes personal access token of user, requests with it are rejected at once
+func (s *Service) RevokeAccessToken(userID, tokenID uuid.UUID) error {
+	return notFound(s.database.Authorizer.RevokeAccessToken(userID, tokenID), ErrNoAccessToken)
+}
+
+// AuthenticateAccessToken returns personal access token of request
+func (s *Service) AuthenticateAccessToken(value string) (*models.AccessToken, error) {
+	token, tokenErr := s.database.Authorizer.ParseAccessToken(value)
+	if tokenErr != nil {
+		if errors.Is(tokenErr, authorizer.ErrInvalidPersonalToken) {
+			return nil, newError(ErrUnauthenticated, problem.CodeUnauthorized, fmt.Sprint(ErrUnauthorized, ": ", tokenErr))
+		}
+		return nil, tokenErr
+	}
+	return token, nil
+}
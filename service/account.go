@@ -0,0 +1,94 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/storage"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangePassword replaces password of user by new strong one, other sessions of user are revoked
+func (s *Service) ChangePassword(userID, sessionID uuid.UUID, change *models.PasswordChange, client *models.SessionClient) error {
+	if change.Password == "" || change.NewPassword == "" {
+		return ErrEmptyFields
+	}
+	if strongPassErr := s.database.PasswordChecker.VerifyPassword(change.NewPassword); strongPassErr != nil {
+		return wrapError(ErrRejected, problem.CodeRejected, strongPassErr)
+	}
+	return accountError(s.database.Authorizer.ChangePassword(userID, sessionID, change, client))
+}
+
+// DeleteAccount deletes user with all notes, cards, creds and files after user is authenticated again.
+// Tokens of user are rejected and data is removed at once, PurgeDeletedAccounts removes data again
+// while tokens of user could be still accepted by other servers and then removes the account
+func (s *Service) DeleteAccount(userID uuid.UUID, deletion *models.AccountDeletion, client *models.SessionClient) error {
+	if deletion.Password == "" {
+		return ErrEmptyFields
+	}
+	if deleteErr := s.database.Authorizer.DeleteAccount(userID, deletion, client); deleteErr != nil {
+		return accountError(deleteErr)
+	}
+	if purgeErr := s.database.Database.DeleteUserData(userID); purgeErr != nil {
+		log.Printf("account %s is deleted, its data will be removed later: %s", userID, purgeErr)
+	}
+	return nil
+}
+
+// PurgeDeletedAccounts removes data of deleted accounts with interval until context is done.
+// Data is removed until accounts are removed, so writes by tokens that other servers accepted
+// after deletion and deletions interrupted by failure are cleaned up
+func (s *Service) PurgeDeletedAccounts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		deleted, deletedErr := s.database.Authorizer.DeletedAccounts()
+		if deletedErr != nil {
+			log.Println("deleted accounts:", deletedErr)
+		}
+		for _, userID := range deleted {
+			if purgeErr := s.purgeAccount(userID); purgeErr != nil {
+				log.Printf("deleted account %s: %s", userID, purgeErr)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeAccount removes data of deleted user and then the user, so data never outlives its user.
+// User stays marked while its tokens could be still valid, data is removed again on next purge then
+func (s *Service) purgeAccount(userID uuid.UUID) error {
+	if deleteErr := s.database.Database.DeleteUserData(userID); deleteErr != nil {
+		return deleteErr
+	}
+	if removeErr := s.database.Authorizer.RemoveAccount(userID); removeErr != nil && !errors.Is(removeErr, storage.ErrNoValues) {
+		return removeErr
+	}
+	return nil
+}
+
+// accountError maps errors of checks before changes of account
+func accountError(err error) error {
+	var throttled *authorizer.ThrottledError
+	switch {
+	case errors.Is(err, authorizer.ErrComparePassword):
+		return ErrWrongPassword
+	case errors.Is(err, authorizer.ErrPasswordChanged):
+		return ErrPasswordRace
+	case errors.Is(err, authorizer.ErrHashPassword):
+		return wrapError(ErrInvalid, problem.CodeInvalidRequest, err)
+	case errors.As(err, &throttled):
+		return throttledError(throttled)
+	default:
+		return twoFactorError(err, ErrForbidden)
+	}
+}
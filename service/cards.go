@@ -0,0 +1,72 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/events"
+	"AlexSarva/GophKeeper/models"
+
+	"github.com/google/uuid"
+)
+
+// NewCard adds card of user, card id could be set by client
+func (s *Service) NewCard(userID uuid.UUID, card *models.NewCard) (models.Card, error) {
+	card.UserID = userID
+	initVersion(&card.ID, &card.Version)
+	if card.CardNumber == "" || card.CardOwner == "" || card.CardExp == "" {
+		return models.Card{}, ErrEmptyFields
+	}
+	newCard, newCardErr := s.database.Database.NewCard(card)
+	s.notify(newCardErr, userID, events.Created, "cards", newCard.ID)
+	return newCard, created(newCardErr)
+}
+
+// Cards returns all cards of user
+func (s *Service) Cards(userID uuid.UUID) ([]models.Card, error) {
+	return s.database.Database.AllCards(userID)
+}
+
+// Card returns card of user by id
+func (s *Service) Card(userID, cardID uuid.UUID) (models.Card, error) {
+	card, cardErr := s.database.Database.GetCard(cardID, userID)
+	return card, notFound(cardErr, ErrNoCard)
+}
+
+// EditCard changes card of user, empty fields keep current values.
+// Version of changed card should follow the current one
+func (s *Service) EditCard(userID, cardID uuid.UUID, editCard models.NewCard) (models.Card, error) {
+	card, cardErr := s.database.Database.GetCard(cardID, userID)
+	if cardErr != nil {
+		return models.Card{}, notFound(cardErr, ErrNoCard)
+	}
+	if editCard.Title == "" {
+		editCard.Title = card.Title
+	}
+	if editCard.CardNumber == "" {
+		editCard.CardNumber = card.CardNumber
+	}
+	if editCard.CardOwner == "" {
+		editCard.CardOwner = card.CardOwner
+	}
+	if editCard.CardExp == "" {
+		editCard.CardExp = card.CardExp
+	}
+	if editCard.Notes == "" {
+		editCard.Notes = card.Notes
+	}
+
+	editCard.ID = card.ID
+	editCard.UserID = userID
+	if !checkVersion(card.Version, &editCard.Version, &editCard.Signature) {
+		return models.Card{}, ErrVersion
+	}
+
+	newCard, newCardErr := s.database.Database.EditCard(editCard)
+	s.notify(newCardErr, userID, events.Updated, "cards", newCard.ID)
+	return newCard, notFound(newCardErr, ErrNoCard)
+}
+
+// DeleteCard removes card of user
+func (s *Service) DeleteCard(userID, cardID uuid.UUID) error {
+	deleteErr := s.database.Database.DeleteCard(cardID, userID)
+	s.notify(deleteErr, userID, events.Deleted, "cards", cardID)
+	return notFound(deleteErr, ErrNoCard)
+}
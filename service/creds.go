@@ -0,0 +1,69 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/events"
+	"AlexSarva/GophKeeper/models"
+
+	"github.com/google/uuid"
+)
+
+// NewCred adds cred of user, cred id could be set by client
+func (s *Service) NewCred(userID uuid.UUID, cred *models.NewCred) (models.Cred, error) {
+	cred.UserID = userID
+	initVersion(&cred.ID, &cred.Version)
+	if cred.Login == "" || cred.Passwd == "" {
+		return models.Cred{}, ErrEmptyFields
+	}
+	newCred, newCredErr := s.database.Database.NewCred(cred)
+	s.notify(newCredErr, userID, events.Created, "creds", newCred.ID)
+	return newCred, created(newCredErr)
+}
+
+// Creds returns all creds of user
+func (s *Service) Creds(userID uuid.UUID) ([]models.Cred, error) {
+	return s.database.Database.AllCreds(userID)
+}
+
+// Cred returns cred of user by id
+func (s *Service) Cred(userID, credID uuid.UUID) (models.Cred, error) {
+	cred, credErr := s.database.Database.GetCred(credID, userID)
+	return cred, notFound(credErr, ErrNoCred)
+}
+
+// EditCred changes cred of user, empty fields keep current values.
+// Version of changed cred should follow the current one
+func (s *Service) EditCred(userID, credID uuid.UUID, editCred models.NewCred) (models.Cred, error) {
+	cred, credErr := s.database.Database.GetCred(credID, userID)
+	if credErr != nil {
+		return models.Cred{}, notFound(credErr, ErrNoCred)
+	}
+	if editCred.Title == "" {
+		editCred.Title = cred.Title
+	}
+	if editCred.Login == "" {
+		editCred.Login = cred.Login
+	}
+	if editCred.Passwd == "" {
+		editCred.Passwd = cred.Passwd
+	}
+	if editCred.Notes == "" {
+		editCred.Notes = cred.Notes
+	}
+
+	editCred.ID = cred.ID
+	editCred.UserID = userID
+	if !checkVersion(cred.Version, &editCred.Version, &editCred.Signature) {
+		return models.Cred{}, ErrVersion
+	}
+
+	newCred, newCredErr := s.database.Database.EditCred(editCred)
+	s.notify(newCredErr, userID, events.Updated, "creds", newCred.ID)
+	return newCred, notFound(newCredErr, ErrNoCred)
+}
+
+// DeleteCred removes cred of user
+func (s *Service) DeleteCred(userID, credID uuid.UUID) error {
+	deleteErr := s.database.Database.DeleteCred(credID, userID)
+	s.notify(deleteErr, userID, events.Deleted, "creds", credID)
+	return notFound(deleteErr, ErrNoCred)
+}
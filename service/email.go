@@ -0,0 +1,67 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"errors"
+	"net/mail"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmailToken    = newError(ErrRejected, problem.CodeEmailTokenInvalid, "code is invalid, expired or already used")
+	ErrEmailVerified = newError(ErrConflict, problem.CodeEmailVerified, "email is already confirmed")
+)
+
+// VerifyEmail confirms email of user by code from message
+func (s *Service) VerifyEmail(verification *models.EmailVerification) error {
+	if verification.Token == "" {
+		return ErrEmptyFields
+	}
+	return emailError(s.database.Authorizer.VerifyEmail(verification.Token))
+}
+
+// ResendVerification sends new code that confirms email of user
+func (s *Service) ResendVerification(userID uuid.UUID) error {
+	return emailError(s.database.Authorizer.ResendVerification(userID))
+}
+
+// ForgotPassword sends code of password reset to email, result doesn't tell whether there is such user
+func (s *Service) ForgotPassword(forgot *models.PasswordForgot) error {
+	if forgot.Email == "" {
+		return ErrEmptyFields
+	}
+	if _, emailCheckErr := mail.ParseAddress(forgot.Email); emailCheckErr != nil {
+		return wrapError(ErrRejected, problem.CodeRejected, emailCheckErr)
+	}
+	return s.database.Authorizer.ForgotPassword(forgot.Email)
+}
+
+// ResetPassword sets new strong password of user by code of password reset, all sessions of user are revoked
+func (s *Service) ResetPassword(reset *models.PasswordReset) error {
+	if reset.Token == "" || reset.NewPassword == "" {
+		return ErrEmptyFields
+	}
+	if strongPassErr := s.database.PasswordChecker.VerifyPassword(reset.NewPassword); strongPassErr != nil {
+		return wrapError(ErrRejected, problem.CodeRejected, strongPassErr)
+	}
+	return emailError(s.database.Authorizer.ResetPassword(reset))
+}
+
+// emailError maps errors of codes sent to email
+func emailError(err error) error {
+	switch {
+	case errors.Is(err, authorizer.ErrInvalidEmailToken):
+		return ErrEmailToken
+	case errors.Is(err, authorizer.ErrEmailVerified):
+		return ErrEmailVerified
+	case errors.Is(err, authorizer.ErrEmailCooldown):
+		cooldown := wrapError(ErrThrottled, problem.CodeMailCooldown, err)
+		cooldown.retryAfter = authorizer.EmailTokenCooldown
+		return cooldown
+	default:
+		return accountError(err)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/crypto/keybundle"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/storage"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// enrollmentTTL time while new device waits for approve
+const enrollmentTTL = 10 * time.Minute
+
+var (
+	ErrNoBundle         = newError(ErrInvalid, problem.CodeInvalidRequest, "dont have bundle in request")
+	ErrEnrollmentExist  = newError(ErrConflict, problem.CodeEnrollmentExists, "enrollment with such key already exists")
+	ErrNoEnrollment     = newError(ErrNotFound, problem.CodeNotFound, "no such enrollment or it is expired")
+	ErrEnrollmentClosed = newError(ErrNotFound, problem.CodeNotFound, "no such enrollment, it is expired or already approved")
+)
+
+// StartEnrollment saves ephemeral public key of new device, returned enrollment has code
+// that user enters on approving device
+func (s *Service) StartEnrollment(userID uuid.UUID, newEnrollment *models.NewEnrollment) (*models.Enrollment, error) {
+	if len(newEnrollment.DevicePublic) != keybundle.DeviceKeySize {
+		return nil, wrapError(ErrInvalid, problem.CodeInvalidRequest, keybundle.ErrDeviceKey)
+	}
+	enrollment, enrollmentErr := s.database.Admin.NewEnrollment(&models.Enrollment{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Code:         keybundle.DeviceCode(newEnrollment.DevicePublic),
+		DevicePublic: newEnrollment.DevicePublic,
+		Expires:      time.Now().Add(enrollmentTTL),
+	})
+	if errors.Is(enrollmentErr, storage.ErrDuplicatePK) {
+		return nil, ErrEnrollmentExist
+	}
+	return enrollment, enrollmentErr
+}
+
+// Enrollment returns enrollment by code: approving device gets public key of new device,
+// new device polls it until sealed bundle appears
+func (s *Service) Enrollment(userID uuid.UUID, code string) (*models.Enrollment, error) {
+	enrollment, enrollmentErr := s.database.Admin.GetEnrollment(userID, keybundle.NormalizeCode(code))
+	if enrollmentErr != nil {
+		return nil, notFound(enrollmentErr, ErrNoEnrollment)
+	}
+	return enrollment, nil
+}
+
+// ApproveEnrollment saves key bundle sealed for public key of new device
+func (s *Service) ApproveEnrollment(userID uuid.UUID, code string, approve *models.EnrollmentApprove) error {
+	if len(approve.Bundle) == 0 {
+		return ErrNoBundle
+	}
+	return notFound(s.database.Admin.ApproveEnrollment(userID, keybundle.NormalizeCode(code), approve.Bundle), ErrEnrollmentClosed)
+}
+
+// DeleteEnrollment removes enrollment of user
+func (s *Service) DeleteEnrollment(userID uuid.UUID, code string) error {
+	return notFound(s.database.Admin.DeleteEnrollment(userID, keybundle.NormalizeCode(code)), ErrNoEnrollment)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/problem"
+	"errors"
+	"time"
+)
+
+// Kinds of service errors, transports map them to their status codes
+var (
+	ErrInvalid         = errors.New("invalid request")
+	ErrRejected        = errors.New("request rejected")
+	ErrConflict        = errors.New("conflict")
+	ErrNotFound        = errors.New("not found")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+	ErrThrottled       = errors.New("too many requests")
+)
+
+var (
+	ErrEmptyFields    = newError(ErrInvalid, problem.CodeEmptyFields, "empty fields error")
+	ErrNoFingerprint  = newError(ErrInvalid, problem.CodeNoFingerprint, "dont have fingerprint in request")
+	ErrUnauthorized   = newError(ErrUnauthenticated, problem.CodeUnauthorized, "user unauthorized")
+	ErrLoginExist     = newError(ErrConflict, problem.CodeLoginExists, "login is already busy")
+	ErrElementExist   = newError(ErrConflict, problem.CodeElementExists, "element with such id already exists")
+	ErrVersion        = newError(ErrConflict, problem.CodeVersionConflict, "element version conflict, reload it and try again")
+	ErrKeyFingerprint = newError(ErrConflict, problem.CodeKeyMismatch, "another key is registered on account")
+	ErrNoNote         = newError(ErrNotFound, problem.CodeNotFound, "no such note in db")
+	ErrNoCard         = newError(ErrNotFound, problem.CodeNotFound, "no such card in db")
+	ErrNoCred         = newError(ErrNotFound, problem.CodeNotFound, "no such cred in db")
+	ErrNoFile         = newError(ErrNotFound, problem.CodeNotFound, "no such file in db")
+	ErrNoElement      = newError(ErrNotFound, problem.CodeNotFound, "some element doesnt exist in db")
+	ErrVaultMismatch  = newError(ErrConflict, problem.CodeVersionConflict, "vault doesnt contain exactly all elements of user, reload it and try again")
+	ErrNoSession      = newError(ErrNotFound, problem.CodeNotFound, "no such active session")
+	ErrTwoFactorOn    = newError(ErrConflict, problem.CodeTwoFactorEnabled, "two-factor authentication is already enabled")
+	ErrTwoFactorOff   = newError(ErrConflict, problem.CodeTwoFactorDisabled, "two-factor authentication is not enabled")
+	ErrNoLockout      = newError(ErrNotFound, problem.CodeNotFound, "no such lockout that is not cleared")
+	ErrWrongPassword  = newError(ErrForbidden, problem.CodeInvalidCredentials, "current password is wrong")
+	ErrPasswordRace   = newError(ErrConflict, problem.CodeVersionConflict, "password is changed meanwhile, try again")
+)
+
+// Error service error, it matches its kind with errors.Is.
+// Code is stable code of error from problem catalogue
+type Error struct {
+	kind       error
+	code       string
+	message    string
+	retryAfter time.Duration
+}
+
+func newError(kind error, code, message string) *Error {
+	return &Error{kind: kind, code: code, message: message}
+}
+
+// wrapError makes error of kind with message of err
+func wrapError(kind error, code string, err error) *Error {
+	return newError(kind, code, err.Error())
+}
+
+// throttledError makes error of throttled login, it could be repeated after delay
+func throttledError(err *authorizer.ThrottledError) *Error {
+	throttled := wrapError(ErrThrottled, problem.CodeTooManyAttempts, err)
+	throttled.retryAfter = err.RetryAfter
+	return throttled
+}
+
+// Error returns message of error
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Code returns stable code of error
+func (e *Error) Code() string {
+	return e.code
+}
+
+// RetryAfter returns delay before request could be repeated, zero if it is not known
+func (e *Error) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Is reports whether error is of target kind
+func (e *Error) Is(target error) bool {
+	return target == e.kind
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/events"
+	"AlexSarva/GophKeeper/models"
+
+	"github.com/google/uuid"
+)
+
+// NewFile adds file of user, file id could be set by client
+func (s *Service) NewFile(userID uuid.UUID, file *models.NewFile) (models.File, error) {
+	file.UserID = userID
+	initVersion(&file.ID, &file.Version)
+	if file.Title == "" || file.FileName == "" {
+		return models.File{}, ErrEmptyFields
+	}
+	newFile, newFileErr := s.database.Database.NewFile(file)
+	s.notify(newFileErr, userID, events.Created, "files", newFile.ID)
+	return newFile, created(newFileErr)
+}
+
+// Files returns all files of user
+func (s *Service) Files(userID uuid.UUID) ([]models.File, error) {
+	return s.database.Database.AllFiles(userID)
+}
+
+// File returns file of user by id
+func (s *Service) File(userID, fileID uuid.UUID) (models.File, error) {
+	file, fileErr := s.database.Database.GetFile(fileID, userID)
+	return file, notFound(fileErr, ErrNoFile)
+}
+
+// EditFile changes file of user, title and file name are required,
+// missing content and empty notes keep current values. Version of changed file should follow the current one
+func (s *Service) EditFile(userID, fileID uuid.UUID, editFile *models.NewFile) (models.File, error) {
+	if editFile.Title == "" || editFile.FileName == "" {
+		return models.File{}, ErrEmptyFields
+	}
+	file, fileErr := s.database.Database.GetFile(fileID, userID)
+	if fileErr != nil {
+		return models.File{}, notFound(fileErr, ErrNoFile)
+	}
+	if editFile.File == nil {
+		editFile.File = file.File
+	}
+	if editFile.Notes == "" {
+		editFile.Notes = file.Notes
+	}
+
+	editFile.ID = file.ID
+	editFile.UserID = userID
+	if !checkVersion(file.Version, &editFile.Version, &editFile.Signature) {
+		return models.File{}, ErrVersion
+	}
+
+	newFile, newFileErr := s.database.Database.EditFile(editFile)
+	s.notify(newFileErr, userID, events.Updated, "files", newFile.ID)
+	return newFile, notFound(newFileErr, ErrNoFile)
+}
+
+// DeleteFile removes file of user
+func (s *Service) DeleteFile(userID, fileID uuid.UUID) error {
+	deleteErr := s.database.Database.DeleteFile(fileID, userID)
+	s.notify(deleteErr, userID, events.Deleted, "files", fileID)
+	return notFound(deleteErr, ErrNoFile)
+}
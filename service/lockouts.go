@@ -0,0 +1,17 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/models"
+
+	"github.com/google/uuid"
+)
+
+// Lockouts returns recent lockouts of logins for admins, active lockouts are not cleared and not expired
+func (s *Service) Lockouts(active bool) ([]models.Lockout, error) {
+	return s.database.Authorizer.Lockouts(active)
+}
+
+// ClearLockout allows logins of subject of lockout at once
+func (s *Service) ClearLockout(id uuid.UUID) error {
+	return notFound(s.database.Authorizer.ClearLockout(id), ErrNoLockout)
+}
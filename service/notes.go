@@ -0,0 +1,63 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/events"
+	"AlexSarva/GophKeeper/models"
+
+	"github.com/google/uuid"
+)
+
+// NewNote adds note of user, note id could be set by client
+func (s *Service) NewNote(userID uuid.UUID, note *models.NewNote) (models.Note, error) {
+	note.UserID = userID
+	initVersion(&note.ID, &note.Version)
+	if note.Note == "" {
+		return models.Note{}, ErrEmptyFields
+	}
+	newNote, newNoteErr := s.database.Database.NewNote(note)
+	s.notify(newNoteErr, userID, events.Created, "notes", newNote.ID)
+	return newNote, created(newNoteErr)
+}
+
+// Notes returns all notes of user
+func (s *Service) Notes(userID uuid.UUID) ([]models.Note, error) {
+	return s.database.Database.AllNotes(userID)
+}
+
+// Note returns note of user by id
+func (s *Service) Note(userID, noteID uuid.UUID) (models.Note, error) {
+	note, noteErr := s.database.Database.GetNote(noteID, userID)
+	return note, notFound(noteErr, ErrNoNote)
+}
+
+// EditNote changes note of user, empty fields keep current values.
+// Version of changed note should follow the current one
+func (s *Service) EditNote(userID, noteID uuid.UUID, editNote models.NewNote) (models.Note, error) {
+	note, noteErr := s.database.Database.GetNote(noteID, userID)
+	if noteErr != nil {
+		return models.Note{}, notFound(noteErr, ErrNoNote)
+	}
+	if editNote.Title == "" {
+		editNote.Title = note.Title
+	}
+	if editNote.Note == "" {
+		editNote.Note = note.Note
+	}
+
+	editNote.ID = note.ID
+	editNote.UserID = userID
+	if !checkVersion(note.Version, &editNote.Version, &editNote.Signature) {
+		return models.Note{}, ErrVersion
+	}
+
+	newNote, newNoteErr := s.database.Database.EditNote(editNote)
+	s.notify(newNoteErr, userID, events.Updated, "notes", newNote.ID)
+	return newNote, notFound(newNoteErr, ErrNoNote)
+}
+
+// DeleteNote removes note of user
+func (s *Service) DeleteNote(userID, noteID uuid.UUID) error {
+	deleteErr := s.database.Database.DeleteNote(noteID, userID)
+	s.notify(deleteErr, userID, events.Deleted, "notes", noteID)
+	return notFound(deleteErr, ErrNoNote)
+}
@@ -0,0 +1,208 @@
+// Package service contains business logic of GophKeeper,
+// it is shared by REST handlers and gRPC server
+package service
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/events"
+	"AlexSarva/GophKeeper/internal/app"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"AlexSarva/GophKeeper/storage"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service business logic over storages of application,
+// changes of elements are published to events hub
+type Service struct {
+	database *app.Storage
+	events   events.Hub
+}
+
+// NewService initializer of Service struct
+func NewService(database *app.Storage, hub events.Hub) *Service {
+	return &Service{database: database, events: hub}
+}
+
+// Register checks new user and signs the user up, returns user with tokens of session of client
+func (s *Service) Register(user models.User, client *models.SessionClient) (*models.User, error) {
+	if user.Email == "" || user.Password == "" || user.Username == "" {
+		return nil, ErrEmptyFields
+	}
+	if strongPassErr := s.database.PasswordChecker.VerifyPassword(user.Password); strongPassErr != nil {
+		return nil, wrapError(ErrRejected, problem.CodeRejected, strongPassErr)
+	}
+	if _, emailCheckErr := mail.ParseAddress(user.Email); emailCheckErr != nil {
+		return nil, wrapError(ErrRejected, problem.CodeRejected, emailCheckErr)
+	}
+
+	user.ID = uuid.New()
+	newUser, newUserErr := s.database.Authorizer.SignUp(user, client)
+	if newUserErr != nil {
+		if errors.Is(newUserErr, storage.ErrDuplicatePK) {
+			return nil, ErrLoginExist
+		}
+		if errors.Is(newUserErr, authorizer.ErrHashPassword) || errors.Is(newUserErr, authorizer.ErrGenerateToken) {
+			return nil, wrapError(ErrInvalid, problem.CodeInvalidRequest, newUserErr)
+		}
+		return nil, newUserErr
+	}
+	return newUser, nil
+}
+
+// Login checks email and password of user, returns user with pair of tokens of new session of client.
+// User with two-factor authentication gets challenge of second step instead
+func (s *Service) Login(userLogin *models.UserLogin, client *models.SessionClient) (*models.User, *models.TwoFactorChallenge, error) {
+	userInfo, challenge, userInfoErr := s.database.Authorizer.SignIn(userLogin, client)
+	if userInfoErr != nil {
+		if errors.Is(userInfoErr, authorizer.ErrNoUserExists) || errors.Is(userInfoErr, authorizer.ErrComparePassword) {
+			return nil, nil, wrapError(ErrUnauthenticated, problem.CodeInvalidCredentials, userInfoErr)
+		}
+		var throttled *authorizer.ThrottledError
+		if errors.As(userInfoErr, &throttled) {
+			return nil, nil, throttledError(throttled)
+		}
+		return nil, nil, userInfoErr
+	}
+	if challenge != nil {
+		return nil, challenge, nil
+	}
+	userInfo.Password = ""
+	return userInfo, nil, nil
+}
+
+// Refresh exchanges refresh token for new pair of tokens, session is seen from client
+func (s *Service) Refresh(refresh *models.TokenRefresh, client *models.SessionClient) (*models.Tokens, error) {
+	if refresh.RefreshToken == "" {
+		return nil, ErrEmptyFields
+	}
+	tokens, tokensErr := s.database.Authorizer.Refresh(refresh.RefreshToken, client)
+	if tokensErr != nil {
+		if errors.Is(tokensErr, authorizer.ErrInvalidRefreshToken) {
+			return nil, wrapError(ErrUnauthenticated, problem.CodeRefreshInvalid, tokensErr)
+		}
+		if errors.Is(tokensErr, authorizer.ErrRefreshTokenReused) {
+			return nil, wrapError(ErrUnauthenticated, problem.CodeRefreshReused, tokensErr)
+		}
+		return nil, tokensErr
+	}
+	return tokens, nil
+}
+
+// Authenticate returns id of user and id of session from token
+// Personal access tokens are rejected here, they are checked by AuthenticateAccessToken on routes of elements
+func (s *Service) Authenticate(token string) (uuid.UUID, uuid.UUID, error) {
+	if authorizer.IsAccessToken(token) {
+		return uuid.UUID{}, uuid.UUID{}, ErrPersonalToken
+	}
+	userID, sessionID, userIDErr := s.database.Authorizer.ParseToken(token)
+	if userIDErr != nil {
+		return uuid.UUID{}, uuid.UUID{}, newError(ErrUnauthenticated, problem.CodeUnauthorized, fmt.Sprint(ErrUnauthorized, ": ", userIDErr))
+	}
+	return userID, sessionID, nil
+}
+
+// Logout revokes access token and its session
+func (s *Service) Logout(token string) error {
+	if logoutErr := s.database.Authorizer.Logout(token); logoutErr != nil {
+		if errors.Is(logoutErr, authorizer.ErrInvalidAccessToken) {
+			return newError(ErrUnauthenticated, problem.CodeUnauthorized, fmt.Sprint(ErrUnauthorized, ": ", logoutErr))
+		}
+		return logoutErr
+	}
+	return nil
+}
+
+// LogoutEverywhere revokes all access, refresh and personal access tokens of user
+func (s *Service) LogoutEverywhere(userID uuid.UUID) error {
+	return s.database.Authorizer.LogoutEverywhere(userID)
+}
+
+// UserInfo returns information about user with fingerprint of registered key
+func (s *Service) UserInfo(userID uuid.UUID) (*models.User, error) {
+	userInfo, userInfoErr := s.database.Admin.GetUserInfo(userID)
+	if userInfoErr != nil {
+		return nil, userInfoErr
+	}
+	userInfo.Password = ""
+	return userInfo, nil
+}
+
+// SetUserKey registers fingerprint of client public key on account
+func (s *Service) SetUserKey(userID uuid.UUID, userKey *models.UserKey) error {
+	if userKey.Fingerprint == "" {
+		return ErrNoFingerprint
+	}
+	setErr := s.database.Admin.SetKeyFingerprint(userID, userKey)
+	if errors.Is(setErr, storage.ErrNoValues) {
+		return ErrKeyFingerprint
+	}
+	return setErr
+}
+
+// ReplaceVault replaces all elements of user in one transaction
+func (s *Service) ReplaceVault(userID uuid.UUID, vault *models.Vault) error {
+	replaceErr := s.database.Database.ReplaceVault(userID, vault)
+	s.notify(replaceErr, userID, events.Updated, events.KindVault, uuid.Nil)
+	if errors.Is(replaceErr, storage.ErrNoValues) {
+		return ErrNoElement
+	}
+	if errors.Is(replaceErr, storage.ErrVaultMismatch) {
+		return ErrVaultMismatch
+	}
+	return replaceErr
+}
+
+// Subscribe returns changes of elements of user, cancel should be called when subscriber is done
+func (s *Service) Subscribe(userID uuid.UUID) (<-chan events.Event, func()) {
+	return s.events.Subscribe(userID)
+}
+
+// notify publishes change of element if it is saved
+func (s *Service) notify(err error, userID uuid.UUID, action, kind string, id uuid.UUID) {
+	if err != nil {
+		return
+	}
+	s.events.Publish(events.Event{UserID: userID, Action: action, Kind: kind, ID: id, Time: time.Now()})
+}
+
+// initVersion prepares new element: ID is generated by client to sign it,
+// clients without signatures dont send it. Version of new element is always 1
+func initVersion(id *uuid.UUID, version *int) {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+	*version = 1
+}
+
+// checkVersion checks that version of changed element follows the current one.
+// Clients without signatures dont send version: it is incremented and the signature is dropped
+func checkVersion(current int, version *int, signature *string) bool {
+	if *version == 0 {
+		*version = current + 1
+		*signature = ""
+		return true
+	}
+	return *version == current+1
+}
+
+// notFound replaces storage error of missing element with service error
+func notFound(err error, notFoundErr error) error {
+	if errors.Is(err, storage.ErrNoValues) {
+		return notFoundErr
+	}
+	return err
+}
+
+// created replaces storage error of existing element with service error
+func created(err error) error {
+	if errors.Is(err, storage.ErrDuplicatePK) {
+		return ErrElementExist
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/models"
+
+	"github.com/google/uuid"
+)
+
+// Sessions returns active sessions of user, session of request is marked as current
+func (s *Service) Sessions(userID, currentID uuid.UUID) ([]models.Session, error) {
+	sessions, sessionsErr := s.database.Authorizer.Sessions(userID)
+	if sessionsErr != nil {
+		return nil, sessionsErr
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes session of user, all tokens of the session are rejected after it
+func (s *Service) RevokeSession(userID, sessionID uuid.UUID) error {
+	return notFound(s.database.Authorizer.RevokeSession(userID, sessionID), ErrNoSession)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"AlexSarva/GophKeeper/authorizer"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// LoginTwoFactor completes login by challenge with code of second factor,
+// returns user with pair of tokens of new session of client
+func (s *Service) LoginTwoFactor(login *models.TwoFactorLogin, client *models.SessionClient) (*models.User, error) {
+	if login.Challenge == "" || login.Code == "" {
+		return nil, ErrEmptyFields
+	}
+	userInfo, userInfoErr := s.database.Authorizer.SignInTwoFactor(login, client)
+	if userInfoErr != nil {
+		if errors.Is(userInfoErr, authorizer.ErrInvalidChallenge) || errors.Is(userInfoErr, authorizer.ErrTwoFactorDisabled) {
+			return nil, wrapError(ErrUnauthenticated, problem.CodeChallengeInvalid, authorizer.ErrInvalidChallenge)
+		}
+		var throttled *authorizer.ThrottledError
+		if errors.As(userInfoErr, &throttled) {
+			return nil, throttledError(throttled)
+		}
+		return nil, twoFactorError(userInfoErr, ErrUnauthenticated)
+	}
+	return userInfo, nil
+}
+
+// SetupTwoFactor generates TOTP secret of user, it is enabled by ConfirmTwoFactor
+func (s *Service) SetupTwoFactor(userID uuid.UUID) (*models.TwoFactorSetup, error) {
+	setup, setupErr := s.database.Authorizer.SetupTwoFactor(userID)
+	if setupErr != nil {
+		return nil, twoFactorError(setupErr, ErrForbidden)
+	}
+	return setup, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication by code of authenticator app, returns recovery codes.
+// Invalid codes are throttled by address of client and by account
+func (s *Service) ConfirmTwoFactor(userID uuid.UUID, code *models.TwoFactorCode, client *models.SessionClient) (*models.RecoveryCodes, error) {
+	if code.Code == "" {
+		return nil, ErrEmptyFields
+	}
+	codes, confirmErr := s.database.Authorizer.ConfirmTwoFactor(userID, code.Code, client)
+	if confirmErr != nil {
+		return nil, accountError(confirmErr)
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor disables two-factor authentication by code of authenticator app or recovery code.
+// Invalid codes are throttled by address of client and by account
+func (s *Service) DisableTwoFactor(userID uuid.UUID, code *models.TwoFactorCode, client *models.SessionClient) error {
+	if code.Code == "" {
+		return ErrEmptyFields
+	}
+	return accountError(s.database.Authorizer.DisableTwoFactor(userID, code.Code, client))
+}
+
+// twoFactorError maps errors of two-factor authentication, invalid code is error of kind
+func twoFactorError(err error, invalidCode error) error {
+	switch {
+	case errors.Is(err, authorizer.ErrInvalidTwoFactorCode):
+		return wrapError(invalidCode, problem.CodeTwoFactorInvalid, err)
+	case errors.Is(err, authorizer.ErrTwoFactorEnabled):
+		return ErrTwoFactorOn
+	case errors.Is(err, authorizer.ErrTwoFactorDisabled):
+		return ErrTwoFactorOff
+	default:
+		return err
+	}
+}
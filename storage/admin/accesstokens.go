@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// accessTokenColumns columns of personal access token
+const accessTokenColumns = "id, user_id, name, token_hash, scopes, items, created, expires, last_used"
+
+// NewAccessToken insert personal access token, expired tokens of user are removed
+func (a *Admin) NewAccessToken(token *models.AccessToken) error {
+	if _, cleanErr := a.database.Exec("delete from public.access_tokens where user_id = $1 and expires < now()", token.UserID); cleanErr != nil {
+		return cleanErr
+	}
+	_, err := a.database.Exec(`
+insert into public.access_tokens (id, user_id, name, token_hash, scopes, items, expires)
+values ($1, $2, $3, $4, $5, $6, $7)`,
+		token.ID, token.UserID, token.Name, token.TokenHash, token.Scopes, token.Items, token.Expires)
+	return err
+}
+
+// UserAccessTokens returns personal access tokens of user that are not expired, the newest are the first
+func (a *Admin) UserAccessTokens(userID uuid.UUID) ([]models.AccessToken, error) {
+	var tokens []models.AccessToken
+	err := a.database.Select(&tokens, `
+select `+accessTokenColumns+` from public.access_tokens
+where user_id = $1 and expires > now()
+order by created desc`, userID)
+	return tokens, err
+}
+
+// GetAccessToken get personal access token by hash, expired token and token of deleted user are not returned
+func (a *Admin) GetAccessToken(tokenHash string) (*models.AccessToken, error) {
+	var token models.AccessToken
+	err := a.database.Get(&token, `
+select `+accessTokenColumns+` from public.access_tokens
+where token_hash = $1 and expires > now() and user_id not in (select d.id from public.deleted_users d)`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNoValues
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// TouchAccessToken updates last used time of personal access token
+func (a *Admin) TouchAccessToken(id uuid.UUID) error {
+	_, err := a.database.Exec("update public.access_tokens set last_used = now() where id = $1", id)
+	return err
+}
+
+// DeleteUserAccessTokens removes all personal access tokens of user
+func (a *Admin) DeleteUserAccessTokens(userID uuid.UUID) error {
+	_, err := a.database.Exec("delete from public.access_tokens where user_id = $1", userID)
+	return err
+}
+
+// DeleteAccessToken removes personal access token of user,
+// storage.ErrNoValues is returned if user has no such token
+func (a *Admin) DeleteAccessToken(userID, id uuid.UUID) error {
+	res, err := a.database.Exec("delete from public.access_tokens where id = $1 and user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	affected, affectedErr := res.RowsAffected()
+	if affectedErr != nil {
+		return affectedErr
+	}
+	if affected == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package admin
 import (
 	"AlexSarva/GophKeeper/models"
 	"AlexSarva/GophKeeper/storage"
+	"AlexSarva/GophKeeper/storage/atrest"
 	"log"
 
 	"github.com/google/uuid"
@@ -13,10 +14,12 @@ import (
 // Admin initializing from PostgreSQL database
 type Admin struct {
 	database *sqlx.DB
+	sealer   *atrest.Sealer
 }
 
-// NewAdminDBConnection initializing from PostgreSQL database connection
-func NewAdminDBConnection(config string) *Admin {
+// NewAdminDBConnection initializing from PostgreSQL database connection,
+// username and email of users are encrypted at rest if sealer is set
+func NewAdminDBConnection(config string, sealer *atrest.Sealer) *Admin {
 
 	db, err := sqlx.Connect("postgres", config)
 	db.MustExec(ddl)
@@ -25,6 +28,7 @@ func NewAdminDBConnection(config string) *Admin {
 	}
 	return &Admin{
 		database: db,
+		sealer:   sealer,
 	}
 }
 
@@ -36,42 +40,21 @@ func (a *Admin) Ping() bool {
 
 // CheckUser insert new User in Database
 func (a *Admin) CheckUser(userID uuid.UUID) bool {
-	var user string
-	resErr := a.database.Get(&user, "select email from public.users where id = $1", userID)
+	var exists bool
+	resErr := a.database.Get(&exists, "select exists(select 1 from public.users where id = $1)", userID)
 	if resErr != nil {
 		return false
 	}
-	if len(user) == 0 {
-		return false
-	}
-	return true
+	return exists
 }
 
 // Register insert new User in Databse
 func (a *Admin) Register(user models.User) error {
-	tx := a.database.MustBegin()
-	resInsert, resErr := tx.NamedExec("INSERT INTO public.users (id, username, email, passwd, token, token_expires) VALUES (:id, :username, :email, :passwd, :token, :token_expires) on conflict (email) do nothing ", &user)
-	if resErr != nil {
-		return resErr
-	}
-	affectedRows, affectedRowsErr := resInsert.RowsAffected()
-	if affectedRowsErr != nil {
-		return affectedRowsErr
+	if a.sealer != nil {
+		return a.registerSealed(user)
 	}
-	if affectedRows == 0 {
-		return storage.ErrDuplicatePK
-	}
-	return tx.Commit()
-}
-
-// RenewToken refresh token for User in Databse
-func (a *Admin) RenewToken(user models.User) error {
 	tx := a.database.MustBegin()
-	resInsert, resErr := tx.Exec(`
-update public.users set token = $1,
-                      token_expires = $2
-                      where id = $3
-`, user.Token, user.TokenExp, user.ID)
+	resInsert, resErr := tx.NamedExec("INSERT INTO public.users (id, username, email, passwd) VALUES (:id, :username, :email, :passwd) on conflict (email) do nothing ", &user)
 	if resErr != nil {
 		return resErr
 	}
@@ -87,21 +70,57 @@ update public.users set token = $1,
 
 // Login insert new User in Databse
 func (a *Admin) Login(userLogin *models.UserLogin) (*models.User, error) {
-	var user models.User
-	err := a.database.Get(&user, "SELECT id, username, email, passwd, token, token_expires FROM public.users WHERE email=$1", userLogin.Email)
+	var user userRow
+	err := a.database.Get(&user, "SELECT "+userColumns+" FROM public.users WHERE (email=$1 or email_hash=$2) and "+notDeleted, userLogin.Email, a.emailHash(userLogin.Email))
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return a.openUser(&user)
 }
 
 // GetUserInfo get user credentials from database by username
 func (a *Admin) GetUserInfo(userID uuid.UUID) (*models.User, error) {
-	var userInfo models.User
-	err := a.database.Get(&userInfo, "SELECT id, username, email, passwd, token, token_expires FROM public.users WHERE id=$1", userID)
+	var row userRow
+	err := a.database.Get(&row, "SELECT "+userColumns+" FROM public.users WHERE id=$1 and "+notDeleted, userID)
 	if err != nil {
 		return nil, err
 	}
-	userInfo.Token = "Bearer " + userInfo.Token
-	return &userInfo, nil
+	return a.openUser(&row)
+}
+
+// SetKeyFingerprint registers fingerprint of public key on account, fingerprint could be changed
+// only if previous one matches it (after keys rotation). Setting the same fingerprint again is allowed,
+// so interrupted rotation could be resumed
+func (a *Admin) SetKeyFingerprint(userID uuid.UUID, userKey *models.UserKey) error {
+	res, err := a.database.Exec(`
+update public.users set key_fingerprint = $1
+where id = $2 and key_fingerprint in ('', $1, $3)`, userKey.Fingerprint, userID, userKey.Previous)
+	if err != nil {
+		return err
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
+}
+
+// SetPasswordHash replaces hash of password by hash of the same password made by current parameters,
+// hash is replaced only if it is not changed since it was checked
+func (a *Admin) SetPasswordHash(userID uuid.UUID, checked, hash string) error {
+	res, err := a.database.Exec("update public.users set passwd = $1 where id = $2 and passwd = $3", hash, userID, checked)
+	if err != nil {
+		return err
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
 }
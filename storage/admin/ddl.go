@@ -15,4 +15,161 @@ create table if not exists public.users
     token_expires timestamp,
     created       timestamp with time zone default now()
 );
+
+alter table public.users add column if not exists key_fingerprint text not null default '';
+alter table public.users add column if not exists sealed bytea;
+alter table public.users add column if not exists key_id text;
+alter table public.users add column if not exists email_hash text;
+create unique index if not exists users_email_hash_idx on public.users (email_hash);
+alter table public.users add column if not exists tokens_valid_after timestamp with time zone;
+alter table public.users add column if not exists email_verified timestamp with time zone;
+-- access tokens of sessions are not stored, legacy tokens of registration are cleared
+update public.users set token = null, token_expires = null where token is not null;
+
+create table if not exists public.enrollments
+(
+    id            uuid not null primary key,
+    user_id       uuid not null references public.users (id) on delete cascade,
+    code          text not null,
+    device_public bytea not null,
+    bundle        bytea,
+    created       timestamp with time zone default now(),
+    expires       timestamp with time zone not null,
+    unique (user_id, code)
+);
+
+create table if not exists public.refresh_tokens
+(
+    id         uuid not null primary key,
+    family_id  uuid not null,
+    user_id    uuid not null references public.users (id) on delete cascade,
+    token_hash text not null unique,
+    created    timestamp with time zone default now(),
+    expires    timestamp with time zone not null,
+    used       timestamp with time zone,
+    revoked    bool not null default false
+);
+
+create index if not exists refresh_tokens_family_idx on public.refresh_tokens (family_id);
+
+create table if not exists public.revoked_tokens
+(
+    jti     uuid not null primary key,
+    user_id uuid not null references public.users (id) on delete cascade,
+    expires timestamp with time zone not null
+);
+
+create table if not exists public.sessions
+(
+    id             uuid not null primary key,
+    user_id        uuid not null references public.users (id) on delete cascade,
+    device_name    text not null default '',
+    client_version text not null default '',
+    ip             text not null default '',
+    user_agent     text not null default '',
+    created        timestamp with time zone not null default now(),
+    last_seen      timestamp with time zone not null default now(),
+    revoked        timestamp with time zone
+);
+
+create index if not exists sessions_user_idx on public.sessions (user_id);
+
+create table if not exists public.two_factor
+(
+    user_id   uuid   not null primary key references public.users (id) on delete cascade,
+    secret    bytea  not null,
+    key_id    text   not null default '',
+    created   timestamp with time zone not null default now(),
+    enabled   timestamp with time zone,
+    last_step bigint not null default 0
+);
+
+create table if not exists public.recovery_codes
+(
+    user_id   uuid not null references public.users (id) on delete cascade,
+    code_hash text not null,
+    used      timestamp with time zone,
+    primary key (user_id, code_hash)
+);
+
+create table if not exists public.login_failures
+(
+    kind         text not null,
+    subject      text not null,
+    failures     int  not null default 0,
+    last_failure timestamp with time zone not null default now(),
+    locked_until timestamp with time zone,
+    primary key (kind, subject)
+);
+
+create table if not exists public.lockouts
+(
+    id           uuid not null primary key,
+    kind         text not null,
+    subject      text not null,
+    failures     int  not null,
+    created      timestamp with time zone not null default now(),
+    locked_until timestamp with time zone not null,
+    cleared      timestamp with time zone
+);
+
+create index if not exists lockouts_created_idx on public.lockouts (created);
+
+create table if not exists public.idempotency_keys
+(
+    user_id      uuid not null references public.users (id) on delete cascade,
+    key          text not null,
+    request_hash text not null,
+    status       int  not null default 0,
+    content_type text not null default '',
+    body         bytea,
+    created      timestamp with time zone default now(),
+    primary key (user_id, key)
+);
+
+alter table public.idempotency_keys add column if not exists key_id text not null default '';
+
+create table if not exists public.email_tokens
+(
+    token_hash text not null primary key,
+    user_id    uuid not null references public.users (id) on delete cascade,
+    purpose    text not null,
+    created    timestamp with time zone not null default now(),
+    expires    timestamp with time zone not null,
+    used       timestamp with time zone
+);
+
+create index if not exists email_tokens_user_idx on public.email_tokens (user_id, purpose);
+
+create table if not exists public.signing_keys
+(
+    id          text not null primary key,
+    algorithm   text not null,
+    private_key bytea not null,
+    key_id      text not null default '',
+    created     timestamp with time zone not null default now(),
+    activates   timestamp with time zone not null,
+    retires     timestamp with time zone
+);
+
+create table if not exists public.access_tokens
+(
+    id         uuid not null primary key,
+    user_id    uuid not null references public.users (id) on delete cascade,
+    name       text not null,
+    token_hash text not null unique,
+    scopes     text not null,
+    items      text not null default '',
+    created    timestamp with time zone not null default now(),
+    expires    timestamp with time zone not null,
+    last_used  timestamp with time zone
+);
+
+create index if not exists access_tokens_user_idx on public.access_tokens (user_id);
+
+create table if not exists public.deleted_users
+(
+    id      uuid not null primary key,
+    deleted timestamp with time zone not null default now()
+);
 `
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// notDeleted condition of user that is not deleted, row of deleted user stays until data of user is removed
+const notDeleted = "id not in (select d.id from public.deleted_users d)"
+
+// MarkUserDeleted marks user as deleted, deleted user can't log in and tokens of user are revoked.
+// Mark is kept after user is removed, so other servers revoke tokens of user
+func (a *Admin) MarkUserDeleted(userID uuid.UUID) error {
+	_, err := a.database.Exec("insert into public.deleted_users (id) values ($1) on conflict (id) do nothing", userID)
+	return err
+}
+
+// DeletedUsers returns users that are marked as deleted but not removed yet,
+// marks of removed users are forgotten after day, when all their tokens are expired
+func (a *Admin) DeletedUsers() ([]uuid.UUID, error) {
+	if _, cleanErr := a.database.Exec(`
+delete from public.deleted_users d
+where d.deleted < now() - interval '1 day' and not exists (select 1 from public.users u where u.id = d.id)`); cleanErr != nil {
+		return nil, cleanErr
+	}
+	var users []uuid.UUID
+	err := a.database.Select(&users, "select u.id from public.users u join public.deleted_users d on d.id = u.id")
+	return users, err
+}
+
+// DeleteUser removes user marked as deleted before time with sessions, tokens and two-factor authentication,
+// storage.ErrNoValues is returned if there is no such user or it is marked later
+func (a *Admin) DeleteUser(userID uuid.UUID, markedBefore time.Time) error {
+	res, err := a.database.Exec(`
+delete from public.users
+where id = $1 and id in (select d.id from public.deleted_users d where d.deleted < $2)`, userID, markedBefore)
+	if err != nil {
+		return err
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewEmailToken saves token sent to email, unused tokens of the same purpose are replaced by it.
+// storage.ErrDuplicatePK is returned if previous token of the purpose is issued less than cooldown ago
+func (a *Admin) NewEmailToken(token *models.EmailToken, cooldown time.Duration) error {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+
+	var recent bool
+	if recentErr := tx.Get(&recent, `
+select exists(select 1 from public.email_tokens where user_id = $1 and purpose = $2 and created > $3)`,
+		token.UserID, token.Purpose, time.Now().Add(-cooldown)); recentErr != nil {
+		return recentErr
+	}
+	if recent {
+		return storage.ErrDuplicatePK
+	}
+	if _, cleanErr := tx.Exec(`
+delete from public.email_tokens
+where user_id = $1 and (purpose = $2 or used is not null or expires < now())`, token.UserID, token.Purpose); cleanErr != nil {
+		return cleanErr
+	}
+	if _, insertErr := tx.Exec(`
+insert into public.email_tokens (token_hash, user_id, purpose, expires)
+values ($1, $2, $3, $4)`, token.TokenHash, token.UserID, token.Purpose, token.Expires); insertErr != nil {
+		return insertErr
+	}
+	return tx.Commit()
+}
+
+// UseEmailToken marks token of purpose as used, storage.ErrNoValues is returned
+// if there is no such token or it is used or expired
+func (a *Admin) UseEmailToken(tokenHash, purpose string) (*models.EmailToken, error) {
+	var token models.EmailToken
+	err := a.database.Get(&token, `
+update public.email_tokens set used = now()
+where token_hash = $1 and purpose = $2 and used is null and expires > now()
+returning token_hash, user_id, purpose, created, expires, used`, tokenHash, purpose)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNoValues
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// SetEmailVerified saves that user confirmed email, time of the first confirmation is kept
+func (a *Admin) SetEmailVerified(userID uuid.UUID) error {
+	_, err := a.database.Exec("update public.users set email_verified = coalesce(email_verified, now()) where id = $1", userID)
+	return err
+}
@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewEnrollment insert enrollment of new device, expired enrollments of user are removed
+func (a *Admin) NewEnrollment(enrollment *models.Enrollment) (*models.Enrollment, error) {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return nil, txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	if _, cleanErr := tx.Exec("delete from public.enrollments where user_id = $1 and expires < now()", enrollment.UserID); cleanErr != nil {
+		return nil, cleanErr
+	}
+	var newEnrollment models.Enrollment
+	insertErr := tx.Get(&newEnrollment, `
+insert into public.enrollments (id, user_id, code, device_public, expires)
+values ($1, $2, $3, $4, $5)
+on conflict (user_id, code) do nothing
+returning id, user_id, code, device_public, created, expires`,
+		enrollment.ID, enrollment.UserID, enrollment.Code, enrollment.DevicePublic, enrollment.Expires)
+	if insertErr != nil {
+		if errors.Is(insertErr, sql.ErrNoRows) {
+			return nil, storage.ErrDuplicatePK
+		}
+		return nil, insertErr
+	}
+	if commitErr := tx.Commit(); commitErr != nil {
+		return nil, commitErr
+	}
+	return &newEnrollment, nil
+}
+
+// GetEnrollment get not expired enrollment of user by code
+func (a *Admin) GetEnrollment(userID uuid.UUID, code string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	err := a.database.Get(&enrollment, `
+select id, user_id, code, device_public, bundle, created, expires
+from public.enrollments
+where user_id = $1 and code = $2 and expires > now()`, userID, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNoValues
+		}
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// ApproveEnrollment saves sealed key bundle, enrollment could be approved only once
+func (a *Admin) ApproveEnrollment(userID uuid.UUID, code string, bundle []byte) error {
+	res, err := a.database.Exec(`
+update public.enrollments set bundle = $1
+where user_id = $2 and code = $3 and bundle is null and expires > now()`, bundle, userID, code)
+	if err != nil {
+		return err
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
+}
+
+// DeleteEnrollment removes enrollment, used after keys are installed or if enrollment is canceled
+func (a *Admin) DeleteEnrollment(userID uuid.UUID, code string) error {
+	res, err := a.database.Exec("delete from public.enrollments where user_id = $1 and code = $2", userID, code)
+	if err != nil {
+		return err
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
+}
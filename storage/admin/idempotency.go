@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage/atrest"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// idempotencyAbandoned age of record in progress after which its request is considered lost,
+// it is longer than timeout of requests
+const idempotencyAbandoned = 2 * time.Minute
+
+// ReserveIdempotencyKey saves record of request in progress, expired and abandoned records of user are removed.
+// If key is already used it returns saved record
+func (a *Admin) ReserveIdempotencyKey(record *models.IdempotentResponse, window time.Duration) (*models.IdempotentResponse, error) {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return nil, txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	now := time.Now()
+	if _, cleanErr := tx.Exec(`
+delete from public.idempotency_keys
+where user_id = $1 and (created < $2 or (status = 0 and created < $3))`,
+		record.UserID, now.Add(-window), now.Add(-idempotencyAbandoned)); cleanErr != nil {
+		return nil, cleanErr
+	}
+	res, insertErr := tx.Exec(`
+insert into public.idempotency_keys (user_id, key, request_hash)
+values ($1, $2, $3)
+on conflict (user_id, key) do nothing`, record.UserID, record.Key, record.RequestHash)
+	if insertErr != nil {
+		return nil, insertErr
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return nil, affectedRowsErr
+	}
+	if affectedRows == 0 {
+		var saved models.IdempotentResponse
+		if getErr := tx.Get(&saved, `
+select user_id, key, request_hash, status, content_type, body, key_id, created
+from public.idempotency_keys
+where user_id = $1 and key = $2`, record.UserID, record.Key); getErr != nil {
+			return nil, getErr
+		}
+		if saved.KeyID != "" {
+			if a.sealer == nil {
+				return nil, ErrSealedUser
+			}
+			body, openErr := a.sealer.Open(saved.Body, atrest.AAD("idempotency_keys", saved.UserID, saved.Key))
+			if openErr != nil {
+				return nil, openErr
+			}
+			saved.Body = body
+		}
+		return &saved, tx.Commit()
+	}
+	return nil, tx.Commit()
+}
+
+// CompleteIdempotencyKey saves response of request, body is sealed if at-rest encryption is enabled,
+// because responses could contain secrets like personal access tokens and recovery codes
+func (a *Admin) CompleteIdempotencyKey(record *models.IdempotentResponse) error {
+	body, keyID := record.Body, ""
+	if a.sealer != nil {
+		var sealErr error
+		body, keyID, sealErr = a.sealer.Seal(record.Body, atrest.AAD("idempotency_keys", record.UserID, record.Key))
+		if sealErr != nil {
+			return sealErr
+		}
+	}
+	_, err := a.database.Exec(`
+update public.idempotency_keys set status = $1, content_type = $2, body = $3, key_id = $4
+where user_id = $5 and key = $6`, record.Status, record.ContentType, body, keyID, record.UserID, record.Key)
+	return err
+}
+
+// ReleaseIdempotencyKey removes record, so request could be retried
+func (a *Admin) ReleaseIdempotencyKey(userID uuid.UUID, key string) error {
+	_, err := a.database.Exec("delete from public.idempotency_keys where user_id = $1 and key = $2", userID, key)
+	return err
+}
+
+// rewrapIdempotency wraps data keys of sealed responses by current at-rest key
+func (a *Admin) rewrapIdempotency(batch int) (int, error) {
+	var rows []models.IdempotentResponse
+	selectErr := a.database.Select(&rows, `
+select user_id, key, body, key_id from public.idempotency_keys
+where key_id <> '' and key_id <> $1 limit $2`, a.sealer.Provider().CurrentKeyID(), batch)
+	if selectErr != nil {
+		return 0, selectErr
+	}
+	var changed int
+	for _, row := range rows {
+		sealed, keyID, rewrapErr := a.sealer.Rewrap(row.Body)
+		if rewrapErr != nil {
+			return changed, rewrapErr
+		}
+		_, updateErr := a.database.Exec("update public.idempotency_keys set body = $1, key_id = $2 where user_id = $3 and key = $4 and key_id = $5",
+			sealed, keyID, row.UserID, row.Key, row.KeyID)
+		if updateErr != nil {
+			return changed, updateErr
+		}
+		changed++
+	}
+	return changed, nil
+}
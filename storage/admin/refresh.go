@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+)
+
+// refreshColumns columns of refresh token
+const refreshColumns = "id, family_id, user_id, token_hash, created, expires, used, revoked"
+
+// NewRefreshToken insert refresh token, expired tokens of user are removed
+func (a *Admin) NewRefreshToken(token *models.RefreshToken) error {
+	if _, cleanErr := a.database.Exec("delete from public.refresh_tokens where user_id = $1 and expires < now()", token.UserID); cleanErr != nil {
+		return cleanErr
+	}
+	_, err := a.database.Exec(`
+insert into public.refresh_tokens (id, family_id, user_id, token_hash, expires)
+values ($1, $2, $3, $4, $5)`, token.ID, token.FamilyID, token.UserID, token.TokenHash, token.Expires)
+	return err
+}
+
+// UseRefreshToken marks refresh token as used, token could be used only once before it expires
+// and while its family is not revoked
+func (a *Admin) UseRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := a.database.Get(&token, `
+update public.refresh_tokens set used = now()
+where token_hash = $1 and used is null and not revoked and expires > now()
+returning `+refreshColumns, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNoValues
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetRefreshToken get refresh token by hash
+func (a *Admin) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := a.database.Get(&token, "select "+refreshColumns+" from public.refresh_tokens where token_hash = $1", tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNoValues
+		}
+		return nil, err
+	}
+	return &token, nil
+}
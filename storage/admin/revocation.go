@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RevokeToken saves id of access token that is revoked until it expires
+func (a *Admin) RevokeToken(token *models.RevokedToken) error {
+	_, err := a.database.Exec(`
+insert into public.revoked_tokens (jti, user_id, expires)
+values ($1, $2, $3)
+on conflict (jti) do nothing`, token.JTI, token.UserID, token.Expires)
+	return err
+}
+
+// RevokeUserTokens revokes access tokens of user issued before time, all refresh tokens and sessions of user.
+// Personal access tokens of user are removed too. Returns ids of revoked sessions
+func (a *Admin) RevokeUserTokens(userID uuid.UUID, before time.Time) ([]uuid.UUID, error) {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return nil, txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	sessions, revokeErr := revokeUserTokens(tx, userID, before)
+	if revokeErr != nil {
+		return nil, revokeErr
+	}
+	return sessions, tx.Commit()
+}
+
+// RevokeDeletedUserTokens revokes tokens of user like RevokeUserTokens and marks user as deleted
+// in one transaction, so user is never deleted with valid tokens. Returns ids of revoked sessions
+func (a *Admin) RevokeDeletedUserTokens(userID uuid.UUID, before time.Time) ([]uuid.UUID, error) {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return nil, txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	sessions, revokeErr := revokeUserTokens(tx, userID, before)
+	if revokeErr != nil {
+		return nil, revokeErr
+	}
+	if _, markErr := tx.Exec("insert into public.deleted_users (id) values ($1) on conflict (id) do nothing", userID); markErr != nil {
+		return nil, markErr
+	}
+	return sessions, tx.Commit()
+}
+
+// revokeUserTokens revokes tokens and sessions of user in transaction
+func revokeUserTokens(tx *sqlx.Tx, userID uuid.UUID, before time.Time) ([]uuid.UUID, error) {
+	if _, updateErr := tx.Exec("update public.users set tokens_valid_after = $1 where id = $2", before, userID); updateErr != nil {
+		return nil, updateErr
+	}
+	if _, revokeErr := tx.Exec("update public.refresh_tokens set revoked = true where user_id = $1", userID); revokeErr != nil {
+		return nil, revokeErr
+	}
+	var sessions []uuid.UUID
+	if sessionsErr := tx.Select(&sessions, `
+update public.sessions set revoked = $1 where user_id = $2 and revoked is null
+returning id`, before, userID); sessionsErr != nil {
+		return nil, sessionsErr
+	}
+	if _, tokensErr := tx.Exec("delete from public.access_tokens where user_id = $1", userID); tokensErr != nil {
+		return nil, tokensErr
+	}
+	return sessions, nil
+}
+
+// RevokedTokens returns revoked access tokens that are not expired yet, expired ones are removed
+func (a *Admin) RevokedTokens() ([]models.RevokedToken, error) {
+	if _, cleanErr := a.database.Exec("delete from public.revoked_tokens where expires < now()"); cleanErr != nil {
+		return nil, cleanErr
+	}
+	var tokens []models.RevokedToken
+	err := a.database.Select(&tokens, "select jti, user_id, expires from public.revoked_tokens")
+	return tokens, err
+}
+
+// RevokedUsers returns users whose tokens are revoked after time, tokens of deleted users
+// are revoked at time of deletion
+func (a *Admin) RevokedUsers(after time.Time) ([]models.UserRevocation, error) {
+	var users []models.UserRevocation
+	err := a.database.Select(&users, `
+select id, tokens_valid_after from public.users where tokens_valid_after > $1
+union all
+select id, deleted from public.deleted_users where deleted > $1`, after)
+	return users, err
+}
@@ -0,0 +1,162 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"AlexSarva/GophKeeper/storage/atrest"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrSealedUser user row is sealed, but server is started without at-rest keys
+var ErrSealedUser = errors.New("user is encrypted at rest, at-rest keys are required")
+
+// userColumns columns of user info, username and email are NULL in sealed rows
+const userColumns = `id, coalesce(username, '') as username, coalesce(email, '') as email,
+passwd, key_fingerprint, email_verified, sealed`
+
+// userRow user info with sealed username and email
+type userRow struct {
+	models.User
+	Sealed []byte `db:"sealed"`
+}
+
+// sealedUser part of user row that is encrypted at rest
+type sealedUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// emailHash returns blind index of email, it is empty if at-rest encryption is disabled
+func (a *Admin) emailHash(email string) string {
+	if a.sealer == nil {
+		return ""
+	}
+	return a.sealer.BlindIndex(email)
+}
+
+// sealUser encrypts username and email of user
+func (a *Admin) sealUser(userID uuid.UUID, username, email string) ([]byte, string, error) {
+	plain, marshalErr := json.Marshal(sealedUser{Username: username, Email: email})
+	if marshalErr != nil {
+		return nil, "", marshalErr
+	}
+	return a.sealer.Seal(plain, atrest.AAD("users", userID))
+}
+
+// openUser decrypts username and email of sealed row
+func (a *Admin) openUser(row *userRow) (*models.User, error) {
+	if row.Sealed == nil {
+		return &row.User, nil
+	}
+	if a.sealer == nil {
+		return nil, ErrSealedUser
+	}
+	plain, openErr := a.sealer.Open(row.Sealed, atrest.AAD("users", row.ID))
+	if openErr != nil {
+		return nil, openErr
+	}
+	var identity sealedUser
+	if unmarshalErr := json.Unmarshal(plain, &identity); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	row.Username, row.Email = identity.Username, identity.Email
+	return &row.User, nil
+}
+
+// registerSealed inserts user with sealed username and email,
+// email is unique among legacy rows and blind indexes of sealed ones
+func (a *Admin) registerSealed(user models.User) error {
+	sealed, keyID, sealErr := a.sealUser(user.ID, user.Username, user.Email)
+	if sealErr != nil {
+		return sealErr
+	}
+	res, resErr := a.database.Exec(`
+insert into public.users (id, passwd, sealed, key_id, email_hash)
+select $1, $2, $3, $4, $5
+where not exists (select 1 from public.users where email = $6)
+on conflict do nothing`,
+		user.ID, user.Password, sealed, keyID, a.emailHash(user.Email), user.Email)
+	if resErr != nil {
+		return resErr
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrDuplicatePK
+	}
+	return nil
+}
+
+// Rewrap wraps data keys of sealed users, TOTP secrets, signing keys and saved responses by current at-rest key
+// and seals ones that were saved before at-rest encryption was enabled
+func (a *Admin) Rewrap(batch int) (int, error) {
+	var rows []struct {
+		ID     uuid.UUID `db:"id"`
+		Sealed []byte    `db:"sealed"`
+		KeyID  string    `db:"key_id"`
+	}
+	selectErr := a.database.Select(&rows, `select id, sealed, key_id from public.users
+where sealed is not null and key_id <> $1 limit $2`, a.sealer.Provider().CurrentKeyID(), batch)
+	if selectErr != nil {
+		return 0, selectErr
+	}
+	var changed int
+	for _, row := range rows {
+		sealed, keyID, rewrapErr := a.sealer.Rewrap(row.Sealed)
+		if rewrapErr != nil {
+			return changed, rewrapErr
+		}
+		_, updateErr := a.database.Exec(`update public.users set sealed = $1, key_id = $2
+where id = $3 and key_id = $4`, sealed, keyID, row.ID, row.KeyID)
+		if updateErr != nil {
+			return changed, updateErr
+		}
+		changed++
+	}
+	if changed >= batch {
+		return changed, nil
+	}
+
+	var legacy []models.User
+	selectErr = a.database.Select(&legacy, `select id, coalesce(username, '') as username, coalesce(email, '') as email
+from public.users where sealed is null limit $1`, batch-changed)
+	if selectErr != nil {
+		return changed, selectErr
+	}
+	for _, user := range legacy {
+		sealed, keyID, sealErr := a.sealUser(user.ID, user.Username, user.Email)
+		if sealErr != nil {
+			return changed, sealErr
+		}
+		_, updateErr := a.database.Exec(`update public.users
+set sealed = $1, key_id = $2, email_hash = $3, username = null, email = null
+where id = $4 and sealed is null`, sealed, keyID, a.emailHash(user.Email), user.ID)
+		if updateErr != nil {
+			return changed, updateErr
+		}
+		changed++
+	}
+	if changed >= batch {
+		return changed, nil
+	}
+
+	rewrapped, rewrapErr := a.rewrapTwoFactor(batch - changed)
+	changed += rewrapped
+	if rewrapErr != nil || changed >= batch {
+		return changed, rewrapErr
+	}
+
+	rewrapped, rewrapErr = a.rewrapSigningKeys(batch - changed)
+	changed += rewrapped
+	if rewrapErr != nil || changed >= batch {
+		return changed, rewrapErr
+	}
+
+	rewrapped, rewrapErr = a.rewrapIdempotency(batch - changed)
+	return changed + rewrapped, rewrapErr
+}
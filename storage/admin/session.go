@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// sessionColumns columns of session
+const sessionColumns = "id, user_id, device_name, client_version, ip, user_agent, created, last_seen, revoked"
+
+// liveSession condition of session that has valid refresh token
+const liveSession = `exists (select 1 from public.refresh_tokens r
+where r.family_id = s.id and r.used is null and not r.revoked and r.expires > now())`
+
+// NewSession insert session of login, sessions of user that are ended more than day ago are removed
+func (a *Admin) NewSession(session *models.Session) error {
+	if _, cleanErr := a.database.Exec(`
+delete from public.sessions s
+where s.user_id = $1 and coalesce(s.revoked, s.last_seen) < now() - interval '1 day' and not `+liveSession, session.UserID); cleanErr != nil {
+		return cleanErr
+	}
+	_, err := a.database.Exec(`
+insert into public.sessions (id, user_id, device_name, client_version, ip, user_agent)
+values ($1, $2, $3, $4, $5, $6)`,
+		session.ID, session.UserID, session.DeviceName, session.ClientVersion, session.IP, session.UserAgent)
+	return err
+}
+
+// TouchSession updates last seen time and address of session
+func (a *Admin) TouchSession(id uuid.UUID, ip string) error {
+	_, err := a.database.Exec("update public.sessions set last_seen = now(), ip = $2 where id = $1", id, ip)
+	return err
+}
+
+// UserSessions returns active sessions of user, recently seen are the first
+func (a *Admin) UserSessions(userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := a.database.Select(&sessions, `
+select `+sessionColumns+` from public.sessions s
+where s.user_id = $1 and s.revoked is null and `+liveSession+`
+order by s.last_seen desc`, userID)
+	return sessions, err
+}
+
+// RevokeSession revokes session of user and its refresh tokens, returns time of revocation
+func (a *Admin) RevokeSession(userID, id uuid.UUID) (time.Time, error) {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return time.Time{}, txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	if _, revokeErr := tx.Exec("update public.refresh_tokens set revoked = true where family_id = $1 and user_id = $2", id, userID); revokeErr != nil {
+		return time.Time{}, revokeErr
+	}
+	var revoked time.Time
+	getErr := tx.Get(&revoked, `
+update public.sessions set revoked = now()
+where id = $1 and user_id = $2 and revoked is null
+returning revoked`, id, userID)
+	if getErr != nil {
+		if errors.Is(getErr, sql.ErrNoRows) {
+			return time.Time{}, storage.ErrNoValues
+		}
+		return time.Time{}, getErr
+	}
+	return revoked, tx.Commit()
+}
+
+// RevokedSessions returns sessions revoked after time
+func (a *Admin) RevokedSessions(after time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := a.database.Select(&sessions, "select "+sessionColumns+" from public.sessions s where s.revoked > $1", after)
+	return sessions, err
+}
@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"AlexSarva/GophKeeper/storage/atrest"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// signingKeyColumns columns of signing keys
+const signingKeyColumns = "id, algorithm, private_key, key_id, created, activates, retires"
+
+// SigningKeys returns keys of JWT signatures that are not retired, private keys are in plaintext.
+// Retired keys are removed
+func (a *Admin) SigningKeys() ([]models.SigningKey, error) {
+	if _, cleanErr := a.database.Exec("delete from public.signing_keys where retires < now()"); cleanErr != nil {
+		return nil, cleanErr
+	}
+	var keys []models.SigningKey
+	if selectErr := a.database.Select(&keys, "select "+signingKeyColumns+" from public.signing_keys order by activates"); selectErr != nil {
+		return nil, selectErr
+	}
+	for i := range keys {
+		if keys[i].KeyID == "" {
+			continue
+		}
+		if a.sealer == nil {
+			return nil, ErrSealedUser
+		}
+		private, openErr := a.sealer.Open(keys[i].PrivateKey, atrest.AAD("signing_keys", keys[i].ID))
+		if openErr != nil {
+			return nil, openErr
+		}
+		keys[i].PrivateKey = private
+	}
+	return keys, nil
+}
+
+// AddSigningKey saves new key of JWT signatures, keys activated before it retire after overlap since its activation.
+// storage.ErrDuplicatePK is returned if the latest key is activated or will be activated less than rotateAfter ago,
+// so servers that rotate keys at the same time add only one key. Zero rotateAfter adds key anyway
+func (a *Admin) AddSigningKey(key *models.SigningKey, rotateAfter, overlap time.Duration) error {
+	private, keyID, sealErr := a.sealSigningKey(key.ID, key.PrivateKey)
+	if sealErr != nil {
+		return sealErr
+	}
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+
+	if _, lockErr := tx.Exec("lock table public.signing_keys in share row exclusive mode"); lockErr != nil {
+		return lockErr
+	}
+	if rotateAfter > 0 {
+		var recent bool
+		if recentErr := tx.Get(&recent, "select exists(select 1 from public.signing_keys where activates > $1)",
+			time.Now().Add(-rotateAfter)); recentErr != nil {
+			return recentErr
+		}
+		if recent {
+			return storage.ErrDuplicatePK
+		}
+	}
+	if _, retireErr := tx.Exec(`
+update public.signing_keys set retires = $1
+where activates < $2 and (retires is null or retires > $1)`, key.Activates.Add(overlap), key.Activates); retireErr != nil {
+		return retireErr
+	}
+	if _, insertErr := tx.Exec(`
+insert into public.signing_keys (id, algorithm, private_key, key_id, activates)
+values ($1, $2, $3, $4, $5)`, key.ID, key.Algorithm, private, keyID, key.Activates); insertErr != nil {
+		return insertErr
+	}
+	return tx.Commit()
+}
+
+// sealSigningKey encrypts private key at rest, it is kept in plaintext if at-rest encryption is disabled
+func (a *Admin) sealSigningKey(id string, private []byte) ([]byte, string, error) {
+	if a.sealer == nil {
+		return private, "", nil
+	}
+	return a.sealer.Seal(private, atrest.AAD("signing_keys", id))
+}
+
+// rewrapSigningKeys wraps data keys of sealed private keys by current at-rest key
+// and seals private keys that were saved before at-rest encryption was enabled
+func (a *Admin) rewrapSigningKeys(batch int) (int, error) {
+	var rows []models.SigningKey
+	selectErr := a.database.Select(&rows, "select "+signingKeyColumns+" from public.signing_keys where key_id <> $1 limit $2",
+		a.sealer.Provider().CurrentKeyID(), batch)
+	if selectErr != nil {
+		return 0, selectErr
+	}
+	var changed int
+	for _, row := range rows {
+		var sealed []byte
+		var keyID string
+		var wrapErr error
+		if row.KeyID == "" {
+			sealed, keyID, wrapErr = a.sealSigningKey(row.ID, row.PrivateKey)
+		} else {
+			sealed, keyID, wrapErr = a.sealer.Rewrap(row.PrivateKey)
+		}
+		if wrapErr != nil {
+			return changed, wrapErr
+		}
+		_, updateErr := a.database.Exec("update public.signing_keys set private_key = $1, key_id = $2 where id = $3 and key_id = $4",
+			sealed, keyID, row.ID, row.KeyID)
+		if updateErr != nil {
+			return changed, updateErr
+		}
+		changed++
+	}
+	return changed, nil
+}
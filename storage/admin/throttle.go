@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// loginFailureColumns columns of failed login attempts
+const loginFailureColumns = "kind, subject, failures, last_failure, locked_until"
+
+// lockoutColumns columns of lockout
+const lockoutColumns = "id, kind, subject, failures, created, locked_until, cleared"
+
+// maxLockouts max count of lockouts returned to admin
+const maxLockouts = 100
+
+// LoginFailure returns failed login attempts of kind and subject
+func (a *Admin) LoginFailure(kind, subject string) (*models.LoginFailure, error) {
+	var failure models.LoginFailure
+	err := a.database.Get(&failure, "select "+loginFailureColumns+" from public.login_failures where kind = $1 and subject = $2", kind, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNoValues
+		}
+		return nil, err
+	}
+	return &failure, nil
+}
+
+// AddLoginFailure counts failed login attempt, failures before window are forgotten.
+// Forgotten failures without active lock are removed
+func (a *Admin) AddLoginFailure(kind, subject string, window time.Duration) (*models.LoginFailure, error) {
+	forgotten := time.Now().Add(-window)
+	if _, cleanErr := a.database.Exec(`
+delete from public.login_failures
+where last_failure < $1 and (locked_until is null or locked_until < now())`, forgotten); cleanErr != nil {
+		return nil, cleanErr
+	}
+	var failure models.LoginFailure
+	err := a.database.Get(&failure, `
+insert into public.login_failures (kind, subject, failures) values ($1, $2, 1)
+on conflict (kind, subject) do update set
+failures = case when public.login_failures.last_failure < $3 then 1 else public.login_failures.failures + 1 end,
+last_failure = now()
+returning `+loginFailureColumns, kind, subject, forgotten)
+	if err != nil {
+		return nil, err
+	}
+	return &failure, nil
+}
+
+// LockLogin locks logins of subject of lockout until its time and records lockout,
+// counter of failures starts again after lock
+func (a *Admin) LockLogin(lockout *models.Lockout) error {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	if _, lockErr := tx.Exec(`
+update public.login_failures set failures = 0, locked_until = $3
+where kind = $1 and subject = $2`, lockout.Kind, lockout.Subject, lockout.LockedUntil); lockErr != nil {
+		return lockErr
+	}
+	if _, insertErr := tx.Exec(`
+insert into public.lockouts (id, kind, subject, failures, locked_until)
+values ($1, $2, $3, $4, $5)`, lockout.ID, lockout.Kind, lockout.Subject, lockout.Failures, lockout.LockedUntil); insertErr != nil {
+		return insertErr
+	}
+	return tx.Commit()
+}
+
+// ResetLoginFailures forgets failed login attempts of kind and subject
+func (a *Admin) ResetLoginFailures(kind, subject string) error {
+	_, err := a.database.Exec("delete from public.login_failures where kind = $1 and subject = $2", kind, subject)
+	return err
+}
+
+// Lockouts returns recent lockouts, the newest are the first. Active lockouts are not cleared and not expired
+func (a *Admin) Lockouts(active bool) ([]models.Lockout, error) {
+	var lockouts []models.Lockout
+	err := a.database.Select(&lockouts, `
+select `+lockoutColumns+` from public.lockouts
+where not $1 or (cleared is null and locked_until > now())
+order by created desc limit $2`, active, maxLockouts)
+	return lockouts, err
+}
+
+// ClearLockout marks lockout as cleared and forgets failures of its subject, so logins are allowed at once
+func (a *Admin) ClearLockout(id uuid.UUID) error {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	var lockout models.Lockout
+	clearErr := tx.Get(&lockout, "update public.lockouts set cleared = now() where id = $1 and cleared is null returning "+lockoutColumns, id)
+	if clearErr != nil {
+		if errors.Is(clearErr, sql.ErrNoRows) {
+			return storage.ErrNoValues
+		}
+		return clearErr
+	}
+	if _, deleteErr := tx.Exec("delete from public.login_failures where kind = $1 and subject = $2", lockout.Kind, lockout.Subject); deleteErr != nil {
+		return deleteErr
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,201 @@
+package admin
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"AlexSarva/GophKeeper/storage/atrest"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// SetTwoFactorSecret saves TOTP secret that is not confirmed yet,
+// secret of enabled two-factor authentication is not replaced
+func (a *Admin) SetTwoFactorSecret(userID uuid.UUID, secret string) error {
+	sealed, keyID, sealErr := a.sealSecret(userID, secret)
+	if sealErr != nil {
+		return sealErr
+	}
+	res, err := a.database.Exec(`
+insert into public.two_factor (user_id, secret, key_id)
+values ($1, $2, $3)
+on conflict (user_id) do update set secret = excluded.secret, key_id = excluded.key_id, created = now(), last_step = 0
+where public.two_factor.enabled is null`, userID, sealed, keyID)
+	if err != nil {
+		return err
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrDuplicatePK
+	}
+	return nil
+}
+
+// GetTwoFactor returns TOTP secret of user in plaintext
+func (a *Admin) GetTwoFactor(userID uuid.UUID) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	err := a.database.Get(&twoFactor, "select user_id, secret, key_id, enabled, last_step from public.two_factor where user_id = $1", userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNoValues
+		}
+		return nil, err
+	}
+	if twoFactor.KeyID == "" {
+		return &twoFactor, nil
+	}
+	if a.sealer == nil {
+		return nil, ErrSealedUser
+	}
+	secret, openErr := a.sealer.Open(twoFactor.Secret, atrest.AAD("two_factor", userID))
+	if openErr != nil {
+		return nil, openErr
+	}
+	twoFactor.Secret = secret
+	return &twoFactor, nil
+}
+
+// EnableTwoFactor enables two-factor authentication, step of confirmation code is used.
+// Hashes of recovery codes replace previous ones
+func (a *Admin) EnableTwoFactor(userID uuid.UUID, step int64, codeHashes []string) error {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	res, enableErr := tx.Exec(`
+update public.two_factor set enabled = now(), last_step = $2
+where user_id = $1 and enabled is null`, userID, step)
+	if enableErr != nil {
+		return enableErr
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	if _, deleteErr := tx.Exec("delete from public.recovery_codes where user_id = $1", userID); deleteErr != nil {
+		return deleteErr
+	}
+	for _, codeHash := range codeHashes {
+		if _, insertErr := tx.Exec("insert into public.recovery_codes (user_id, code_hash) values ($1, $2)", userID, codeHash); insertErr != nil {
+			return insertErr
+		}
+	}
+	return tx.Commit()
+}
+
+// UseTwoFactorStep marks time step of TOTP code as used, codes of used and earlier steps are rejected
+func (a *Admin) UseTwoFactorStep(userID uuid.UUID, step int64) error {
+	res, err := a.database.Exec(`
+update public.two_factor set last_step = $2
+where user_id = $1 and enabled is not null and last_step < $2`, userID, step)
+	return affected(res, err)
+}
+
+// UseChallenge records used two-factor challenge among revoked tokens until it expires,
+// storage.ErrNoValues is returned if challenge is already used
+func (a *Admin) UseChallenge(challenge *models.RevokedToken) error {
+	res, err := a.database.Exec(`
+insert into public.revoked_tokens (jti, user_id, expires)
+values ($1, $2, $3)
+on conflict (jti) do nothing`, challenge.JTI, challenge.UserID, challenge.Expires)
+	return affected(res, err)
+}
+
+// UseRecoveryCode marks recovery code as used, every code could be used only once
+func (a *Admin) UseRecoveryCode(userID uuid.UUID, codeHash string) error {
+	res, err := a.database.Exec(`
+update public.recovery_codes set used = now()
+where user_id = $1 and code_hash = $2 and used is null`, userID, codeHash)
+	return affected(res, err)
+}
+
+// DisableTwoFactor removes TOTP secret and recovery codes of user
+func (a *Admin) DisableTwoFactor(userID uuid.UUID) error {
+	tx, txErr := a.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+	if _, deleteErr := tx.Exec("delete from public.recovery_codes where user_id = $1", userID); deleteErr != nil {
+		return deleteErr
+	}
+	if _, deleteErr := tx.Exec("delete from public.two_factor where user_id = $1", userID); deleteErr != nil {
+		return deleteErr
+	}
+	return tx.Commit()
+}
+
+// affected returns storage.ErrNoValues if statement changed nothing
+func affected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
+}
+
+// sealSecret encrypts TOTP secret if at-rest encryption is enabled, plaintext secret has empty key id
+func (a *Admin) sealSecret(userID uuid.UUID, secret string) ([]byte, string, error) {
+	if a.sealer == nil {
+		return []byte(secret), "", nil
+	}
+	return a.sealer.Seal([]byte(secret), atrest.AAD("two_factor", userID))
+}
+
+// rewrapTwoFactor wraps data keys of sealed TOTP secrets by current at-rest key
+// and seals secrets that were saved before at-rest encryption was enabled
+func (a *Admin) rewrapTwoFactor(batch int) (int, error) {
+	var rows []models.TwoFactor
+	selectErr := a.database.Select(&rows, `select user_id, secret, key_id from public.two_factor
+where key_id <> $1 limit $2`, a.sealer.Provider().CurrentKeyID(), batch)
+	if selectErr != nil {
+		return 0, selectErr
+	}
+	var changed int
+	for _, row := range rows {
+		var sealed []byte
+		var keyID string
+		var wrapErr error
+		if row.KeyID == "" {
+			sealed, keyID, wrapErr = a.sealSecret(row.UserID, string(row.Secret))
+		} else {
+			sealed, keyID, wrapErr = a.sealer.Rewrap(row.Secret)
+		}
+		if wrapErr != nil {
+			return changed, wrapErr
+		}
+		_, updateErr := a.database.Exec("update public.two_factor set secret = $1, key_id = $2 where user_id = $3 and key_id = $4",
+			sealed, keyID, row.UserID, row.KeyID)
+		if updateErr != nil {
+			return changed, updateErr
+		}
+		changed++
+	}
+	return changed, nil
+}
@@ -0,0 +1,102 @@
+package atrest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealRotateRewrap(t *testing.T) {
+	keysPath := filepath.Join(t.TempDir(), "atrest.json")
+	provider, providerErr := LoadFileKeys(keysPath)
+	assert.NoError(t, providerErr)
+	sealer := NewSealer(provider)
+	aad := AAD("notes", "0f1c5a6e", 3)
+
+	sealed, oldKeyID, sealErr := sealer.Seal([]byte("row"), aad)
+	assert.NoError(t, sealErr)
+	plain, openErr := sealer.Open(sealed, aad)
+	assert.NoError(t, openErr)
+	assert.Equal(t, "row", string(plain))
+	_, openErr = sealer.Open(sealed, AAD("notes", "0f1c5a6e", 4))
+	assert.ErrorIs(t, openErr, ErrOpen)
+
+	newKeyID, rotateErr := RotateFileKeys(keysPath)
+	assert.NoError(t, rotateErr)
+	assert.NotEqual(t, oldKeyID, newKeyID)
+	assert.NoError(t, provider.Reload())
+	assert.Equal(t, newKeyID, provider.CurrentKeyID())
+
+	// rows wrapped by old key are still readable
+	plain, openErr = sealer.Open(sealed, aad)
+	assert.NoError(t, openErr)
+	assert.Equal(t, "row", string(plain))
+
+	rewrapped, keyID, rewrapErr := sealer.Rewrap(sealed)
+	assert.NoError(t, rewrapErr)
+	assert.Equal(t, newKeyID, keyID)
+	plain, openErr = sealer.Open(rewrapped, aad)
+	assert.NoError(t, openErr)
+	assert.Equal(t, "row", string(plain))
+
+	assert.Equal(t, sealer.BlindIndex("User@Example.com "), sealer.BlindIndex("user@example.com"))
+	reloaded, reloadedErr := LoadFileKeys(keysPath)
+	assert.NoError(t, reloadedErr)
+	assert.Equal(t, sealer.BlindIndex("user@example.com"), NewSealer(reloaded).BlindIndex("user@example.com"))
+}
+
+// rewrapFunc implements Rewrapper by function
+type rewrapFunc func(batch int) (int, error)
+
+func (f rewrapFunc) Rewrap(batch int) (int, error) {
+	return f(batch)
+}
+
+func TestRetireKeys(t *testing.T) {
+	keysPath := filepath.Join(t.TempDir(), "atrest.json")
+	provider, providerErr := LoadFileKeys(keysPath)
+	assert.NoError(t, providerErr)
+	sealer := NewSealer(provider)
+	sealed, oldKeyID, sealErr := sealer.Seal([]byte("row"), nil)
+	assert.NoError(t, sealErr)
+	newKeyID, rotateErr := RotateFileKeys(keysPath)
+	assert.NoError(t, rotateErr)
+	assert.NoError(t, provider.Reload())
+
+	retired, retireErr := provider.RetireKeys(oldKeyID)
+	assert.NoError(t, retireErr)
+	assert.Empty(t, retired, "key that is not current is never kept alone")
+
+	// rows are not re-wrapped, so pass is not completed and old key is kept
+	ctx, cancel := context.WithCancel(context.Background())
+	pending := rewrapFunc(func(batch int) (int, error) {
+		cancel()
+		return 0, errors.New("database is not available")
+	})
+	RunRewrap(ctx, sealer, time.Nanosecond, pending)
+	_, openErr := sealer.Open(sealed, nil)
+	assert.NoError(t, openErr)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	var passes int
+	rewrapped := rewrapFunc(func(batch int) (int, error) {
+		passes++
+		if passes > 1 {
+			cancel()
+		}
+		return 0, nil
+	})
+	RunRewrap(ctx, sealer, time.Nanosecond, rewrapped)
+	_, openErr = sealer.Open(sealed, nil)
+	assert.ErrorIs(t, openErr, ErrUnknownKey)
+
+	reloaded, reloadedErr := LoadFileKeys(keysPath)
+	assert.NoError(t, reloadedErr)
+	assert.Equal(t, newKeyID, reloaded.CurrentKeyID())
+	_, unwrapErr := reloaded.Unwrap(oldKeyID, nil)
+	assert.ErrorIs(t, unwrapErr, ErrUnknownKey)
+}
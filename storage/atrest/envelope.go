@@ -0,0 +1,168 @@
+package atrest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const envelopeVersion = 1
+
+var (
+	ErrEnvelope = errors.New("wrong at-rest envelope")
+	ErrOpen     = errors.New("fail to decrypt row, it could be moved from another row")
+)
+
+// Sealer encrypts rows in envelopes:
+// version, key id (uint8 length), wrapped data key (uint16 length), nonce, AES-GCM ciphertext.
+// Additional data binds ciphertext to its row, so it cant be moved to another one
+type Sealer struct {
+	provider KeyProvider
+}
+
+// NewSealer initializer of Sealer struct
+func NewSealer(provider KeyProvider) *Sealer {
+	return &Sealer{provider: provider}
+}
+
+// Provider returns key provider of sealer
+func (s *Sealer) Provider() KeyProvider {
+	return s.provider
+}
+
+// envelope parsed envelope
+type envelope struct {
+	keyID   string
+	wrapped []byte
+	payload []byte
+}
+
+// Seal encrypts plain with new data key, returns envelope and id of key that wraps data key
+func (s *Sealer) Seal(plain, aad []byte) ([]byte, string, error) {
+	dataKey, dataKeyErr := newKey()
+	if dataKeyErr != nil {
+		return nil, "", dataKeyErr
+	}
+	aead, aeadErr := newAEAD(dataKey)
+	if aeadErr != nil {
+		return nil, "", aeadErr
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, randErr := io.ReadFull(rand.Reader, nonce); randErr != nil {
+		return nil, "", randErr
+	}
+	keyID := s.provider.CurrentKeyID()
+	wrapped, wrapErr := s.provider.Wrap(keyID, dataKey)
+	if wrapErr != nil {
+		return nil, "", wrapErr
+	}
+	env := envelope{keyID: keyID, wrapped: wrapped, payload: aead.Seal(nonce, nonce, plain, aad)}
+	sealed, marshalErr := env.marshal()
+	return sealed, keyID, marshalErr
+}
+
+// Open decrypts envelope, aad should be the same as while sealing
+func (s *Sealer) Open(sealed, aad []byte) ([]byte, error) {
+	env, parseErr := parseEnvelope(sealed)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	dataKey, unwrapErr := s.provider.Unwrap(env.keyID, env.wrapped)
+	if unwrapErr != nil {
+		return nil, unwrapErr
+	}
+	aead, aeadErr := newAEAD(dataKey)
+	if aeadErr != nil {
+		return nil, aeadErr
+	}
+	if len(env.payload) < aead.NonceSize() {
+		return nil, ErrEnvelope
+	}
+	plain, openErr := aead.Open(nil, env.payload[:aead.NonceSize()], env.payload[aead.NonceSize():], aad)
+	if openErr != nil {
+		return nil, ErrOpen
+	}
+	return plain, nil
+}
+
+// Rewrap wraps data key of envelope by current key, ciphertext of row isn't changed.
+// Returns new envelope and id of current key
+func (s *Sealer) Rewrap(sealed []byte) ([]byte, string, error) {
+	env, parseErr := parseEnvelope(sealed)
+	if parseErr != nil {
+		return nil, "", parseErr
+	}
+	keyID := s.provider.CurrentKeyID()
+	if env.keyID == keyID {
+		return sealed, keyID, nil
+	}
+	dataKey, unwrapErr := s.provider.Unwrap(env.keyID, env.wrapped)
+	if unwrapErr != nil {
+		return nil, "", unwrapErr
+	}
+	wrapped, wrapErr := s.provider.Wrap(keyID, dataKey)
+	if wrapErr != nil {
+		return nil, "", wrapErr
+	}
+	env.keyID, env.wrapped = keyID, wrapped
+	newSealed, marshalErr := env.marshal()
+	return newSealed, keyID, marshalErr
+}
+
+// BlindIndex returns HMAC of normalized value, it allows to search rows by value without decryption
+func (s *Sealer) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, s.provider.IndexKey())
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AAD returns additional data of row from table name and values that identify row
+func AAD(table string, values ...interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(table)
+	for _, value := range values {
+		buf.WriteString("|")
+		buf.WriteString(fmt.Sprint(value))
+	}
+	return buf.Bytes()
+}
+
+func (e *envelope) marshal() ([]byte, error) {
+	if len(e.keyID) > 255 || len(e.wrapped) > 65535 {
+		return nil, ErrEnvelope
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(envelopeVersion)
+	buf.WriteByte(byte(len(e.keyID)))
+	buf.WriteString(e.keyID)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(e.wrapped)))
+	buf.Write(e.wrapped)
+	buf.Write(e.payload)
+	return buf.Bytes(), nil
+}
+
+func parseEnvelope(sealed []byte) (*envelope, error) {
+	if len(sealed) < 2 || sealed[0] != envelopeVersion {
+		return nil, ErrEnvelope
+	}
+	keyIDEnd := 2 + int(sealed[1])
+	if len(sealed) < keyIDEnd+2 {
+		return nil, ErrEnvelope
+	}
+	wrappedEnd := keyIDEnd + 2 + int(binary.BigEndian.Uint16(sealed[keyIDEnd:]))
+	if len(sealed) < wrappedEnd {
+		return nil, ErrEnvelope
+	}
+	return &envelope{
+		keyID:   string(sealed[2:keyIDEnd]),
+		wrapped: sealed[keyIDEnd+2 : wrappedEnd],
+		payload: sealed[wrappedEnd:],
+	}, nil
+}
@@ -0,0 +1,260 @@
+// Package atrest implements server-side envelope encryption of database rows.
+// Every row is encrypted with its own data key, data key is wrapped by key of KeyProvider,
+// so rotation of provider key re-wraps only data keys.
+package atrest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeySize size of provider keys and data keys (AES-256)
+const KeySize = 32
+
+var (
+	ErrUnknownKey = errors.New("unknown at-rest key id")
+	ErrUnwrap     = errors.New("fail to unwrap data key")
+)
+
+// KeyProvider provides keys that wrap data keys of rows, it could be implemented by KMS
+type KeyProvider interface {
+	// CurrentKeyID returns id of key that wraps data keys of new rows
+	CurrentKeyID() string
+	// Wrap encrypts data key with key of keyID
+	Wrap(keyID string, dataKey []byte) ([]byte, error)
+	// Unwrap decrypts data key with key of keyID
+	Unwrap(keyID string, wrapped []byte) ([]byte, error)
+	// IndexKey returns key of blind indexes, it never changes while rotation
+	IndexKey() []byte
+}
+
+// Reloader is implemented by providers which keys could be changed while server works
+type Reloader interface {
+	Reload() error
+}
+
+// Retirer is implemented by providers which old keys could be removed when no rows are wrapped by them
+type Retirer interface {
+	// RetireKeys removes all keys except current key keep, returns ids of removed keys.
+	// Nothing is removed if keep is not current anymore
+	RetireKeys(keep string) ([]string, error)
+}
+
+// keyFile format of local key file
+type keyFile struct {
+	Current  string            `json:"current"`
+	IndexKey []byte            `json:"index_key"`
+	Keys     map[string][]byte `json:"keys"`
+}
+
+// FileKeyProvider keeps keys in local JSON file, it is created with one key at first start.
+// Old keys stay in file after rotation, so rows are readable until they are re-wrapped,
+// then they are removed by RetireKeys
+type FileKeyProvider struct {
+	mu       sync.RWMutex
+	path     string
+	modified time.Time
+	keys     keyFile
+}
+
+// LoadFileKeys reads key file, file with new key is created if it doesnt exist
+func LoadFileKeys(path string) (*FileKeyProvider, error) {
+	provider := &FileKeyProvider{path: path}
+	if _, statErr := os.Stat(path); errors.Is(statErr, os.ErrNotExist) {
+		indexKey, indexKeyErr := newKey()
+		if indexKeyErr != nil {
+			return nil, indexKeyErr
+		}
+		keys := keyFile{IndexKey: indexKey, Keys: make(map[string][]byte)}
+		if _, addErr := keys.add(); addErr != nil {
+			return nil, addErr
+		}
+		if saveErr := keys.save(path); saveErr != nil {
+			return nil, saveErr
+		}
+	}
+	if reloadErr := provider.Reload(); reloadErr != nil {
+		return nil, reloadErr
+	}
+	return provider, nil
+}
+
+// RotateFileKeys adds new key in key file and makes it current, returns id of new key.
+// Running server picks it up and re-wraps rows in background
+func RotateFileKeys(path string) (string, error) {
+	provider, loadErr := LoadFileKeys(path)
+	if loadErr != nil {
+		return "", loadErr
+	}
+	keyID, addErr := provider.keys.add()
+	if addErr != nil {
+		return "", addErr
+	}
+	return keyID, provider.keys.save(path)
+}
+
+// Reload reads key file again if it was changed
+func (p *FileKeyProvider) Reload() error {
+	info, statErr := os.Stat(p.path)
+	if statErr != nil {
+		return statErr
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if info.ModTime().Equal(p.modified) {
+		return nil
+	}
+	content, readErr := os.ReadFile(p.path)
+	if readErr != nil {
+		return readErr
+	}
+	var keys keyFile
+	if unmarshalErr := json.Unmarshal(content, &keys); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	if _, ok := keys.Keys[keys.Current]; !ok || len(keys.IndexKey) != KeySize {
+		return fmt.Errorf("wrong at-rest key file %s", p.path)
+	}
+	p.keys = keys
+	p.modified = info.ModTime()
+	return nil
+}
+
+// RetireKeys removes old keys from key file, file is read again, so keys added meanwhile are kept
+func (p *FileKeyProvider) RetireKeys(keep string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	content, readErr := os.ReadFile(p.path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	var keys keyFile
+	if unmarshalErr := json.Unmarshal(content, &keys); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	key, ok := keys.Keys[keep]
+	if !ok || keys.Current != keep || len(keys.Keys) == 1 {
+		return nil, nil
+	}
+	retired := make([]string, 0, len(keys.Keys)-1)
+	for keyID := range keys.Keys {
+		if keyID != keep {
+			retired = append(retired, keyID)
+		}
+	}
+	keys.Keys = map[string][]byte{keep: key}
+	if saveErr := keys.save(p.path); saveErr != nil {
+		return nil, saveErr
+	}
+	info, statErr := os.Stat(p.path)
+	if statErr != nil {
+		return nil, statErr
+	}
+	p.keys = keys
+	p.modified = info.ModTime()
+	return retired, nil
+}
+
+// CurrentKeyID returns id of key that wraps data keys of new rows
+func (p *FileKeyProvider) CurrentKeyID() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.keys.Current
+}
+
+// Wrap encrypts data key with AES-GCM by key of keyID
+func (p *FileKeyProvider) Wrap(keyID string, dataKey []byte) ([]byte, error) {
+	aead, aeadErr := p.aead(keyID)
+	if aeadErr != nil {
+		return nil, aeadErr
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, randErr := io.ReadFull(rand.Reader, nonce); randErr != nil {
+		return nil, randErr
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+// Unwrap decrypts data key by key of keyID
+func (p *FileKeyProvider) Unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	aead, aeadErr := p.aead(keyID)
+	if aeadErr != nil {
+		return nil, aeadErr
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrUnwrap
+	}
+	dataKey, openErr := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+	if openErr != nil {
+		return nil, ErrUnwrap
+	}
+	return dataKey, nil
+}
+
+// IndexKey returns key of blind indexes
+func (p *FileKeyProvider) IndexKey() []byte {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.keys.IndexKey
+}
+
+func (p *FileKeyProvider) aead(keyID string) (cipher.AEAD, error) {
+	p.mu.RLock()
+	key, ok := p.keys.Keys[keyID]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return newAEAD(key)
+}
+
+// add generates new key and makes it current
+func (k *keyFile) add() (string, error) {
+	key, keyErr := newKey()
+	if keyErr != nil {
+		return "", keyErr
+	}
+	suffix := make([]byte, 4)
+	if _, randErr := io.ReadFull(rand.Reader, suffix); randErr != nil {
+		return "", randErr
+	}
+	// random suffix keeps ids unique even if keys are added at the same time
+	keyID := time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+	k.Keys[keyID] = key
+	k.Current = keyID
+	return keyID, nil
+}
+
+// save writes key file, it is readable only by owner
+func (k *keyFile) save(path string) error {
+	content, marshalErr := json.MarshalIndent(k, "", "  ")
+	if marshalErr != nil {
+		return marshalErr
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+func newKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, randErr := io.ReadFull(rand.Reader, key); randErr != nil {
+		return nil, randErr
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, blockErr := aes.NewCipher(key)
+	if blockErr != nil {
+		return nil, blockErr
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,94 @@
+package atrest
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// rewrapBatch number of rows that are re-wrapped in one query
+const rewrapBatch = 100
+
+// Rewrapper is implemented by storages with sealed rows
+type Rewrapper interface {
+	// Rewrap wraps data keys of up to batch rows by current key and seals rows saved in plaintext,
+	// returns number of changed rows
+	Rewrap(batch int) (int, error)
+}
+
+// retireAfter number of rewrap intervals since key became current, after which old keys could be retired.
+// Other servers reload keys with the same interval, so they dont wrap new rows by old keys anymore
+const retireAfter = 3
+
+// RunRewrap periodically reloads keys of provider and re-wraps rows of storages,
+// it works until context is canceled. Old keys are retired when pass of all storages
+// is completed without errors and changes, as no rows are wrapped by old keys then
+func RunRewrap(ctx context.Context, sealer *Sealer, interval time.Duration, storages ...Rewrapper) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var currentKeyID string
+	var currentSince time.Time
+	for {
+		if reloader, ok := sealer.Provider().(Reloader); ok {
+			if reloadErr := reloader.Reload(); reloadErr != nil {
+				log.Println(reloadErr)
+			}
+		}
+		// key is read before pass, so rows of pass are re-wrapped by it
+		if keyID := sealer.Provider().CurrentKeyID(); keyID != currentKeyID {
+			currentKeyID, currentSince = keyID, time.Now()
+		}
+		completed := true
+		for _, storage := range storages {
+			changed, done := rewrapAll(ctx, storage)
+			completed = completed && done && changed == 0
+		}
+		if completed && time.Since(currentSince) >= retireAfter*interval {
+			retireKeys(sealer.Provider(), currentKeyID)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rewrapAll re-wraps rows of storage batch by batch until all of them use current key,
+// returns number of changed rows and whether all rows of storage use current key
+func rewrapAll(ctx context.Context, storage Rewrapper) (int, bool) {
+	var total int
+	var done bool
+	for ctx.Err() == nil {
+		changed, rewrapErr := storage.Rewrap(rewrapBatch)
+		if rewrapErr != nil {
+			log.Println(rewrapErr)
+			break
+		}
+		total += changed
+		if changed < rewrapBatch {
+			done = true
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("%d rows are re-wrapped with at-rest key", total)
+	}
+	return total, done
+}
+
+// retireKeys removes old keys of provider, keys of providers that dont support it are kept
+func retireKeys(provider KeyProvider, keep string) {
+	retirer, ok := provider.(Retirer)
+	if !ok {
+		return
+	}
+	retired, retireErr := retirer.RetireKeys(keep)
+	if retireErr != nil {
+		log.Println(retireErr)
+		return
+	}
+	if len(retired) > 0 {
+		log.Printf("at-rest keys %v are retired", retired)
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"AlexSarva/GophKeeper/models"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -13,6 +14,9 @@ var ErrDuplicatePK = errors.New("duplicate PK")
 // ErrNoValues error that occurs when no values selected from database
 var ErrNoValues = errors.New("no values from select")
 
+// ErrVaultMismatch error that occurs when replaced vault doesnt contain exactly all elements of user
+var ErrVaultMismatch = errors.New("elements of vault dont match elements of user")
+
 // Database primary interface for all types of databases
 type Database interface {
 	Ping() bool
@@ -40,4 +44,18 @@ type Database interface {
 	GetFile(cardID uuid.UUID, userID uuid.UUID) (models.File, error)
 	EditFile(file *models.NewFile) (models.File, error)
 	DeleteFile(fileID uuid.UUID, userID uuid.UUID) error
+
+	ReplaceVault(userID uuid.UUID, vault *models.Vault) error
+	DeleteUserData(userID uuid.UUID) error
+}
+
+// Idempotency storage of responses of requests with idempotency keys, records live for window
+type Idempotency interface {
+	// ReserveIdempotencyKey saves record of request in progress,
+	// if key is already used it returns saved record and nothing is changed
+	ReserveIdempotencyKey(record *models.IdempotentResponse, window time.Duration) (*models.IdempotentResponse, error)
+	// CompleteIdempotencyKey saves response of request
+	CompleteIdempotencyKey(record *models.IdempotentResponse) error
+	// ReleaseIdempotencyKey removes record, so request could be retried
+	ReleaseIdempotencyKey(userID uuid.UUID, key string) error
 }
@@ -0,0 +1,30 @@
+package storagepg
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// DeleteUserData removes all notes, cards, creds and files of user in one transaction
+func (d *PostgresDB) DeleteUserData(userID uuid.UUID) error {
+	tx, txErr := d.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+
+	for _, table := range []string{"notes", "cards", "creds", "files"} {
+		if _, deleteErr := tx.Exec("delete from public."+table+" where user_id = $1", userID); deleteErr != nil {
+			return deleteErr
+		}
+	}
+	return tx.Commit()
+}
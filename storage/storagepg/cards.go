@@ -3,21 +3,30 @@ package storagepg
 import (
 	"AlexSarva/GophKeeper/models"
 	"AlexSarva/GophKeeper/storage"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// NewCard adds new credit card to database
+// NewCard adds new credit card to database, ID of card is generated by client
 func (d *PostgresDB) NewCard(card *models.NewCard) (models.Card, error) {
+	if d.sealer != nil {
+		newCard := models.Card{ID: card.ID, Title: card.Title, CardNumber: card.CardNumber, CardOwner: card.CardOwner, CardExp: card.CardExp, Notes: card.Notes, Version: card.Version, Signature: card.Signature, Created: time.Now()}
+		if insertErr := d.insertSealed(cardsTable, card.UserID, card.ID, card.Version, newCard); insertErr != nil {
+			return models.Card{}, insertErr
+		}
+		return newCard, nil
+	}
 	var newCard models.Card
-	resErr := d.database.Get(&newCard, `insert into public.cards (user_id, title, card_number,
-card_owner, card_exp, notes)
-values ($1, $2, $3, $4, $5, $6)
+	resErr := d.database.Get(&newCard, `insert into public.cards (id, user_id, title, card_number,
+card_owner, card_exp, notes, version, signature)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+on conflict (id) do nothing
 returning id, title, card_number,
-card_owner, card_exp, notes, created, changed;`,
-		card.UserID, card.Title, card.CardNumber, card.CardOwner, card.CardExp, card.Notes)
+card_owner, card_exp, notes, version, signature, created, changed;`,
+		card.ID, card.UserID, card.Title, card.CardNumber, card.CardOwner, card.CardExp, card.Notes, card.Version, card.Signature)
 	if resErr != nil {
-		return models.Card{}, resErr
+		return models.Card{}, insertErr(resErr)
 	}
 	return newCard, nil
 }
@@ -26,21 +35,43 @@ card_owner, card_exp, notes, created, changed;`,
 func (d *PostgresDB) AllCards(userID uuid.UUID) ([]models.Card, error) {
 	var cards []models.Card
 	resErr := d.database.Select(&cards, `select id, title, card_number,
-card_owner, card_exp, notes, created, changed
-from public.cards where user_id = $1 order by changed desc nulls last, created desc`,
+card_owner, card_exp, notes, version, signature, created, changed
+from public.cards where user_id = $1 and sealed is null order by changed desc nulls last, created desc`,
 		userID)
 	if resErr != nil {
 		return nil, resErr
 	}
+	if d.sealer == nil {
+		return cards, nil
+	}
+	sealedCards, sealedErr := allSealed[models.Card](d, cardsTable, userID)
+	if sealedErr != nil {
+		return nil, sealedErr
+	}
+	cards = append(cards, sealedCards...)
+	sortElements(cards, func(card models.Card) (time.Time, time.Time) {
+		return card.Changed.Get(), card.Created
+	})
 	return cards, nil
 }
 
 // GetCard returns credit card from database by current user and credit card ID
 func (d *PostgresDB) GetCard(cardID uuid.UUID, userID uuid.UUID) (models.Card, error) {
+	if d.sealer != nil {
+		card, found, sealedErr := getSealed[models.Card](d, cardsTable, userID, cardID)
+		if sealedErr != nil || found {
+			return card, sealedErr
+		}
+	}
+	return d.getCard(cardID, userID)
+}
+
+// getCard returns credit card saved in plaintext
+func (d *PostgresDB) getCard(cardID uuid.UUID, userID uuid.UUID) (models.Card, error) {
 	var card models.Card
 	resErr := d.database.Get(&card, `select id, title, card_number,
-card_owner, card_exp, notes, created, changed
-from public.cards where user_id = $1 and id = $2`,
+card_owner, card_exp, notes, version, signature, created, changed
+from public.cards where user_id = $1 and id = $2 and sealed is null`,
 		userID, cardID)
 	if resErr != nil {
 		return models.Card{}, resErr
@@ -48,8 +79,20 @@ from public.cards where user_id = $1 and id = $2`,
 	return card, nil
 }
 
-// EditCard changes information in database about credit card by current user and credit card ID
+// EditCard changes information in database about credit card by current user and credit card ID,
+// card is changed only if its current version precedes the new one
 func (d *PostgresDB) EditCard(card models.NewCard) (models.Card, error) {
+	if d.sealer != nil {
+		current, currentErr := d.GetCard(card.ID, card.UserID)
+		if currentErr != nil {
+			return models.Card{}, updateErr(currentErr)
+		}
+		newCard := models.Card{ID: card.ID, Title: card.Title, CardNumber: card.CardNumber, CardOwner: card.CardOwner, CardExp: card.CardExp, Notes: card.Notes, Version: card.Version, Signature: card.Signature, Created: current.Created, Changed: changedNow()}
+		if sealErr := d.updateSealed(d.database, cardsTable, card.UserID, card.ID, card.Version, card.Version-1, newCard); sealErr != nil {
+			return models.Card{}, sealErr
+		}
+		return newCard, nil
+	}
 	var newCard models.Card
 	resErr := d.database.Get(&newCard, `update public.cards 
 set title = $1,
@@ -57,15 +100,18 @@ set title = $1,
     card_owner = $3,
     card_exp = $4,
     notes = $5,
+    version = $6,
+    signature = $7,
     changed = now()
 where 1=1
-and user_id = $6
-and id = $7
+and user_id = $8
+and id = $9
+and version = $6 - 1
 returning id, title, card_number,
-card_owner, card_exp, notes, created, changed;`,
-		card.Title, card.CardNumber, card.CardOwner, card.CardExp, card.Notes, card.UserID, card.ID)
+card_owner, card_exp, notes, version, signature, created, changed;`,
+		card.Title, card.CardNumber, card.CardOwner, card.CardExp, card.Notes, card.Version, card.Signature, card.UserID, card.ID)
 	if resErr != nil {
-		return models.Card{}, resErr
+		return models.Card{}, updateErr(resErr)
 	}
 	return newCard, nil
 }
@@ -3,19 +3,28 @@ package storagepg
 import (
 	"AlexSarva/GophKeeper/models"
 	"AlexSarva/GophKeeper/storage"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// NewCred adds new credentials to database
+// NewCred adds new credentials to database, ID of credentials is generated by client
 func (d *PostgresDB) NewCred(cred *models.NewCred) (models.Cred, error) {
+	if d.sealer != nil {
+		newCred := models.Cred{ID: cred.ID, Title: cred.Title, Login: cred.Login, Passwd: cred.Passwd, Notes: cred.Notes, Version: cred.Version, Signature: cred.Signature, Created: time.Now()}
+		if insertErr := d.insertSealed(credsTable, cred.UserID, cred.ID, cred.Version, newCred); insertErr != nil {
+			return models.Cred{}, insertErr
+		}
+		return newCred, nil
+	}
 	var newCred models.Cred
-	resErr := d.database.Get(&newCred, `insert into public.creds (user_id, title, login, passwd, notes)
-values ($1, $2, $3, $4, $5)
-returning id, title, login, passwd, notes, created, changed;`,
-		cred.UserID, cred.Title, cred.Login, cred.Passwd, cred.Notes)
+	resErr := d.database.Get(&newCred, `insert into public.creds (id, user_id, title, login, passwd, notes, version, signature)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+on conflict (id) do nothing
+returning id, title, login, passwd, notes, version, signature, created, changed;`,
+		cred.ID, cred.UserID, cred.Title, cred.Login, cred.Passwd, cred.Notes, cred.Version, cred.Signature)
 	if resErr != nil {
-		return models.Cred{}, resErr
+		return models.Cred{}, insertErr(resErr)
 	}
 	return newCred, nil
 }
@@ -23,20 +32,42 @@ returning id, title, login, passwd, notes, created, changed;`,
 // AllCreds returns all credentials from database by current user
 func (d *PostgresDB) AllCreds(userID uuid.UUID) ([]models.Cred, error) {
 	var creds []models.Cred
-	resErr := d.database.Select(&creds, `select id, title, login, passwd, notes, created, changed
-from public.creds where user_id = $1 order by changed desc nulls last, created desc`,
+	resErr := d.database.Select(&creds, `select id, title, login, passwd, notes, version, signature, created, changed
+from public.creds where user_id = $1 and sealed is null order by changed desc nulls last, created desc`,
 		userID)
 	if resErr != nil {
 		return nil, resErr
 	}
+	if d.sealer == nil {
+		return creds, nil
+	}
+	sealedCreds, sealedErr := allSealed[models.Cred](d, credsTable, userID)
+	if sealedErr != nil {
+		return nil, sealedErr
+	}
+	creds = append(creds, sealedCreds...)
+	sortElements(creds, func(cred models.Cred) (time.Time, time.Time) {
+		return cred.Changed.Get(), cred.Created
+	})
 	return creds, nil
 }
 
 // GetCred returns credential from database by current user and credential ID
 func (d *PostgresDB) GetCred(credID, userID uuid.UUID) (models.Cred, error) {
+	if d.sealer != nil {
+		cred, found, sealedErr := getSealed[models.Cred](d, credsTable, userID, credID)
+		if sealedErr != nil || found {
+			return cred, sealedErr
+		}
+	}
+	return d.getCred(credID, userID)
+}
+
+// getCred returns credential saved in plaintext
+func (d *PostgresDB) getCred(credID, userID uuid.UUID) (models.Cred, error) {
 	var cred models.Cred
-	resErr := d.database.Get(&cred, `select id, title, login, passwd, notes, created, changed
-from public.creds where user_id = $1 and id = $2`,
+	resErr := d.database.Get(&cred, `select id, title, login, passwd, notes, version, signature, created, changed
+from public.creds where user_id = $1 and id = $2 and sealed is null`,
 		userID, credID)
 	if resErr != nil {
 		return models.Cred{}, resErr
@@ -44,22 +75,37 @@ from public.creds where user_id = $1 and id = $2`,
 	return cred, nil
 }
 
-// EditCred changes information in database about credential by current user and credential ID
+// EditCred changes information in database about credential by current user and credential ID,
+// credential is changed only if its current version precedes the new one
 func (d *PostgresDB) EditCred(cred models.NewCred) (models.Cred, error) {
+	if d.sealer != nil {
+		current, currentErr := d.GetCred(cred.ID, cred.UserID)
+		if currentErr != nil {
+			return models.Cred{}, updateErr(currentErr)
+		}
+		newCred := models.Cred{ID: cred.ID, Title: cred.Title, Login: cred.Login, Passwd: cred.Passwd, Notes: cred.Notes, Version: cred.Version, Signature: cred.Signature, Created: current.Created, Changed: changedNow()}
+		if sealErr := d.updateSealed(d.database, credsTable, cred.UserID, cred.ID, cred.Version, cred.Version-1, newCred); sealErr != nil {
+			return models.Cred{}, sealErr
+		}
+		return newCred, nil
+	}
 	var newCred models.Cred
 	resErr := d.database.Get(&newCred, `update public.creds
 set title = $1,
     login = $2,
     passwd = $3,
     notes = $4,
+    version = $5,
+    signature = $6,
     changed = now()
 where 1=1
-and user_id = $5
-and id = $6
-returning id, title, login, passwd, notes, created, changed;`,
-		cred.Title, cred.Login, cred.Passwd, cred.Notes, cred.UserID, cred.ID)
+and user_id = $7
+and id = $8
+and version = $5 - 1
+returning id, title, login, passwd, notes, version, signature, created, changed;`,
+		cred.Title, cred.Login, cred.Passwd, cred.Notes, cred.Version, cred.Signature, cred.UserID, cred.ID)
 	if resErr != nil {
-		return models.Cred{}, resErr
+		return models.Cred{}, updateErr(resErr)
 	}
 	return newCred, nil
 }
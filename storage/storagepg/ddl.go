@@ -42,4 +42,21 @@ create table if not exists public.cards (
   notes text,
   created timestamp default now(),
 changed timestamp
-);`
+);
+
+alter table public.creds add column if not exists version integer not null default 1;
+alter table public.creds add column if not exists signature text not null default '';
+alter table public.notes add column if not exists version integer not null default 1;
+alter table public.notes add column if not exists signature text not null default '';
+alter table public.files add column if not exists version integer not null default 1;
+alter table public.files add column if not exists signature text not null default '';
+alter table public.cards add column if not exists version integer not null default 1;
+alter table public.cards add column if not exists signature text not null default '';
+alter table public.creds add column if not exists sealed bytea;
+alter table public.creds add column if not exists key_id text;
+alter table public.notes add column if not exists sealed bytea;
+alter table public.notes add column if not exists key_id text;
+alter table public.files add column if not exists sealed bytea;
+alter table public.files add column if not exists key_id text;
+alter table public.cards add column if not exists sealed bytea;
+alter table public.cards add column if not exists key_id text;`
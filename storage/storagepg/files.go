@@ -4,19 +4,28 @@ import (
 	"AlexSarva/GophKeeper/models"
 	"AlexSarva/GophKeeper/storage"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// NewFile adds new file to database
+// NewFile adds new file to database, ID of file is generated by client
 func (d *PostgresDB) NewFile(file *models.NewFile) (models.File, error) {
+	if d.sealer != nil {
+		newFile := models.File{ID: file.ID, Title: file.Title, FileName: file.FileName, File: file.File, Notes: file.Notes, Version: file.Version, Signature: file.Signature, Created: time.Now()}
+		if insertErr := d.insertSealed(filesTable, file.UserID, file.ID, file.Version, newFile); insertErr != nil {
+			return models.File{}, insertErr
+		}
+		return newFile, nil
+	}
 	var newFile models.File
-	resErr := d.database.Get(&newFile, `insert into public.files (user_id, title, file_name, file, notes)
-values ($1, $2, $3, $4, $5)
-returning id, title, file, file_name, notes, created, changed;`,
-		file.UserID, file.Title, file.FileName, file.File, file.Notes)
+	resErr := d.database.Get(&newFile, `insert into public.files (id, user_id, title, file_name, file, notes, version, signature)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+on conflict (id) do nothing
+returning id, title, file, file_name, notes, version, signature, created, changed;`,
+		file.ID, file.UserID, file.Title, file.FileName, file.File, file.Notes, file.Version, file.Signature)
 	if resErr != nil {
-		return models.File{}, resErr
+		return models.File{}, insertErr(resErr)
 	}
 	return newFile, nil
 }
@@ -24,20 +33,42 @@ returning id, title, file, file_name, notes, created, changed;`,
 // AllFiles returns all files from database by current user
 func (d *PostgresDB) AllFiles(userID uuid.UUID) ([]models.File, error) {
 	var files []models.File
-	resErr := d.database.Select(&files, `select id, title, file_name, file, notes, created, changed
-from public.files where user_id = $1 order by changed desc nulls last, created desc`,
+	resErr := d.database.Select(&files, `select id, title, file_name, file, notes, version, signature, created, changed
+from public.files where user_id = $1 and sealed is null order by changed desc nulls last, created desc`,
 		userID)
 	if resErr != nil {
 		return nil, resErr
 	}
+	if d.sealer == nil {
+		return files, nil
+	}
+	sealedFiles, sealedErr := allSealed[models.File](d, filesTable, userID)
+	if sealedErr != nil {
+		return nil, sealedErr
+	}
+	files = append(files, sealedFiles...)
+	sortElements(files, func(file models.File) (time.Time, time.Time) {
+		return file.Changed.Get(), file.Created
+	})
 	return files, nil
 }
 
 // GetFile returns file from database by current user and file ID
 func (d *PostgresDB) GetFile(cardID uuid.UUID, userID uuid.UUID) (models.File, error) {
+	if d.sealer != nil {
+		file, found, sealedErr := getSealed[models.File](d, filesTable, userID, cardID)
+		if sealedErr != nil || found {
+			return file, sealedErr
+		}
+	}
+	return d.getFile(cardID, userID)
+}
+
+// getFile returns file saved in plaintext
+func (d *PostgresDB) getFile(cardID uuid.UUID, userID uuid.UUID) (models.File, error) {
 	var file models.File
-	resErr := d.database.Get(&file, `select id, title, file_name, file, notes, created, changed
-from public.files where user_id = $1 and id = $2`,
+	resErr := d.database.Get(&file, `select id, title, file_name, file, notes, version, signature, created, changed
+from public.files where user_id = $1 and id = $2 and sealed is null`,
 		userID, cardID)
 	if resErr != nil {
 		return models.File{}, resErr
@@ -45,8 +76,20 @@ from public.files where user_id = $1 and id = $2`,
 	return file, nil
 }
 
-// EditFile changes information in database about file by current user and file ID
+// EditFile changes information in database about file by current user and file ID,
+// file is changed only if its current version precedes the new one
 func (d *PostgresDB) EditFile(file *models.NewFile) (models.File, error) {
+	if d.sealer != nil {
+		current, currentErr := d.GetFile(file.ID, file.UserID)
+		if currentErr != nil {
+			return models.File{}, updateErr(currentErr)
+		}
+		newFile := models.File{ID: file.ID, Title: file.Title, FileName: file.FileName, File: file.File, Notes: file.Notes, Version: file.Version, Signature: file.Signature, Created: current.Created, Changed: changedNow()}
+		if sealErr := d.updateSealed(d.database, filesTable, file.UserID, file.ID, file.Version, file.Version-1, newFile); sealErr != nil {
+			return models.File{}, sealErr
+		}
+		return newFile, nil
+	}
 	var newFile models.File
 	log.Printf("%+v\n", file)
 	resErr := d.database.Get(&newFile, `update public.files 
@@ -54,14 +97,17 @@ set title = $1,
     file = $2,
     file_name = $3,
     notes = $4,
+    version = $5,
+    signature = $6,
     changed = now()
 where 1=1
-and user_id = $5
-and id = $6
-returning id, title, file_name, file, notes, created, changed;`,
-		file.Title, file.File, file.FileName, file.Notes, file.UserID, file.ID)
+and user_id = $7
+and id = $8
+and version = $5 - 1
+returning id, title, file_name, file, notes, version, signature, created, changed;`,
+		file.Title, file.File, file.FileName, file.Notes, file.Version, file.Signature, file.UserID, file.ID)
 	if resErr != nil {
-		return models.File{}, resErr
+		return models.File{}, updateErr(resErr)
 	}
 	return newFile, nil
 }
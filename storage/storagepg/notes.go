@@ -3,19 +3,28 @@ package storagepg
 import (
 	"AlexSarva/GophKeeper/models"
 	"AlexSarva/GophKeeper/storage"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// NewNote adds new note to database
+// NewNote adds new note to database, ID of note is generated by client
 func (d *PostgresDB) NewNote(note *models.NewNote) (models.Note, error) {
+	if d.sealer != nil {
+		newNote := models.Note{ID: note.ID, Title: note.Title, Note: note.Note, Version: note.Version, Signature: note.Signature, Created: time.Now()}
+		if insertErr := d.insertSealed(notesTable, note.UserID, note.ID, note.Version, newNote); insertErr != nil {
+			return models.Note{}, insertErr
+		}
+		return newNote, nil
+	}
 	var newNote models.Note
-	resErr := d.database.Get(&newNote, `insert into public.notes (user_id, title, note)
-values ($1, $2, $3)
-returning id, title, note, created, changed;`,
-		note.UserID, note.Title, note.Note)
+	resErr := d.database.Get(&newNote, `insert into public.notes (id, user_id, title, note, version, signature)
+values ($1, $2, $3, $4, $5, $6)
+on conflict (id) do nothing
+returning id, title, note, version, signature, created, changed;`,
+		note.ID, note.UserID, note.Title, note.Note, note.Version, note.Signature)
 	if resErr != nil {
-		return models.Note{}, resErr
+		return models.Note{}, insertErr(resErr)
 	}
 	return newNote, nil
 }
@@ -23,20 +32,42 @@ returning id, title, note, created, changed;`,
 // AllNotes returns all notes from database by current user
 func (d *PostgresDB) AllNotes(userID uuid.UUID) ([]models.Note, error) {
 	var notes []models.Note
-	resErr := d.database.Select(&notes, `select id, title, note, created, changed
-from public.notes where user_id = $1 order by changed desc nulls last, created desc`,
+	resErr := d.database.Select(&notes, `select id, title, note, version, signature, created, changed
+from public.notes where user_id = $1 and sealed is null order by changed desc nulls last, created desc`,
 		userID)
 	if resErr != nil {
 		return nil, resErr
 	}
+	if d.sealer == nil {
+		return notes, nil
+	}
+	sealedNotes, sealedErr := allSealed[models.Note](d, notesTable, userID)
+	if sealedErr != nil {
+		return nil, sealedErr
+	}
+	notes = append(notes, sealedNotes...)
+	sortElements(notes, func(note models.Note) (time.Time, time.Time) {
+		return note.Changed.Get(), note.Created
+	})
 	return notes, nil
 }
 
 // GetNote returns note from database by current user and note ID
 func (d *PostgresDB) GetNote(noteID uuid.UUID, userID uuid.UUID) (models.Note, error) {
+	if d.sealer != nil {
+		note, found, sealedErr := getSealed[models.Note](d, notesTable, userID, noteID)
+		if sealedErr != nil || found {
+			return note, sealedErr
+		}
+	}
+	return d.getNote(noteID, userID)
+}
+
+// getNote returns note saved in plaintext
+func (d *PostgresDB) getNote(noteID uuid.UUID, userID uuid.UUID) (models.Note, error) {
 	var note models.Note
-	resErr := d.database.Get(&note, `select id, title, note, created, changed
-from public.notes where user_id = $1 and id = $2`,
+	resErr := d.database.Get(&note, `select id, title, note, version, signature, created, changed
+from public.notes where user_id = $1 and id = $2 and sealed is null`,
 		userID, noteID)
 	if resErr != nil {
 		return models.Note{}, resErr
@@ -44,20 +75,35 @@ from public.notes where user_id = $1 and id = $2`,
 	return note, nil
 }
 
-// EditNote changes information in database about note by current user and note ID
+// EditNote changes information in database about note by current user and note ID,
+// note is changed only if its current version precedes the new one
 func (d *PostgresDB) EditNote(note models.NewNote) (models.Note, error) {
+	if d.sealer != nil {
+		current, currentErr := d.GetNote(note.ID, note.UserID)
+		if currentErr != nil {
+			return models.Note{}, updateErr(currentErr)
+		}
+		newNote := models.Note{ID: note.ID, Title: note.Title, Note: note.Note, Version: note.Version, Signature: note.Signature, Created: current.Created, Changed: changedNow()}
+		if sealErr := d.updateSealed(d.database, notesTable, note.UserID, note.ID, note.Version, note.Version-1, newNote); sealErr != nil {
+			return models.Note{}, sealErr
+		}
+		return newNote, nil
+	}
 	var newNote models.Note
 	resErr := d.database.Get(&newNote, `update public.notes 
 set title = $1,
     note = $2,
+    version = $3,
+    signature = $4,
     changed = now()
 where 1=1
-and user_id = $3
-and id = $4
-returning id, title, note, created, changed;`,
-		note.Title, note.Note, note.UserID, note.ID)
+and user_id = $5
+and id = $6
+and version = $3 - 1
+returning id, title, note, version, signature, created, changed;`,
+		note.Title, note.Note, note.Version, note.Signature, note.UserID, note.ID)
 	if resErr != nil {
-		return models.Note{}, resErr
+		return models.Note{}, updateErr(resErr)
 	}
 	return newNote, nil
 }
@@ -0,0 +1,307 @@
+package storagepg
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"AlexSarva/GophKeeper/storage/atrest"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// sealedTable table which rows could be sealed, plaintext columns are cleared in sealed rows.
+// Only id, user_id and version stay in plaintext, they are required by queries
+type sealedTable struct {
+	name    string
+	columns []string
+}
+
+var (
+	notesTable = sealedTable{name: "notes", columns: []string{"title", "note", "signature"}}
+	cardsTable = sealedTable{name: "cards", columns: []string{"title", "card_number", "card_owner", "card_exp", "notes", "signature"}}
+	credsTable = sealedTable{name: "creds", columns: []string{"title", "login", "passwd", "notes", "signature"}}
+	filesTable = sealedTable{name: "files", columns: []string{"title", "file_name", "file", "notes", "signature"}}
+)
+
+// sealedRow plaintext columns of sealed row
+type sealedRow struct {
+	ID      uuid.UUID `db:"id"`
+	UserID  uuid.UUID `db:"user_id"`
+	Version int       `db:"version"`
+	Sealed  []byte    `db:"sealed"`
+	KeyID   string    `db:"key_id"`
+}
+
+// aad binds sealed row to its table, id, owner and version
+func (t sealedTable) aad(userID, id uuid.UUID, version int) []byte {
+	return atrest.AAD(t.name, id, userID, version)
+}
+
+// blank returns assignments that clear plaintext columns
+func (t sealedTable) blank() string {
+	assignments := make([]string, 0, len(t.columns))
+	for _, column := range t.columns {
+		assignments = append(assignments, column+" = ''")
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// seal encrypts whole element, returns envelope and id of at-rest key
+func (d *PostgresDB) seal(table sealedTable, userID, id uuid.UUID, version int, elem interface{}) ([]byte, string, error) {
+	plain, marshalErr := json.Marshal(elem)
+	if marshalErr != nil {
+		return nil, "", marshalErr
+	}
+	return d.sealer.Seal(plain, table.aad(userID, id, version))
+}
+
+// open decrypts sealed row in elem
+func (d *PostgresDB) open(table sealedTable, row sealedRow, elem interface{}) error {
+	plain, openErr := d.sealer.Open(row.Sealed, table.aad(row.UserID, row.ID, row.Version))
+	if openErr != nil {
+		return openErr
+	}
+	return json.Unmarshal(plain, elem)
+}
+
+// insertSealed adds sealed element, timestamps are kept only in envelope
+func (d *PostgresDB) insertSealed(table sealedTable, userID, id uuid.UUID, version int, elem interface{}) error {
+	sealed, keyID, sealErr := d.seal(table, userID, id, version, elem)
+	if sealErr != nil {
+		return sealErr
+	}
+	blankValues := strings.TrimSuffix(strings.Repeat("'', ", len(table.columns)), ", ")
+	res, resErr := d.database.Exec(fmt.Sprintf(`insert into public.%s (id, user_id, version, sealed, key_id, %s, created)
+values ($1, $2, $3, $4, $5, %s, null)
+on conflict (id) do nothing`, table.name, strings.Join(table.columns, ", "), blankValues),
+		id, userID, version, sealed, keyID)
+	if checkErr := checkAffected(res, resErr); checkErr != nil {
+		if errors.Is(checkErr, storage.ErrNoValues) {
+			return storage.ErrDuplicatePK
+		}
+		return checkErr
+	}
+	return nil
+}
+
+// updateSealed replaces row by sealed element if row has expected version,
+// plaintext values of legacy row are cleared
+func (d *PostgresDB) updateSealed(exec sqlx.Execer, table sealedTable, userID, id uuid.UUID, version, expected int, elem interface{}) error {
+	sealed, keyID, sealErr := d.seal(table, userID, id, version, elem)
+	if sealErr != nil {
+		return sealErr
+	}
+	res, resErr := exec.Exec(fmt.Sprintf(`update public.%s
+set version = $3,
+    sealed = $4,
+    key_id = $5,
+    %s,
+    created = null,
+    changed = null
+where user_id = $1
+and id = $2
+and version = $6`, table.name, table.blank()),
+		userID, id, version, sealed, keyID, expected)
+	return checkAffected(res, resErr)
+}
+
+// selectSealed returns sealed rows of user, only row with id if it is set
+func (d *PostgresDB) selectSealed(table sealedTable, userID uuid.UUID, id *uuid.UUID) ([]sealedRow, error) {
+	var rows []sealedRow
+	query := fmt.Sprintf(`select id, user_id, version, sealed, key_id
+from public.%s where sealed is not null and user_id = $1`, table.name)
+	if id == nil {
+		return rows, d.database.Select(&rows, query, userID)
+	}
+	return rows, d.database.Select(&rows, query+" and id = $2", userID, *id)
+}
+
+// openRows decrypts sealed rows
+func openRows[T any](d *PostgresDB, table sealedTable, rows []sealedRow) ([]T, error) {
+	elems := make([]T, 0, len(rows))
+	for _, row := range rows {
+		var elem T
+		if openErr := d.open(table, row, &elem); openErr != nil {
+			return nil, openErr
+		}
+		elems = append(elems, elem)
+	}
+	return elems, nil
+}
+
+// allSealed returns decrypted elements of user
+func allSealed[T any](d *PostgresDB, table sealedTable, userID uuid.UUID) ([]T, error) {
+	rows, rowsErr := d.selectSealed(table, userID, nil)
+	if rowsErr != nil {
+		return nil, rowsErr
+	}
+	return openRows[T](d, table, rows)
+}
+
+// getSealed returns decrypted element, found is false if there is no sealed row with such id
+func getSealed[T any](d *PostgresDB, table sealedTable, userID, id uuid.UUID) (elem T, found bool, err error) {
+	rows, rowsErr := d.selectSealed(table, userID, &id)
+	if rowsErr != nil || len(rows) == 0 {
+		return elem, false, rowsErr
+	}
+	elems, openErr := openRows[T](d, table, rows)
+	if openErr != nil {
+		return elem, false, openErr
+	}
+	return elems[0], true, nil
+}
+
+// sortElements orders elements like queries do: by change date (unchanged last), then by creation date
+func sortElements[T any](elems []T, dates func(T) (time.Time, time.Time)) {
+	sort.SliceStable(elems, func(i, j int) bool {
+		changedI, createdI := dates(elems[i])
+		changedJ, createdJ := dates(elems[j])
+		if !changedI.Equal(changedJ) {
+			if changedI.IsZero() || changedJ.IsZero() {
+				return changedJ.IsZero()
+			}
+			return changedI.After(changedJ)
+		}
+		return createdI.After(createdJ)
+	})
+}
+
+// changedNow returns change time of edited element
+func changedNow() *models.NullTime {
+	return &models.NullTime{Time: time.Now(), Valid: true}
+}
+
+// replaceSealedVault replaces elements of vault by sealed ones in transaction, dates of elements are kept
+func (d *PostgresDB) replaceSealedVault(tx *sqlx.Tx, userID uuid.UUID, vault *models.Vault) error {
+	for _, note := range vault.Notes {
+		current, currentErr := d.GetNote(note.ID, userID)
+		if currentErr != nil {
+			return updateErr(currentErr)
+		}
+		note.Created, note.Changed = current.Created, current.Changed
+		if sealErr := d.updateSealed(tx, notesTable, userID, note.ID, note.Version, note.Version-1, note); sealErr != nil {
+			return sealErr
+		}
+	}
+
+	for _, card := range vault.Cards {
+		current, currentErr := d.GetCard(card.ID, userID)
+		if currentErr != nil {
+			return updateErr(currentErr)
+		}
+		card.Created, card.Changed = current.Created, current.Changed
+		if sealErr := d.updateSealed(tx, cardsTable, userID, card.ID, card.Version, card.Version-1, card); sealErr != nil {
+			return sealErr
+		}
+	}
+
+	for _, cred := range vault.Creds {
+		current, currentErr := d.GetCred(cred.ID, userID)
+		if currentErr != nil {
+			return updateErr(currentErr)
+		}
+		cred.Created, cred.Changed = current.Created, current.Changed
+		if sealErr := d.updateSealed(tx, credsTable, userID, cred.ID, cred.Version, cred.Version-1, cred); sealErr != nil {
+			return sealErr
+		}
+	}
+
+	for _, file := range vault.Files {
+		current, currentErr := d.GetFile(file.ID, userID)
+		if currentErr != nil {
+			return updateErr(currentErr)
+		}
+		file.Created, file.Changed = current.Created, current.Changed
+		if sealErr := d.updateSealed(tx, filesTable, userID, file.ID, file.Version, file.Version-1, file); sealErr != nil {
+			return sealErr
+		}
+	}
+	return nil
+}
+
+// Rewrap wraps data keys of sealed rows by current at-rest key
+// and seals rows that were saved before at-rest encryption was enabled
+func (d *PostgresDB) Rewrap(batch int) (int, error) {
+	var total int
+	for _, table := range []sealedTable{notesTable, cardsTable, credsTable, filesTable} {
+		if total >= batch {
+			break
+		}
+		changed, rewrapErr := d.rewrapTable(table, batch-total)
+		total += changed
+		if rewrapErr != nil {
+			return total, rewrapErr
+		}
+		changed, sealErr := d.sealLegacy(table, batch-total)
+		total += changed
+		if sealErr != nil {
+			return total, sealErr
+		}
+	}
+	return total, nil
+}
+
+// rewrapTable re-wraps rows with old at-rest keys, rows changed meanwhile are skipped
+func (d *PostgresDB) rewrapTable(table sealedTable, batch int) (int, error) {
+	var rows []sealedRow
+	selectErr := d.database.Select(&rows, fmt.Sprintf(`select id, user_id, version, sealed, key_id
+from public.%s where sealed is not null and key_id <> $1 limit $2`, table.name),
+		d.sealer.Provider().CurrentKeyID(), batch)
+	if selectErr != nil {
+		return 0, selectErr
+	}
+	var changed int
+	for _, row := range rows {
+		sealed, keyID, rewrapErr := d.sealer.Rewrap(row.Sealed)
+		if rewrapErr != nil {
+			return changed, rewrapErr
+		}
+		res, resErr := d.database.Exec(fmt.Sprintf(`update public.%s set sealed = $1, key_id = $2
+where id = $3 and key_id = $4`, table.name), sealed, keyID, row.ID, row.KeyID)
+		if checkErr := checkAffected(res, resErr); checkErr != nil && !errors.Is(checkErr, storage.ErrNoValues) {
+			return changed, checkErr
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// sealLegacy seals rows saved in plaintext, version of rows isn't changed
+func (d *PostgresDB) sealLegacy(table sealedTable, batch int) (int, error) {
+	var rows []sealedRow
+	selectErr := d.database.Select(&rows, fmt.Sprintf(`select id, user_id, version
+from public.%s where sealed is null limit $1`, table.name), batch)
+	if selectErr != nil {
+		return 0, selectErr
+	}
+	var changed int
+	for _, row := range rows {
+		var elem interface{}
+		var getErr error
+		switch table.name {
+		case notesTable.name:
+			elem, getErr = d.getNote(row.ID, row.UserID)
+		case cardsTable.name:
+			elem, getErr = d.getCard(row.ID, row.UserID)
+		case credsTable.name:
+			elem, getErr = d.getCred(row.ID, row.UserID)
+		case filesTable.name:
+			elem, getErr = d.getFile(row.ID, row.UserID)
+		}
+		if getErr != nil {
+			return changed, getErr
+		}
+		updateErr := d.updateSealed(d.database, table, row.UserID, row.ID, row.Version, row.Version, elem)
+		if updateErr != nil && !errors.Is(updateErr, storage.ErrNoValues) {
+			return changed, updateErr
+		}
+		changed++
+	}
+	return changed, nil
+}
@@ -1,19 +1,23 @@
 package storagepg
 
 import (
+	"AlexSarva/GophKeeper/storage/atrest"
 	"log"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
-// PostgresDB represents PostgreSQL connection
+// PostgresDB represents PostgreSQL connection.
+// If sealer is set, rows are saved with server-side at-rest encryption
 type PostgresDB struct {
 	database *sqlx.DB
+	sealer   *atrest.Sealer
 }
 
-// PostgresDBConn init PostgreSQL connection by config information
-func PostgresDBConn(config string) *PostgresDB {
+// PostgresDBConn init PostgreSQL connection by config information,
+// sealer is optional, once rows are sealed they cant be read without it
+func PostgresDBConn(config string, sealer *atrest.Sealer) *PostgresDB {
 	db, err := sqlx.Connect("postgres", config)
 	db.MustExec(ddl)
 	if err != nil {
@@ -21,6 +25,7 @@ func PostgresDBConn(config string) *PostgresDB {
 	}
 	return &PostgresDB{
 		database: db,
+		sealer:   sealer,
 	}
 }
 
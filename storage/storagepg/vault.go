@@ -0,0 +1,192 @@
+package storagepg
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/storage"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ReplaceVault replaces encrypted values and signatures of all user elements in one transaction,
+// every element should have the next version. If vault doesnt contain exactly all elements of user,
+// some element doesnt exist or was changed meanwhile nothing will be changed
+func (d *PostgresDB) ReplaceVault(userID uuid.UUID, vault *models.Vault) error {
+	tx, txErr := d.database.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	defer func(tx *sqlx.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Println(err)
+		}
+	}(tx)
+
+	if checkErr := checkVaultIDs(tx, userID, vault); checkErr != nil {
+		return checkErr
+	}
+
+	if d.sealer != nil {
+		if replaceErr := d.replaceSealedVault(tx, userID, vault); replaceErr != nil {
+			return replaceErr
+		}
+		return tx.Commit()
+	}
+
+	for _, note := range vault.Notes {
+		res, resErr := tx.Exec(`update public.notes
+set title = $1,
+    note = $2,
+    version = $3,
+    signature = $4
+where user_id = $5
+and id = $6
+and version = $3 - 1`,
+			note.Title, note.Note, note.Version, note.Signature, userID, note.ID)
+		if checkErr := checkAffected(res, resErr); checkErr != nil {
+			return checkErr
+		}
+	}
+
+	for _, card := range vault.Cards {
+		res, resErr := tx.Exec(`update public.cards
+set title = $1,
+    card_number = $2,
+    card_owner = $3,
+    card_exp = $4,
+    notes = $5,
+    version = $6,
+    signature = $7
+where user_id = $8
+and id = $9
+and version = $6 - 1`,
+			card.Title, card.CardNumber, card.CardOwner, card.CardExp, card.Notes, card.Version, card.Signature, userID, card.ID)
+		if checkErr := checkAffected(res, resErr); checkErr != nil {
+			return checkErr
+		}
+	}
+
+	for _, cred := range vault.Creds {
+		res, resErr := tx.Exec(`update public.creds
+set title = $1,
+    login = $2,
+    passwd = $3,
+    notes = $4,
+    version = $5,
+    signature = $6
+where user_id = $7
+and id = $8
+and version = $5 - 1`,
+			cred.Title, cred.Login, cred.Passwd, cred.Notes, cred.Version, cred.Signature, userID, cred.ID)
+		if checkErr := checkAffected(res, resErr); checkErr != nil {
+			return checkErr
+		}
+	}
+
+	for _, file := range vault.Files {
+		res, resErr := tx.Exec(`update public.files
+set title = $1,
+    file_name = $2,
+    file = $3,
+    notes = $4,
+    version = $5,
+    signature = $6
+where user_id = $7
+and id = $8
+and version = $5 - 1`,
+			file.Title, file.FileName, file.File, file.Notes, file.Version, file.Signature, userID, file.ID)
+		if checkErr := checkAffected(res, resErr); checkErr != nil {
+			return checkErr
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkVaultIDs returns ErrVaultMismatch if ids of vault elements differ from ids of user elements,
+// rows of user elements are locked till the end of transaction, so elements cant be added meanwhile
+func checkVaultIDs(tx *sqlx.Tx, userID uuid.UUID, vault *models.Vault) error {
+	submitted := map[string][]uuid.UUID{
+		"notes": make([]uuid.UUID, 0, len(vault.Notes)),
+		"cards": make([]uuid.UUID, 0, len(vault.Cards)),
+		"creds": make([]uuid.UUID, 0, len(vault.Creds)),
+		"files": make([]uuid.UUID, 0, len(vault.Files)),
+	}
+	for _, note := range vault.Notes {
+		submitted["notes"] = append(submitted["notes"], note.ID)
+	}
+	for _, card := range vault.Cards {
+		submitted["cards"] = append(submitted["cards"], card.ID)
+	}
+	for _, cred := range vault.Creds {
+		submitted["creds"] = append(submitted["creds"], cred.ID)
+	}
+	for _, file := range vault.Files {
+		submitted["files"] = append(submitted["files"], file.ID)
+	}
+
+	for table, ids := range submitted {
+		var current []uuid.UUID
+		// table is one of known names, not user input
+		if selectErr := tx.Select(&current, `select id from public.`+table+` where user_id = $1 for update`, userID); selectErr != nil {
+			return selectErr
+		}
+		if !sameIDs(current, ids) {
+			return storage.ErrVaultMismatch
+		}
+	}
+	return nil
+}
+
+// sameIDs checks that both lists contain the same ids exactly once
+func sameIDs(current, submitted []uuid.UUID) bool {
+	if len(current) != len(submitted) {
+		return false
+	}
+	set := make(map[uuid.UUID]bool, len(current))
+	for _, id := range current {
+		set[id] = true
+	}
+	for _, id := range submitted {
+		if !set[id] {
+			return false
+		}
+		delete(set, id)
+	}
+	return true
+}
+
+// insertErr returns ErrDuplicatePK if element with such ID already exists
+func insertErr(resErr error) error {
+	if errors.Is(resErr, sql.ErrNoRows) {
+		return storage.ErrDuplicatePK
+	}
+	return resErr
+}
+
+// updateErr returns ErrNoValues if element doesnt exist or it has another version
+func updateErr(resErr error) error {
+	if errors.Is(resErr, sql.ErrNoRows) {
+		return storage.ErrNoValues
+	}
+	return resErr
+}
+
+// checkAffected returns ErrNoValues if query didnt change any row
+func checkAffected(res sql.Result, resErr error) error {
+	if resErr != nil {
+		return resErr
+	}
+	affectedRows, affectedRowsErr := res.RowsAffected()
+	if affectedRowsErr != nil {
+		return affectedRowsErr
+	}
+	if affectedRows == 0 {
+		return storage.ErrNoValues
+	}
+	return nil
+}
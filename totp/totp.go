@@ -0,0 +1,112 @@
+// Package totp implements time-based one-time passwords of RFC 6238 used for two-factor authentication.
+// Codes have 6 digits, are derived by HMAC-SHA1 and change every 30 seconds like in authenticator apps
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Period lifetime of one code
+	Period = 30 * time.Second
+	// Digits number of digits in code
+	Digits = 6
+	// secretSize size of secret in bytes, RFC 4226 recommends 160 bits
+	secretSize = 20
+	// skew number of periods before and after current one whose codes are accepted,
+	// it allows clock drift and delay of typing
+	skew = 1
+)
+
+// ErrSecret secret is not valid base32 string
+var ErrSecret = errors.New("invalid totp secret")
+
+// encoding base32 without padding that authenticator apps use
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns new random secret encoded in base32
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, randErr := rand.Read(secret); randErr != nil {
+		return "", randErr
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// Code returns code of secret at time
+func Code(secret string, t time.Time) (string, error) {
+	key, keyErr := decodeSecret(secret)
+	if keyErr != nil {
+		return "", keyErr
+	}
+	return hotp(key, step(t), Digits), nil
+}
+
+// Validate checks code at time, returns time step of matched code.
+// Caller should reject steps that are not after the last used one, so code can't be replayed
+func Validate(secret, code string, t time.Time) (int64, bool) {
+	key, keyErr := decodeSecret(secret)
+	if keyErr != nil || len(code) != Digits {
+		return 0, false
+	}
+	current := step(t)
+	for counter := current - skew; counter <= current+skew; counter++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, counter, Digits)), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// URI returns otpauth URI of secret, authenticator apps add account by it or by its QR code
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period.Seconds())))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// step returns number of period of time
+func step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// decodeSecret decodes base32 secret, spaces and lower case that users type are allowed
+func decodeSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, decodeErr := encoding.DecodeString(strings.TrimRight(normalized, "="))
+	if decodeErr != nil || len(key) == 0 {
+		return nil, ErrSecret
+	}
+	return key, nil
+}
+
+// hotp returns HMAC-based one-time password of RFC 4226 for counter
+func hotp(key []byte, counter int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRFC6238 checks test vectors of SHA1 from appendix B of RFC 6238
+func TestRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "94287082"},
+		{unix: 1111111109, code: "07081804"},
+		{unix: 1111111111, code: "14050471"},
+		{unix: 1234567890, code: "89005924"},
+		{unix: 2000000000, code: "69279037"},
+		{unix: 20000000000, code: "65353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.code, hotp(key, step(time.Unix(tt.unix, 0)), 8))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, secretErr := GenerateSecret()
+	require.NoError(t, secretErr)
+	now := time.Unix(1669888800, 0)
+	code, codeErr := Code(secret, now)
+	require.NoError(t, codeErr)
+
+	tests := []struct {
+		name string
+		code string
+		at   time.Time
+		ok   bool
+	}{
+		{name: "current period", code: code, at: now, ok: true},
+		{name: "previous period", code: code, at: now.Add(Period), ok: true},
+		{name: "next period", code: code, at: now.Add(-Period), ok: true},
+		{name: "expired code", code: code, at: now.Add(2 * Period)},
+		{name: "wrong code", code: "000000", at: now},
+		{name: "short code", code: code[:5], at: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, ok := Validate(secret, tt.code, tt.at)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, step(now), matched)
+			}
+		})
+	}
+
+	_, ok := Validate("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri, parseErr := url.Parse(URI("GophKeeper", "user@example.com", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, parseErr)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/GophKeeper:user@example.com", uri.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	assert.Equal(t, "GophKeeper", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+}
@@ -0,0 +1,42 @@
+// Package qrtext renders QR codes as text for terminals
+package qrtext
+
+import (
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// quiet size of quiet zone around code in modules
+const quiet = 2
+
+// Render returns QR code of text drawn with half blocks, light modules are filled,
+// so code is readable in dark terminals
+func Render(text string) (string, error) {
+	code, codeErr := qr.Encode(text, qr.L)
+	if codeErr != nil {
+		return "", codeErr
+	}
+	light := func(x, y int) bool {
+		return !code.Black(x-quiet, y-quiet)
+	}
+	size := code.Size + 2*quiet
+	var sb strings.Builder
+	for y := 0; y < size; y += 2 {
+		for x := 0; x < size; x++ {
+			top, bottom := light(x, y), y+1 < size && light(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
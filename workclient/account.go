@@ -0,0 +1,28 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"errors"
+)
+
+var (
+	// ErrPassword current password of user is wrong
+	ErrPassword = errors.New("password is wrong")
+	// ErrReauth password or code of second factor is wrong
+	ErrReauth = errors.New("password or two-factor code is wrong")
+)
+
+// passwordErrors wrong credentials of logged in user mean wrong password, not wrong login
+var passwordErrors = map[string]error{problem.CodeInvalidCredentials: ErrPassword}
+
+// ChangePassword changes password of user, other devices of user have to log in again
+func (c *Client) ChangePassword(password, newPassword string) error {
+	return c.transport.changePassword(&models.PasswordChange{Password: password, NewPassword: newPassword})
+}
+
+// DeleteAccount deletes user with all elements in service, code of second factor is required
+// if two-factor authentication is enabled. Client is logged out after deletion
+func (c *Client) DeleteAccount(password, code string) error {
+	return c.transport.deleteAccount(&models.AccountDeletion{Password: password, Code: code})
+}
@@ -0,0 +1,43 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/agent"
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/models"
+)
+
+// initAgentClient initialize client which keys and secret are kept by keys agent,
+// keys folder of agent is used for local index if it isn't set in config
+func initAgentClient(cfg *models.GUIConfig) (*Client, error) {
+	agentClient, dialErr := agent.Dial(cfg.Agent)
+	if dialErr != nil {
+		return nil, dialErr
+	}
+	info, infoErr := agentClient.Info()
+	if infoErr != nil {
+		return nil, infoErr
+	}
+	cryptorizer, cryptorizerErr := crypto.InitRemoteCryptorizer(info.Algorithm, agentClient.Crypto)
+	if cryptorizerErr != nil {
+		return nil, cryptorizerErr
+	}
+	keysPath := cfg.KeysPath
+	if keysPath == "" {
+		keysPath = info.KeysPath
+	}
+	rest, trans, transErr := newTransport(cfg)
+	if transErr != nil {
+		return nil, transErr
+	}
+	symCrypto := agentClient.SymCrypto()
+	return &Client{
+		rest:        rest,
+		transport:   newSessionTransport(rest, trans),
+		cryptorizer: cryptorizer,
+		symCrypto:   symCrypto,
+		keysPath:    keysPath,
+		keysSize:    cfg.KeysSize,
+		algorithm:   info.Algorithm,
+		index:       loadIndex(keysPath, symCrypto),
+	}, nil
+}
@@ -0,0 +1,35 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"errors"
+)
+
+var (
+	// ErrEmailToken code from message is invalid, expired or already used
+	ErrEmailToken = errors.New("code is invalid, expired or already used")
+	// ErrEmailVerified email of user is already confirmed
+	ErrEmailVerified = errors.New("email is already confirmed")
+	// ErrMailCooldown message with code is sent recently
+	ErrMailCooldown = errors.New("message is sent recently, check email or try again later")
+)
+
+// VerifyEmail confirms email of user by code from message, user doesn't have to be logged in
+func (c *Client) VerifyEmail(token string) error {
+	return c.transport.verifyEmail(&models.EmailVerification{Token: token})
+}
+
+// ResendVerification sends new code that confirms email of user
+func (c *Client) ResendVerification() error {
+	return c.transport.resendVerification()
+}
+
+// ForgotPassword asks service to send code of password reset to email
+func (c *Client) ForgotPassword(email string) error {
+	return c.transport.forgotPassword(&models.PasswordForgot{Email: email})
+}
+
+// ResetPassword sets new password by code of password reset, user logs in with new password after it
+func (c *Client) ResetPassword(token, newPassword string) error {
+	return c.transport.resetPassword(&models.PasswordReset{Token: token, NewPassword: newPassword})
+}
@@ -0,0 +1,137 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/crypto/keybundle"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/problem"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	ErrEnrollment        = errors.New("no such enrollment, it is expired or already approved")
+	ErrEnrollmentExpired = errors.New("enrollment is expired, start it again")
+)
+
+// enrollFolder folder in keys folder for received keys that are not confirmed by user yet
+const enrollFolder = "enroll"
+
+// enrollmentErrors missing enrollment is not missing element of vault
+var enrollmentErrors = map[string]error{problem.CodeNotFound: ErrEnrollment}
+
+// enrollmentCodes missing enrollment is not missing element of vault
+var enrollmentCodes = map[codes.Code]error{codes.NotFound: ErrEnrollment}
+
+// StartEnrollment sends ephemeral public key of new device in service,
+// returned enrollment contains code that should be entered on approving device
+func (c *Client) StartEnrollment(device *keybundle.DeviceKey) (*models.Enrollment, error) {
+	return c.transport.startEnrollment(&models.NewEnrollment{DevicePublic: device.Public[:]})
+}
+
+// Enrollment loads enrollment by code
+func (c *Client) Enrollment(code string) (*models.Enrollment, error) {
+	return c.transport.enrollment(keybundle.NormalizeCode(code))
+}
+
+// ApproveEnrollment seals private keys of client and secret of files encryption
+// for public key of new device and uploads them in service.
+// Code is checked against public key, so service can't substitute the device
+func (c *Client) ApproveEnrollment(code, secret string) error {
+	enrollment, enrollmentErr := c.Enrollment(code)
+	if enrollmentErr != nil {
+		return enrollmentErr
+	}
+	bundle, exportErr := keybundle.Export(c.keysPath, secret)
+	if exportErr != nil {
+		return exportErr
+	}
+	sealed, sealErr := keybundle.Seal(bundle, enrollment.DevicePublic, code)
+	if sealErr != nil {
+		return sealErr
+	}
+	return c.transport.approveEnrollment(enrollment.Code, &models.EnrollmentApprove{Bundle: sealed})
+}
+
+// CancelEnrollment removes enrollment from service
+func (c *Client) CancelEnrollment(code string) error {
+	return c.transport.deleteEnrollment(keybundle.NormalizeCode(code))
+}
+
+// PendingKeys keys received by enrollment. Sealed bundle doesnt authenticate its sender,
+// so keys are kept apart from keys folder until user confirms their fingerprints on approving device
+type PendingKeys struct {
+	Secret       string
+	Fingerprints []string
+	keysPath     string
+	pendingPath  string
+}
+
+// CompleteEnrollment polls enrollment until it is approved, opens sealed bundle
+// with ephemeral key of device and saves keys in pending folder of keysPath.
+// Keys are trusted only after Install of returned pending keys
+func (c *Client) CompleteEnrollment(enrollment *models.Enrollment, device *keybundle.DeviceKey, keysPath string, poll time.Duration) (*PendingKeys, error) {
+	for len(enrollment.Bundle) == 0 {
+		if time.Now().After(enrollment.Expires) {
+			return nil, ErrEnrollmentExpired
+		}
+		time.Sleep(poll)
+		current, currentErr := c.Enrollment(enrollment.Code)
+		if currentErr != nil {
+			if errors.Is(currentErr, ErrEnrollment) {
+				return nil, ErrEnrollmentExpired
+			}
+			return nil, currentErr
+		}
+		enrollment = current
+	}
+
+	bundle, openErr := device.Open(enrollment.Bundle)
+	if openErr != nil {
+		return nil, openErr
+	}
+	pending := &PendingKeys{keysPath: keysPath, pendingPath: filepath.Join(keysPath, enrollFolder)}
+	// keys of interrupted enrollment are not trusted
+	if removeErr := pending.Discard(); removeErr != nil {
+		return nil, removeErr
+	}
+	if mkdirErr := os.MkdirAll(pending.pendingPath, 0700); mkdirErr != nil {
+		return nil, mkdirErr
+	}
+	secret, importErr := keybundle.Import(bundle, pending.pendingPath)
+	if importErr != nil {
+		return nil, importErr
+	}
+	fingerprints, fingerprintsErr := crypto.Fingerprints(pending.pendingPath)
+	if fingerprintsErr != nil {
+		return nil, fingerprintsErr
+	}
+	pending.Secret, pending.Fingerprints = secret, fingerprints
+	if cancelErr := c.CancelEnrollment(enrollment.Code); cancelErr != nil {
+		log.Println(cancelErr)
+	}
+	return pending, nil
+}
+
+// Install moves confirmed keys in keys folder, existing keys are never replaced
+func (p *PendingKeys) Install() error {
+	for _, keyFile := range crypto.KeyFiles() {
+		if _, statErr := os.Stat(filepath.Join(p.keysPath, keyFile)); statErr == nil {
+			return keybundle.ErrKeyExist
+		}
+	}
+	if installErr := installKeys(p.keysPath, p.pendingPath); installErr != nil {
+		return installErr
+	}
+	return os.Remove(p.pendingPath)
+}
+
+// Discard removes keys that are not confirmed by user
+func (p *PendingKeys) Discard() error {
+	return os.RemoveAll(p.pendingPath)
+}
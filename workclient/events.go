@@ -0,0 +1,106 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/events"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultEventsRetry delay before reconnect if service didnt send its own
+const defaultEventsRetry = 2 * time.Second
+
+// WatchEvents listens changes of elements made by other clients of user and calls handle for each of them.
+// Stream is opened by REST API with any transport and is reconnected until context is done or token is invalid
+func (c *Client) WatchEvents(ctx context.Context, handle func(event events.Event)) error {
+	retry := defaultEventsRetry
+	for {
+		var serverRetry time.Duration
+		streamErr := c.transport.authorized(func() error {
+			var err error
+			serverRetry, err = c.streamEvents(ctx, handle)
+			return err
+		})
+		if errors.Is(streamErr, ErrToken) {
+			return streamErr
+		}
+		if streamErr != nil && ctx.Err() == nil {
+			log.Println("events stream:", streamErr)
+		}
+		if serverRetry > 0 {
+			retry = serverRetry
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retry):
+		}
+	}
+}
+
+// streamEvents reads one stream of events until service closes it,
+// returns reconnect delay sent by service
+func (c *Client) streamEvents(ctx context.Context, handle func(event events.Event)) (time.Duration, error) {
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/events", c.rest.baseURL), nil)
+	if reqErr != nil {
+		return 0, reqErr
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+c.rest.bearer())
+	res, resErr := http.DefaultClient.Do(req)
+	if resErr != nil {
+		return 0, resErr
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(res.Body)
+		if readErr != nil {
+			return 0, readErr
+		}
+		return 0, problemError(res.StatusCode, res.Header, body, nil)
+	}
+	return readEvents(res.Body, handle)
+}
+
+// readEvents parses Server-Sent Events, unknown fields and comments are skipped
+func readEvents(r io.Reader, handle func(event events.Event)) (time.Duration, error) {
+	var (
+		retry time.Duration
+		data  strings.Builder
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data.Len() != 0 {
+				var event events.Event
+				if unmarshalErr := json.Unmarshal([]byte(data.String()), &event); unmarshalErr != nil {
+					log.Println("events stream:", unmarshalErr)
+				} else {
+					handle(event)
+				}
+				data.Reset()
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data.WriteString(value)
+		case "retry":
+			if millis, parseErr := strconv.Atoi(value); parseErr == nil {
+				retry = time.Duration(millis) * time.Millisecond
+			}
+		}
+	}
+	return retry, scanner.Err()
+}
@@ -0,0 +1,592 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/keeperpb"
+	"AlexSarva/GophKeeper/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// grpcTimeout timeout of one gRPC call
+const grpcTimeout = 5 * time.Second
+
+var (
+	ErrNoGRPCAddress = errors.New("cant obtain gRPC address of service")
+	ErrFileStream    = errors.New("file stream doesnt start with file info")
+)
+
+// grpcTransport sends requests to gRPC API of service
+type grpcTransport struct {
+	client keeperpb.KeeperClient
+	token  string
+	// device metadata with device and version of client
+	device metadata.MD
+}
+
+// newGRPCTransport connects to gRPC API, connection is established on first call
+func newGRPCTransport(address string, useTLS bool) (*grpcTransport, error) {
+	if address == "" {
+		return nil, ErrNoGRPCAddress
+	}
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewClientTLSFromCert(nil, "")
+	}
+	conn, dialErr := grpc.Dial(address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(keeperpb.MaxMessageSize), grpc.MaxCallSendMsgSize(keeperpb.MaxMessageSize)),
+	)
+	if dialErr != nil {
+		return nil, dialErr
+	}
+	return &grpcTransport{client: keeperpb.NewKeeperClient(conn)}, nil
+}
+
+// grpcError maps status of gRPC error to errors of client, method could override errors of some codes
+func grpcError(err error, overrides map[codes.Code]error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if override, found := overrides[st.Code()]; found {
+		return override
+	}
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return ErrReqFormat
+	case codes.FailedPrecondition:
+		return errors.New(st.Message())
+	case codes.Unauthenticated:
+		return ErrToken
+	case codes.PermissionDenied:
+		return ErrTokenExpired
+	case codes.NotFound:
+		return ErrNoData
+	case codes.Aborted:
+		return ErrConflict
+	case codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", ErrThrottled, st.Message())
+	case codes.Internal:
+		return ErrInternalServer
+	default:
+		return err
+	}
+}
+
+// describe sets device and version of client in metadata of calls, service shows them in sessions of user
+func (t *grpcTransport) describe(device, version string) {
+	t.device = metadata.Pairs("x-device-name", device, "x-client-version", version)
+}
+
+// callContext returns context of call with token and device of client in metadata
+func (t *grpcTransport) callContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(context.Background(), t.device), grpcTimeout)
+	if t.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+t.token)
+	}
+	return ctx, cancel
+}
+
+func (t *grpcTransport) useToken(token string) {
+	t.token = token
+}
+
+func (t *grpcTransport) refresh(refreshToken string) (*models.Tokens, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	tokens, tokensErr := t.client.RefreshToken(ctx, &keeperpb.RefreshRequest{RefreshToken: refreshToken})
+	if tokensErr != nil {
+		return nil, grpcError(tokensErr, nil)
+	}
+	return keeperpb.TokensFromPB(tokens), nil
+}
+
+func (t *grpcTransport) logout() error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, logoutErr := t.client.Logout(ctx, &emptypb.Empty{}); logoutErr != nil {
+		return grpcError(logoutErr, nil)
+	}
+	return nil
+}
+
+func (t *grpcTransport) logoutEverywhere() error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, logoutErr := t.client.LogoutEverywhere(ctx, &emptypb.Empty{}); logoutErr != nil {
+		return grpcError(logoutErr, nil)
+	}
+	return nil
+}
+
+func (t *grpcTransport) register(userInfo *models.UserRegister) (*models.User, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	user, userErr := t.client.Register(ctx, &keeperpb.RegisterRequest{
+		Username: userInfo.Username,
+		Email:    userInfo.Email,
+		Password: userInfo.Password,
+	})
+	if userErr != nil {
+		return nil, grpcError(userErr, map[codes.Code]error{codes.Aborted: ErrUserExist})
+	}
+	return keeperpb.UserFromPB(user)
+}
+
+func (t *grpcTransport) login(userInfo *models.UserLogin) (*models.User, *models.TwoFactorChallenge, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	user, userErr := t.client.Login(ctx, &keeperpb.LoginRequest{Email: userInfo.Email, Password: userInfo.Password})
+	if userErr != nil {
+		return nil, nil, grpcError(userErr, map[codes.Code]error{codes.Unauthenticated: ErrCreds})
+	}
+	if challenge := keeperpb.ChallengeFromPB(user); challenge != nil {
+		return nil, challenge, nil
+	}
+	loggedUser, loggedUserErr := keeperpb.UserFromPB(user)
+	return loggedUser, nil, loggedUserErr
+}
+
+func (t *grpcTransport) loginTwoFactor(login *models.TwoFactorLogin) (*models.User, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	user, userErr := t.client.LoginTwoFactor(ctx, &keeperpb.TwoFactorLoginRequest{Challenge: login.Challenge, Code: login.Code})
+	if userErr != nil {
+		return nil, grpcError(userErr, map[codes.Code]error{codes.Unauthenticated: ErrTwoFactorCode})
+	}
+	return keeperpb.UserFromPB(user)
+}
+
+func (t *grpcTransport) me() (*models.User, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	user, userErr := t.client.GetMe(ctx, &emptypb.Empty{})
+	if userErr != nil {
+		return nil, grpcError(userErr, nil)
+	}
+	return keeperpb.UserFromPB(user)
+}
+
+func (t *grpcTransport) setKey(userKey models.UserKey) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	_, setErr := t.client.SetKey(ctx, &keeperpb.SetKeyRequest{Fingerprint: userKey.Fingerprint, Previous: userKey.Previous})
+	if setErr != nil {
+		return grpcError(setErr, map[codes.Code]error{codes.Aborted: ErrKeyMismatch})
+	}
+	return nil
+}
+
+func (t *grpcTransport) sessions() ([]models.Session, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	sessions, sessionsErr := t.client.ListSessions(ctx, &emptypb.Empty{})
+	if sessionsErr != nil {
+		return nil, grpcError(sessionsErr, nil)
+	}
+	return keeperpb.SessionsFromPB(sessions)
+}
+
+func (t *grpcTransport) revokeSession(id uuid.UUID) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, revokeErr := t.client.RevokeSession(ctx, &keeperpb.ElementID{Id: id.String()}); revokeErr != nil {
+		return grpcError(revokeErr, nil)
+	}
+	return nil
+}
+
+func (t *grpcTransport) setupTwoFactor() (*models.TwoFactorSetup, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	setup, setupErr := t.client.SetupTwoFactor(ctx, &emptypb.Empty{})
+	if setupErr != nil {
+		return nil, grpcError(setupErr, map[codes.Code]error{codes.Aborted: ErrTwoFactorState})
+	}
+	return &models.TwoFactorSetup{Secret: setup.GetSecret(), URI: setup.GetUri()}, nil
+}
+
+func (t *grpcTransport) confirmTwoFactor(code string) (*models.RecoveryCodes, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	recovery, confirmErr := t.client.ConfirmTwoFactor(ctx, &keeperpb.TwoFactorCode{Code: code})
+	if confirmErr != nil {
+		return nil, grpcError(confirmErr, map[codes.Code]error{codes.PermissionDenied: ErrTwoFactorCode, codes.Aborted: ErrTwoFactorState})
+	}
+	return &models.RecoveryCodes{Codes: recovery.GetCodes()}, nil
+}
+
+func (t *grpcTransport) disableTwoFactor(code string) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, disableErr := t.client.DisableTwoFactor(ctx, &keeperpb.TwoFactorCode{Code: code}); disableErr != nil {
+		return grpcError(disableErr, map[codes.Code]error{codes.PermissionDenied: ErrTwoFactorCode, codes.Aborted: ErrTwoFactorState})
+	}
+	return nil
+}
+
+func (t *grpcTransport) changePassword(change *models.PasswordChange) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	req := &keeperpb.PasswordChange{Password: change.Password, NewPassword: change.NewPassword}
+	if _, changeErr := t.client.ChangePassword(ctx, req); changeErr != nil {
+		return grpcError(changeErr, map[codes.Code]error{codes.PermissionDenied: ErrPassword})
+	}
+	return nil
+}
+
+func (t *grpcTransport) deleteAccount(deletion *models.AccountDeletion) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	req := &keeperpb.AccountDeletion{Password: deletion.Password, Code: deletion.Code}
+	if _, deleteErr := t.client.DeleteAccount(ctx, req); deleteErr != nil {
+		return grpcError(deleteErr, map[codes.Code]error{codes.PermissionDenied: ErrReauth})
+	}
+	return nil
+}
+
+func (t *grpcTransport) verifyEmail(verification *models.EmailVerification) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	req := &keeperpb.EmailVerification{Token: verification.Token}
+	if _, verifyErr := t.client.VerifyEmail(ctx, req); verifyErr != nil {
+		return grpcError(verifyErr, map[codes.Code]error{codes.FailedPrecondition: ErrEmailToken})
+	}
+	return nil
+}
+
+func (t *grpcTransport) resendVerification() error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, sendErr := t.client.ResendVerification(ctx, &emptypb.Empty{}); sendErr != nil {
+		return grpcError(sendErr, map[codes.Code]error{codes.Aborted: ErrEmailVerified, codes.ResourceExhausted: ErrMailCooldown})
+	}
+	return nil
+}
+
+func (t *grpcTransport) forgotPassword(forgot *models.PasswordForgot) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, forgotErr := t.client.ForgotPassword(ctx, &keeperpb.PasswordForgot{Email: forgot.Email}); forgotErr != nil {
+		return grpcError(forgotErr, nil)
+	}
+	return nil
+}
+
+func (t *grpcTransport) resetPassword(reset *models.PasswordReset) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	req := &keeperpb.PasswordReset{Token: reset.Token, NewPassword: reset.NewPassword}
+	if _, resetErr := t.client.ResetPassword(ctx, req); resetErr != nil {
+		return grpcError(resetErr, nil)
+	}
+	return nil
+}
+
+func (t *grpcTransport) startEnrollment(newEnrollment *models.NewEnrollment) (*models.Enrollment, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	enrollment, enrollmentErr := t.client.StartEnrollment(ctx, &keeperpb.NewEnrollment{DevicePublic: newEnrollment.DevicePublic})
+	if enrollmentErr != nil {
+		return nil, grpcError(enrollmentErr, map[codes.Code]error{codes.Aborted: ErrEnrollment})
+	}
+	return keeperpb.EnrollmentFromPB(enrollment)
+}
+
+func (t *grpcTransport) enrollment(code string) (*models.Enrollment, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	enrollment, enrollmentErr := t.client.GetEnrollment(ctx, &keeperpb.EnrollmentCode{Code: code})
+	if enrollmentErr != nil {
+		return nil, grpcError(enrollmentErr, enrollmentCodes)
+	}
+	return keeperpb.EnrollmentFromPB(enrollment)
+}
+
+func (t *grpcTransport) approveEnrollment(code string, approve *models.EnrollmentApprove) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, approveErr := t.client.ApproveEnrollment(ctx, &keeperpb.EnrollmentApprove{Code: code, Bundle: approve.Bundle}); approveErr != nil {
+		return grpcError(approveErr, enrollmentCodes)
+	}
+	return nil
+}
+
+func (t *grpcTransport) deleteEnrollment(code string) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, deleteErr := t.client.DeleteEnrollment(ctx, &keeperpb.EnrollmentCode{Code: code}); deleteErr != nil {
+		return grpcError(deleteErr, enrollmentCodes)
+	}
+	return nil
+}
+
+func (t *grpcTransport) list(infoType string, elems interface{}) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	switch infoType {
+	case "notes":
+		resp, listErr := t.client.ListNotes(ctx, &emptypb.Empty{})
+		if listErr != nil {
+			return grpcError(listErr, nil)
+		}
+		notes := elems.(*[]models.Note)
+		for _, pbNote := range resp.GetNotes() {
+			note, noteErr := keeperpb.NoteFromPB(pbNote)
+			if noteErr != nil {
+				return noteErr
+			}
+			*notes = append(*notes, note)
+		}
+	case "cards":
+		resp, listErr := t.client.ListCards(ctx, &emptypb.Empty{})
+		if listErr != nil {
+			return grpcError(listErr, nil)
+		}
+		cards := elems.(*[]models.Card)
+		for _, pbCard := range resp.GetCards() {
+			card, cardErr := keeperpb.CardFromPB(pbCard)
+			if cardErr != nil {
+				return cardErr
+			}
+			*cards = append(*cards, card)
+		}
+	case "creds":
+		resp, listErr := t.client.ListCreds(ctx, &emptypb.Empty{})
+		if listErr != nil {
+			return grpcError(listErr, nil)
+		}
+		creds := elems.(*[]models.Cred)
+		for _, pbCred := range resp.GetCreds() {
+			cred, credErr := keeperpb.CredFromPB(pbCred)
+			if credErr != nil {
+				return credErr
+			}
+			*creds = append(*creds, cred)
+		}
+	case "files":
+		// list of files doesnt contain content, every file is downloaded
+		resp, listErr := t.client.ListFiles(ctx, &emptypb.Empty{})
+		if listErr != nil {
+			return grpcError(listErr, nil)
+		}
+		files := elems.(*[]models.File)
+		for _, info := range resp.GetFiles() {
+			fileID, fileIDErr := keeperpb.ParseID(info.GetId())
+			if fileIDErr != nil {
+				return fileIDErr
+			}
+			file, fileErr := t.download(fileID)
+			if fileErr != nil {
+				return fileErr
+			}
+			*files = append(*files, file)
+		}
+	default:
+		return errors.New("wrong info type parameter")
+	}
+	return nil
+}
+
+// download receives file info and then file content by chunks
+func (t *grpcTransport) download(id uuid.UUID) (models.File, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	stream, streamErr := t.client.DownloadFile(ctx, &keeperpb.ElementID{Id: id.String()})
+	if streamErr != nil {
+		return models.File{}, grpcError(streamErr, nil)
+	}
+	first, recvErr := stream.Recv()
+	if recvErr != nil {
+		return models.File{}, grpcError(recvErr, nil)
+	}
+	info := first.GetInfo()
+	if info == nil {
+		return models.File{}, ErrFileStream
+	}
+	var data []byte
+	for {
+		chunk, chunkErr := stream.Recv()
+		if errors.Is(chunkErr, io.EOF) {
+			break
+		}
+		if chunkErr != nil {
+			return models.File{}, grpcError(chunkErr, nil)
+		}
+		data = append(data, chunk.GetData()...)
+	}
+	return keeperpb.FileFromPB(info, data)
+}
+
+func (t *grpcTransport) get(infoType string, id uuid.UUID) (interface{}, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	req := &keeperpb.ElementID{Id: id.String()}
+	switch infoType {
+	case "notes":
+		note, noteErr := t.client.GetNote(ctx, req)
+		if noteErr != nil {
+			return nil, grpcError(noteErr, nil)
+		}
+		return keeperpb.NoteFromPB(note)
+	case "cards":
+		card, cardErr := t.client.GetCard(ctx, req)
+		if cardErr != nil {
+			return nil, grpcError(cardErr, nil)
+		}
+		return keeperpb.CardFromPB(card)
+	case "creds":
+		cred, credErr := t.client.GetCred(ctx, req)
+		if credErr != nil {
+			return nil, grpcError(credErr, nil)
+		}
+		return keeperpb.CredFromPB(cred)
+	case "files":
+		return t.download(id)
+	default:
+		return nil, errors.New("wrong info type parameter")
+	}
+}
+
+// fileUploader stream of file chunks to service
+type fileUploader interface {
+	Send(*keeperpb.FileChunk) error
+	CloseAndRecv() (*keeperpb.FileInfo, error)
+}
+
+// upload sends file info and then file content by chunks
+func upload(stream fileUploader, file *models.NewFile) (models.File, error) {
+	if sendErr := stream.Send(&keeperpb.FileChunk{Chunk: &keeperpb.FileChunk_Info{Info: keeperpb.NewFileToPB(file)}}); sendErr != nil {
+		return models.File{}, grpcError(sendErr, nil)
+	}
+	for start := 0; start < len(file.File); start += keeperpb.ChunkSize {
+		end := start + keeperpb.ChunkSize
+		if end > len(file.File) {
+			end = len(file.File)
+		}
+		if sendErr := stream.Send(&keeperpb.FileChunk{Chunk: &keeperpb.FileChunk_Data{Data: file.File[start:end]}}); sendErr != nil {
+			return models.File{}, grpcError(sendErr, nil)
+		}
+	}
+	info, closeErr := stream.CloseAndRecv()
+	if closeErr != nil {
+		return models.File{}, grpcError(closeErr, map[codes.Code]error{codes.NotFound: ErrConflict})
+	}
+	return keeperpb.FileFromPB(info, file.File)
+}
+
+func (t *grpcTransport) add(_ string, elem interface{}) (interface{}, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	switch value := elem.(type) {
+	case *models.NewNote:
+		note, noteErr := t.client.CreateNote(ctx, keeperpb.NewNoteToPB(value))
+		if noteErr != nil {
+			return nil, grpcError(noteErr, nil)
+		}
+		return keeperpb.NoteFromPB(note)
+	case *models.NewCard:
+		card, cardErr := t.client.CreateCard(ctx, keeperpb.NewCardToPB(value))
+		if cardErr != nil {
+			return nil, grpcError(cardErr, nil)
+		}
+		return keeperpb.CardFromPB(card)
+	case *models.NewCred:
+		cred, credErr := t.client.CreateCred(ctx, keeperpb.NewCredToPB(value))
+		if credErr != nil {
+			return nil, grpcError(credErr, nil)
+		}
+		return keeperpb.CredFromPB(cred)
+	case *models.NewFile:
+		stream, streamErr := t.client.CreateFile(ctx)
+		if streamErr != nil {
+			return nil, grpcError(streamErr, nil)
+		}
+		return upload(stream, value)
+	default:
+		return nil, errors.New("wrong info type parameter")
+	}
+}
+
+func (t *grpcTransport) edit(_ string, elem interface{}, id uuid.UUID) (interface{}, error) {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	// element not found by service is conflict for client as in REST API
+	overrides := map[codes.Code]error{codes.NotFound: ErrConflict}
+	switch value := elem.(type) {
+	case *models.NewNote:
+		value.ID = id
+		note, noteErr := t.client.EditNote(ctx, keeperpb.NewNoteToPB(value))
+		if noteErr != nil {
+			return nil, grpcError(noteErr, overrides)
+		}
+		return keeperpb.NoteFromPB(note)
+	case *models.NewCard:
+		value.ID = id
+		card, cardErr := t.client.EditCard(ctx, keeperpb.NewCardToPB(value))
+		if cardErr != nil {
+			return nil, grpcError(cardErr, overrides)
+		}
+		return keeperpb.CardFromPB(card)
+	case *models.NewCred:
+		value.ID = id
+		cred, credErr := t.client.EditCred(ctx, keeperpb.NewCredToPB(value))
+		if credErr != nil {
+			return nil, grpcError(credErr, overrides)
+		}
+		return keeperpb.CredFromPB(cred)
+	case *models.NewFile:
+		value.ID = id
+		stream, streamErr := t.client.EditFile(ctx)
+		if streamErr != nil {
+			return nil, grpcError(streamErr, overrides)
+		}
+		return upload(stream, value)
+	default:
+		return nil, errors.New("wrong info type parameter")
+	}
+}
+
+func (t *grpcTransport) remove(infoType string, id uuid.UUID) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	req := &keeperpb.ElementID{Id: id.String()}
+	var delErr error
+	switch infoType {
+	case "notes":
+		_, delErr = t.client.DeleteNote(ctx, req)
+	case "cards":
+		_, delErr = t.client.DeleteCard(ctx, req)
+	case "creds":
+		_, delErr = t.client.DeleteCred(ctx, req)
+	case "files":
+		_, delErr = t.client.DeleteFile(ctx, req)
+	default:
+		return errors.New("wrong info type parameter")
+	}
+	if delErr != nil {
+		return grpcError(delErr, nil)
+	}
+	return nil
+}
+
+func (t *grpcTransport) replaceVault(vault *models.Vault) error {
+	ctx, cancel := t.callContext()
+	defer cancel()
+	if _, replaceErr := t.client.ReplaceVault(ctx, keeperpb.VaultToPB(vault)); replaceErr != nil {
+		return grpcError(replaceErr, map[codes.Code]error{codes.NotFound: ErrNoData, codes.Aborted: ErrNoData})
+	}
+	return nil
+}
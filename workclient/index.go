@@ -0,0 +1,213 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/crypto/cryptoblock"
+	"AlexSarva/GophKeeper/models"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const indexFile = "index.db"
+
+// Sort orders of index entries
+const (
+	SortByDate  = "date"
+	SortByTitle = "title"
+)
+
+// IndexEntry decrypted metadata of element, used for listing, sorting and search in GUI,
+// because service stores only encrypted titles
+type IndexEntry struct {
+	ID       uuid.UUID `json:"id"`
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	FileName string    `json:"file_name,omitempty"`
+	Version  int       `json:"version"`
+	Created  time.Time `json:"created"`
+	Changed  time.Time `json:"changed,omitempty"`
+}
+
+// lastDate returns time of last change of element
+func (e *IndexEntry) lastDate() time.Time {
+	if e.Changed.After(e.Created) {
+		return e.Changed
+	}
+	return e.Created
+}
+
+// Index client-side index of decrypted metadata,
+// it is stored in keys folder and encrypted with sym crypt
+type Index struct {
+	mu        sync.RWMutex
+	path      string
+	symCrypto cryptoblock.SymCrypto
+	entries   map[uuid.UUID]IndexEntry
+}
+
+// loadIndex reads index from keys folder, returns empty index if it doesnt exist or can't be decrypted
+func loadIndex(keysPath string, symCrypto cryptoblock.SymCrypto) *Index {
+	index := &Index{
+		path:      filepath.Join(keysPath, indexFile),
+		symCrypto: symCrypto,
+		entries:   make(map[uuid.UUID]IndexEntry),
+	}
+	cryptIndex, readErr := os.ReadFile(index.path)
+	if readErr != nil {
+		return index
+	}
+	indexBytes, decryptErr := symCrypto.Decrypt(cryptIndex)
+	if decryptErr != nil {
+		return index
+	}
+	var entries []IndexEntry
+	if unmarshalErr := json.Unmarshal(indexBytes, &entries); unmarshalErr != nil {
+		return index
+	}
+	for _, entry := range entries {
+		index.entries[entry.ID] = entry
+	}
+	return index
+}
+
+// save writes encrypted index in keys folder
+func (i *Index) save() error {
+	entries := make([]IndexEntry, 0, len(i.entries))
+	for _, entry := range i.entries {
+		entries = append(entries, entry)
+	}
+	indexBytes, marshalErr := json.Marshal(entries)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	cryptIndex, encryptErr := i.symCrypto.Encrypt(indexBytes)
+	if encryptErr != nil {
+		return encryptErr
+	}
+	return os.WriteFile(i.path, cryptIndex, 0600)
+}
+
+// setSymCrypto changes sym crypt of index, used after keys rotation
+func (i *Index) setSymCrypto(symCrypto cryptoblock.SymCrypto) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.symCrypto = symCrypto
+	return i.save()
+}
+
+// replace replaces all entries of selected type, used after loading of elements list
+func (i *Index) replace(infoType string, entries []IndexEntry) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	latest := make(map[uuid.UUID]IndexEntry)
+	for id, entry := range i.entries {
+		if entry.Type == infoType {
+			latest[id] = entry
+			delete(i.entries, id)
+		}
+	}
+	for _, entry := range entries {
+		if current, ok := latest[entry.ID]; ok && current.Version > entry.Version {
+			entry.Version = current.Version
+		}
+		i.entries[entry.ID] = entry
+	}
+	return i.save()
+}
+
+// put adds or changes entry
+func (i *Index) put(entry IndexEntry) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.putLatest(entry)
+	return i.save()
+}
+
+// putLatest adds or changes entry, the last seen version of element is kept
+// even if service returns an older one, so rollback can be detected
+func (i *Index) putLatest(entry IndexEntry) {
+	if current, ok := i.entries[entry.ID]; ok && current.Version > entry.Version {
+		entry.Version = current.Version
+	}
+	i.entries[entry.ID] = entry
+}
+
+// version returns the last seen version of element, 0 if element is unknown
+func (i *Index) version(id uuid.UUID) int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.entries[id].Version
+}
+
+// remove deletes entry by element id
+func (i *Index) remove(id uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.entries, id)
+	return i.save()
+}
+
+// Search returns entries of selected type which title or file name contains query (case-insensitive),
+// sorted by title or by date of last change
+func (i *Index) Search(infoType, query, sortBy string) []IndexEntry {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	query = strings.ToLower(query)
+	var entries []IndexEntry
+	for _, entry := range i.entries {
+		if entry.Type != infoType {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(entry.Title), query) &&
+			!strings.Contains(strings.ToLower(entry.FileName), query) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		if sortBy == SortByTitle {
+			return strings.ToLower(entries[a].Title) < strings.ToLower(entries[b].Title)
+		}
+		return entries[a].lastDate().After(entries[b].lastDate())
+	})
+	return entries
+}
+
+// indexEntries makes index entries from decrypted elements
+func indexEntries(infoType string, elems interface{}) ([]IndexEntry, error) {
+	var entries []IndexEntry
+	switch infoType {
+	case "notes":
+		for _, note := range elems.([]models.Note) {
+			entries = append(entries, IndexEntry{ID: note.ID, Type: infoType, Title: note.Title, Version: note.Version, Created: note.Created, Changed: note.Changed.Get()})
+		}
+	case "cards":
+		for _, card := range elems.([]models.Card) {
+			entries = append(entries, IndexEntry{ID: card.ID, Type: infoType, Title: card.Title, Version: card.Version, Created: card.Created, Changed: card.Changed.Get()})
+		}
+	case "creds":
+		for _, cred := range elems.([]models.Cred) {
+			entries = append(entries, IndexEntry{ID: cred.ID, Type: infoType, Title: cred.Title, Version: cred.Version, Created: cred.Created, Changed: cred.Changed.Get()})
+		}
+	case "files":
+		for _, file := range elems.([]models.File) {
+			entries = append(entries, IndexEntry{ID: file.ID, Type: infoType, Title: file.Title, FileName: file.FileName, Version: file.Version, Created: file.Created, Changed: file.Changed.Get()})
+		}
+	default:
+		return nil, errors.New("wrong info type parameter")
+	}
+	return entries, nil
+}
+
+// Search returns index entries of selected type that match query, sorted by sortBy (SortByDate or SortByTitle)
+func (c *Client) Search(infoType, query, sortBy string) []IndexEntry {
+	return c.index.Search(infoType, query, sortBy)
+}
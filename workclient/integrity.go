@@ -0,0 +1,49 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/models"
+
+	"github.com/google/uuid"
+)
+
+// checkRollback returns IntegrityRollback if service returned element with correct signature
+// but older than the last version seen by client
+func (c *Client) checkRollback(id uuid.UUID, version int, integrity models.Integrity) models.Integrity {
+	if integrity == models.IntegrityOK && c.index.version(id) > version {
+		return models.IntegrityRollback
+	}
+	return integrity
+}
+
+// nextVersion returns version of changed element, if it isn't set by caller
+// the last version seen by client is used
+func (c *Client) nextVersion(id uuid.UUID, version int) int {
+	if version != 0 {
+		return version
+	}
+	return c.index.version(id) + 1
+}
+
+// verifyElement checks signature of encrypted element
+func (c *Client) verifyElement(elem interface{}) interface{} {
+	switch value := elem.(type) {
+	case models.Note:
+		value.Verify(c.cryptorizer)
+		value.Integrity = c.checkRollback(value.ID, value.Version, value.Integrity)
+		return value
+	case models.Card:
+		value.Verify(c.cryptorizer)
+		value.Integrity = c.checkRollback(value.ID, value.Version, value.Integrity)
+		return value
+	case models.Cred:
+		value.Verify(c.cryptorizer)
+		value.Integrity = c.checkRollback(value.ID, value.Version, value.Integrity)
+		return value
+	case models.File:
+		value.Verify(c.cryptorizer)
+		value.Integrity = c.checkRollback(value.ID, value.Version, value.Integrity)
+		return value
+	default:
+		return elem
+	}
+}
@@ -0,0 +1,60 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"errors"
+)
+
+var ErrKeyMismatch = errors.New("local keys differ from keys registered on account, use keys of account (see enroll and recover-key commands) or run rotate-keys")
+
+// registerKey registers fingerprint of public key on account,
+// previous fingerprint is required if another one is registered
+func (c *Client) registerKey(fingerprint, previous string) error {
+	return c.transport.setKey(models.UserKey{Fingerprint: fingerprint, Previous: previous})
+}
+
+// checkKey compares fingerprint of local public key with fingerprint registered on account,
+// local key is registered at first use. If they differ, client refuses to write in vault,
+// otherwise vault would have elements that no single key can decrypt
+func (c *Client) checkKey(user *models.User) error {
+	if c.cryptorizer == nil {
+		return nil
+	}
+	fingerprint, fingerprintErr := c.cryptorizer.Fingerprint()
+	if fingerprintErr != nil {
+		return fingerprintErr
+	}
+	if user.KeyFingerprint == "" {
+		if registerErr := c.registerKey(fingerprint, ""); registerErr != nil {
+			return registerErr
+		}
+		user.KeyFingerprint = fingerprint
+	}
+	c.accountKey = user.KeyFingerprint
+	c.keyErr = nil
+	if user.KeyFingerprint != fingerprint {
+		c.keyErr = ErrKeyMismatch
+	}
+	c.keyChecked = true
+	return c.keyErr
+}
+
+// KeyError returns ErrKeyMismatch if local keys differ from keys registered on account
+func (c *Client) KeyError() error {
+	return c.keyErr
+}
+
+// writable checks that element could be written with local keys,
+// fingerprint is loaded from service if it wasn't checked while login
+func (c *Client) writable() error {
+	if !c.keyChecked {
+		user, userErr := c.Me()
+		if userErr != nil {
+			return userErr
+		}
+		if checkErr := c.checkKey(user); checkErr != nil {
+			return checkErr
+		}
+	}
+	return c.keyErr
+}
@@ -0,0 +1,113 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/models"
+)
+
+// EncryptMetadata encrypts titles, file names and notes that were saved before metadata encryption.
+// Vault is replaced in service as a whole in one transaction, so all elements are signed with the next version.
+// Returns number of changed elements
+func (c *Client) EncryptMetadata() (int, error) {
+	var vault models.Vault
+	if listErr := c.rawElementList("notes", &vault.Notes); listErr != nil {
+		return 0, listErr
+	}
+	if listErr := c.rawElementList("cards", &vault.Cards); listErr != nil {
+		return 0, listErr
+	}
+	if listErr := c.rawElementList("creds", &vault.Creds); listErr != nil {
+		return 0, listErr
+	}
+	if listErr := c.rawElementList("files", &vault.Files); listErr != nil {
+		return 0, listErr
+	}
+
+	var count int
+	for i := range vault.Notes {
+		note := &vault.Notes[i]
+		ok, encryptErr := encryptPlainMeta(c.cryptorizer, &note.Title)
+		if encryptErr != nil {
+			return 0, encryptErr
+		}
+		if ok {
+			count++
+		}
+		note.Version++
+		if signErr := note.Sign(c.cryptorizer); signErr != nil {
+			return 0, signErr
+		}
+	}
+	for i := range vault.Cards {
+		card := &vault.Cards[i]
+		ok, encryptErr := encryptPlainMeta(c.cryptorizer, &card.Title, &card.Notes)
+		if encryptErr != nil {
+			return 0, encryptErr
+		}
+		if ok {
+			count++
+		}
+		card.Version++
+		if signErr := card.Sign(c.cryptorizer); signErr != nil {
+			return 0, signErr
+		}
+	}
+	for i := range vault.Creds {
+		cred := &vault.Creds[i]
+		ok, encryptErr := encryptPlainMeta(c.cryptorizer, &cred.Title, &cred.Notes)
+		if encryptErr != nil {
+			return 0, encryptErr
+		}
+		if ok {
+			count++
+		}
+		cred.Version++
+		if signErr := cred.Sign(c.cryptorizer); signErr != nil {
+			return 0, signErr
+		}
+	}
+	for i := range vault.Files {
+		file := &vault.Files[i]
+		ok, encryptErr := encryptPlainMeta(c.cryptorizer, &file.Title, &file.FileName, &file.Notes)
+		if encryptErr != nil {
+			return 0, encryptErr
+		}
+		if ok {
+			count++
+		}
+		file.Version++
+		if signErr := file.Sign(c.cryptorizer); signErr != nil {
+			return 0, signErr
+		}
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+	if replaceErr := c.ReplaceVault(&vault); replaceErr != nil {
+		return 0, replaceErr
+	}
+	return count, nil
+}
+
+// encryptPlainMeta encrypts values identified as saved before metadata encryption,
+// values that only cant be decrypted by current keys are left as is.
+// Returns true if any of values was changed
+func encryptPlainMeta(cryptorizer *crypto.Cryptorizer, values ...*string) (bool, error) {
+	var changed bool
+	for _, value := range values {
+		if *value == "" {
+			continue
+		}
+		if crypto.IsEncrypted(*value) {
+			continue
+		}
+		cipher, encryptErr := cryptorizer.Encrypt(*value)
+		if encryptErr != nil {
+			return false, encryptErr
+		}
+		*value = cipher
+		changed = true
+	}
+	return changed, nil
+}
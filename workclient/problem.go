@@ -0,0 +1,129 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/problem"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"gopkg.in/h2non/gentleman.v2"
+)
+
+var (
+	// ErrRejected service rejected request, detail of problem explains the reason
+	ErrRejected = errors.New("request rejected")
+	// ErrInProgress previous attempt of request is still in progress in service
+	ErrInProgress = errors.New("request is still in progress, try again later")
+	// ErrThrottled too many failed logins, detail of problem tells when login is allowed
+	ErrThrottled = errors.New("login is throttled")
+)
+
+// codeErrors errors of client by codes of problems
+var codeErrors = map[string]error{
+	problem.CodeInvalidRequest:     ErrReqFormat,
+	problem.CodeInvalidID:          ErrReqFormat,
+	problem.CodeEmptyFields:        ErrReqFormat,
+	problem.CodeNoFingerprint:      ErrReqFormat,
+	problem.CodeRouteNotFound:      ErrReqFormat,
+	problem.CodeMethodNotAllowed:   ErrReqFormat,
+	problem.CodeRejected:           ErrRejected,
+	problem.CodeUnauthorized:       ErrToken,
+	problem.CodeInvalidCredentials: ErrCreds,
+	problem.CodeTokenInvalid:       ErrTokenExpired,
+	problem.CodeRefreshInvalid:     ErrToken,
+	problem.CodeRefreshReused:      ErrToken,
+	problem.CodeTwoFactorInvalid:   ErrTwoFactorCode,
+	problem.CodeChallengeInvalid:   ErrChallenge,
+	problem.CodeTwoFactorEnabled:   ErrTwoFactorState,
+	problem.CodeTwoFactorDisabled:  ErrTwoFactorState,
+	problem.CodeTooManyAttempts:    ErrThrottled,
+	problem.CodeEmailTokenInvalid:  ErrEmailToken,
+	problem.CodeEmailVerified:      ErrEmailVerified,
+	problem.CodeMailCooldown:       ErrMailCooldown,
+	problem.CodeLoginExists:        ErrUserExist,
+	problem.CodeElementExists:      ErrConflict,
+	problem.CodeVersionConflict:    ErrConflict,
+	problem.CodeKeyMismatch:        ErrKeyMismatch,
+	problem.CodeIdempotencyReused:  ErrReqFormat,
+	problem.CodeRequestInProgress:  ErrInProgress,
+	problem.CodeNotFound:           ErrNoData,
+	problem.CodeEnrollmentExists:   ErrEnrollment,
+	problem.CodeInternal:           ErrInternalServer,
+}
+
+// ServiceError error of failed request. It matches error of client with errors.Is
+// and gives problem details of service with errors.As
+type ServiceError struct {
+	Problem *problem.Problem
+	err     error
+}
+
+// Error returns error of client with detail of problem
+func (e *ServiceError) Error() string {
+	if e.Problem.Detail == "" {
+		return e.err.Error()
+	}
+	return e.err.Error() + ": " + e.Problem.Detail
+}
+
+// Unwrap returns error of client
+func (e *ServiceError) Unwrap() error {
+	return e.err
+}
+
+// As sets target to problem details of service
+func (e *ServiceError) As(target interface{}) bool {
+	p, ok := target.(**problem.Problem)
+	if ok {
+		*p = e.Problem
+	}
+	return ok
+}
+
+// responseError decodes problem details of failed response,
+// method could override errors of some codes
+func responseError(res *gentleman.Response, overrides map[string]error) error {
+	return problemError(res.StatusCode, res.Header, res.Bytes(), overrides)
+}
+
+// problemError makes error of client from problem details,
+// response without them gets problem by its status code
+func problemError(status int, header http.Header, body []byte, overrides map[string]error) error {
+	var p *problem.Problem
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == problem.ContentType {
+		if unmarshalErr := json.Unmarshal(body, &p); unmarshalErr != nil || p.Code == "" {
+			p = nil
+		}
+	}
+	if p == nil {
+		p = problem.New(status, statusCode(status), strings.TrimSpace(string(body)))
+	}
+
+	err, found := overrides[p.Code]
+	if !found {
+		err, found = codeErrors[p.Code]
+	}
+	if !found {
+		err = ErrReqFormat
+	}
+	return &ServiceError{Problem: p, err: err}
+}
+
+// statusCode returns code of problem by status of response without problem details
+func statusCode(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return problem.CodeUnauthorized
+	case status == http.StatusNotFound:
+		return problem.CodeNotFound
+	case status == http.StatusConflict:
+		return problem.CodeVersionConflict
+	case status >= http.StatusInternalServerError:
+		return problem.CodeInternal
+	default:
+		return problem.CodeInvalidRequest
+	}
+}
@@ -0,0 +1,654 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/codec"
+	"AlexSarva/GophKeeper/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/eapache/go-resiliency.v1/retrier"
+	retry "gopkg.in/h2non/gentleman-retry.v2"
+	"gopkg.in/h2non/gentleman.v2"
+	gcontext "gopkg.in/h2non/gentleman.v2/context"
+	"gopkg.in/h2non/gentleman.v2/plugins/body"
+	"gopkg.in/h2non/gentleman.v2/plugins/query"
+	"gopkg.in/h2non/gentleman.v2/plugins/timeout"
+)
+
+// restTransport sends requests to REST API of service. Binary encodings are preferred in responses,
+// request bodies are sent in JSON until service responds with binary encoding
+type restTransport struct {
+	client  *gentleman.Client
+	baseURL string
+	mu      sync.Mutex
+	codec   codec.Codec
+	token   string
+}
+
+// idempotencyHeader header with key of request, service replays response on retries with the same key
+const idempotencyHeader = "Idempotency-Key"
+
+const (
+	// deviceNameHeader header with name of device, service saves it in session on login
+	deviceNameHeader = "X-Device-Name"
+	// clientVersionHeader header with version of client, service saves it in session on login
+	clientVersionHeader = "X-Client-Version"
+)
+
+// retryEvaluator retries failed requests like default evaluator of retries
+// and requests whose previous attempt is still in progress in service
+func retryEvaluator(err error, res *http.Response, req *http.Request) error {
+	if err == nil && res.StatusCode == http.StatusConflict && res.Header.Get("Retry-After") != "" {
+		return retry.ErrServer
+	}
+	return defaultEvaluator(err, res, req)
+}
+
+// defaultEvaluator default evaluator of retries
+var defaultEvaluator = retry.Evaluator
+
+// newHTTPClient returns http client with timeout and retries
+func newHTTPClient() *gentleman.Client {
+	retry.Evaluator = retryEvaluator
+	cli := gentleman.New()
+	cli.Use(timeout.Request(5 * time.Second))
+	cli.Use(retry.New(retrier.New(retrier.ExponentialBackoff(5, 100*time.Millisecond), nil)))
+	cli.SetHeader("Accept", codec.Preferred)
+	return cli
+}
+
+func newRESTTransport(serverAddress string) *restTransport {
+	t := &restTransport{
+		client:  newHTTPClient(),
+		baseURL: serverAddress,
+		codec:   codec.JSON,
+	}
+	// token is read on each request, so refreshed token is used by next requests
+	t.client.UseRequest(func(ctx *gcontext.Context, h gcontext.Handler) {
+		if token := t.bearer(); token != "" {
+			ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		h.Next(ctx)
+	})
+	return t
+}
+
+// describe sets device and version of client on requests, service shows them in sessions of user
+func (t *restTransport) describe(device, version string) {
+	t.client.SetHeader(deviceNameHeader, device)
+	t.client.SetHeader(clientVersionHeader, version)
+}
+
+// setBody encodes body of request by encoding that service uses in responses
+func (t *restTransport) setBody(req *gentleman.Request, data interface{}) error {
+	t.mu.Lock()
+	bodyCodec := t.codec
+	t.mu.Unlock()
+	payload, marshalErr := bodyCodec.Marshal(data)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	req.SetHeader("Content-Type", bodyCodec.ContentType())
+	req.Use(body.Reader(bytes.NewReader(payload)))
+	return nil
+}
+
+// decode decodes response by its content type,
+// binary response means that service accepts this encoding in requests too
+func (t *restTransport) decode(res *gentleman.Response, data interface{}) error {
+	resCodec := codec.ForContentType(res.Header.Get("Content-Type"))
+	if resCodec != codec.JSON {
+		t.mu.Lock()
+		t.codec = resCodec
+		t.mu.Unlock()
+	}
+	return resCodec.Unmarshal(res.Bytes(), data)
+}
+
+func (t *restTransport) switchType(infoType string, r *gentleman.Response) (interface{}, error) {
+	var res interface{}
+	switch infoType {
+	case "cards":
+		var card models.Card
+		if respErr := t.decode(r, &card); respErr != nil {
+			return nil, respErr
+		}
+		res = card
+	case "notes":
+		var note models.Note
+		if respErr := t.decode(r, &note); respErr != nil {
+			return nil, respErr
+		}
+		res = note
+	case "files":
+		var file models.File
+		if respErr := t.decode(r, &file); respErr != nil {
+			return nil, respErr
+		}
+		res = file
+	case "creds":
+		var cred models.Cred
+		if respErr := t.decode(r, &cred); respErr != nil {
+			return nil, respErr
+		}
+		res = cred
+	}
+	return res, nil
+}
+
+func (t *restTransport) useToken(token string) {
+	t.mu.Lock()
+	t.token = token
+	t.mu.Unlock()
+}
+
+// bearer returns token of user
+func (t *restTransport) bearer() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token
+}
+
+func (t *restTransport) register(userInfo *models.UserRegister) (*models.User, error) {
+	var user *models.User
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/register", t.baseURL))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, userInfo); bodyErr != nil {
+		return nil, bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &user); respErr != nil {
+		return nil, respErr
+	}
+	return user, nil
+}
+
+func (t *restTransport) login(userInfo *models.UserLogin) (*models.User, *models.TwoFactorChallenge, error) {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/login", t.baseURL))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, userInfo); bodyErr != nil {
+		return nil, nil, bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !res.Ok {
+		return nil, nil, responseError(res, nil)
+	}
+	if res.StatusCode == http.StatusAccepted {
+		var challenge *models.TwoFactorChallenge
+		if respErr := t.decode(res, &challenge); respErr != nil {
+			return nil, nil, respErr
+		}
+		return nil, challenge, nil
+	}
+	var user *models.User
+	if respErr := t.decode(res, &user); respErr != nil {
+		return nil, nil, respErr
+	}
+	return user, nil, nil
+}
+
+func (t *restTransport) loginTwoFactor(login *models.TwoFactorLogin) (*models.User, error) {
+	var user *models.User
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/login/2fa", t.baseURL))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, login); bodyErr != nil {
+		return nil, bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &user); respErr != nil {
+		return nil, respErr
+	}
+	return user, nil
+}
+
+func (t *restTransport) refresh(refreshToken string) (*models.Tokens, error) {
+	var tokens *models.Tokens
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/token/refresh", t.baseURL))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, models.TokenRefresh{RefreshToken: refreshToken}); bodyErr != nil {
+		return nil, bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &tokens); respErr != nil {
+		return nil, respErr
+	}
+	return tokens, nil
+}
+
+func (t *restTransport) logout() error {
+	return t.post("logout")
+}
+
+func (t *restTransport) logoutEverywhere() error {
+	return t.post("logout/all")
+}
+
+// post sends POST request without body
+func (t *restTransport) post(path string) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/%s", t.baseURL, path))
+	req.Method("POST")
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+	return nil
+}
+
+func (t *restTransport) me() (*models.User, error) {
+	var user *models.User
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me", t.baseURL))
+	req.Method("GET")
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &user); respErr != nil {
+		return nil, respErr
+	}
+	return user, nil
+}
+
+func (t *restTransport) setKey(userKey models.UserKey) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me/key", t.baseURL))
+	req.Method("PUT")
+	if bodyErr := t.setBody(req, userKey); bodyErr != nil {
+		return bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+	return nil
+}
+
+func (t *restTransport) sessions() ([]models.Session, error) {
+	var sessions []models.Session
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me/sessions", t.baseURL))
+	req.Method("GET")
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &sessions); respErr != nil {
+		return nil, respErr
+	}
+	return sessions, nil
+}
+
+func (t *restTransport) revokeSession(id uuid.UUID) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me/sessions/%s", t.baseURL, id))
+	req.Method("DELETE")
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+	return nil
+}
+
+func (t *restTransport) setupTwoFactor() (*models.TwoFactorSetup, error) {
+	var setup *models.TwoFactorSetup
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me/2fa", t.baseURL))
+	req.Method("POST")
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &setup); respErr != nil {
+		return nil, respErr
+	}
+	return setup, nil
+}
+
+func (t *restTransport) confirmTwoFactor(code string) (*models.RecoveryCodes, error) {
+	var codes *models.RecoveryCodes
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me/2fa/confirm", t.baseURL))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, models.TwoFactorCode{Code: code}); bodyErr != nil {
+		return nil, bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &codes); respErr != nil {
+		return nil, respErr
+	}
+	return codes, nil
+}
+
+func (t *restTransport) disableTwoFactor(code string) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me/2fa/disable", t.baseURL))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, models.TwoFactorCode{Code: code}); bodyErr != nil {
+		return bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+	return nil
+}
+
+func (t *restTransport) changePassword(change *models.PasswordChange) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me/password", t.baseURL))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, change); bodyErr != nil {
+		return bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, passwordErrors)
+	}
+	return nil
+}
+
+func (t *restTransport) deleteAccount(deletion *models.AccountDeletion) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/users/me", t.baseURL))
+	req.Method("DELETE")
+	if bodyErr := t.setBody(req, deletion); bodyErr != nil {
+		return bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, passwordErrors)
+	}
+	return nil
+}
+
+func (t *restTransport) verifyEmail(verification *models.EmailVerification) error {
+	return t.postBody("email/verify", verification)
+}
+
+func (t *restTransport) resendVerification() error {
+	return t.post("users/me/email/verify")
+}
+
+func (t *restTransport) forgotPassword(forgot *models.PasswordForgot) error {
+	return t.postBody("password/forgot", forgot)
+}
+
+func (t *restTransport) resetPassword(reset *models.PasswordReset) error {
+	return t.postBody("password/reset", reset)
+}
+
+func (t *restTransport) startEnrollment(newEnrollment *models.NewEnrollment) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/enrollments", t.baseURL))
+	req.Method("POST")
+	idempotent(req)
+	if bodyErr := t.setBody(req, newEnrollment); bodyErr != nil {
+		return nil, bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	if respErr := t.decode(res, &enrollment); respErr != nil {
+		return nil, respErr
+	}
+	return &enrollment, nil
+}
+
+func (t *restTransport) enrollment(code string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/enrollments/%s", t.baseURL, code))
+	req.Method("GET")
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, enrollmentErrors)
+	}
+	if respErr := t.decode(res, &enrollment); respErr != nil {
+		return nil, respErr
+	}
+	return &enrollment, nil
+}
+
+func (t *restTransport) approveEnrollment(code string, approve *models.EnrollmentApprove) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/enrollments/%s", t.baseURL, code))
+	req.Method("PUT")
+	if bodyErr := t.setBody(req, approve); bodyErr != nil {
+		return bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, enrollmentErrors)
+	}
+	return nil
+}
+
+func (t *restTransport) deleteEnrollment(code string) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/enrollments/%s", t.baseURL, code))
+	req.Method("DELETE")
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, enrollmentErrors)
+	}
+	return nil
+}
+
+// postBody sends POST request with body to path of API
+func (t *restTransport) postBody(path string, body interface{}) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/%s", t.baseURL, path))
+	req.Method("POST")
+	if bodyErr := t.setBody(req, body); bodyErr != nil {
+		return bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+	return nil
+}
+
+func (t *restTransport) list(infoType string, elems interface{}) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/info/%s", t.baseURL, infoType))
+	req.Method("GET")
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+	if res.StatusCode == 204 {
+		return nil
+	}
+	return t.decode(res, elems)
+}
+
+func (t *restTransport) get(infoType string, id uuid.UUID) (interface{}, error) {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/info/%s/%s", t.baseURL, infoType, id))
+	req.Method("GET")
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	return t.switchType(infoType, res)
+}
+
+func (t *restTransport) add(infoType string, elem interface{}) (interface{}, error) {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/info/%s", t.baseURL, infoType))
+	req.Method("POST")
+	if file, ok := elem.(*models.NewFile); ok {
+		req.Use(query.Set("id", file.ID.String()))
+		req.Use(query.Set("signature", file.Signature))
+		req.Use(query.Set("title", file.Title))
+		req.Use(query.Set("notes", file.Notes))
+		req.Use(query.Set("filename", file.FileName))
+		f := io.NopCloser(bytes.NewBuffer(file.File))
+		req.Use(body.Reader(f))
+	} else if bodyErr := t.setBody(req, elem); bodyErr != nil {
+		return nil, bodyErr
+	}
+	return t.sendElement(infoType, req)
+}
+
+func (t *restTransport) edit(infoType string, elem interface{}, id uuid.UUID) (interface{}, error) {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/info/%s/%s", t.baseURL, infoType, id))
+	req.Method("PATCH")
+	if file, ok := elem.(*models.NewFile); ok {
+		req.Use(query.Set("version", strconv.Itoa(file.Version)))
+		req.Use(query.Set("signature", file.Signature))
+		req.Use(query.Set("title", file.Title))
+		req.Use(query.Set("filename", file.FileName))
+		req.Use(query.Set("notes", file.Notes))
+		f := io.NopCloser(bytes.NewBuffer(file.File))
+		req.Use(body.Reader(f))
+	} else if bodyErr := t.setBody(req, elem); bodyErr != nil {
+		return nil, bodyErr
+	}
+	return t.sendElement(infoType, req)
+}
+
+// idempotent sets new idempotency key on request, retries of request keep it
+func idempotent(req *gentleman.Request) {
+	req.SetHeader(idempotencyHeader, uuid.New().String())
+}
+
+// sendElement sends added or changed element, returns element saved in service.
+// Request is sent with idempotency key, so retries dont write element twice
+func (t *restTransport) sendElement(infoType string, req *gentleman.Request) (interface{}, error) {
+	idempotent(req)
+	res, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ok {
+		return nil, responseError(res, nil)
+	}
+	return t.switchType(infoType, res)
+}
+
+func (t *restTransport) remove(infoType string, id uuid.UUID) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/info/%s/%s", t.baseURL, infoType, id))
+	req.Method("DELETE")
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+
+	if res.StatusCode != 200 {
+		return errors.New("something went wrong")
+	}
+	return nil
+}
+
+func (t *restTransport) replaceVault(vault *models.Vault) error {
+	req := t.client.Request()
+	req.URL(fmt.Sprintf("%s/info/vault", t.baseURL))
+	req.Method("PUT")
+	if bodyErr := t.setBody(req, vault); bodyErr != nil {
+		return bodyErr
+	}
+	res, err := req.Send()
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return responseError(res, nil)
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/crypto"
+	"AlexSarva/GophKeeper/crypto/cryptoblock"
+	"AlexSarva/GophKeeper/models"
+	"AlexSarva/GophKeeper/passhash"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	rotateFolder    = "rotate"
+	archiveFolder   = "archive"
+	rotateStateFile = "state.json"
+)
+
+var (
+	ErrRotateSecret  = errors.New("new secret differs from the secret of interrupted rotation")
+	ErrRotateDecrypt = errors.New("element can't be decrypted with old or new key")
+)
+
+// rotateStage stage of keys rotation
+type rotateStage string
+
+const (
+	stageGenerated rotateStage = "generated"
+	stageUploaded  rotateStage = "uploaded"
+	stageArchived  rotateStage = "archived"
+)
+
+// rotateState journal of keys rotation, it allows to resume interrupted rotation.
+// New secret is kept only as salted Argon2id hash, it is checked when rotation is resumed
+type rotateState struct {
+	Stage      rotateStage `json:"stage"`
+	Started    time.Time   `json:"started"`
+	SecretHash string      `json:"secret_hash"`
+}
+
+// hashRotateSecret returns salted hash of new secret for journal of rotation
+func hashRotateSecret(newSecret string) (string, error) {
+	hasher, hasherErr := passhash.New(passhash.DefaultParams)
+	if hasherErr != nil {
+		return "", hasherErr
+	}
+	return hasher.Hash(newSecret)
+}
+
+// checkRotateSecret returns ErrRotateSecret if new secret differs from the secret of interrupted rotation
+func checkRotateSecret(newSecret, secretHash string) error {
+	hasher, hasherErr := passhash.New(passhash.DefaultParams)
+	if hasherErr != nil {
+		return hasherErr
+	}
+	ok, _, verifyErr := hasher.Verify(newSecret, secretHash)
+	if verifyErr != nil {
+		return verifyErr
+	}
+	if !ok {
+		return ErrRotateSecret
+	}
+	return nil
+}
+
+// readRotateState reads journal of interrupted rotation, returns empty state if rotation wasn't started
+func readRotateState(rotatePath string) (*rotateState, error) {
+	var state rotateState
+	stateBytes, readErr := os.ReadFile(filepath.Join(rotatePath, rotateStateFile))
+	if readErr != nil {
+		if errors.Is(readErr, os.ErrNotExist) {
+			return &state, nil
+		}
+		return nil, readErr
+	}
+	if unmarshalErr := json.Unmarshal(stateBytes, &state); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return &state, nil
+}
+
+// save writes journal of rotation
+func (s *rotateState) save(rotatePath string) error {
+	stateBytes, marshalErr := json.Marshal(s)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	return os.WriteFile(filepath.Join(rotatePath, rotateStateFile), stateBytes, 0600)
+}
+
+// RotateKeys generates new keys, re-encrypts all user information with them
+// and replaces it in service in one transaction, old keys are moved to archive folder.
+// newSecret used for files encryption, it could be equal to the current secret.
+// Keys are generated for the algorithm from client config, so rotation also migrates
+// all elements encrypted by other algorithm.
+// If rotation was interrupted, the next call resumes it from the last finished stage.
+func (c *Client) RotateKeys(newSecret string) error {
+	rotatePath := filepath.Join(c.keysPath, rotateFolder)
+	state, stateErr := readRotateState(rotatePath)
+	if stateErr != nil {
+		return stateErr
+	}
+
+	if state.Stage != "" {
+		if checkErr := checkRotateSecret(newSecret, state.SecretHash); checkErr != nil {
+			return checkErr
+		}
+	}
+
+	newSymCrypto := cryptoblock.InitAEADCrypto(newSecret)
+
+	if state.Stage == "" || state.Stage == stageGenerated {
+		// keys will be generated only once, interrupted rotation uses existing ones
+		newCryptorizer, newCryptorizerErr := crypto.InitCryptorizer(rotatePath, c.keysSize, c.algorithm)
+		if newCryptorizerErr != nil {
+			return newCryptorizerErr
+		}
+		if state.Stage == "" {
+			state.Stage = stageGenerated
+			state.Started = time.Now()
+			secretHash, hashErr := hashRotateSecret(newSecret)
+			if hashErr != nil {
+				return hashErr
+			}
+			state.SecretHash = secretHash
+			if saveErr := state.save(rotatePath); saveErr != nil {
+				return saveErr
+			}
+		}
+
+		vault, vaultErr := c.reencryptVault(newCryptorizer, newSymCrypto)
+		if vaultErr != nil {
+			return vaultErr
+		}
+		if replaceErr := c.replaceVault(vault); replaceErr != nil {
+			return replaceErr
+		}
+		if registerErr := c.registerRotatedKey(newCryptorizer); registerErr != nil {
+			return registerErr
+		}
+
+		state.Stage = stageUploaded
+		if saveErr := state.save(rotatePath); saveErr != nil {
+			return saveErr
+		}
+	}
+
+	archivePath := filepath.Join(c.keysPath, archiveFolder, state.Started.Format("20060102T150405"))
+	if state.Stage == stageUploaded {
+		if archiveErr := archiveKeys(c.keysPath, archivePath); archiveErr != nil {
+			return archiveErr
+		}
+		state.Stage = stageArchived
+		if saveErr := state.save(rotatePath); saveErr != nil {
+			return saveErr
+		}
+	}
+
+	if installErr := installKeys(c.keysPath, rotatePath); installErr != nil {
+		return installErr
+	}
+
+	cryptorizer, cryptorizerErr := crypto.InitCryptorizer(c.keysPath, c.keysSize, c.algorithm)
+	if cryptorizerErr != nil {
+		return cryptorizerErr
+	}
+	c.cryptorizer = cryptorizer
+	c.symCrypto = newSymCrypto
+	c.keyChecked = false
+	if indexErr := c.index.setSymCrypto(newSymCrypto); indexErr != nil {
+		log.Println(indexErr)
+	}
+
+	return os.RemoveAll(rotatePath)
+}
+
+// registerRotatedKey registers fingerprint of new keys on account instead of the current one,
+// fingerprint of account is loaded if it wasn't checked while login
+func (c *Client) registerRotatedKey(newCryptorizer *crypto.Cryptorizer) error {
+	if !c.keyChecked {
+		user, userErr := c.Me()
+		if userErr != nil {
+			return userErr
+		}
+		c.accountKey = user.KeyFingerprint
+	}
+	fingerprint, fingerprintErr := newCryptorizer.Fingerprint()
+	if fingerprintErr != nil {
+		return fingerprintErr
+	}
+	if registerErr := c.registerKey(fingerprint, c.accountKey); registerErr != nil {
+		return registerErr
+	}
+	c.accountKey = fingerprint
+	return nil
+}
+
+// reencryptVault loads all elements of user, re-encrypts them with new keys
+// and signs them with the next version
+func (c *Client) reencryptVault(newCryptorizer *crypto.Cryptorizer, newSymCrypto *cryptoblock.AEADCrypto) (*models.Vault, error) {
+	var vault models.Vault
+	if listErr := c.rawElementList("notes", &vault.Notes); listErr != nil {
+		return nil, listErr
+	}
+	if listErr := c.rawElementList("cards", &vault.Cards); listErr != nil {
+		return nil, listErr
+	}
+	if listErr := c.rawElementList("creds", &vault.Creds); listErr != nil {
+		return nil, listErr
+	}
+	if listErr := c.rawElementList("files", &vault.Files); listErr != nil {
+		return nil, listErr
+	}
+
+	for i := range vault.Notes {
+		note := &vault.Notes[i]
+		if reencryptErr := reencryptValues(c.cryptorizer, newCryptorizer, &note.Note); reencryptErr != nil {
+			return nil, reencryptErr
+		}
+		if reencryptErr := reencryptMeta(c.cryptorizer, newCryptorizer, &note.Title); reencryptErr != nil {
+			return nil, reencryptErr
+		}
+		note.Version++
+		if signErr := note.Sign(newCryptorizer); signErr != nil {
+			return nil, signErr
+		}
+	}
+	for i := range vault.Cards {
+		card := &vault.Cards[i]
+		if reencryptErr := reencryptValues(c.cryptorizer, newCryptorizer, &card.CardNumber, &card.CardOwner, &card.CardExp); reencryptErr != nil {
+			return nil, reencryptErr
+		}
+		if reencryptErr := reencryptMeta(c.cryptorizer, newCryptorizer, &card.Title, &card.Notes); reencryptErr != nil {
+			return nil, reencryptErr
+		}
+		card.Version++
+		if signErr := card.Sign(newCryptorizer); signErr != nil {
+			return nil, signErr
+		}
+	}
+	for i := range vault.Creds {
+		cred := &vault.Creds[i]
+		if reencryptErr := reencryptValues(c.cryptorizer, newCryptorizer, &cred.Login, &cred.Passwd); reencryptErr != nil {
+			return nil, reencryptErr
+		}
+		if reencryptErr := reencryptMeta(c.cryptorizer, newCryptorizer, &cred.Title, &cred.Notes); reencryptErr != nil {
+			return nil, reencryptErr
+		}
+		cred.Version++
+		if signErr := cred.Sign(newCryptorizer); signErr != nil {
+			return nil, signErr
+		}
+	}
+	for i := range vault.Files {
+		file := &vault.Files[i]
+		if reencryptErr := reencryptMeta(c.cryptorizer, newCryptorizer, &file.Title, &file.FileName, &file.Notes); reencryptErr != nil {
+			return nil, reencryptErr
+		}
+		content, decryptErr := c.symCrypto.Decrypt(file.File)
+		if decryptErr != nil {
+			// file could be already re-encrypted in interrupted rotation
+			if _, newDecryptErr := newSymCrypto.Decrypt(file.File); newDecryptErr != nil {
+				return nil, ErrRotateDecrypt
+			}
+		} else {
+			cryptContent, encryptErr := newSymCrypto.Encrypt(content)
+			if encryptErr != nil {
+				return nil, encryptErr
+			}
+			file.File = cryptContent
+		}
+		file.Version++
+		if signErr := file.Sign(newCryptorizer); signErr != nil {
+			return nil, signErr
+		}
+	}
+
+	return &vault, nil
+}
+
+// reencryptValues decrypts values with old keys and encrypts them with new keys
+func reencryptValues(oldCryptorizer, newCryptorizer *crypto.Cryptorizer, values ...*string) error {
+	for _, value := range values {
+		payload, decryptErr := oldCryptorizer.Decrypt(*value)
+		if decryptErr != nil {
+			// value could be already re-encrypted in interrupted rotation
+			if _, newDecryptErr := newCryptorizer.Decrypt(*value); newDecryptErr == nil {
+				continue
+			}
+			return ErrRotateDecrypt
+		}
+		cipher, encryptErr := newCryptorizer.Encrypt(payload)
+		if encryptErr != nil {
+			return encryptErr
+		}
+		*value = cipher
+	}
+	return nil
+}
+
+// reencryptMeta re-encrypts metadata values (titles, file names and notes) with new keys,
+// values that were saved before metadata encryption are encrypted as is
+func reencryptMeta(oldCryptorizer, newCryptorizer *crypto.Cryptorizer, values ...*string) error {
+	for _, value := range values {
+		if *value == "" {
+			continue
+		}
+		payload, decryptErr := oldCryptorizer.Decrypt(*value)
+		if decryptErr != nil {
+			if _, newDecryptErr := newCryptorizer.Decrypt(*value); newDecryptErr == nil {
+				continue
+			}
+			payload = *value
+		}
+		cipher, encryptErr := newCryptorizer.Encrypt(payload)
+		if encryptErr != nil {
+			return encryptErr
+		}
+		*value = cipher
+	}
+	return nil
+}
+
+// archiveKeys moves current keys of all algorithms to archive folder,
+// could be called again if it was interrupted
+func archiveKeys(keysPath, archivePath string) error {
+	if mkdirErr := os.MkdirAll(archivePath, 0700); mkdirErr != nil {
+		return mkdirErr
+	}
+	for _, keyFile := range crypto.KeyFiles() {
+		currentKey := filepath.Join(keysPath, keyFile)
+		if _, statErr := os.Stat(currentKey); statErr != nil {
+			continue
+		}
+		if renameErr := os.Rename(currentKey, filepath.Join(archivePath, keyFile)); renameErr != nil {
+			return renameErr
+		}
+	}
+	log.Printf("old keys are moved to %s", archivePath)
+	return nil
+}
+
+// installKeys moves new keys from rotation folder on place of archived keys,
+// could be called again if it was interrupted
+func installKeys(keysPath, rotatePath string) error {
+	entries, entriesErr := os.ReadDir(rotatePath)
+	if entriesErr != nil {
+		return entriesErr
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == rotateStateFile {
+			continue
+		}
+		if renameErr := os.Rename(filepath.Join(rotatePath, entry.Name()), filepath.Join(keysPath, entry.Name())); renameErr != nil {
+			return renameErr
+		}
+	}
+	return nil
+}
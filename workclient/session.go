@@ -0,0 +1,233 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// sessionTransport keeps tokens of user. Rejected access token is refreshed by refresh token
+// and the call is repeated once. Refresh tokens are single-use, so refreshes are serialized
+type sessionTransport struct {
+	transport
+	rest         *restTransport
+	mu           sync.Mutex
+	refreshToken string
+}
+
+func newSessionTransport(rest *restTransport, trans transport) *sessionTransport {
+	return &sessionTransport{transport: trans, rest: rest}
+}
+
+// useToken sets access token on transports, token is always used by REST transport
+// because events are available only by REST
+func (s *sessionTransport) useToken(bearer string) {
+	token := strings.Split(bearer, " ")
+	s.rest.useToken(token[len(token)-1])
+	if s.transport != transport(s.rest) {
+		s.transport.useToken(token[len(token)-1])
+	}
+}
+
+// useTokens sets pair of tokens issued after registration or login
+func (s *sessionTransport) useTokens(bearer, refreshToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.useToken(bearer)
+	s.refreshToken = refreshToken
+}
+
+// authorized runs call, if access token is rejected it is refreshed and call is repeated
+func (s *sessionTransport) authorized(call func() error) error {
+	rejected := s.rest.bearer()
+	callErr := call()
+	if !errors.Is(callErr, ErrToken) {
+		return callErr
+	}
+	if refreshErr := s.refresh(rejected); refreshErr != nil {
+		return callErr
+	}
+	return call()
+}
+
+// refresh exchanges refresh token for new pair of tokens,
+// token could be already refreshed by concurrent call
+func (s *sessionTransport) refresh(rejected string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rest.bearer() != rejected {
+		return nil
+	}
+	if s.refreshToken == "" {
+		return ErrToken
+	}
+	tokens, tokensErr := s.transport.refresh(s.refreshToken)
+	if tokensErr != nil {
+		// spent or revoked refresh token is useless, user should log in again
+		if errors.Is(tokensErr, ErrToken) {
+			s.refreshToken = ""
+		}
+		return tokensErr
+	}
+	s.refreshToken = tokens.RefreshToken
+	s.useToken(tokens.Token)
+	return nil
+}
+
+// endSession revokes tokens in service, tokens are forgotten even if service is unavailable
+func (s *sessionTransport) endSession(everywhere bool) error {
+	logoutErr := s.authorized(func() error {
+		if everywhere {
+			return s.transport.logoutEverywhere()
+		}
+		return s.transport.logout()
+	})
+	s.useTokens("", "")
+	return logoutErr
+}
+
+func (s *sessionTransport) me() (user *models.User, err error) {
+	err = s.authorized(func() error {
+		user, err = s.transport.me()
+		return err
+	})
+	return user, err
+}
+
+func (s *sessionTransport) setKey(userKey models.UserKey) error {
+	return s.authorized(func() error {
+		return s.transport.setKey(userKey)
+	})
+}
+
+func (s *sessionTransport) sessions() (sessions []models.Session, err error) {
+	err = s.authorized(func() error {
+		sessions, err = s.transport.sessions()
+		return err
+	})
+	return sessions, err
+}
+
+func (s *sessionTransport) revokeSession(id uuid.UUID) error {
+	return s.authorized(func() error {
+		return s.transport.revokeSession(id)
+	})
+}
+
+func (s *sessionTransport) setupTwoFactor() (setup *models.TwoFactorSetup, err error) {
+	err = s.authorized(func() error {
+		setup, err = s.transport.setupTwoFactor()
+		return err
+	})
+	return setup, err
+}
+
+func (s *sessionTransport) confirmTwoFactor(code string) (codes *models.RecoveryCodes, err error) {
+	err = s.authorized(func() error {
+		codes, err = s.transport.confirmTwoFactor(code)
+		return err
+	})
+	return codes, err
+}
+
+func (s *sessionTransport) disableTwoFactor(code string) error {
+	return s.authorized(func() error {
+		return s.transport.disableTwoFactor(code)
+	})
+}
+
+func (s *sessionTransport) changePassword(change *models.PasswordChange) error {
+	return s.authorized(func() error {
+		return s.transport.changePassword(change)
+	})
+}
+
+// deleteAccount deletes account in service, tokens of deleted user are forgotten
+func (s *sessionTransport) deleteAccount(deletion *models.AccountDeletion) error {
+	deleteErr := s.authorized(func() error {
+		return s.transport.deleteAccount(deletion)
+	})
+	if deleteErr == nil {
+		s.useTokens("", "")
+	}
+	return deleteErr
+}
+
+func (s *sessionTransport) resendVerification() error {
+	return s.authorized(func() error {
+		return s.transport.resendVerification()
+	})
+}
+
+func (s *sessionTransport) list(infoType string, elems interface{}) error {
+	return s.authorized(func() error {
+		return s.transport.list(infoType, elems)
+	})
+}
+
+func (s *sessionTransport) get(infoType string, id uuid.UUID) (elem interface{}, err error) {
+	err = s.authorized(func() error {
+		elem, err = s.transport.get(infoType, id)
+		return err
+	})
+	return elem, err
+}
+
+func (s *sessionTransport) add(infoType string, elem interface{}) (saved interface{}, err error) {
+	err = s.authorized(func() error {
+		saved, err = s.transport.add(infoType, elem)
+		return err
+	})
+	return saved, err
+}
+
+func (s *sessionTransport) edit(infoType string, elem interface{}, id uuid.UUID) (saved interface{}, err error) {
+	err = s.authorized(func() error {
+		saved, err = s.transport.edit(infoType, elem, id)
+		return err
+	})
+	return saved, err
+}
+
+func (s *sessionTransport) remove(infoType string, id uuid.UUID) error {
+	return s.authorized(func() error {
+		return s.transport.remove(infoType, id)
+	})
+}
+
+func (s *sessionTransport) replaceVault(vault *models.Vault) error {
+	return s.authorized(func() error {
+		return s.transport.replaceVault(vault)
+	})
+}
+
+func (s *sessionTransport) startEnrollment(newEnrollment *models.NewEnrollment) (enrollment *models.Enrollment, err error) {
+	err = s.authorized(func() error {
+		enrollment, err = s.transport.startEnrollment(newEnrollment)
+		return err
+	})
+	return enrollment, err
+}
+
+func (s *sessionTransport) enrollment(code string) (enrollment *models.Enrollment, err error) {
+	err = s.authorized(func() error {
+		enrollment, err = s.transport.enrollment(code)
+		return err
+	})
+	return enrollment, err
+}
+
+func (s *sessionTransport) approveEnrollment(code string, approve *models.EnrollmentApprove) error {
+	return s.authorized(func() error {
+		return s.transport.approveEnrollment(code, approve)
+	})
+}
+
+func (s *sessionTransport) deleteEnrollment(code string) error {
+	return s.authorized(func() error {
+		return s.transport.deleteEnrollment(code)
+	})
+}
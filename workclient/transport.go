@@ -0,0 +1,85 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"errors"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// Transports of client
+const (
+	TransportREST = "rest"
+	TransportGRPC = "grpc"
+)
+
+var ErrTransport = errors.New("unknown transport, use rest or grpc")
+
+// transport sends requests of client to service. Elements are sent and received encrypted,
+// list fills elems with pointer to slice of elements of selected type
+type transport interface {
+	useToken(token string)
+	register(userInfo *models.UserRegister) (*models.User, error)
+	login(userInfo *models.UserLogin) (*models.User, *models.TwoFactorChallenge, error)
+	loginTwoFactor(login *models.TwoFactorLogin) (*models.User, error)
+	refresh(refreshToken string) (*models.Tokens, error)
+	logout() error
+	logoutEverywhere() error
+	me() (*models.User, error)
+	setKey(userKey models.UserKey) error
+	sessions() ([]models.Session, error)
+	revokeSession(id uuid.UUID) error
+	setupTwoFactor() (*models.TwoFactorSetup, error)
+	confirmTwoFactor(code string) (*models.RecoveryCodes, error)
+	disableTwoFactor(code string) error
+	changePassword(change *models.PasswordChange) error
+	deleteAccount(deletion *models.AccountDeletion) error
+	verifyEmail(verification *models.EmailVerification) error
+	resendVerification() error
+	forgotPassword(forgot *models.PasswordForgot) error
+	resetPassword(reset *models.PasswordReset) error
+	startEnrollment(newEnrollment *models.NewEnrollment) (*models.Enrollment, error)
+	enrollment(code string) (*models.Enrollment, error)
+	approveEnrollment(code string, approve *models.EnrollmentApprove) error
+	deleteEnrollment(code string) error
+	list(infoType string, elems interface{}) error
+	get(infoType string, id uuid.UUID) (interface{}, error)
+	add(infoType string, elem interface{}) (interface{}, error)
+	edit(infoType string, elem interface{}, id uuid.UUID) (interface{}, error)
+	remove(infoType string, id uuid.UUID) error
+	replaceVault(vault *models.Vault) error
+}
+
+// newTransport returns transport selected in config, events are available only by REST,
+// so REST transport is always created
+func newTransport(cfg *models.GUIConfig) (*restTransport, transport, error) {
+	rest := newRESTTransport(cfg.ServerAddress)
+	device := deviceName(cfg.DeviceName)
+	rest.describe(device, cfg.Version)
+	switch cfg.Transport {
+	case "", TransportREST:
+		return rest, rest, nil
+	case TransportGRPC:
+		grpcTrans, grpcErr := newGRPCTransport(cfg.GRPCAddress, cfg.GRPCTLS)
+		if grpcErr != nil {
+			return nil, nil, grpcErr
+		}
+		grpcTrans.describe(device, cfg.Version)
+		return rest, grpcTrans, nil
+	default:
+		return nil, nil, ErrTransport
+	}
+}
+
+// deviceName returns name of device that is shown in sessions of user, host name is used by default
+func deviceName(name string) string {
+	if name != "" {
+		return name
+	}
+	host, hostErr := os.Hostname()
+	if hostErr != nil {
+		return ""
+	}
+	return host
+}
@@ -0,0 +1,54 @@
+package workclient
+
+import (
+	"AlexSarva/GophKeeper/models"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTwoFactorRequired password is correct, login is completed by code of second factor
+	ErrTwoFactorRequired = errors.New("two-factor code is required")
+	// ErrTwoFactorCode code of authenticator app or recovery code is invalid or already used
+	ErrTwoFactorCode = errors.New("two-factor code is invalid or already used")
+	// ErrChallenge challenge of login is expired, user should log in again
+	ErrChallenge = errors.New("login is expired, enter email and password again")
+	// ErrTwoFactorState two-factor authentication is already enabled or not set up
+	ErrTwoFactorState = errors.New("two-factor authentication is already enabled or not set up")
+)
+
+// LoginTwoFactor completes login that returned ErrTwoFactorRequired by code of authenticator app or recovery code
+func (c *Client) LoginTwoFactor(code string) (*models.User, error) {
+	if c.challenge == nil || time.Now().After(c.challenge.Expires) {
+		c.challenge = nil
+		return nil, ErrChallenge
+	}
+	user, userErr := c.transport.loginTwoFactor(&models.TwoFactorLogin{Challenge: c.challenge.Challenge, Code: code})
+	if userErr != nil {
+		if errors.Is(userErr, ErrChallenge) {
+			c.challenge = nil
+		}
+		return nil, userErr
+	}
+	c.challenge = nil
+
+	c.startSession(user)
+	return user, nil
+}
+
+// SetupTwoFactor generates TOTP secret, authenticator app adds account by its URI.
+// Two-factor authentication is enabled by ConfirmTwoFactor
+func (c *Client) SetupTwoFactor() (*models.TwoFactorSetup, error) {
+	return c.transport.setupTwoFactor()
+}
+
+// ConfirmTwoFactor enables two-factor authentication by code of authenticator app,
+// returns recovery codes that service shows only once
+func (c *Client) ConfirmTwoFactor(code string) (*models.RecoveryCodes, error) {
+	return c.transport.confirmTwoFactor(code)
+}
+
+// DisableTwoFactor disables two-factor authentication by code of authenticator app or recovery code
+func (c *Client) DisableTwoFactor(code string) error {
+	return c.transport.disableTwoFactor(code)
+}
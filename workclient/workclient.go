@@ -4,21 +4,10 @@ import (
 	"AlexSarva/GophKeeper/crypto"
 	"AlexSarva/GophKeeper/crypto/cryptoblock"
 	"AlexSarva/GophKeeper/models"
-	"bytes"
 	"errors"
-	"fmt"
-	"io"
-	"strings"
-	"time"
+	"log"
 
 	"github.com/google/uuid"
-	"gopkg.in/eapache/go-resiliency.v1/retrier"
-	retry "gopkg.in/h2non/gentleman-retry.v2"
-	"gopkg.in/h2non/gentleman.v2"
-	"gopkg.in/h2non/gentleman.v2/plugins/auth"
-	"gopkg.in/h2non/gentleman.v2/plugins/body"
-	"gopkg.in/h2non/gentleman.v2/plugins/query"
-	"gopkg.in/h2non/gentleman.v2/plugins/timeout"
 )
 
 var (
@@ -29,18 +18,21 @@ var (
 	ErrReqFormat      = errors.New("invalid request format")
 	ErrNoData         = errors.New("no info in DB")
 	ErrTokenExpired   = errors.New("unauthorized: token is expired")
+	ErrConflict       = errors.New("element was changed by another client or doesnt exist, reload it and try again")
 )
 
-func (c *Client) switchTypesList(infoType string, r *gentleman.Response) (interface{}, error) {
+func (c *Client) switchTypesList(infoType string) (interface{}, error) {
 	var res interface{}
 	switch infoType {
 	case "cards":
 		var cards []models.Card
-		if respErr := r.JSON(&cards); respErr != nil {
+		if respErr := c.rawElementList(infoType, &cards); respErr != nil {
 			return nil, respErr
 		}
 		var decrCards []models.Card
 		for _, card := range cards {
+			card.Verify(c.cryptorizer)
+			card.Integrity = c.checkRollback(card.ID, card.Version, card.Integrity)
 			if decryptErr := card.Decrypt(c.cryptorizer); decryptErr != nil {
 				return nil, decryptErr
 			}
@@ -49,11 +41,13 @@ func (c *Client) switchTypesList(infoType string, r *gentleman.Response) (interf
 		res = decrCards
 	case "notes":
 		var notes []models.Note
-		if respErr := r.JSON(&notes); respErr != nil {
+		if respErr := c.rawElementList(infoType, &notes); respErr != nil {
 			return nil, respErr
 		}
 		var descrNotes []models.Note
 		for _, note := range notes {
+			note.Verify(c.cryptorizer)
+			note.Integrity = c.checkRollback(note.ID, note.Version, note.Integrity)
 			if decryptErr := note.Decrypt(c.cryptorizer); decryptErr != nil {
 				return nil, decryptErr
 			}
@@ -62,12 +56,14 @@ func (c *Client) switchTypesList(infoType string, r *gentleman.Response) (interf
 		res = descrNotes
 	case "files":
 		var files []models.File
-		if respErr := r.JSON(&files); respErr != nil {
+		if respErr := c.rawElementList(infoType, &files); respErr != nil {
 			return nil, respErr
 		}
 		var descrFiles []models.File
 		for _, file := range files {
-			if symDecrErr := file.Decrypt(c.symCrypto); symDecrErr != nil {
+			file.Verify(c.cryptorizer)
+			file.Integrity = c.checkRollback(file.ID, file.Version, file.Integrity)
+			if symDecrErr := file.Decrypt(c.cryptorizer, c.symCrypto); symDecrErr != nil {
 				return nil, symDecrErr
 			}
 			descrFiles = append(descrFiles, file)
@@ -75,394 +71,358 @@ func (c *Client) switchTypesList(infoType string, r *gentleman.Response) (interf
 		res = descrFiles
 	case "creds":
 		var creds []models.Cred
-		if respErr := r.JSON(&creds); respErr != nil {
+		if respErr := c.rawElementList(infoType, &creds); respErr != nil {
 			return nil, respErr
 		}
 		var descrCreds []models.Cred
 		for _, cred := range creds {
+			cred.Verify(c.cryptorizer)
+			cred.Integrity = c.checkRollback(cred.ID, cred.Version, cred.Integrity)
 			if decryptErr := cred.Decrypt(c.cryptorizer); decryptErr != nil {
 				return nil, decryptErr
 			}
 			descrCreds = append(descrCreds, cred)
 		}
 		res = descrCreds
-	}
-	return res, nil
-}
-
-func switchType(infoType string, r *gentleman.Response) (interface{}, error) {
-	var res interface{}
-	switch infoType {
-	case "cards":
-		var card models.Card
-		if respErr := r.JSON(&card); respErr != nil {
-			return nil, respErr
-		}
-		res = card
-	case "notes":
-		var note models.Note
-		if respErr := r.JSON(&note); respErr != nil {
-			return nil, respErr
-		}
-		res = note
-	case "files":
-		var file models.File
-		if respErr := r.JSON(&file); respErr != nil {
-			return nil, respErr
-		}
-		res = file
-	case "creds":
-		var cred models.Cred
-		if respErr := r.JSON(&cred); respErr != nil {
-			return nil, respErr
-		}
-		res = cred
+	default:
+		return nil, errors.New("wrong info type parameter")
 	}
 	return res, nil
 }
 
 // Client custom type of work client
 type Client struct {
-	client      *gentleman.Client
-	baseURL     string
+	rest        *restTransport
+	transport   *sessionTransport
 	cryptorizer *crypto.Cryptorizer
-	symCrypto   *cryptoblock.AEADCrypto
+	symCrypto   cryptoblock.SymCrypto
+	keysPath    string
+	keysSize    int
+	algorithm   string
+	index       *Index
+	accountKey  string
+	keyChecked  bool
+	keyErr      error
+	challenge   *models.TwoFactorChallenge
+}
+
+// InitServiceClient initialize client without keys by transport selected in config, it is used by new device
+// before enrollment and could call only account and enrollment methods
+func InitServiceClient(cfg *models.GUIConfig) (*Client, error) {
+	rest, trans, transErr := newTransport(cfg)
+	if transErr != nil {
+		return nil, transErr
+	}
+	return &Client{
+		rest:      rest,
+		transport: newSessionTransport(rest, trans),
+	}, nil
 }
 
-// InitClient initialize new client for work with service
+// InitClient initialize new client for work with service by transport selected in config,
+// if socket of keys agent is set keys and secret are used through agent
 func InitClient(cfg *models.GUIConfig) (*Client, error) {
-	cli := gentleman.New()
-	cli.Use(timeout.Request(5 * time.Second))
-	cli.Use(retry.New(retrier.New(retrier.ExponentialBackoff(5, 100*time.Millisecond), nil)))
-	cryptorizer, cryptorizerErr := crypto.InitCryptorizer(cfg.KeysPath, cfg.KeysSize)
+	if cfg.Agent != "" {
+		return initAgentClient(cfg)
+	}
+	rest, trans, transErr := newTransport(cfg)
+	if transErr != nil {
+		return nil, transErr
+	}
+	cryptorizer, cryptorizerErr := crypto.InitCryptorizer(cfg.KeysPath, cfg.KeysSize, cfg.Algorithm)
 	if cryptorizerErr != nil {
 		return nil, cryptorizerErr
 	}
 	symCrypto := cryptoblock.InitAEADCrypto(cfg.Secret)
 	return &Client{
-		client:      cli,
-		baseURL:     cfg.ServerAddress,
+		rest:        rest,
+		transport:   newSessionTransport(rest, trans),
 		cryptorizer: cryptorizer,
 		symCrypto:   symCrypto,
+		keysPath:    cfg.KeysPath,
+		keysSize:    cfg.KeysSize,
+		algorithm:   cfg.Algorithm,
+		index:       loadIndex(cfg.KeysPath, symCrypto),
 	}, nil
 }
 
 // UseToken method uses to add bearer token to client
+// token is always used by REST transport because events are available only by REST
 func (c *Client) UseToken(bearer string) *Client {
-	token := strings.Split(bearer, " ")
-	c.client.Use(auth.Bearer(token[len(token)-1]))
+	c.transport.useToken(bearer)
 	return c
 }
 
 // Register provides registration in service
 func (c *Client) Register(userInfo *models.UserRegister) (*models.User, error) {
-	var user *models.User
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/register", c.baseURL))
-	req.Method("POST")
-	req.SetHeader("Content-Type", "application/json")
-	req.Use(body.JSON(userInfo))
-	res, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 409 {
-			return nil, ErrUserExist
-		}
-		if res.StatusCode == 417 {
-			return nil, errors.New(res.String())
-		}
-		if res.StatusCode == 500 {
-			return nil, ErrInternalServer
-		}
-		return nil, ErrReqFormat
-	}
-	if respErr := res.JSON(&user); respErr != nil {
-		return nil, respErr
+	user, userErr := c.transport.register(userInfo)
+	if userErr != nil {
+		return nil, userErr
 	}
 
-	if res.Ok {
-		c.UseToken(user.Token)
+	c.transport.useTokens(user.Token, user.RefreshToken)
+	if keyErr := c.checkKey(user); keyErr != nil {
+		log.Println(keyErr)
 	}
 
 	return user, nil
 }
 
-// Login provides log in service
+// Login provides log in service. If user enabled two-factor authentication ErrTwoFactorRequired is returned,
+// login is completed by LoginTwoFactor
 func (c *Client) Login(userInfo *models.UserLogin) (*models.User, error) {
-	var user *models.User
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/login", c.baseURL))
-	req.Method("POST")
-	req.SetHeader("Content-Type", "application/json")
-	req.Use(body.JSON(userInfo))
-	res, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 401 {
-			return nil, ErrCreds
-		}
-		if res.StatusCode == 403 {
-			return nil, ErrTokenExpired
-		}
-		if res.StatusCode == 500 {
-			return nil, ErrInternalServer
-		}
-		return nil, ErrReqFormat
+	user, challenge, userErr := c.transport.login(userInfo)
+	if userErr != nil {
+		return nil, userErr
 	}
-	if respErr := res.JSON(&user); respErr != nil {
-		return nil, respErr
+	c.challenge = challenge
+	if challenge != nil {
+		return nil, ErrTwoFactorRequired
 	}
 
-	if res.Ok {
-		c.UseToken(user.Token)
+	c.startSession(user)
+	return user, nil
+}
+
+// startSession uses tokens of logged in user
+func (c *Client) startSession(user *models.User) {
+	c.transport.useTokens(user.Token, user.RefreshToken)
+	if keyErr := c.checkKey(user); keyErr != nil {
+		log.Println(keyErr)
 	}
+}
 
-	return user, nil
+// Logout revokes tokens of this login in service
+func (c *Client) Logout() error {
+	return c.transport.endSession(false)
+}
+
+// LogoutEverywhere revokes all tokens of user, so every device has to log in again
+func (c *Client) LogoutEverywhere() error {
+	return c.transport.endSession(true)
 }
 
 // Me provides get information about user
 func (c *Client) Me() (*models.User, error) {
-	var user *models.User
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/users/me", c.baseURL))
-	req.Method("GET")
-	res, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 401 {
-			return nil, ErrToken
-		}
-		if res.StatusCode == 500 {
-			return nil, ErrInternalServer
-		}
-		return nil, ErrReqFormat
-	}
-	if respErr := res.JSON(&user); respErr != nil {
-		return nil, respErr
-	}
+	return c.transport.me()
+}
 
-	return user, nil
+// Sessions returns devices where user is logged in
+func (c *Client) Sessions() ([]models.Session, error) {
+	return c.transport.sessions()
 }
 
-// ElementList returns list of elements of selected type
-func (c *Client) ElementList(infoType string) (interface{}, error) {
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/info/%s", c.baseURL, infoType))
-	req.Method("GET")
-	res, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 401 {
-			return nil, ErrToken
-		}
-		if res.StatusCode == 500 {
-			return nil, ErrInternalServer
-		}
-		return nil, ErrReqFormat
-	}
+// RevokeSession logs out device of session, revoke of current session logs out this client
+func (c *Client) RevokeSession(id uuid.UUID) error {
+	return c.transport.revokeSession(id)
+}
 
-	result, resultErr := c.switchTypesList(infoType, res)
+// ElementList returns list of decrypted elements of selected type,
+// client-side index is updated by their metadata
+func (c *Client) ElementList(infoType string) (interface{}, error) {
+	result, resultErr := c.switchTypesList(infoType)
 	if resultErr != nil {
 		return nil, resultErr
 	}
 
+	entries, entriesErr := indexEntries(infoType, result)
+	if entriesErr != nil {
+		return nil, entriesErr
+	}
+	if indexErr := c.index.replace(infoType, entries); indexErr != nil {
+		log.Println(indexErr)
+	}
+
 	return result, nil
 }
 
+// rawElementList loads list of elements of selected type in elems without decryption
+func (c *Client) rawElementList(infoType string, elems interface{}) error {
+	return c.transport.list(infoType, elems)
+}
+
 // Element returns element of selected type and id
 func (c *Client) Element(infoType string, id uuid.UUID) (interface{}, error) {
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/info/%s/%s", c.baseURL, infoType, id))
-	req.Method("GET")
-	res, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 401 {
-			return nil, ErrToken
-		}
-		if res.StatusCode == 409 {
-			return nil, ErrNoData
-		}
-		if res.StatusCode == 500 {
-			return nil, ErrInternalServer
-		}
-		return nil, ErrReqFormat
-	}
-
-	result, resultErr := switchType(infoType, res)
+	result, resultErr := c.transport.get(infoType, id)
 	if resultErr != nil {
 		return nil, resultErr
 	}
 
-	return result, nil
+	return c.verifyElement(result), nil
 }
 
 // AddElement provides add element of selected type in service
 func (c *Client) AddElement(infoType string, elem interface{}) (interface{}, error) {
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/info/%s", c.baseURL, infoType))
-	req.Method("POST")
-
+	if writeErr := c.writable(); writeErr != nil {
+		return nil, writeErr
+	}
+	var entry IndexEntry
 	switch infoType {
 	case "cards":
 		card := elem.(*models.NewCard)
 		if checkErr := card.CheckValid(); checkErr != nil {
 			return nil, checkErr
 		}
+		entry.Title = card.Title
+		card.ID, card.Version = uuid.New(), 1
 		if cryptoErr := card.Encrypt(c.cryptorizer); cryptoErr != nil {
 			return nil, cryptoErr
 		}
-		req.Use(body.JSON(card))
+		if signErr := card.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
+		}
 	case "creds":
 		cred := elem.(*models.NewCred)
+		entry.Title = cred.Title
+		cred.ID, cred.Version = uuid.New(), 1
 		if cryptoErr := cred.Encrypt(c.cryptorizer); cryptoErr != nil {
 			return nil, cryptoErr
 		}
-		req.Use(body.JSON(cred))
+		if signErr := cred.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
+		}
 	case "notes":
 		note := elem.(*models.NewNote)
+		entry.Title = note.Title
+		note.ID, note.Version = uuid.New(), 1
 		if cryptoErr := note.Encrypt(c.cryptorizer); cryptoErr != nil {
 			return nil, cryptoErr
 		}
-		req.Use(body.JSON(note))
+		if signErr := note.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
+		}
 	case "files":
 		file := elem.(*models.NewFile)
-		file.Encrypt(c.symCrypto)
-		req.Use(query.Set("title", file.Title))
-		req.Use(query.Set("notes", file.Notes))
-		req.Use(query.Set("filename", file.FileName))
-		f := io.NopCloser(bytes.NewBuffer(file.File))
-		req.Use(body.Reader(f))
-	default:
-		return nil, errors.New("wrong info type parameter")
-	}
-
-	res, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 400 {
-			return nil, ErrReqFormat
-		}
-		if res.StatusCode == 401 {
-			return nil, ErrToken
+		entry.Title = file.Title
+		entry.FileName = file.FileName
+		file.ID, file.Version = uuid.New(), 1
+		if cryptoErr := file.Encrypt(c.cryptorizer, c.symCrypto); cryptoErr != nil {
+			return nil, cryptoErr
 		}
-		if res.StatusCode == 500 {
-			return nil, ErrInternalServer
+		if signErr := file.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
 		}
-		return nil, ErrReqFormat
+	default:
+		return nil, errors.New("wrong info type parameter")
 	}
 
-	result, resultErr := switchType(infoType, res)
+	result, resultErr := c.transport.add(infoType, elem)
 	if resultErr != nil {
 		return nil, resultErr
 	}
 
+	c.indexElement(infoType, result, entry)
+
 	return result, nil
 }
 
 // EditElement provides edit element of selected type in service
 func (c *Client) EditElement(infoType string, elem interface{}, id uuid.UUID) (interface{}, error) {
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/info/%s/%s", c.baseURL, infoType, id))
-	req.Method("PATCH")
+	if writeErr := c.writable(); writeErr != nil {
+		return nil, writeErr
+	}
+	var entry IndexEntry
 	switch infoType {
 	case "cards":
 		card := elem.(*models.NewCard)
 		if checkErr := card.CheckValid(); checkErr != nil {
 			return nil, checkErr
 		}
+		entry.Title = card.Title
+		card.ID, card.Version = id, c.nextVersion(id, card.Version)
 		if cryptoErr := card.Encrypt(c.cryptorizer); cryptoErr != nil {
 			return nil, cryptoErr
 		}
-		req.Use(body.JSON(card))
+		if signErr := card.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
+		}
 	case "creds":
 		cred := elem.(*models.NewCred)
+		entry.Title = cred.Title
+		cred.ID, cred.Version = id, c.nextVersion(id, cred.Version)
 		if cryptoErr := cred.Encrypt(c.cryptorizer); cryptoErr != nil {
 			return nil, cryptoErr
 		}
-		req.Use(body.JSON(cred))
+		if signErr := cred.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
+		}
 	case "notes":
 		note := elem.(*models.NewNote)
+		entry.Title = note.Title
+		note.ID, note.Version = id, c.nextVersion(id, note.Version)
 		if cryptoErr := note.Encrypt(c.cryptorizer); cryptoErr != nil {
 			return nil, cryptoErr
 		}
-		req.Use(body.JSON(note))
+		if signErr := note.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
+		}
 	case "files":
 		file := elem.(*models.NewFile)
-		file.Encrypt(c.symCrypto)
-		req.Use(query.Set("title", file.Title))
-		req.Use(query.Set("filename", file.FileName))
-		req.Use(query.Set("notes", file.Notes))
-		f := io.NopCloser(bytes.NewBuffer(file.File))
-		req.Use(body.Reader(f))
-	default:
-		return nil, errors.New("wrong info type parameter")
-	}
-
-	res, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 400 {
-			return nil, ErrReqFormat
-		}
-		if res.StatusCode == 401 {
-			return nil, ErrToken
-		}
-		if res.StatusCode == 409 {
-			return nil, ErrNoData
+		entry.Title = file.Title
+		entry.FileName = file.FileName
+		file.ID, file.Version = id, c.nextVersion(id, file.Version)
+		if cryptoErr := file.Encrypt(c.cryptorizer, c.symCrypto); cryptoErr != nil {
+			return nil, cryptoErr
 		}
-		if res.StatusCode == 500 {
-			return nil, ErrInternalServer
+		if signErr := file.Sign(c.cryptorizer); signErr != nil {
+			return nil, signErr
 		}
-		return nil, ErrReqFormat
+	default:
+		return nil, errors.New("wrong info type parameter")
 	}
 
-	result, resultErr := switchType(infoType, res)
+	result, resultErr := c.transport.edit(infoType, elem, id)
 	if resultErr != nil {
 		return nil, resultErr
 	}
 
+	c.indexElement(infoType, result, entry)
+
 	return result, nil
 }
 
+// ReplaceVault replaces all elements in service in one transaction
+func (c *Client) ReplaceVault(vault *models.Vault) error {
+	if writeErr := c.writable(); writeErr != nil {
+		return writeErr
+	}
+	return c.replaceVault(vault)
+}
+
+// replaceVault replaces all elements without check of keys, used by keys rotation
+func (c *Client) replaceVault(vault *models.Vault) error {
+	return c.transport.replaceVault(vault)
+}
+
+// indexElement puts in client-side index metadata of added or changed element,
+// entry contains title and file name of element before encryption
+func (c *Client) indexElement(infoType string, elem interface{}, entry IndexEntry) {
+	entry.Type = infoType
+	switch value := elem.(type) {
+	case models.Note:
+		entry.ID, entry.Version, entry.Created, entry.Changed = value.ID, value.Version, value.Created, value.Changed.Get()
+	case models.Card:
+		entry.ID, entry.Version, entry.Created, entry.Changed = value.ID, value.Version, value.Created, value.Changed.Get()
+	case models.Cred:
+		entry.ID, entry.Version, entry.Created, entry.Changed = value.ID, value.Version, value.Created, value.Changed.Get()
+	case models.File:
+		entry.ID, entry.Version, entry.Created, entry.Changed = value.ID, value.Version, value.Created, value.Changed.Get()
+	default:
+		return
+	}
+	if indexErr := c.index.put(entry); indexErr != nil {
+		log.Println(indexErr)
+	}
+}
+
 // Delete removes element from service by selected type and id
 func (c *Client) Delete(infoType string, id uuid.UUID) (bool, error) {
-	req := c.client.Request()
-	req.URL(fmt.Sprintf("%s/info/%s/%s", c.baseURL, infoType, id))
-	req.Method("DELETE")
-	res, err := req.Send()
-	if err != nil {
-		return false, err
-	}
-	if !res.Ok {
-		if res.StatusCode == 401 {
-			return false, ErrToken
-		}
-		if res.StatusCode == 409 {
-			return false, ErrNoData
-		}
-		if res.StatusCode == 500 {
-			return false, ErrInternalServer
-		}
-		return false, ErrReqFormat
+	if removeErr := c.transport.remove(infoType, id); removeErr != nil {
+		return false, removeErr
 	}
 
-	if res.StatusCode != 200 {
-		return false, errors.New("something went wrong")
+	if indexErr := c.index.remove(id); indexErr != nil {
+		log.Println(indexErr)
 	}
 
 	return true, nil